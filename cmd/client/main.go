@@ -1,29 +1,31 @@
-// cmd/client/main.go – AVID‑FP Object Store client (v2.4.0, May 2025)
+// cmd/client/main.go – AVID‑FP Object Store client (v2.4.0, May 2025)
 // Supports either the old flag set (-peers -m -n) or a YAML file
-// loaded via -config, just like the server.
+// loaded via -config, just like the server. The work is done by
+// pkg/client; this is its command‑line front end.
 
 package main
 
 import (
-	"bytes"
 	"context"
-	"crypto/sha256"
+	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
-	"net"
 	"os"
-	"runtime"
+	"os/signal"
+	"path/filepath"
+	"sort"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/dattu/distributed_object_store/pkg/client"
+	"github.com/dattu/distributed_object_store/pkg/compression"
 	"github.com/dattu/distributed_object_store/pkg/config"
+	"github.com/dattu/distributed_object_store/pkg/envelope"
 	"github.com/dattu/distributed_object_store/pkg/erasure"
-	"github.com/dattu/distributed_object_store/pkg/fingerprint"
 	"github.com/dattu/distributed_object_store/pkg/protocol"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 /* -------------------------------------------------------------------- */
@@ -33,228 +35,418 @@ import (
 func main() {
 	/* -------- flags -------- */
 	cfgPath   := flag.String("config", "", "YAML config file (optional)")
-	mode      := flag.String("mode", "disperse", "disperse | retrieve")
+	mode      := flag.String("mode", "disperse", "disperse | retrieve | stat | versions | list | delete | transcode | keygen | members | add-node | remove-node | replace-node | drain")
 	filePath  := flag.String("file", "", "Path to input (disperse) or output (retrieve)")
 	objectID  := flag.String("id", "", "Unique object ID")
 	peersFlag := flag.String("peers", "", "Comma‑separated host:port list (override)")
 	mFlag     := flag.Int("m", 0, "data shards (override)")
 	nFlag     := flag.Int("n", 0, "total shards (override)")
+	codecFlag := flag.String("codec", "", "erasure codec for disperse / transcode: "+strings.Join(erasure.Names(), " | "))
+	nodeFlag  := flag.String("node", "", "host:port of the node to add / remove / replace / drain")
+	newFlag   := flag.String("replacement", "", "host:port of the replacement node (replace-node)")
+	labelFlag := flag.String("labels", "", "topology labels for add-node / replace-node, e.g. zone=a,rack=r1")
+	offFlag   := flag.Int64("offset", 0, "first object byte to retrieve")
+	lenFlag   := flag.Int64("length", 0, "bytes to retrieve from -offset (0 = to the end)")
+	encFlag   := flag.Bool("encrypt", false, "encrypt the object on the client before dispersing it")
+	keyFlag   := flag.String("key-file", "", "comma‑separated key files; the first encrypts, all decrypt (keygen writes -file)")
+	passFlag  := flag.String("passphrase-env", "", "environment variable holding an encryption passphrase")
+	compFlag  := flag.String("compress", "", "compress before dispersing, unless the input looks incompressible: "+strings.Join(compression.Names(), " | "))
+	dedupFlag := flag.Bool("dedup", false, "split the input into content‑defined chunks and only disperse those the cluster lacks")
+	prefFlag  := flag.String("prefix", "", "only list object IDs starting with this")
+	coordFlag := flag.String("coordinator", "", "host:port of a node to disperse / retrieve through; it encodes and verifies")
+	verFlag   := flag.String("version", "", "version ID to retrieve / stat, as -mode versions prints it (default the current one)")
+	noneFlag  := flag.Bool("if-none-match", false, "disperse only if the object does not exist yet")
+	matchFlag := flag.String("if-match", "", "disperse only if this version ID is the object's current version")
+	metaFlag  := flag.String("meta", "", "metadata to disperse with, e.g. content-type=text/plain,owner=ci")
+	tagsFlag  := flag.String("tags", "", "tags to disperse with, or that list requires, e.g. env=prod,team=a")
+	ttlFlag   := flag.Duration("ttl", 0, "disperse: the version expires this long after the write, e.g. 1h (default the servers' ttl and lifecycle rules)")
+	expFlag   := flag.String("expires", "", "disperse: the version expires at this RFC 3339 time")
+	keepFlag  := flag.Bool("retain", false, "disperse: the version never expires")
 	flag.Parse()
+	var expires time.Time
+	if *expFlag != "" {
+		t, err := time.Parse(time.RFC3339, *expFlag)
+		if err != nil {
+			log.Fatalf("-expires: %v", err)
+		}
+		expires = t
+	}
 
 	/* -------- load YAML if given -------- */
 	var (
-		peers []string
-		m, n  int
+		cc      client.Config
+		passEnv string
 	)
 	if *cfgPath != "" {
 		cfg, err := config.Load(*cfgPath)
 		if err != nil {
 			log.Fatalf("config: %v", err)
 		}
-		peers = append([]string{}, cfg.Cluster.Peers...)
-		m, n = cfg.Erasure.Data, cfg.Erasure.Total
+		// the passphrase is read below, once -passphrase-env had its say
+		passEnv, cfg.Encryption.PassphraseEnv = cfg.Encryption.PassphraseEnv, ""
+		if cc, err = client.FromConfig(cfg); err != nil {
+			log.Fatalf("config: %v", err)
+		}
 	}
 
 	/* -------- CLI overrides win -------- */
 	if *peersFlag != "" {
-		peers = strings.Split(*peersFlag, ",")
+		cc.Peers = strings.Split(*peersFlag, ",")
 	}
 	if *mFlag != 0 {
-		m = *mFlag
+		cc.Data = *mFlag
 	}
 	if *nFlag != 0 {
-		n = *nFlag
+		cc.Total = *nFlag
+	}
+	if *codecFlag != "" {
+		cc.Codec = *codecFlag
+	}
+	if *keyFlag != "" {
+		cc.KeyFiles = strings.Split(*keyFlag, ",")
 	}
+	if *passFlag != "" {
+		passEnv = *passFlag
+	}
+	if passEnv != "" {
+		if cc.Passphrase = os.Getenv(passEnv); cc.Passphrase == "" {
+			log.Fatalf("passphrase variable %s is empty", passEnv)
+		}
+	}
+	cc.Encrypt = cc.Encrypt || *encFlag
+	if *compFlag != "" {
+		cc.Compression = *compFlag
+	}
+	cc.Dedup = cc.Dedup || *dedupFlag
+	cc.Logf = func(format string, args ...any) { fmt.Printf(format+"\n", args...) }
 
-	/* -------- sanity checks -------- */
-	if *objectID == "" || *filePath == "" || len(peers) == 0 || m == 0 || n == 0 {
-		log.Fatalf("need -id, -file, and peers/m/n via flags or -config")
+	if *mode == "keygen" {
+		if *filePath == "" {
+			log.Fatalf("keygen needs -file")
+		}
+		kid, err := envelope.GenerateKey(*filePath)
+		if err != nil {
+			log.Fatalf("keygen: %v", err)
+		}
+		fmt.Printf("Wrote key %s to %q\n", kid, *filePath)
+		return
 	}
 
-	f := n - m
+	// Ctrl‑C cancels the operation in flight rather than killing it halfway
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	/* -------- thin client: a coordinator node does the work -------- */
+	if *coordFlag != "" {
+		if *encFlag || *keyFlag != "" || *passFlag != "" {
+			log.Fatalf("-coordinator: the node encrypts with its own keys, if its config says so")
+		}
+		opts := client.PutOptions{Codec: *codecFlag, Data: *mFlag, Total: *nFlag, Compression: *compFlag, Dedup: *dedupFlag,
+			IfNoneMatch: *noneFlag, IfMatchVersion: *matchFlag, Metadata: parseLabels(*metaFlag), Tags: parseLabels(*tagsFlag),
+			TTL: *ttlFlag, Expires: expires, Retain: *keepFlag}
+		coordinated(ctx, *coordFlag, cc.TLS, *mode, *objectID, *filePath, *verFlag, *offFlag, *lenFlag, opts)
+		return
+	}
+
+	if len(cc.Peers) == 0 {
+		log.Fatalf("need peers via -peers or -config")
+	}
+	c, err := client.New(cc)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer c.Close()
+
+	/* -------- membership admin -------- */
+	switch *mode {
+	case "members", "add-node", "remove-node", "replace-node", "drain":
+		admin(ctx, c, *mode, *nodeFlag, *newFlag, parseLabels(*labelFlag))
+		return
+	case "list":
+		list(ctx, c, *prefFlag, parseLabels(*tagsFlag))
+		return
+	}
+
+	/* -------- sanity checks -------- */
+	// m/n are only needed to write: readers take them from the object's FPCC
+	if *objectID == "" || ((*mode == "disperse" || *mode == "retrieve") && *filePath == "") {
+		log.Fatalf("need -id and -file")
+	}
+	if (*mode == "disperse" || *mode == "transcode") && (cc.Data == 0 || cc.Total == 0) {
+		log.Fatalf("%s needs m/n via flags or -config", *mode)
+	}
 
 	switch *mode {
 	case "disperse":
-		if pingPeers(peers) < 2*f {
-			log.Fatalf("quorum impossible: need ≥%d reachable peers", 2*f)
+		f, err := os.Open(*filePath)
+		if err != nil {
+			log.Fatalf("Open: %v", err)
+		}
+		defer f.Close()
+		opts := c.PutOptions()
+		opts.IfNoneMatch, opts.IfMatchVersion = *noneFlag, *matchFlag
+		opts.Metadata, opts.Tags = parseLabels(*metaFlag), parseLabels(*tagsFlag)
+		opts.TTL, opts.Expires, opts.Retain = *ttlFlag, expires, *keepFlag
+		info, err := c.Put(ctx, *objectID, f, &opts)
+		if err != nil {
+			fatal(err)
+		}
+		if info.Generation > 0 {
+			fmt.Printf("%q now at version %s (generation %d)\n", *objectID, info.VersionID(), info.Generation)
+		}
+	case "transcode":
+		info, err := c.Transcode(ctx, *objectID, cc.Codec, cc.Data, cc.Total)
+		if err != nil {
+			fatal(err)
 		}
-		disperse(peers, *filePath, *objectID, m, n)
+		fmt.Printf("Transcoded %q to %s %d‑of‑%d (generation %d)\n", *objectID, info.Codec, info.Data, info.Total, info.Generation)
 	case "retrieve":
-		retrieve(peers, *filePath, *objectID, m, n)
+		retrieve(ctx, c, *filePath, *objectID, *verFlag, *offFlag, *lenFlag)
+	case "versions":
+		vs, err := c.Versions(ctx, *objectID)
+		if err != nil {
+			fatal(err)
+		}
+		for _, v := range vs {
+			state := "current"
+			if !v.Superseded.IsZero() {
+				state = "superseded " + v.Superseded.Format(time.RFC3339)
+			}
+			fmt.Printf("%s\tgeneration %d\t%d bytes\tcreated %s\t%s\n", v.VersionID(), v.Generation, v.Size, v.Created.Format(time.RFC3339), state)
+		}
+	case "stat":
+		info, err := c.StatVersion(ctx, *objectID, *verFlag)
+		if err != nil {
+			fatal(err)
+		}
+		layers := ""
+		switch {
+		case info.Chunks > 0:
+			// the manifest's own fragments are empty: nothing else is stored under it
+			layers = fmt.Sprintf(", deduplicated into %d chunks", info.Chunks)
+		case info.KeyID != "":
+			layers = fmt.Sprintf(", encrypted with key %s", info.KeyID)
+		}
+		if info.Compression != "" {
+			layers = fmt.Sprintf(", %s‑compressed%s", info.Compression, layers)
+		}
+		if info.Size != info.Stored && info.Chunks == 0 {
+			layers += fmt.Sprintf(" (%d bytes stored)", info.Stored)
+		}
+		fmt.Printf("%s: %d bytes, %s %d‑of‑%d, generation %d, created %s%s\n", *objectID, info.Size, info.Codec, info.Data, info.Total,
+			info.Generation, info.Created.Format(time.RFC3339), layers)
+		switch {
+		case info.Retain:
+			fmt.Println("  retained: never expires")
+		case !info.Expires.IsZero():
+			fmt.Printf("  expires %s\n", info.Expires.Format(time.RFC3339))
+		}
+		for _, kv := range []struct {
+			name string
+			m    map[string]string
+		}{{"metadata", info.Metadata}, {"tags", info.Tags}} {
+			keys := make([]string, 0, len(kv.m))
+			for k := range kv.m {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Printf("  %s %s=%s\n", kv.name, k, kv.m[k])
+			}
+		}
+	case "delete":
+		if err := c.Delete(ctx, *objectID); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("Deleted %q\n", *objectID)
 	default:
-		log.Fatalf("unknown mode %q; must be disperse or retrieve", *mode)
+		log.Fatalf("unknown mode %q; see -h for the list of modes", *mode)
 	}
 }
 
-/* -------------------------------------------------------------------- */
-/* helpers: network / erasure                                            */
-/* -------------------------------------------------------------------- */
-
-func pingPeers(peers []string) int {
-	cnt := 0
-	for _, p := range peers {
-		if c, err := net.DialTimeout("tcp", p, 2*time.Second); err == nil {
-			cnt++
-			c.Close()
-		}
+// fatal reports err, with a hint where a flag would fix it.
+func fatal(err error) {
+	if errors.Is(err, envelope.ErrNoKey) {
+		log.Fatalf("%v; pass -key-file or -passphrase-env", err)
 	}
-	return cnt
+	log.Fatal(err)
 }
 
-func disperse(servers []string, path, id string, m, n int) {
-	data, err := os.ReadFile(path)
+// getter is what retrieve reads through: a Client, or a Coordinator.
+type getter interface {
+	GetVersion(ctx context.Context, id, version string, w io.Writer, off, length int64) (*client.ObjectInfo, error)
+}
+
+// retrieve decodes version of id ("" = the current one), or bytes
+// [off, off+length) of it, into a temporary
+// file next to out and renames it into place once every fragment it used
+// has verified – and, for an encrypted object, every segment has
+// authenticated.
+func retrieve(ctx context.Context, c getter, out, id, version string, off, length int64) {
+	tmp, err := os.CreateTemp(filepath.Dir(out), "."+filepath.Base(out)+".*")
 	if err != nil {
-		log.Fatalf("ReadFile: %v", err)
+		log.Fatalf("CreateTemp: %v", err)
 	}
-	enc, err := erasure.New(m, n)
+	defer os.Remove(tmp.Name()) // no-op after the rename
+	ranged := off != 0 || length != 0
+	info, err := c.GetVersion(ctx, id, version, tmp, off, length)
 	if err != nil {
-		log.Fatalf("erasure.New: %v", err)
+		tmp.Close()
+		os.Remove(tmp.Name())
+		fatal(err)
 	}
-	shards, _, err := enc.Encode(data)
-	if err != nil {
-		log.Fatalf("Encode: %v", err)
+	if err := tmp.Chmod(0644); err != nil {
+		log.Fatalf("Chmod: %v", err)
 	}
-
-	fpGen, _ := fingerprint.NewRandom()
-	hashes := make([][]byte, n)
-	fps := make([]uint64, n)
-	var wg sync.WaitGroup
-	sem := make(chan struct{}, runtime.NumCPU())
-	for i, sh := range shards {
-		i, sh := i, sh
-		wg.Add(1)
-		sem <- struct{}{}
-		go func() {
-			defer func() { <-sem; wg.Done() }()
-			h := sha256.Sum256(sh)
-			hashes[i] = h[:]
-			fps[i] = fpGen.Eval(sh)
-		}()
-	}
-	wg.Wait()
-
-	fpcc := &protocol.FPCC{Hashes: hashes, Fps: fps, Seed: fpGen.Seed()}
-
-	for i, shard := range shards {
-		req := &protocol.DisperseRequest{
-			ObjectId:       id,
-			FragmentIndex:  uint32(i),
-			Fragment:       shard,
-			Fpcc:           fpcc,
-		}
-		var wgSend sync.WaitGroup
-		wgSend.Add(len(servers))
-		for _, addr := range servers {
-			go func(a string) { defer wgSend.Done(); fanOutShard(a, req) }(strings.TrimSpace(addr))
-		}
-		wgSend.Wait()
-		fmt.Printf("Shard %d/%d dispersed\n", i+1, n)
+	if err := tmp.Close(); err != nil {
+		log.Fatalf("Close: %v", err)
 	}
-	fmt.Printf("Disperse complete for %q\n", id)
-}
-
-func fanOutShard(addr string, req *protocol.DisperseRequest) {
-	for attempt := 1; attempt <= 3; attempt++ {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		conn, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
-		cancel()
-		if err != nil {
-			log.Printf("dial %s failed (%d/3): %v", addr, attempt, err)
-			time.Sleep(2 * time.Second)
-			continue
-		}
-		c := protocol.NewDispersalClient(conn)
-		rCtx, rCancel := context.WithTimeout(context.Background(), 30*time.Second)
-		resp, err := c.Disperse(rCtx, req)
-		rCancel()
-		conn.Close()
-		if err != nil || !resp.Ok {
-			log.Printf("disperse to %s failed (%d/3): %v / %s", addr, attempt, err, resp.GetError())
-			time.Sleep(2 * time.Second)
-			continue
+	if err := os.Rename(tmp.Name(), out); err != nil {
+		log.Fatalf("Rename: %v", err)
+	}
+	if ranged {
+		if length == 0 {
+			length = info.Size - off
 		}
+		fmt.Printf("Retrieved bytes %d–%d of %q → %q\n", off, off+length, id, out)
 		return
 	}
-	log.Fatalf("shard %d → %s failed after 3 attempts", req.FragmentIndex, addr)
+	fmt.Printf("Retrieved %q → %q\n", id, out)
 }
 
-func retrieve(servers []string, out, id string, m, n int) {
-	ctx := context.Background()
-	connPool := make(map[string]*grpc.ClientConn)
-	clientFor := func(addr string) (protocol.DispersalClient, error) {
-		if c, ok := connPool[addr]; ok {
-			return protocol.NewDispersalClient(c), nil
+// coordinated runs disperse or retrieve through the node at addr, which
+// encodes, fingerprints and disperses, or collects and verifies, for us.
+// Flags left unset take the node's config.
+func coordinated(ctx context.Context, addr string, tlsConf *tls.Config, mode, id, file, version string, off, length int64, opts client.PutOptions) {
+	if id == "" || file == "" {
+		log.Fatalf("need -id and -file")
+	}
+	co := client.NewCoordinator(addr, 0, tlsConf)
+	defer co.Close()
+	switch mode {
+	case "disperse":
+		f, err := os.Open(file)
+		if err != nil {
+			log.Fatalf("Open: %v", err)
 		}
-		c, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock(), grpc.WithTimeout(15*time.Second))
+		defer f.Close()
+		info, err := co.Put(ctx, id, f, &opts)
 		if err != nil {
-			return nil, err
+			fatal(err)
 		}
-		connPool[addr] = c
-		return protocol.NewDispersalClient(c), nil
+		fmt.Printf("Disperse complete for %q via %s (%d bytes, %s %d‑of‑%d)\n", id, addr, info.Size, info.Codec, info.Data, info.Total)
+	case "retrieve":
+		retrieve(ctx, co, file, id, version, off, length)
+	default:
+		log.Fatalf("-coordinator only supports -mode disperse and retrieve")
 	}
+}
 
-	// 1) fetch FPCC + shard‑0
-	var fpcc *protocol.FPCC
-	shards := make([][]byte, n)
-	var fpGen *fingerprint.Fingerprint
-	for _, addr := range servers {
-		client, err := clientFor(strings.TrimSpace(addr))
+// list prints every object whose ID starts with prefix and that carries
+// tags, a page at a time.
+func list(ctx context.Context, c *client.Client, prefix string, tags map[string]string) {
+	for after := ""; ; {
+		objs, next, err := c.List(ctx, client.ListOptions{Prefix: prefix, StartAfter: after, Tags: tags})
 		if err != nil {
-			continue
+			fatal(err)
 		}
-		r0, err := client.Retrieve(ctx, &protocol.RetrieveRequest{ObjectId: id, FragmentIndex: 0})
-		if err != nil || !r0.Ok {
-			continue
+		for _, o := range objs {
+			fmt.Printf("%s\t%d bytes\tgeneration %d\t%s\n", o.ID, o.Size, o.Generation, o.Created.Format(time.RFC3339))
 		}
-		// verify shard‑0
-		h0 := sha256.Sum256(r0.Fragment)
-		if !bytes.Equal(h0[:], r0.Fpcc.Hashes[0]) ||
-			fingerprint.NewWithSeed(r0.Fpcc.Seed).Eval(r0.Fragment) != r0.Fpcc.Fps[0] {
-			continue
+		if next == "" {
+			return
 		}
-		fpcc = r0.Fpcc
-		fpGen = fingerprint.NewWithSeed(fpcc.Seed)
-		shards[0] = r0.Fragment
-		break
-	}
-	if fpcc == nil {
-		log.Fatalf("failed to retrieve a valid shard‑0 from any peer")
+		after = next
 	}
+}
 
-	// 2) fetch other shards
-	received := 1
-	for idx := 1; idx < n && received < m; idx++ {
-		for _, addr := range servers {
-			client, err := clientFor(strings.TrimSpace(addr))
-			if err != nil {
-				continue
-			}
-			r, err := client.Retrieve(ctx, &protocol.RetrieveRequest{ObjectId: id, FragmentIndex: uint32(idx)})
-			if err != nil || !r.Ok {
-				continue
-			}
-			h := sha256.Sum256(r.Fragment)
-			if !bytes.Equal(h[:], fpcc.Hashes[idx]) || fpGen.Eval(r.Fragment) != fpcc.Fps[idx] {
-				continue
-			}
-			shards[idx] = r.Fragment
-			received++
-			break
-		}
+/* -------------------------------------------------------------------- */
+/* helpers: membership                                                   */
+/* -------------------------------------------------------------------- */
+
+// parseLabels turns "k1=v1,k2=v2" – labels, metadata or tags – into a
+// map; nil when s is empty.
+func parseLabels(s string) map[string]string {
+	if s == "" {
+		return nil
 	}
-	if received < m {
-		log.Fatalf("only %d/%d good shards; cannot decode", received, m)
+	out := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			log.Fatalf("bad pair %q; want key=value", kv)
+		}
+		out[strings.TrimSpace(k)] = strings.TrimSpace(v)
 	}
+	return out
+}
 
-	// 3) decode and write file
-	enc, _ := erasure.New(m, n)
-	raw, err := enc.Decode(shards, len(shards[0])*m)
+// admin runs a membership command and prints the resulting view.
+func admin(ctx context.Context, c *client.Client, mode, node, replacement string, labels map[string]string) {
+	if mode != "members" && node == "" {
+		log.Fatalf("%s needs -node", mode)
+	}
+	if mode == "replace-node" && replacement == "" {
+		log.Fatalf("replace-node needs -replacement")
+	}
+	var (
+		view *protocol.View
+		err  error
+	)
+	switch mode {
+	case "members":
+		view, err = c.Members(ctx)
+	case "add-node":
+		view, err = c.AddNode(ctx, node, labels)
+	case "remove-node":
+		view, err = c.RemoveNode(ctx, node)
+	case "replace-node":
+		view, err = c.ReplaceNode(ctx, node, replacement, labels)
+	case "drain":
+		view, err = c.Drain(ctx, node)
+	}
 	if err != nil {
-		log.Fatalf("Decode: %v", err)
+		log.Fatal(err)
+	}
+	fmt.Printf("epoch %d\n", view.Epoch)
+	for _, mem := range view.Members {
+		line := "  " + mem.Addr
+		if mem.State != protocol.MemberState_ACTIVE {
+			line += " (" + strings.ToLower(mem.State.String()) + ")"
+		}
+		keys := make([]string, 0, len(mem.Labels))
+		for k := range mem.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			line += " " + k + "=" + mem.Labels[k]
+		}
+		fmt.Println(line)
 	}
-	data := bytes.TrimRight(raw, "\x00")
-	if err := os.WriteFile(out, data, 0644); err != nil {
-		log.Fatalf("WriteFile: %v", err)
+	if mode == "drain" {
+		watchDrain(ctx, c, node)
+	}
+}
+
+// watchDrain polls the draining node until all of its objects are back at
+// full redundancy on the remaining nodes.
+func watchDrain(ctx context.Context, c *client.Client, node string) {
+	for {
+		st, err := c.DrainStatus(ctx, node)
+		switch {
+		case errors.Is(err, client.ErrRejected):
+			log.Fatal(err)
+		case ctx.Err() != nil:
+			log.Fatal(ctx.Err())
+		case err != nil:
+			log.Printf("%v", err)
+		case st.SafeToRemove:
+			fmt.Printf("%s: %d/%d objects re-homed — safe to remove (-mode remove-node -node %s)\n", node, st.Rehomed, st.Objects, node)
+			return
+		default:
+			fmt.Printf("%s: %d/%d objects re-homed\n", node, st.Rehomed, st.Objects)
+		}
+		time.Sleep(2 * time.Second)
 	}
-	fmt.Printf("Retrieved %q → %q\n", id, out)
 }
@@ -0,0 +1,123 @@
+// cmd/s3gw/main.go – S3 REST gateway for the AVID‑FP Object Store.
+// Serves PutObject, GetObject, HeadObject, DeleteObject, ListObjectsV2
+// and multipart uploads over HTTP, authenticated with SigV4 against a
+// local credentials file, and stores objects through pkg/client, so
+// `aws s3 cp` and the AWS SDKs work against the cluster. Takes the same
+// YAML config as the client plus its s3: section.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/client"
+	"github.com/dattu/distributed_object_store/pkg/config"
+	"github.com/dattu/distributed_object_store/pkg/s3"
+	"github.com/dattu/distributed_object_store/pkg/sigv4"
+)
+
+func main() {
+	/* -------- flags -------- */
+	cfgPath   := flag.String("config", "", "YAML config file (optional)")
+	listen    := flag.String("listen", "", "HTTP listen address (override)")
+	credsFlag := flag.String("credentials", "", "AWS‑style credentials file (override)")
+	region    := flag.String("region", "", "region clients sign for (override)")
+	buckets   := flag.String("buckets", "", "comma‑separated buckets to serve (override)")
+	domain    := flag.String("domain", "", "serve <bucket>.<domain> virtual‑hosted‑style (override)")
+	peersFlag := flag.String("peers", "", "comma‑separated host:port list (override)")
+	mFlag     := flag.Int("m", 0, "data shards (override)")
+	nFlag     := flag.Int("n", 0, "total shards (override)")
+	flag.Parse()
+
+	/* -------- config, then CLI overrides -------- */
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	cc, err := client.FromConfig(cfg)
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	if *peersFlag != "" {
+		cc.Peers = strings.Split(*peersFlag, ",")
+	}
+	if *mFlag != 0 {
+		cc.Data = *mFlag
+	}
+	if *nFlag != 0 {
+		cc.Total = *nFlag
+	}
+	sc := cfg.S3
+	if *listen != "" {
+		sc.Listen = *listen
+	}
+	if *credsFlag != "" {
+		sc.Credentials = *credsFlag
+	}
+	if *region != "" {
+		sc.Region = *region
+	}
+	if *buckets != "" {
+		sc.Buckets = strings.Split(*buckets, ",")
+	}
+	if *domain != "" {
+		sc.Domain = *domain
+	}
+	if len(cc.Peers) == 0 {
+		log.Fatalf("need peers via -peers or -config")
+	}
+	if sc.Credentials == "" {
+		log.Fatalf("need a credentials file via -credentials or s3.credentials")
+	}
+	creds, err := sigv4.LoadCredentials(sc.Credentials)
+	if err != nil {
+		log.Fatalf("credentials: %v", err)
+	}
+
+	/* -------- gateway -------- */
+	cc.Logf = log.Printf
+	c, err := client.New(cc)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer c.Close()
+	gw, err := s3.New(c, s3.Config{
+		Buckets:  sc.Buckets,
+		Region:   sc.Region,
+		Domain:   sc.Domain,
+		Verifier: &sigv4.Verifier{Creds: creds, Region: sc.Region},
+		Logf:     log.Printf,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := &http.Server{
+		Addr:              sc.Listen,
+		Handler:           gw,
+		ReadHeaderTimeout: 30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	// SIGINT / SIGTERM: stop accepting, let requests in flight finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shut, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		srv.Shutdown(shut)
+	}()
+	log.Printf("S3 gateway on %s for buckets %v (%d peers, %d‑of‑%d)", sc.Listen, sc.Buckets, len(cc.Peers), cc.Data, cc.Total)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
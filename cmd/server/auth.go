@@ -0,0 +1,107 @@
+// cmd/server/auth.go – who sent a peer RPC.
+// Echo / Ready quorums count distinct senders, so a sender has to be the
+// node it claims to be, not merely some member of the object's group. With
+// TLS on (config tls) every node presents a certificate whose common name
+// is its host:port as the view lists it, and that name is the sender.
+// Without TLS the only evidence is the connection: the claimed address must
+// resolve to the IP the request came from. That keeps a node from speaking
+// for nodes on other hosts, but not for others on its own.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+
+	"github.com/dattu/distributed_object_store/pkg/membership"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+)
+
+// transportCreds secures every gRPC connection a node makes or accepts;
+// main switches it to TLS when the config has a CA.
+var transportCreds = insecure.NewCredentials()
+
+// useTLS makes the node serve and dial with tc, which must hold its
+// certificate.
+func useTLS(tc *tls.Config) error {
+	if len(tc.Certificates) == 0 {
+		return errors.New("tls.cert_file is required on a node")
+	}
+	transportCreds = credentials.NewTLS(tc)
+	return nil
+}
+
+func serverOpts() []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.Creds(transportCreds)}
+}
+
+// sender authenticates claimed, the node an RPC says it comes from, against
+// the connection it came over.
+func (s *server) sender(ctx context.Context, claimed string) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", errors.New("no peer on the connection")
+	}
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		chains := info.State.VerifiedChains
+		if len(chains) == 0 || len(chains[0]) == 0 {
+			return "", errors.New("peer presented no certificate")
+		}
+		if name := chains[0][0].Subject.CommonName; name != claimed {
+			return "", fmt.Errorf("sender %q presented a certificate for %q", claimed, name)
+		}
+		return claimed, nil
+	}
+	// members of the previous view too: one just removed hands its
+	// fragments on (see Handoff)
+	s.mu.Lock()
+	known := membership.Contains(s.view, claimed) || membership.Contains(s.prevView, claimed)
+	s.mu.Unlock()
+	if !known {
+		return "", fmt.Errorf("sender %q is not a member", claimed)
+	}
+	tcp, ok := p.Addr.(*net.TCPAddr)
+	if !ok {
+		return "", fmt.Errorf("peer address %s is not TCP", p.Addr)
+	}
+	ips, err := s.resolve(ctx, claimed)
+	if err != nil {
+		return "", err
+	}
+	if !slices.ContainsFunc(ips, tcp.IP.Equal) {
+		return "", fmt.Errorf("sender %s does not resolve to %s", claimed, tcp.IP)
+	}
+	return claimed, nil
+}
+
+// resolve returns the IPs of addr's host, cached until the view changes.
+func (s *server) resolve(ctx context.Context, addr string) ([]net.IP, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	ips, ok := s.resolved[host]
+	s.mu.Unlock()
+	if ok {
+		return ips, nil
+	}
+	found, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range found {
+		ips = append(ips, a.IP)
+	}
+	s.mu.Lock()
+	s.resolved[host] = ips
+	s.mu.Unlock()
+	return ips, nil
+}
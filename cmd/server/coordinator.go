@@ -0,0 +1,39 @@
+// cmd/server/coordinator.go – coordinator mode. Any node accepts whole
+// objects over PutObject and serves them over GetObject, doing for thin
+// clients what cmd/client does: erasure coding, FPCC generation and
+// dispersal to the owners (itself among them) on the way in, collecting
+// and verifying m fragments on the way out. The node's own pkg/client
+// Client does the work, configured from the node's YAML.
+
+package main
+
+import (
+	"log"
+
+	"github.com/dattu/distributed_object_store/pkg/client"
+	"github.com/dattu/distributed_object_store/pkg/config"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+// newCoordinator returns the Client a node coordinates with. It starts
+// from peers, or the node itself, and follows the cluster's view from
+// there.
+func newCoordinator(cfg *config.Config, peers []string, self string) (*client.Client, error) {
+	cc, err := client.FromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cc.Peers = peers; len(peers) == 0 {
+		cc.Peers = []string{self}
+	}
+	cc.Logf = log.Printf
+	return client.New(cc)
+}
+
+func (s *server) PutObject(stream protocol.Dispersal_PutObjectServer) error {
+	return s.coord.ServePutObject(stream)
+}
+
+func (s *server) GetObject(req *protocol.GetObjectRequest, stream protocol.Dispersal_GetObjectServer) error {
+	return s.coord.ServeGetObject(req, stream)
+}
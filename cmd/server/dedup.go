@@ -0,0 +1,140 @@
+// cmd/server/dedup.go – chunk references for deduplicated objects.
+// A deduplicated object is a manifest: an FPCC listing content‑addressed
+// chunks, each stored once as an ordinary object under chunker.ID. Chunks are
+// shared, so they cannot simply expire with their own TTL. Every node keeps
+// the references committed manifests hold on each chunk in refsBucket, and a
+// chunk past its TTL is only collected once no live manifest refers to it.
+// The chunks a manifest lists are placed on other groups than the manifest,
+// so manifests announce their commit to every node (see broadcastReady).
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/chunker"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+	bolt "go.etcd.io/bbolt"
+)
+
+// chunkGrace is how long HasChunks promises a chunk it reports held will
+// survive – enough for the client to finish dispersing the rest of the
+// object and commit its manifest.
+const chunkGrace = 10 * time.Minute
+
+// refKey names the reference manifest holds on chunk. A chunk's references
+// share its ID as a prefix.
+func refKey(chunk, manifest string) []byte {
+	return []byte(chunk + "|" + manifest)
+}
+
+// addRefs records the references of a manifest that has just committed,
+// valid until the manifest expires. A manifest committed again under the
+// same ID renews them.
+func (s *server) addRefs(obj string, fpcc *protocol.FPCC) {
+	until := make([]byte, 8)
+	binary.BigEndian.PutUint64(until, uint64(s.expiry(obj, fpcc, time.Now()).Unix()))
+	_ = s.metaDB.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(refsBucket))
+		for _, c := range fpcc.GetManifest().GetChunks() {
+			if err := b.Put(refKey(chunker.ID(c.Hash), obj), until); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// dropRefs deletes the references of a manifest that is going away.
+func dropRefs(tx *bolt.Tx, obj string, man *protocol.Manifest) {
+	b := tx.Bucket([]byte(refsBucket))
+	for _, c := range man.GetChunks() {
+		b.Delete(refKey(chunker.ID(c.Hash), obj))
+	}
+}
+
+// sweepRefs deletes the references that have lapsed and returns, for every
+// chunk still referenced, when its last reference lapses, along with the
+// manifests whose references ran out.
+func (s *server) sweepRefs(now time.Time) (map[string]time.Time, []string) {
+	live := make(map[string]time.Time)
+	released := make(map[string]bool)
+	_ = s.metaDB.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(refsBucket))
+		var stale [][]byte
+		b.ForEach(func(k, v []byte) error {
+			chunk, manifest, _ := strings.Cut(string(k), "|")
+			until := refUntil(v)
+			if until.Before(now) {
+				stale = append(stale, bytes.Clone(k))
+				released[manifest] = true
+				return nil
+			}
+			if until.After(live[chunk]) {
+				live[chunk] = until
+			}
+			return nil
+		})
+		for _, k := range stale {
+			b.Delete(k)
+		}
+		return nil
+	})
+	out := make([]string, 0, len(released))
+	for manifest := range released {
+		out = append(out, manifest)
+	}
+	return live, out
+}
+
+func refUntil(v []byte) time.Time {
+	if len(v) != 8 {
+		return time.Time{}
+	}
+	return time.Unix(int64(binary.BigEndian.Uint64(v)), 0)
+}
+
+// chunkExpiry is when chunk becomes collectable: the end of its own TTL or
+// of its last reference, whichever is later.
+func (s *server) chunkExpiry(tx *bolt.Tx, chunk string) time.Time {
+	var exp time.Time
+	if meta, ok := s.getMeta(tx, chunk); ok {
+		exp = meta.Created.Add(s.ttl)
+	}
+	prefix := []byte(chunk + "|")
+	c := tx.Bucket([]byte(refsBucket)).Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		if until := refUntil(v); until.After(exp) {
+			exp = until
+		}
+	}
+	return exp
+}
+
+/* --- HasChunks --- */
+
+// HasChunks tells a deduplicating client which chunks it can reference
+// instead of dispersing them again: those this node saw commit and that
+// will outlive chunkGrace.
+func (s *server) HasChunks(ctx context.Context, req *protocol.HasChunksRequest) (*protocol.HasChunksResponse, error) {
+	horizon := time.Now().Add(chunkGrace)
+	held := make([]bool, len(req.Hashes))
+	s.mu.Lock()
+	for i, h := range req.Hashes {
+		held[i] = s.committedLocked(chunker.ID(h))
+	}
+	s.mu.Unlock()
+	// s.mu is not held across the transaction: disperse takes them the
+	// other way round
+	_ = s.metaDB.View(func(tx *bolt.Tx) error {
+		for i, h := range req.Hashes {
+			held[i] = held[i] && s.chunkExpiry(tx, chunker.ID(h)).After(horizon)
+		}
+		return nil
+	})
+	return &protocol.HasChunksResponse{Ok: true, Held: held}, nil
+}
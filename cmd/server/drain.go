@@ -0,0 +1,599 @@
+// cmd/server/drain.go – drain / decommission workflow for the AVID‑FP store.
+// A draining node is read‑only: it refuses new fragments and re‑homes every
+// fragment it holds to the node placement now assigns it to, rebuilding any
+// fragment the cluster has lost from m surviving ones on the way.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/fingerprint"
+	"github.com/dattu/distributed_object_store/pkg/membership"
+	"github.com/dattu/distributed_object_store/pkg/placement"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	handoffTimeout     = 30 * time.Second
+	vouchTimeout       = 5 * time.Second
+	drainRetryInterval = 10 * time.Second
+)
+
+// drainProgress is the state reported by DrainStatus; guarded by server.mu.
+type drainProgress struct {
+	active  bool
+	objects int
+	rehomed int
+	safe    bool
+}
+
+/* ------------------------------------------------------------------------ */
+/* RPC – Handoff, Locate, DrainStatus                                       */
+/* ------------------------------------------------------------------------ */
+
+// Handoff stores a fragment another member moves here, of any generation
+// it holds: the current one, a noncurrent version or a round still in
+// progress. The sender is one node, so neither its FPCC nor its say‑so
+// about that FPCC is taken on trust: the fragment must be one placement
+// gives this node, and an FPCC this node does not already hold must be
+// vouched for by f+1 members (see vouch).
+func (s *server) Handoff(ctx context.Context, req *protocol.HandoffRequest) (*protocol.HandoffResponse, error) {
+	sender, err := s.sender(ctx, req.Sender)
+	s.mu.Lock()
+	// a member just removed or replaced still hands its fragments on
+	member := membership.Contains(s.view, sender) || membership.Contains(s.prevView, sender)
+	cur, pending := s.fpccs[req.ObjectId], s.pending[req.ObjectId]
+	view := s.view
+	s.mu.Unlock()
+	badID := protocol.CheckID(req.ObjectId)
+	gen := req.Fpcc.GetGeneration()
+
+	switch {
+	case err != nil:
+		return &protocol.HandoffResponse{Ok: false, Error: err.Error()}, nil
+	case badID != nil:
+		return &protocol.HandoffResponse{Ok: false, Error: badID.Error()}, nil
+	case !member:
+		return &protocol.HandoffResponse{Ok: false, Error: "sender not in the current or previous view"}, nil
+	case !validFPCC(req.Fpcc) || int(req.FragmentIndex) >= len(req.Fpcc.Hashes):
+		return &protocol.HandoffResponse{Ok: false, Error: "bad fragment index"}, nil
+	case cur != nil && gen == cur.GetGeneration() && !eqFPCC(cur, req.Fpcc):
+		return &protocol.HandoffResponse{Ok: false, Error: "FPCC mismatch"}, nil
+	}
+	m, n := s.profileOf(req.Fpcc)
+	if owners, err := placement.ForView(view, req.ObjectId, m, n); err != nil || owners[req.FragmentIndex] != s.selfAddr {
+		return &protocol.HandoffResponse{Ok: false, Error: fmt.Sprintf("fragment %d is not placed on %s in epoch %d", req.FragmentIndex, s.selfAddr, view.Epoch)}, nil
+	}
+	if !fragmentMatches(req.Fpcc, req.FragmentIndex, req.Fragment) {
+		return &protocol.HandoffResponse{Ok: false, Error: "fragment does not match FPCC"}, nil
+	}
+
+	// a generation this node already holds only needs the fragment;
+	// anything else is filed as the members say it stands
+	adopt := func() error { return nil }
+	known := (cur != nil && eqFPCC(cur, req.Fpcc)) || (pending != nil && eqFPCC(pending, req.Fpcc))
+	if v := s.versionFPCC(req.ObjectId, gen); !known && v != nil {
+		if !eqFPCC(v, req.Fpcc) {
+			return &protocol.HandoffResponse{Ok: false, Error: "FPCC mismatch"}, nil
+		}
+		known = true
+	}
+	if !known {
+		v := s.vouch(req.ObjectId, req.Fpcc)
+		switch {
+		case v.superseded >= v.need:
+			adopt = func() error { return s.adoptVersion(req.ObjectId, req.Fpcc, v.supersededAt) }
+		case v.committed >= v.need && cur != nil && gen < cur.GetGeneration():
+			return &protocol.HandoffResponse{Ok: false, Error: "stale generation"}, nil
+		case v.committed >= v.need && cur == nil:
+			adopt = func() error { s.adoptFPCC(req.ObjectId, req.Fpcc); return nil }
+		case v.committed >= v.need:
+			adopt = func() error { s.promote(req.ObjectId, req.Fpcc); return nil } // sender saw the commit first
+		case v.committed+v.open >= v.need && (cur == nil || gen > cur.GetGeneration()):
+			adopt = func() error { return s.adoptRound(req.ObjectId, req.Fpcc) }
+		default:
+			return &protocol.HandoffResponse{Ok: false, Error: "FPCC not vouched for by f+1 members"}, nil
+		}
+	}
+	if err := adopt(); err != nil {
+		return &protocol.HandoffResponse{Ok: false, Error: err.Error()}, nil
+	}
+	if err := s.persistFragment(req.ObjectId, gen, req.FragmentIndex, req.Fragment); err != nil {
+		return &protocol.HandoffResponse{Ok: false, Error: "fragment write"}, nil
+	}
+	log.Printf("[Handoff] %s gen=%d idx=%d from %s", req.ObjectId, gen, req.FragmentIndex, req.Sender)
+	return &protocol.HandoffResponse{Ok: true}, nil
+}
+
+func (s *server) Locate(ctx context.Context, req *protocol.LocateRequest) (*protocol.LocateResponse, error) {
+	if err := protocol.CheckID(req.ObjectId); err != nil {
+		return &protocol.LocateResponse{Ok: false, Error: err.Error()}, nil
+	}
+	fpcc := s.generationFPCC(req.ObjectId, req.Generation)
+	if fpcc == nil {
+		return &protocol.LocateResponse{Ok: true}, nil
+	}
+	return &protocol.LocateResponse{Ok: true, FragmentIndices: s.heldIndices(req.ObjectId, fpcc)}, nil
+}
+
+func (s *server) DrainStatus(ctx context.Context, req *protocol.DrainRequest) (*protocol.DrainStatusResponse, error) {
+	if req.Addr != "" && req.Addr != s.selfAddr {
+		return &protocol.DrainStatusResponse{Ok: false, Error: fmt.Sprintf("ask %s directly", req.Addr)}, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &protocol.DrainStatusResponse{
+		Ok:           true,
+		Draining:     membership.IsDraining(s.view, s.selfAddr),
+		Objects:      uint32(s.drain.objects),
+		Rehomed:      uint32(s.drain.rehomed),
+		SafeToRemove: s.drain.safe,
+	}, nil
+}
+
+/* ------------------------------------------------------------------------ */
+/* drain loop                                                               */
+/* ------------------------------------------------------------------------ */
+
+// maybeStartDrain kicks off the drain loop once this node is marked draining.
+func (s *server) maybeStartDrain() {
+	s.mu.Lock()
+	start := membership.IsDraining(s.view, s.selfAddr) && !s.drain.active
+	if start {
+		s.drain = drainProgress{active: true}
+	}
+	s.mu.Unlock()
+	if start {
+		go s.drainLoop()
+	}
+}
+
+func (s *server) drainLoop() {
+	log.Printf("drain: %s is read-only, re-homing fragments", s.selfAddr)
+	for {
+		objs := s.localObjects()
+		s.mu.Lock()
+		s.drain.objects, s.drain.rehomed = len(objs), 0
+		s.mu.Unlock()
+
+		for _, obj := range objs {
+			if err := s.rehome(obj); err != nil {
+				log.Printf("drain: %s: %v", obj, err)
+				continue
+			}
+			s.mu.Lock()
+			s.drain.rehomed++
+			s.mu.Unlock()
+		}
+
+		s.mu.Lock()
+		s.drain.safe = s.drain.rehomed == s.drain.objects
+		safe, still := s.drain.safe, membership.IsDraining(s.view, s.selfAddr)
+		if safe || !still {
+			s.drain.active = false
+		}
+		s.mu.Unlock()
+		if safe {
+			log.Printf("drain: %s re-homed %d objects, safe to remove", s.selfAddr, len(objs))
+			return
+		}
+		if !still {
+			return
+		}
+		time.Sleep(drainRetryInterval)
+	}
+}
+
+// localObjects lists the objects with at least one fragment on this node,
+// of any generation.
+func (s *server) localObjects() []string {
+	s.mu.Lock()
+	objs := make(map[string]bool, len(s.fpccs))
+	for _, fpccs := range []map[string]*protocol.FPCC{s.fpccs, s.pending} {
+		for obj := range fpccs {
+			objs[obj] = true
+		}
+	}
+	s.mu.Unlock()
+
+	var out []string
+	for obj := range objs {
+		if len(s.heldGenerations(obj)) > 0 {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+// heldGenerations returns the FPCC of every generation of obj this node
+// holds fragments of: the current one first, then a round in progress and
+// the noncurrent versions.
+func (s *server) heldGenerations(obj string) []*protocol.FPCC {
+	var out []*protocol.FPCC
+	s.mu.Lock()
+	for _, fpcc := range []*protocol.FPCC{s.fpccs[obj], s.pending[obj]} {
+		if fpcc != nil {
+			out = append(out, fpcc)
+		}
+	}
+	s.mu.Unlock()
+	_ = s.metaDB.View(func(tx *bolt.Tx) error {
+		for _, v := range s.versions(tx, obj) {
+			out = append(out, v.Fpcc)
+		}
+		return nil
+	})
+	return slices.DeleteFunc(out, func(fpcc *protocol.FPCC) bool { return len(s.heldIndices(obj, fpcc)) == 0 })
+}
+
+// profiles returns the node's default m‑of‑n and the distinct ones of the
+// objects it holds, as profileOf sizes their placement.
+func (s *server) profiles() [][2]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := [][2]int{{s.m, s.n}}
+	for _, fpccs := range []map[string]*protocol.FPCC{s.fpccs, s.pending} {
+		for _, fpcc := range fpccs {
+			if m, n := s.profileOf(fpcc); !slices.Contains(out, [2]int{m, n}) {
+				out = append(out, [2]int{m, n})
+			}
+		}
+	}
+	return out
+}
+
+// rehome makes sure every fragment of every generation of obj this node
+// holds sits on the writable node placement assigns it to.
+func (s *server) rehome(obj string) error {
+	for _, fpcc := range s.heldGenerations(obj) {
+		if err := s.rehomeGeneration(obj, fpcc); err != nil {
+			return fmt.Errorf("generation %d: %w", fpcc.GetGeneration(), err)
+		}
+	}
+	return nil
+}
+
+// rehomeGeneration places the fragments of one generation of obj, pushing
+// local copies and rebuilding the rest.
+func (s *server) rehomeGeneration(obj string, fpcc *protocol.FPCC) error {
+	s.mu.Lock()
+	view := s.view
+	s.mu.Unlock()
+
+	m, n := s.profileOf(fpcc)
+	owners, err := placement.ForView(view, obj, m, n)
+	if err != nil {
+		return err
+	}
+
+	located := make(map[string]map[uint32]bool)
+	holds := func(addr string, idx uint32) bool {
+		if _, ok := located[addr]; !ok {
+			located[addr] = s.locate(addr, obj, fpcc)
+		}
+		return located[addr][idx]
+	}
+	var missing []uint32
+	for i, owner := range owners {
+		if !holds(owner, uint32(i)) {
+			missing = append(missing, uint32(i))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	shards := make([][]byte, n)
+	rebuild := false
+	for _, idx := range missing {
+		if frag, err := s.loadFragment(obj, fpcc.GetGeneration(), idx); err == nil && fragmentMatches(fpcc, idx, frag) {
+			shards[idx] = frag
+		} else {
+			rebuild = true
+		}
+	}
+	if rebuild {
+		if err := s.reconstruct(obj, fpcc, shards, missing, view, holds); err != nil {
+			return err
+		}
+	}
+
+	for _, idx := range missing {
+		if err := s.handoff(owners[idx], obj, idx, shards[idx], fpcc); err != nil {
+			return fmt.Errorf("handoff idx=%d to %s: %w", idx, owners[idx], err)
+		}
+	}
+	return nil
+}
+
+// reconstruct rebuilds the required fragments. It first fetches each one's
+// repair set, which for a locally repairable code is just its group, and
+// only falls back to gathering enough fragments to decode the whole object.
+func (s *server) reconstruct(obj string, fpcc *protocol.FPCC, shards [][]byte, required []uint32, view *protocol.View, holds func(string, uint32) bool) error {
+	codec, err := s.codecOf(fpcc)
+	if err != nil {
+		return err
+	}
+	fetch := func(idx int) bool {
+		if shards[idx] != nil {
+			return true
+		}
+		for _, addr := range membership.Addrs(view) {
+			if !holds(addr, uint32(idx)) {
+				continue
+			}
+			if frag := s.fetchFragment(addr, obj, fpcc.GetGeneration(), uint32(idx)); frag != nil && fragmentMatches(fpcc, uint32(idx), frag) {
+				shards[idx] = frag
+				return true
+			}
+		}
+		return false
+	}
+
+	want := make([]bool, len(shards))
+	for _, idx := range required {
+		if shards[idx] != nil {
+			continue
+		}
+		want[idx] = true
+		for _, j := range codec.RepairSet(int(idx)) {
+			fetch(j)
+		}
+	}
+	if codec.ReconstructSome(shards, want) != nil {
+		k, _ := s.profileOf(fpcc)
+		have := 0
+		for idx := range shards {
+			if have >= k {
+				break
+			}
+			if fetch(idx) {
+				have++
+			}
+		}
+		if have < k {
+			return fmt.Errorf("only %d/%d good fragments in the cluster", have, k)
+		}
+		if err := codec.ReconstructSome(shards, want); err != nil {
+			return err
+		}
+	}
+	for _, idx := range required {
+		if !fragmentMatches(fpcc, idx, shards[idx]) {
+			return fmt.Errorf("rebuilt fragment %d does not match FPCC", idx)
+		}
+	}
+	return nil
+}
+
+/* ------------------------------------------------------------------------ */
+/* helpers                                                                  */
+/* ------------------------------------------------------------------------ */
+
+// vouch is what the other members say of one FPCC of an object.
+type vouch struct {
+	need         int       // f+1 for the FPCC's profile in this view
+	committed    int       // members listing it as a committed version
+	superseded   int       // of those, the ones listing it as noncurrent
+	open         int       // members that know it only as a round in progress
+	supersededAt time.Time // the latest time a member says it was superseded
+}
+
+// vouch asks every other member what it knows of fpcc. With f+1 answers
+// alike, f being the faults fpcc's profile tolerates in this view, at
+// least one comes from an honest node, and an honest node only lists a
+// version that won its Ready quorum.
+func (s *server) vouch(obj string, fpcc *protocol.FPCC) vouch {
+	s.mu.Lock()
+	_, q := s.quorumLocked(obj, fpcc)
+	addrs := membership.Addrs(s.view)
+	s.mu.Unlock()
+	digest := fpcc.Digest()
+
+	out := vouch{need: q.F + 1}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, addr := range addrs {
+		if addr == s.selfAddr {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = withPeer(addr, vouchTimeout, func(ctx context.Context, c protocol.DispersalClient) error {
+				resp, err := c.Versions(ctx, &protocol.VersionsRequest{ObjectId: obj})
+				if err != nil {
+					return err
+				}
+				for _, v := range resp.Versions {
+					if !bytes.Equal(v.Fpcc.Digest(), digest) {
+						continue
+					}
+					mu.Lock()
+					out.committed++
+					if at := time.Unix(v.SupersededUnix, 0); v.SupersededUnix != 0 {
+						out.superseded++
+						if at.After(out.supersededAt) {
+							out.supersededAt = at
+						}
+					}
+					mu.Unlock()
+					return nil
+				}
+				r, err := c.GetFPCC(ctx, &protocol.GetFPCCRequest{ObjectId: obj, Generation: fpcc.GetGeneration(), Digest: digest})
+				if err == nil && r.Ok {
+					mu.Lock()
+					out.open++
+					mu.Unlock()
+				}
+				return err
+			})
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+func fragmentMatches(fpcc *protocol.FPCC, idx uint32, frag []byte) bool {
+	if int(idx) >= len(fpcc.Hashes) {
+		return false
+	}
+	h := sha256.Sum256(frag)
+	return bytes.Equal(h[:], fpcc.Hashes[idx]) && fingerprint.NewWithSeed(fpcc.Seed).Eval(frag) == fpcc.Fps[idx]
+}
+
+func (s *server) heldIndices(obj string, fpcc *protocol.FPCC) []uint32 {
+	var out []uint32
+	for i := range fpcc.Hashes {
+		if _, err := os.Stat(s.fragPath(obj, fpcc.GetGeneration(), uint32(i))); err == nil {
+			out = append(out, uint32(i))
+		}
+	}
+	return out
+}
+
+// adoptFPCC records an FPCC learned through Handoff rather than dispersal;
+// the object is already committed cluster‑wide, so its commit channel is
+// born closed.
+func (s *server) adoptFPCC(obj string, fpcc *protocol.FPCC) {
+	s.mu.Lock()
+	if _, ok := s.fpccs[obj]; ok {
+		s.mu.Unlock()
+		return
+	}
+	s.fpccs[obj] = fpcc
+	s.closeCommitLocked(roundKey(obj, fpcc))
+	s.mu.Unlock()
+
+	_ = s.metaDB.Update(func(tx *bolt.Tx) error {
+		if err := s.putFPCC(tx, obj, fpcc); err != nil {
+			return err
+		}
+		meta := newMeta(fpcc, time.Now())
+		meta.Committed = true
+		return s.putMeta(tx, obj, meta)
+	})
+}
+
+// adoptVersion records fpcc, handed off as a noncurrent version of obj,
+// superseded at the time its vouchers gave.
+func (s *server) adoptVersion(obj string, fpcc *protocol.FPCC, superseded time.Time) error {
+	return s.metaDB.Update(func(tx *bolt.Tx) error {
+		return s.putVersion(tx, obj, version{Fpcc: fpcc, Created: createdAt(fpcc, superseded), Superseded: superseded})
+	})
+}
+
+// adoptRound records fpcc, handed off as a round still in progress, the
+// way Disperse opens one: a first round as the object's uncommitted FPCC,
+// a later generation as pending until its Readies promote it.
+func (s *server) adoptRound(obj string, fpcc *protocol.FPCC) error {
+	gen, rk := fpcc.GetGeneration(), roundKey(obj, fpcc)
+	committed := false
+	s.mu.Lock()
+	switch known := s.roundFPCC(obj, gen); {
+	case known != nil && !eqFPCC(known, fpcc):
+		s.mu.Unlock()
+		return errors.New("FPCC mismatch")
+	case known != nil:
+		s.mu.Unlock()
+		return nil
+	case gen > 0 && s.pending[obj] != nil:
+		s.mu.Unlock()
+		return errors.New("another round of the object is in progress")
+	case gen > 0:
+		s.pending[obj] = fpcc
+	default:
+		s.fpccs[obj] = fpcc
+		_, q := s.quorumLocked(obj, fpcc)
+		s.commitChan[rk] = make(chan struct{})
+		if committed = len(s.readySeen[rk]) >= q.Ready; committed {
+			s.closeCommitLocked(rk)
+		}
+	}
+	s.mu.Unlock()
+	if gen > 0 {
+		return nil // persisted by promote once committed
+	}
+	return s.metaDB.Update(func(tx *bolt.Tx) error {
+		if err := s.putFPCC(tx, obj, fpcc); err != nil {
+			return err
+		}
+		meta := newMeta(fpcc, time.Now())
+		meta.Committed = committed
+		return s.putMeta(tx, obj, meta)
+	})
+}
+
+// locate asks addr which fragments of obj it holds.
+func (s *server) locate(addr, obj string, fpcc *protocol.FPCC) map[uint32]bool {
+	out := make(map[uint32]bool)
+	if addr == s.selfAddr {
+		for _, i := range s.heldIndices(obj, fpcc) {
+			out[i] = true
+		}
+		return out
+	}
+	_ = withPeer(addr, handoffTimeout, func(ctx context.Context, c protocol.DispersalClient) error {
+		resp, err := c.Locate(ctx, &protocol.LocateRequest{ObjectId: obj, Generation: fpcc.GetGeneration()})
+		if err == nil && resp.Ok {
+			for _, i := range resp.FragmentIndices {
+				out[i] = true
+			}
+		}
+		return err
+	})
+	return out
+}
+
+func (s *server) fetchFragment(addr, obj string, gen uint64, idx uint32) []byte {
+	if addr == s.selfAddr {
+		frag, _ := s.loadFragment(obj, gen, idx)
+		return frag
+	}
+	var frag []byte
+	_ = withPeer(addr, handoffTimeout, func(ctx context.Context, c protocol.DispersalClient) error {
+		resp, err := c.Retrieve(ctx, &protocol.RetrieveRequest{ObjectId: obj, FragmentIndex: idx, Generation: gen})
+		if err == nil && resp.Ok {
+			frag = resp.Fragment
+		}
+		return err
+	})
+	return frag
+}
+
+func (s *server) handoff(addr, obj string, idx uint32, frag []byte, fpcc *protocol.FPCC) error {
+	if addr == s.selfAddr {
+		if err := s.persistFragment(obj, fpcc.GetGeneration(), idx, frag); err != nil {
+			return err
+		}
+		s.adoptFPCC(obj, fpcc)
+		return nil
+	}
+	return withPeer(addr, handoffTimeout, func(ctx context.Context, c protocol.DispersalClient) error {
+		resp, err := c.Handoff(ctx, &protocol.HandoffRequest{
+			ObjectId:      obj,
+			FragmentIndex: idx,
+			Fragment:      frag,
+			Fpcc:          fpcc,
+			Sender:        s.selfAddr,
+		})
+		if err != nil {
+			return err
+		}
+		if !resp.Ok {
+			return fmt.Errorf("%s", resp.Error)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,217 @@
+// cmd/server/gossip.go – batched Echo / Ready delivery.
+// Every peer gets an outbox drained by one goroutine over one connection.
+// Messages that arrive within gossipWindow of each other travel as a single
+// EchoBatch / ReadyBatch RPC, so with many small objects in flight the RPC
+// count stays flat while the per-object message count grows – the same trick
+// storage.Batcher plays on bolt transactions.
+
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/membership"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+	"google.golang.org/grpc"
+)
+
+const (
+	gossipWindow   = 5 * time.Millisecond // how long a batch stays open after its first message
+	gossipMaxBatch = 256                  // messages per batch before it is sent early
+	gossipQueue    = 4096                 // queued messages per peer before new ones are dropped
+)
+
+// gossipMsg is one Echo or one Ready bound for a peer.
+type gossipMsg struct {
+	echo  *protocol.EchoRequest
+	ready *protocol.ReadyRequest
+}
+
+type outbox struct {
+	ch   chan gossipMsg
+	done chan struct{} // closed once the peer leaves the view
+}
+
+// gossip queues msg for addr. Like the unbatched sends before it, delivery
+// is best effort: a peer that is down or far behind loses messages rather
+// than stalling the sender.
+func (s *server) gossip(addr string, msg gossipMsg) {
+	s.outboxMu.Lock()
+	ob := s.outboxes[addr]
+	if ob == nil {
+		ob = &outbox{ch: make(chan gossipMsg, gossipQueue), done: make(chan struct{})}
+		s.outboxes[addr] = ob
+		go s.outboxLoop(addr, ob)
+	}
+	s.outboxMu.Unlock()
+
+	select {
+	case ob.ch <- msg:
+	case <-ob.done:
+	default:
+		log.Printf("[Gossip] queue to %s full; dropping message for %s", addr, msg.object())
+	}
+}
+
+// dropOutboxes stops the outboxes of the peers v no longer lists; their
+// queued messages go with them.
+func (s *server) dropOutboxes(v *protocol.View) {
+	s.outboxMu.Lock()
+	defer s.outboxMu.Unlock()
+	for addr, ob := range s.outboxes {
+		if !membership.Contains(v, addr) {
+			close(ob.done)
+			delete(s.outboxes, addr)
+		}
+	}
+}
+
+func (m gossipMsg) object() string {
+	if m.echo != nil {
+		return m.echo.ObjectId
+	}
+	return m.ready.ObjectId
+}
+
+func (s *server) outboxLoop(addr string, ob *outbox) {
+	var (
+		conn    *grpc.ClientConn
+		echoes  []*protocol.EchoRequest
+		readies []*protocol.ReadyRequest
+	)
+	add := func(m gossipMsg) {
+		if m.echo != nil {
+			echoes = append(echoes, m.echo)
+		} else {
+			readies = append(readies, m.ready)
+		}
+	}
+	flush := func() {
+		defer func() { echoes, readies = nil, nil }()
+		if conn == nil {
+			c, err := dialPeer(addr, echoDialTimeout)
+			if err != nil {
+				return
+			}
+			conn = c
+		}
+		client := protocol.NewDispersalClient(conn)
+		ctx, cancel := context.WithTimeout(context.Background(), readyDialTimeout)
+		defer cancel()
+		var err error
+		// echoes first: within a window they precede the readies they led to
+		if len(echoes) > 0 {
+			_, err = client.EchoBatch(ctx, &protocol.EchoBatchRequest{Echoes: echoes})
+		}
+		if err == nil && len(readies) > 0 {
+			_, err = client.ReadyBatch(ctx, &protocol.ReadyBatchRequest{Readies: readies})
+		}
+		if err != nil {
+			conn.Close() // redial on the next batch
+			conn = nil
+		}
+	}
+
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	for {
+		select {
+		case m := <-ob.ch:
+			add(m)
+		case <-ob.done:
+			return
+		}
+		window := time.After(gossipWindow)
+	collect:
+		for len(echoes)+len(readies) < gossipMaxBatch {
+			select {
+			case m := <-ob.ch:
+				add(m)
+			case <-window:
+				break collect
+			case <-ob.done:
+				return
+			}
+		}
+		flush()
+	}
+}
+
+/* --- EchoBatch / ReadyBatch --- */
+
+// prefetchParallel bounds the GetFPCC calls one batch makes at a time.
+const prefetchParallel = 16
+
+// fpccRef names the FPCC of one round by digest, and the node that sent it.
+type fpccRef struct {
+	obj    string
+	gen    uint64
+	digest []byte
+	sender string
+}
+
+// prefetchFPCCs fetches the FPCCs a batch names that this node has not
+// seen, all at once: handled one after another, a single slow fetch would
+// run the rest of the batch out of time. Only authenticated senders are
+// asked; whatever is still unknown afterwards fails its entry.
+func (s *server) prefetchFPCCs(ctx context.Context, refs []fpccRef) {
+	senders := make(map[string]string) // claimed → authenticated, "" if not
+	seen := make(map[string]bool)
+	sem := make(chan struct{}, prefetchParallel)
+	var wg sync.WaitGroup
+	for _, r := range refs {
+		key := learnedKey(r.obj, r.gen, r.digest)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		sender, ok := senders[r.sender]
+		if !ok {
+			sender, _ = s.sender(ctx, r.sender)
+			senders[r.sender] = sender
+		}
+		if sender == "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			_, _ = s.resolveFPCC(r.obj, r.gen, r.digest, sender)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *server) EchoBatch(ctx context.Context, req *protocol.EchoBatchRequest) (*protocol.EchoBatchResponse, error) {
+	refs := make([]fpccRef, len(req.Echoes))
+	for i, e := range req.Echoes {
+		refs[i] = fpccRef{e.ObjectId, e.Generation, e.Digest, e.Sender}
+	}
+	s.prefetchFPCCs(ctx, refs)
+	out := &protocol.EchoBatchResponse{Results: make([]*protocol.EchoResponse, len(req.Echoes))}
+	for i, e := range req.Echoes {
+		out.Results[i] = s.echo(ctx, e, false)
+	}
+	return out, nil
+}
+
+func (s *server) ReadyBatch(ctx context.Context, req *protocol.ReadyBatchRequest) (*protocol.ReadyBatchResponse, error) {
+	refs := make([]fpccRef, len(req.Readies))
+	for i, r := range req.Readies {
+		refs[i] = fpccRef{r.ObjectId, r.Generation, r.Digest, r.Sender}
+	}
+	s.prefetchFPCCs(ctx, refs)
+	out := &protocol.ReadyBatchResponse{Results: make([]*protocol.ReadyResponse, len(req.Readies))}
+	for i, r := range req.Readies {
+		out.Results[i] = s.ready(ctx, r, false)
+	}
+	return out, nil
+}
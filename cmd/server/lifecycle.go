@@ -0,0 +1,163 @@
+// cmd/server/lifecycle.go – when objects expire, and lifecycle transcodes.
+// An object expires at the time its FPCC names (expires_unix), never if the
+// FPCC says to retain it, and otherwise after the expire of the lifecycle
+// rule with the longest prefix matching its ID, or object.ttl, counted
+// from the creation time on its FPCC. Both were agreed on by the quorum
+// with the rest of the FPCC, so every node drops the object at the same
+// time; objects from before FPCCs carried a creation time count from when
+// each node first saw them.
+// A rule may also transcode objects to a cheaper profile once they are old
+// enough; of an object's group only the first node does, through its
+// coordinator client, so that the nodes do not race each other for the
+// transcode's generation. Chunks of deduplicated objects live by their
+// references (see dedup.go) and manifests are not transcoded.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/chunker"
+	"github.com/dattu/distributed_object_store/pkg/config"
+	"github.com/dattu/distributed_object_store/pkg/erasure"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// gcInterval is the longest the GC waits between sweeps, however long
+	// object.ttl is: per‑object expiry and lifecycle rules are finer.
+	gcInterval = time.Minute
+	// lifecycleTimeout bounds one lifecycle transcode.
+	lifecycleTimeout = 30 * time.Minute
+)
+
+// never is the expiry of an object that does not expire.
+var never = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// checkLifecycle makes sure every transcode rule names a known profile,
+// filling in the default codec.
+func checkLifecycle(rules []config.LifecycleRule, codec string) error {
+	for i := range rules {
+		r := &rules[i]
+		if r.Codec == "" {
+			r.Codec = codec
+		}
+		if r.Transcode == 0 {
+			continue
+		}
+		if _, err := erasure.Lookup(r.Codec, r.Data, r.Total); err != nil {
+			return fmt.Errorf("lifecycle rule %q: %v", r.Prefix, err)
+		}
+	}
+	return nil
+}
+
+// rule returns the lifecycle rule for obj, nil if none applies.
+func (s *server) rule(obj string) *config.LifecycleRule {
+	if chunker.IsID(obj) {
+		return nil
+	}
+	var best *config.LifecycleRule
+	for i, r := range s.lifecycle {
+		if strings.HasPrefix(obj, r.Prefix) && (best == nil || len(r.Prefix) > len(best.Prefix)) {
+			best = &s.lifecycle[i]
+		}
+	}
+	return best
+}
+
+// expiry returns when the version fpcc of obj expires, counting rule and
+// TTL from the creation time in fpcc, or from created, when this node saw
+// it, if fpcc has none; fpcc may be nil for an object this node holds no
+// FPCC of.
+func (s *server) expiry(obj string, fpcc *protocol.FPCC, created time.Time) time.Time {
+	created = createdAt(fpcc, created)
+	switch {
+	case fpcc.GetRetain():
+		return never
+	case fpcc.GetExpiresUnix() != 0:
+		return time.Unix(fpcc.GetExpiresUnix(), 0)
+	}
+	if r := s.rule(obj); r != nil && r.Expire > 0 {
+		return created.Add(r.Expire)
+	}
+	return created.Add(s.ttl)
+}
+
+// lifecycleJob is an object due for its lifecycle transcode.
+type lifecycleJob struct {
+	obj  string
+	rule *config.LifecycleRule
+}
+
+// applyLifecycle transcodes the objects whose lifecycle rule says they are
+// old enough for its profile, where this node is the first of the group.
+func (s *server) applyLifecycle() {
+	if s.coord == nil {
+		return
+	}
+	for _, j := range s.lifecycleDue(time.Now()) {
+		ctx, cancel := context.WithTimeout(context.Background(), lifecycleTimeout)
+		info, err := s.coord.Transcode(ctx, j.obj, j.rule.Codec, j.rule.Data, j.rule.Total)
+		cancel()
+		if err != nil {
+			log.Printf("[Lifecycle] transcode %s: %v", j.obj, err)
+			continue
+		}
+		log.Printf("[Lifecycle] %s transcoded to %s %d‑of‑%d (generation %d)", j.obj, info.Codec, info.Data, info.Total, info.Generation)
+	}
+}
+
+// lifecycleDue returns the objects applyLifecycle transcodes at now.
+func (s *server) lifecycleDue(now time.Time) []lifecycleJob {
+	type candidate struct {
+		lifecycleJob
+		fpcc *protocol.FPCC
+	}
+	var due []candidate
+	s.mu.Lock()
+	for obj, fpcc := range s.fpccs {
+		r := s.rule(obj)
+		if r == nil || r.Transcode == 0 || fpcc.GetManifest() != nil || !s.committedLocked(obj) || s.pending[obj] != nil {
+			continue
+		}
+		if group, _ := s.quorumLocked(obj, fpcc); len(group) == 0 || group[0] != s.selfAddr || s.hasProfile(fpcc, r) {
+			continue
+		}
+		due = append(due, candidate{lifecycleJob{obj, r}, fpcc})
+	}
+	s.mu.Unlock()
+
+	// s.mu is not held across the transaction: disperse takes them the
+	// other way round
+	var jobs []lifecycleJob
+	_ = s.metaDB.View(func(tx *bolt.Tx) error {
+		for _, c := range due {
+			if meta, ok := s.getMeta(tx, c.obj); ok && now.Sub(createdAt(c.fpcc, meta.Created)) > c.rule.Transcode {
+				jobs = append(jobs, c.lifecycleJob)
+			}
+		}
+		return nil
+	})
+	return jobs
+}
+
+// hasProfile reports whether fpcc is already encoded as r asks.
+func (s *server) hasProfile(fpcc *protocol.FPCC, r *config.LifecycleRule) bool {
+	have, err := s.codecOf(fpcc)
+	if err != nil {
+		return false
+	}
+	want, err := erasure.Lookup(r.Codec, r.Data, r.Total)
+	if err != nil {
+		return true // checkLifecycle let it through: nothing to do
+	}
+	hm, hn := have.Shards()
+	wm, wn := want.Shards()
+	return have.Name() == want.Name() && hm == wm && hn == wn
+}
@@ -9,28 +9,36 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"maps"
 	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/dattu/distributed_object_store/pkg/blockhash"
+	"github.com/dattu/distributed_object_store/pkg/chunker"
+	"github.com/dattu/distributed_object_store/pkg/client"
 	"github.com/dattu/distributed_object_store/pkg/config"
+	"github.com/dattu/distributed_object_store/pkg/erasure"
 	"github.com/dattu/distributed_object_store/pkg/fingerprint"
+	"github.com/dattu/distributed_object_store/pkg/membership"
+	"github.com/dattu/distributed_object_store/pkg/merkle"
+	"github.com/dattu/distributed_object_store/pkg/placement"
 	"github.com/dattu/distributed_object_store/pkg/protocol"
 	"github.com/dattu/distributed_object_store/pkg/storage"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	bolt "go.etcd.io/bbolt"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/grpc/peer"
 )
 
 /* ------------------------------------------------------------------------ */
@@ -42,12 +50,19 @@ const (
     echoDialTimeout  = 5 * time.Second
     readyDialTimeout = 5 * time.Second
 
-    fpccsBucket = "fpccs"
-    echoBucket  = "echoSeen"
-    readyBucket = "readySeen"
-    metaBucket  = "meta"
+    fpccsBucket    = "fpccs"
+    echoBucket     = "echoSeen"
+    readyBucket    = "readySeen"
+    metaBucket     = "meta"
+    viewBucket     = "membership"
+    refsBucket     = "chunkRefs" // chunk|manifest → when the reference lapses
+    versionsBucket = "versions"  // obj#generation → a noncurrent version, sealed
 )
 
+// retireGrace is how long a transcoded or superseded generation's fragments
+// stay readable; a variable so tests can shorten it.
+var retireGrace = 2 * time.Minute
+
 /* ------------------------------------------------------------------------ */
 /* Prometheus metrics                                                       */
 /* ------------------------------------------------------------------------ */
@@ -71,6 +86,10 @@ var (
         Help:    "Latency of Retrieve RPCs.",
         Buckets: prometheus.DefBuckets,
     })
+    degradedRetrieveTotal = prometheus.NewCounter(prometheus.CounterOpts{
+        Name: "avid_fp_retrieve_degraded_total",
+        Help: "Retrieve RPCs for parity fragments, which readers only need when a data fragment is lost or bad.",
+    })
 )
 
 /* ------------------------------------------------------------------------ */
@@ -78,7 +97,27 @@ var (
 /* ------------------------------------------------------------------------ */
 
 func dialOpts() grpc.DialOption {
-    return grpc.WithTransportCredentials(insecure.NewCredentials())
+    return grpc.WithTransportCredentials(transportCreds)
+}
+
+// dialPeer opens a blocking connection to another node, giving up after timeout.
+func dialPeer(addr string, timeout time.Duration) (*grpc.ClientConn, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), timeout)
+    defer cancel()
+    return grpc.DialContext(ctx, addr, dialOpts(), grpc.WithBlock())
+}
+
+// withPeer dials addr and runs fn against its Dispersal service; dial and
+// call share the same timeout.
+func withPeer(addr string, timeout time.Duration, fn func(context.Context, protocol.DispersalClient) error) error {
+    conn, err := dialPeer(addr, timeout)
+    if err != nil {
+        return err
+    }
+    defer conn.Close()
+    ctx, cancel := context.WithTimeout(context.Background(), timeout)
+    defer cancel()
+    return fn(ctx, protocol.NewDispersalClient(conn))
 }
 
 /* ------------------------------------------------------------------------ */
@@ -87,27 +126,55 @@ func dialOpts() grpc.DialOption {
 
 type server struct {
     protocol.UnimplementedDispersalServer
-
-    peers               []string
+    protocol.UnimplementedMembershipServer
+
+    peers               []string // members of the current view; guarded by mu
+    view                *protocol.View
+    prevView            *protocol.View // the view before view, whose members may still be handing off
+    pendingView         *protocol.View // accepted proposal awaiting commit
+    pendingAt           time.Time
+    drain               drainProgress
+    rebalanceCh         chan struct{}
     selfAddr            string // host:port string for this node
     m, n, f             int
     metaDB              *bolt.DB
     dataDir             string
+    vault               *storage.Vault // seals fragments and FPCCs at rest; nil = plaintext
     ttl                 time.Duration
+    keepVersions        int           // noncurrent versions kept per object
+    noncurrentTTL       time.Duration // 0 = noncurrent versions only expire with the object TTL
+    lifecycle           []config.LifecycleRule // see lifecycle.go
     echoBatcher         *storage.Batcher
     readyBatcher        *storage.Batcher
+    outboxMu            sync.Mutex
+    outboxes            map[string]*outbox // Echo / Ready queues, one per peer
     mu                  sync.Mutex
     fpccs               map[string]*protocol.FPCC
+    pending             map[string]*protocol.FPCC // later generations dispersed but not yet committed
+    fences              map[string]uint64         // obj → generations below this are never readied here
+    learned             map[string]*protocol.FPCC // fetched with GetFPCC, by round key + "@" + digest
     echoSeen, readySeen map[string]map[string]bool
     readySent           map[string]bool
     commitChan          map[string]chan struct{}
+    resolved            map[string][]net.IP // peer host → its IPs; see sender
+    coord               *client.Client // PutObject / GetObject and the HTTP API on behalf of thin clients
 }
 
 /* ------------------------------------------------------------------------ */
 /* constructor                                                              */
 /* ------------------------------------------------------------------------ */
 
-func newServer(self string, peers []string, m, n int, db *bolt.DB, dataDir string, ttl time.Duration) *server {
+// createBuckets makes sure every bucket the server uses exists.
+func createBuckets(db *bolt.DB) {
+    db.Update(func(tx *bolt.Tx) error {
+        for _, b := range []string{fpccsBucket, echoBucket, readyBucket, metaBucket, viewBucket, refsBucket, versionsBucket} {
+            tx.CreateBucketIfNotExists([]byte(b))
+        }
+        return nil
+    })
+}
+
+func newServer(self string, initial *protocol.View, m, n int, db *bolt.DB, dataDir string, vault *storage.Vault, ttl time.Duration) *server {
     echo := make(map[string]map[string]bool)
     ready := make(map[string]map[string]bool)
 
@@ -137,18 +204,24 @@ func newServer(self string, peers []string, m, n int, db *bolt.DB, dataDir strin
     // construct the server instance
     srv := &server{
         selfAddr:     self,
-        peers:        peers,
         m:            m,
         n:            n,
         f:            n - m,
         metaDB:       db,
         dataDir:      dataDir,
+        vault:        vault,
         ttl:          ttl,
         fpccs:        make(map[string]*protocol.FPCC),
+        pending:      make(map[string]*protocol.FPCC),
+        fences:       make(map[string]uint64),
         echoSeen:     echo,
         readySeen:    ready,
         readySent:    make(map[string]bool),
+        learned:      make(map[string]*protocol.FPCC),
+        outboxes:     make(map[string]*outbox),
         commitChan:   make(map[string]chan struct{}),
+        resolved:     make(map[string][]net.IP),
+        rebalanceCh:  make(chan struct{}, 1),
         echoBatcher:  storage.NewBatcher(db, echoBucket),
         readyBatcher: storage.NewBatcher(db, readyBucket),
     }
@@ -157,15 +230,33 @@ func newServer(self string, peers []string, m, n int, db *bolt.DB, dataDir strin
     _ = db.View(func(tx *bolt.Tx) error {
         b := tx.Bucket([]byte(fpccsBucket))
         return b.ForEach(func(k, v []byte) error {
-            var stored protocol.FPCC
-            if err := json.Unmarshal(v, &stored); err != nil {
+            stored, err := srv.decodeFPCC(string(k), v)
+            if err != nil {
                 return err
             }
-            srv.fpccs[string(k)] = &stored
+            srv.fpccs[string(k)] = stored
             return nil
         })
     })
 
+    // a persisted view outranks the static peer list
+    srv.view = loadView(db, initial)
+    srv.peers = membership.Addrs(srv.view)
+
+    // reopen committed rounds closed; records from before the marker are
+    // judged by their generation and the Readies counted
+    _ = db.View(func(tx *bolt.Tx) error {
+        for obj, fpcc := range srv.fpccs {
+            meta, _ := srv.getMeta(tx, obj)
+            _, q := srv.quorumLocked(obj, fpcc)
+            rk := roundKey(obj, fpcc)
+            if meta.Committed || fpcc.GetGeneration() > 0 || len(ready[rk]) >= q.Ready {
+                srv.commitChan[rk] = closedChan()
+            }
+        }
+        return nil
+    })
+
     return srv
 }
 
@@ -173,117 +264,376 @@ func newServer(self string, peers []string, m, n int, db *bolt.DB, dataDir strin
 /* helpers                                                                  */
 /* ------------------------------------------------------------------------ */
 
-func (s *server) fragPath(obj string, idx uint32) string {
+// fragPath keeps the first generation of an object directly under its
+// directory and each transcoded generation in a g<N> subdirectory.
+func (s *server) fragPath(obj string, gen uint64, idx uint32) string {
+    if gen > 0 {
+        return filepath.Join(s.dataDir, obj, fmt.Sprintf("g%d", gen), fmt.Sprintf("%d.bin", idx))
+    }
     return filepath.Join(s.dataDir, obj, fmt.Sprintf("%d.bin", idx))
 }
 
-func (s *server) persistFragment(obj string, idx uint32, data []byte) error {
-    path := s.fragPath(obj, idx)
+func (s *server) persistFragment(obj string, gen uint64, idx uint32, data []byte) error {
+    path := s.fragPath(obj, gen, idx)
     if _, err := os.Stat(path); err == nil {
         return nil
     }
     if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
         return err
     }
-    return storage.AtomicWrite(path, data, 0o644)
+    return s.vault.AtomicWrite(path, data, 0o600)
+}
+
+var (
+    errHashMismatch        = errors.New("hash mismatch")
+    errFingerprintMismatch = errors.New("fingerprint mismatch")
+)
+
+// persistChecked streams fragment idx of obj to disk while hashing and
+// fingerprinting it, and only keeps it if both match the FPCC.
+func (s *server) persistChecked(obj string, fpcc *protocol.FPCC, idx uint32, body io.Reader) (int64, error) {
+    path := s.fragPath(obj, fpcc.GetGeneration(), idx)
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return 0, err
+    }
+    h := sha256.New()
+    fp := fingerprint.NewWithSeed(fpcc.Seed).Stream()
+    sums := io.Writer(io.Discard)
+    var blocks *blockhash.Writer
+    if len(fpcc.Roots) > 0 {
+        blocks = blockhash.New(int(fpcc.Profile.Stripe))
+        sums = blocks
+    }
+    var size int64
+    err := s.vault.AtomicWriteFrom(path, io.TeeReader(body, io.MultiWriter(h, fp, sums)), 0o600, func(n int64) error {
+        size = n
+        if !bytes.Equal(h.Sum(nil), fpcc.Hashes[idx]) {
+            return errHashMismatch
+        }
+        if blocks != nil && !bytes.Equal(merkle.Root(blocks.Sums()), fpcc.Roots[idx]) {
+            return errHashMismatch
+        }
+        if fp.Sum64() != fpcc.Fps[idx] {
+            return errFingerprintMismatch
+        }
+        return nil
+    })
+    if err == nil && blocks != nil {
+        _ = s.saveLeaves(obj, fpcc.GetGeneration(), idx, blocks.Sums()) // rebuilt on demand if lost
+    }
+    return size, err
 }
 
-func (s *server) loadFragment(obj string, idx uint32) ([]byte, error) {
-    return os.ReadFile(s.fragPath(obj, idx))
+func (s *server) loadFragment(obj string, gen uint64, idx uint32) ([]byte, error) {
+    return s.vault.ReadFile(s.fragPath(obj, gen, idx))
 }
 
-func eqFPCC(a, b *protocol.FPCC) bool {
-    if a.Seed != b.Seed || len(a.Hashes) != len(b.Hashes) || len(a.Fps) != len(b.Fps) {
-        return false
+// putFPCC stores obj's committed FPCC, sealed when the node has a key:
+// its seed is what keeps fingerprints unforgeable.
+func (s *server) putFPCC(tx *bolt.Tx, obj string, fpcc *protocol.FPCC) error {
+    raw, _ := json.Marshal(fpcc)
+    return tx.Bucket([]byte(fpccsBucket)).Put([]byte(obj), s.vault.Seal(fpccName(obj), raw))
+}
+
+func (s *server) decodeFPCC(obj string, raw []byte) (*protocol.FPCC, error) {
+    plain, err := s.vault.Unseal(fpccName(obj), raw)
+    if err != nil {
+        return nil, fmt.Errorf("FPCC of %s: %w", obj, err)
     }
-    for i := range a.Hashes {
-        if !bytes.Equal(a.Hashes[i], b.Hashes[i]) || a.Fps[i] != b.Fps[i] {
-            return false
+    var fpcc protocol.FPCC
+    if err := json.Unmarshal(plain, &fpcc); err != nil {
+        return nil, err
+    }
+    return &fpcc, nil
+}
+
+// fpccName binds a sealed FPCC to its bucket and key.
+func fpccName(obj string) []byte {
+    return sealName(fpccsBucket, obj)
+}
+
+// sealName binds a sealed bucket value to its bucket and key.
+func sealName(bucket, key string) []byte {
+    return []byte(bucket + "/" + key)
+}
+
+// roundKey names the Echo/Ready round of one generation of an object. The
+// first generation uses the bare ID so state persisted before transcoding
+// existed still lines up.
+func roundKey(obj string, fpcc *protocol.FPCC) string {
+    if g := fpcc.GetGeneration(); g > 0 {
+        return fmt.Sprintf("%s#%d", obj, g)
+    }
+    return obj
+}
+
+func learnedKey(obj string, gen uint64, digest []byte) string {
+    return roundKey(obj, &protocol.FPCC{Generation: gen}) + "@" + string(digest)
+}
+
+// resolveFPCC returns the FPCC that an Echo or Ready names by digest: the
+// round's own when this node has one – any other digest is a mismatch – or
+// else one fetched from the sender and checked against the digest.
+func (s *server) resolveFPCC(obj string, gen uint64, digest []byte, sender string) (*protocol.FPCC, error) {
+    if err := protocol.CheckID(obj); err != nil {
+        return nil, err // its round key could be another object's
+    }
+    key := learnedKey(obj, gen, digest)
+    s.mu.Lock()
+    known := s.roundFPCC(obj, gen)
+    if known == nil {
+        known = s.learned[key]
+    }
+    s.mu.Unlock()
+    if known != nil {
+        if !bytes.Equal(known.Digest(), digest) {
+            return nil, errors.New("FPCC mismatch")
         }
+        return known, nil
     }
-    return true
+    if sender == "" {
+        return nil, errors.New("unknown FPCC and no sender to fetch it from")
+    }
+
+    var fpcc *protocol.FPCC
+    err := withPeer(sender, echoDialTimeout, func(ctx context.Context, c protocol.DispersalClient) error {
+        r, err := c.GetFPCC(ctx, &protocol.GetFPCCRequest{ObjectId: obj, Generation: gen, Digest: digest})
+        if err != nil {
+            return err
+        }
+        if !r.Ok {
+            return errors.New(r.Error)
+        }
+        fpcc = r.Fpcc
+        return nil
+    })
+    if err != nil {
+        return nil, fmt.Errorf("GetFPCC from %s: %w", sender, err)
+    }
+    if !validFPCC(fpcc) || fpcc.GetGeneration() != gen || !bytes.Equal(fpcc.Digest(), digest) {
+        return nil, errors.New("fetched FPCC does not match its digest")
+    }
+    s.mu.Lock()
+    s.learned[key] = fpcc
+    s.mu.Unlock()
+    return fpcc, nil
+}
+
+// committedLocked reports whether obj's current version has committed:
+// whether the commit channel of its round is closed. Caller
+// holds s.mu.
+func (s *server) committedLocked(obj string) bool {
+    fpcc := s.fpccs[obj]
+    if fpcc == nil {
+        return false
+    }
+    select {
+    case <-s.commitChan[roundKey(obj, fpcc)]:
+        return true
+    default:
+        return false
+    }
+}
+
+// closedChan returns the commit channel of a round known to have
+// committed.
+func closedChan() chan struct{} {
+    ch := make(chan struct{})
+    close(ch)
+    return ch
+}
+
+// closeCommitLocked marks round rk committed, releasing the Disperse calls
+// waiting on it. Caller holds s.mu.
+func (s *server) closeCommitLocked(rk string) {
+    ch := s.commitChan[rk]
+    if ch == nil {
+        s.commitChan[rk] = closedChan()
+        return
+    }
+    select {
+    case <-ch:
+    default:
+        close(ch)
+    }
+}
+
+// profileOf returns how many of an object's n fragments always suffice to
+// decode it, and n. Placement and quorums are sized from it: that is m for
+// Reed–Solomon, and n minus the tolerance for a locally repairable code.
+// Objects that predate per‑object profiles fall back to the node's m.
+func (s *server) profileOf(fpcc *protocol.FPCC) (int, int) {
+    if codec, err := s.codecOf(fpcc); err == nil {
+        _, n := codec.Shards()
+        return n - codec.Tolerance(), n
+    }
+    if p := fpcc.GetProfile(); p != nil {
+        return int(p.Data), int(p.Total)
+    }
+    return s.m, len(fpcc.GetHashes())
+}
+
+// codecOf returns the erasure codec fpcc's object was encoded with.
+func (s *server) codecOf(fpcc *protocol.FPCC) (erasure.Codec, error) {
+    if p := fpcc.GetProfile(); p != nil {
+        return erasure.Lookup(p.Codec, int(p.Data), int(p.Total))
+    }
+    return erasure.Lookup(erasure.RS, s.m, len(fpcc.GetHashes()))
+}
+
+// validFPCC checks the FPCC is internally consistent before anything indexes into it.
+func validFPCC(f *protocol.FPCC) bool {
+    if f == nil || len(f.Hashes) == 0 || len(f.Fps) != len(f.Hashes) {
+        return false
+    }
+    if p := f.Profile; p != nil && (p.Data == 0 || p.Data > p.Total || int(p.Total) != len(f.Hashes) || !erasure.Known(p.Codec)) {
+        return false
+    }
+    if len(f.Roots) > 0 && (len(f.Roots) != len(f.Hashes) || f.GetProfile().GetStripe() == 0) {
+        return false // Merkle leaves are stripe blocks
+    }
+    if f.ExpiresUnix < 0 || f.CreatedUnix < 0 || (f.Retain && f.ExpiresUnix != 0) {
+        return false
+    }
+    return protocol.CheckMetadata(f.Metadata, f.Tags) == nil
+}
+
+// eqFPCC compares two FPCCs by their canonical digest.
+func eqFPCC(a, b *protocol.FPCC) bool {
+    return bytes.Equal(a.Digest(), b.Digest())
 }
 
 /* ------------------------------------------------------------------------ */
 /* network broadcasters                                                     */
 /* ------------------------------------------------------------------------ */
 
+func (s *server) peerList() []string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.peers
+}
+
 func (s *server) broadcastEcho(objectID string, fpcc *protocol.FPCC) {
-    for _, addr := range s.peers {
-        go func(a string) {
-            ctx, cancel := context.WithTimeout(context.Background(), echoDialTimeout)
-            conn, err := grpc.DialContext(ctx, a, dialOpts(), grpc.WithBlock())
-            cancel()
-            if err == nil {
-                defer conn.Close()
-                protocol.NewDispersalClient(conn).Echo(context.Background(), &protocol.EchoRequest{ObjectId: objectID, Fpcc: fpcc})
-            }
-        }(addr)
+    digest := fpcc.Digest()
+    for _, addr := range s.group(objectID, fpcc) {
+        s.gossip(addr, gossipMsg{echo: &protocol.EchoRequest{ObjectId: objectID, Digest: digest, Generation: fpcc.GetGeneration(), Sender: s.selfAddr}})
     }
 }
 
 func (s *server) broadcastReady(objectID string, fpcc *protocol.FPCC) {
-    for _, addr := range s.peers {
-        go func(a string) {
-            ctx, cancel := context.WithTimeout(context.Background(), readyDialTimeout)
-            conn, err := grpc.DialContext(ctx, a, dialOpts(), grpc.WithBlock())
-            cancel()
-            if err == nil {
-                defer conn.Close()
-                protocol.NewDispersalClient(conn).Ready(context.Background(), &protocol.ReadyRequest{ObjectId: objectID, Fpcc: fpcc})
-            }
-        }(addr)
+    targets := s.group(objectID, fpcc)
+    if fpcc.GetGeneration() > 0 || fpcc.GetManifest() != nil {
+        // nodes that only hold the previous generation must hear about the
+        // commit too, or they would keep serving it; and a manifest's chunks
+        // live outside its group, on nodes that count references to them
+        targets = s.peerList()
+    }
+    digest := fpcc.Digest()
+    for _, addr := range targets {
+        s.gossip(addr, gossipMsg{ready: &protocol.ReadyRequest{ObjectId: objectID, Digest: digest, Generation: fpcc.GetGeneration(), Sender: s.selfAddr}})
     }
 }
 
 /* --- Disperse --- */
 
 func (s *server) Disperse(ctx context.Context, req *protocol.DisperseRequest) (*protocol.DisperseResponse, error) {
+    return s.disperse(req, bytes.NewReader(req.Fragment)), nil
+}
+
+// disperse runs one fragment through AVID‑FP: the fragment body is checked
+// against the FPCC as it streams to disk, then the node echoes and waits
+// for the object to commit. Shared by Disperse and DisperseStream.
+func (s *server) disperse(req *protocol.DisperseRequest, body io.Reader) *protocol.DisperseResponse {
     timer := prometheus.NewTimer(disperseLatency)
     defer timer.ObserveDuration()
     disperseTotal.Inc()
 
-    log.Printf("[Disperse] %s idx=%d bytes=%d", req.ObjectId, req.FragmentIndex, len(req.Fragment))
+    view := s.currentView()
+    if membership.IsDraining(view, s.selfAddr) {
+        return &protocol.DisperseResponse{Ok: false, Error: "node is draining"}
+    }
+    if err := protocol.CheckID(req.ObjectId); err != nil {
+        return &protocol.DisperseResponse{Ok: false, Error: err.Error()}
+    }
+    if !validFPCC(req.Fpcc) {
+        return &protocol.DisperseResponse{Ok: false, Error: "malformed FPCC or erasure profile"}
+    }
+    m, n := s.profileOf(req.Fpcc)
+    owners, err := placement.ForView(view, req.ObjectId, m, n)
+    if err != nil {
+        return &protocol.DisperseResponse{Ok: false, Error: err.Error()}
+    }
+    if int(req.FragmentIndex) >= len(owners) || owners[req.FragmentIndex] != s.selfAddr {
+        return &protocol.DisperseResponse{Ok: false, Error: fmt.Sprintf("fragment %d is not placed on %s in epoch %d", req.FragmentIndex, s.selfAddr, view.Epoch)}
+    }
 
     /* commit‑channel & self‑echo setup */
+    // a generation above 0 is a transcode: it stays pending, and readers keep
+    // the current FPCC, until its own Ready quorum promotes it
+    gen := req.Fpcc.GetGeneration()
+    rk := roundKey(req.ObjectId, req.Fpcc)
     s.mu.Lock()
-    if _, ok := s.commitChan[req.ObjectId]; !ok {
-        s.commitChan[req.ObjectId] = make(chan struct{})
-        s.fpccs[req.ObjectId] = req.Fpcc
+    if err := s.preconditionLocked(req.ObjectId, req.Fpcc); err != nil {
+        s.mu.Unlock()
+        return &protocol.DisperseResponse{Ok: false, Error: err.Error()}
+    }
+    if cur := s.fpccs[req.ObjectId]; cur != nil && gen < cur.GetGeneration() {
+        s.mu.Unlock()
+        return &protocol.DisperseResponse{Ok: false, Error: fmt.Sprintf("stale generation %d; object is at %d", gen, cur.GetGeneration())}
+    }
+    if fence := s.fences[req.ObjectId]; gen < fence {
+        s.mu.Unlock()
+        return &protocol.DisperseResponse{Ok: false, Error: fmt.Sprintf("stale generation %d; a conditional write claimed %d", gen, fence)}
+    }
+    if _, ok := s.commitChan[rk]; !ok {
+        // the Readies may all have arrived before the fragment did
+        _, q := s.quorumLocked(req.ObjectId, req.Fpcc)
+        committed := len(s.readySeen[rk]) >= q.Ready
+        s.commitChan[rk] = make(chan struct{})
+        if committed {
+            close(s.commitChan[rk])
+        }
+        if gen == 0 {
+            s.fpccs[req.ObjectId] = req.Fpcc
+        } else {
+            s.pending[req.ObjectId] = req.Fpcc
+        }
 
-        if s.echoSeen[req.ObjectId] == nil {
-            s.echoSeen[req.ObjectId] = make(map[string]bool)
+        if s.echoSeen[rk] == nil {
+            s.echoSeen[rk] = make(map[string]bool)
         }
-        s.echoSeen[req.ObjectId][s.selfAddr] = true
+        s.echoSeen[rk][s.selfAddr] = true
 
         s.metaDB.Update(func(tx *bolt.Tx) error {
-            raw, _ := json.Marshal(struct{ Created time.Time }{time.Now()})
-            return tx.Bucket([]byte(metaBucket)).Put([]byte(req.ObjectId), raw)
+            if _, ok := s.getMeta(tx, req.ObjectId); ok {
+                return nil // transcoding keeps the original creation time
+            }
+            meta := newMeta(req.Fpcc, time.Now())
+            meta.Committed = committed && gen == 0
+            return s.putMeta(tx, req.ObjectId, meta)
         })
-    } else if !eqFPCC(s.fpccs[req.ObjectId], req.Fpcc) {
+    } else if known := s.roundFPCC(req.ObjectId, gen); known == nil || !eqFPCC(known, req.Fpcc) {
         s.mu.Unlock()
-        return &protocol.DisperseResponse{Ok: false, Error: "FPCC mismatch"}, nil
+        return &protocol.DisperseResponse{Ok: false, Error: "FPCC mismatch"}
     }
-    commitCh := s.commitChan[req.ObjectId]
+    commitCh := s.commitChan[rk]
     s.mu.Unlock()
 
-    /* integrity checks */
-    if h := sha256.Sum256(req.Fragment); !bytes.Equal(h[:], req.Fpcc.Hashes[req.FragmentIndex]) {
-        return &protocol.DisperseResponse{Ok: false, Error: "hash mismatch"}, nil
-    }
-    if fingerprint.NewWithSeed(req.Fpcc.Seed).Eval(req.Fragment) != req.Fpcc.Fps[req.FragmentIndex] {
-        return &protocol.DisperseResponse{Ok: false, Error: "fingerprint mismatch"}, nil
-    }
-
-    /* persist fragment & FPCC */
-    if err := s.persistFragment(req.ObjectId, req.FragmentIndex, req.Fragment); err != nil {
-        return &protocol.DisperseResponse{Ok: false, Error: "fragment write"}, nil
+    /* persist fragment, checking it against the FPCC on the way, & FPCC */
+    size, err := s.persistChecked(req.ObjectId, req.Fpcc, req.FragmentIndex, body)
+    switch {
+    case errors.Is(err, errHashMismatch), errors.Is(err, errFingerprintMismatch):
+        return &protocol.DisperseResponse{Ok: false, Error: err.Error()}
+    case err != nil:
+        return &protocol.DisperseResponse{Ok: false, Error: "fragment write"}
     }
+    log.Printf("[Disperse] %s idx=%d bytes=%d", req.ObjectId, req.FragmentIndex, size)
     _ = s.metaDB.Update(func(tx *bolt.Tx) error {
-        b := tx.Bucket([]byte(fpccsBucket))
-        if b.Get([]byte(req.ObjectId)) == nil {
-            raw, _ := json.Marshal(req.Fpcc)
-            b.Put([]byte(req.ObjectId), raw)
+        if gen > 0 {
+            return nil // persisted by promote once committed
+        }
+        if tx.Bucket([]byte(fpccsBucket)).Get([]byte(req.ObjectId)) == nil {
+            return s.putFPCC(tx, req.ObjectId, req.Fpcc)
         }
         return nil
     })
@@ -293,67 +643,114 @@ func (s *server) Disperse(ctx context.Context, req *protocol.DisperseRequest) (*
 
     select {
     case <-commitCh:
-        return &protocol.DisperseResponse{Ok: true}, nil
+        return &protocol.DisperseResponse{Ok: true}
     case <-time.After(disperseTimeout):
-        return &protocol.DisperseResponse{Ok: false, Error: "timeout waiting for readies"}, nil
+        return &protocol.DisperseResponse{Ok: false, Error: "timeout waiting for readies"}
     }
 }
 
 
 /* ------------------------------------------------------------------------ */
-/* RPC – Echo, Ready, Retrieve (unchanged from v2.3.1)                      */
+/* RPC – Echo, Ready, Retrieve, Stat                                        */
 /* ------------------------------------------------------------------------ */
 /* --- Echo --- */
 
 func (s *server) Echo(ctx context.Context, req *protocol.EchoRequest) (*protocol.EchoResponse, error) {
-	peerAddr := func() string {
-		if p, ok := peer.FromContext(ctx); ok {
-			return p.Addr.String()
-		}
-		return ""
-	}()
+	return s.echo(ctx, req, true), nil
+}
+
+// echo records one Echo; fetch says whether an FPCC this node has not seen
+// may be fetched from the sender, which EchoBatch has done up front.
+func (s *server) echo(ctx context.Context, req *protocol.EchoRequest, fetch bool) *protocol.EchoResponse {
+	peerAddr, err := s.sender(ctx, req.Sender)
+	if err != nil {
+		return &protocol.EchoResponse{Ok: false, Error: err.Error()}
+	}
+	fpcc, err := s.resolveFPCC(req.ObjectId, req.Generation, req.Digest, fetchFrom(peerAddr, fetch))
+	if err != nil {
+		return &protocol.EchoResponse{Ok: false, Error: err.Error()}
+	}
 	s.mu.Lock()
-	if s.echoSeen[req.ObjectId] == nil {
-		s.echoSeen[req.ObjectId] = make(map[string]bool)
+	group, q := s.quorumLocked(req.ObjectId, fpcc)
+	if !slices.Contains(group, peerAddr) {
+		s.mu.Unlock()
+		return &protocol.EchoResponse{Ok: false, Error: "sender not in object group"}
+	}
+	rk := roundKey(req.ObjectId, fpcc)
+	if s.echoSeen[rk] == nil {
+		s.echoSeen[rk] = make(map[string]bool)
 	}
-	s.echoSeen[req.ObjectId][peerAddr] = true
-	if len(s.echoSeen[req.ObjectId]) >= s.m+s.f && !s.readySent[req.ObjectId] {
-		s.readySent[req.ObjectId] = true
-		go s.broadcastReady(req.ObjectId, req.Fpcc)
+	s.echoSeen[rk][peerAddr] = true
+	if len(s.echoSeen[rk]) >= q.Echo && !s.readySent[rk] && fpcc.GetGeneration() >= s.fences[req.ObjectId] {
+		s.readySent[rk] = true
+		go s.broadcastReady(req.ObjectId, fpcc)
 	}
 	s.mu.Unlock()
 
-	s.echoBatcher.Put([]byte(fmt.Sprintf("%s|%s", req.ObjectId, peerAddr)), []byte{1})
-	return &protocol.EchoResponse{Ok: true}, nil
+	s.echoBatcher.Put([]byte(fmt.Sprintf("%s|%s", rk, peerAddr)), []byte{1})
+	return &protocol.EchoResponse{Ok: true}
+}
+
+// fetchFrom is the node resolveFPCC may fetch from: sender, or none.
+func fetchFrom(sender string, fetch bool) string {
+	if !fetch {
+		return ""
+	}
+	return sender
 }
 
 /* --- Ready --- */
 
 func (s *server) Ready(ctx context.Context, req *protocol.ReadyRequest) (*protocol.ReadyResponse, error) {
-	peerAddr := func() string {
-		if p, ok := peer.FromContext(ctx); ok {
-			return p.Addr.String()
-		}
-		return ""
-	}()
+	return s.ready(ctx, req, true), nil
+}
+
+// ready records one Ready; fetch is as for echo.
+func (s *server) ready(ctx context.Context, req *protocol.ReadyRequest, fetch bool) *protocol.ReadyResponse {
+	peerAddr, err := s.sender(ctx, req.Sender)
+	if err != nil {
+		return &protocol.ReadyResponse{Ok: false, Error: err.Error()}
+	}
+	fpcc, err := s.resolveFPCC(req.ObjectId, req.Generation, req.Digest, fetchFrom(peerAddr, fetch))
+	if err != nil {
+		return &protocol.ReadyResponse{Ok: false, Error: err.Error()}
+	}
 	s.mu.Lock()
-	if s.readySeen[req.ObjectId] == nil {
-		s.readySeen[req.ObjectId] = make(map[string]bool)
-	}
-	s.readySeen[req.ObjectId][peerAddr] = true
-	if len(s.readySeen[req.ObjectId]) >= 2*s.f+1 {
-		if ch := s.commitChan[req.ObjectId]; ch != nil {
-			select {
-			case <-ch:
-			default:
-				close(ch)
-			}
+	group, q := s.quorumLocked(req.ObjectId, fpcc)
+	if !slices.Contains(group, peerAddr) {
+		s.mu.Unlock()
+		return &protocol.ReadyResponse{Ok: false, Error: "sender not in object group"}
+	}
+	rk := roundKey(req.ObjectId, fpcc)
+	if s.readySeen[rk] == nil {
+		s.readySeen[rk] = make(map[string]bool)
+	}
+	before := len(s.readySeen[rk])
+	s.readySeen[rk][peerAddr] = true
+	committed := before < q.Ready && len(s.readySeen[rk]) >= q.Ready
+	promote := false
+	if len(s.readySeen[rk]) >= q.Ready {
+		// a round this node has not been dispersed to stays unknown here,
+		// so a late Disperse can still join it
+		if s.commitChan[rk] != nil {
+			s.closeCommitLocked(rk)
 		}
+		promote = fpcc.GetGeneration() > s.fpccs[req.ObjectId].GetGeneration() &&
+			(s.fpccs[req.ObjectId] != nil || s.pending[req.ObjectId] != nil)
 	}
 	s.mu.Unlock()
 
-	s.readyBatcher.Put([]byte(fmt.Sprintf("%s|%s", req.ObjectId, peerAddr)), []byte{1})
-	return &protocol.ReadyResponse{Ok: true}, nil
+	if promote {
+		s.promote(req.ObjectId, fpcc)
+	}
+	if committed && fpcc.GetGeneration() == 0 {
+		_ = s.metaDB.Update(func(tx *bolt.Tx) error { return s.markCommitted(tx, req.ObjectId) })
+	}
+	if committed && fpcc.GetManifest() != nil {
+		s.addRefs(req.ObjectId, fpcc)
+	}
+	s.readyBatcher.Put([]byte(fmt.Sprintf("%s|%s", rk, peerAddr)), []byte{1})
+	return &protocol.ReadyResponse{Ok: true}
 }
 
 /* --- Retrieve --- */
@@ -363,29 +760,214 @@ func (s *server) Retrieve(ctx context.Context, req *protocol.RetrieveRequest) (*
 	defer timer.ObserveDuration()
 	retrieveTotal.Inc()
 
-	frag, err := s.loadFragment(req.ObjectId, req.FragmentIndex)
+	if err := protocol.CheckID(req.ObjectId); err != nil {
+		return &protocol.RetrieveResponse{Ok: false, Error: err.Error()}, nil
+	}
+	f, err := s.vault.Open(s.fragPath(req.ObjectId, req.Generation, req.FragmentIndex))
 	if err != nil {
 		return &protocol.RetrieveResponse{Ok: false, Error: "fragment missing"}, nil
 	}
-	s.mu.Lock()
-	fpcc := s.fpccs[req.ObjectId]
-	s.mu.Unlock()
+	defer f.Close()
+	lo, hi, err := fragmentRange(f, req)
+	if err != nil {
+		return &protocol.RetrieveResponse{Ok: false, Error: err.Error()}, nil
+	}
+	frag := make([]byte, hi-lo)
+	if _, err := f.ReadAt(frag, lo); err != nil {
+		return &protocol.RetrieveResponse{Ok: false, Error: "fragment unreadable"}, nil
+	}
+	fpcc := s.generationFPCC(req.ObjectId, req.Generation)
+	s.countRetrieve(fpcc, req.FragmentIndex)
+	var proofs []*protocol.BlockProof
+	if req.Proofs {
+		if proofs, err = s.blockProofs(req.ObjectId, fpcc, req.FragmentIndex, f, lo, hi); err != nil {
+			return &protocol.RetrieveResponse{Ok: false, Error: err.Error()}, nil
+		}
+	}
 	return &protocol.RetrieveResponse{
 		Ok:            true,
 		Fragment:      frag,
 		FragmentIndex: req.FragmentIndex,
 		Fpcc:          fpcc,
+		Proofs:        proofs,
 	}, nil
 }
 
+// generationFPCC returns the FPCC of generation gen of obj: the current or
+// pending one, or a noncurrent version's.
+func (s *server) generationFPCC(obj string, gen uint64) *protocol.FPCC {
+	s.mu.Lock()
+	fpcc := s.roundFPCC(obj, gen)
+	s.mu.Unlock()
+	if fpcc == nil {
+		fpcc = s.versionFPCC(obj, gen)
+	}
+	return fpcc
+}
+
+// fragmentRange resolves req's offset and length against the fragment in f;
+// a zero length means the rest of the fragment.
+func fragmentRange(f storage.File, req *protocol.RetrieveRequest) (int64, int64, error) {
+	size := uint64(f.Size())
+	if req.Offset > size || req.Length > size-req.Offset {
+		return 0, 0, fmt.Errorf("range %d+%d outside %d‑byte fragment", req.Offset, req.Length, size)
+	}
+	end := size
+	if req.Length > 0 {
+		end = req.Offset + req.Length
+	}
+	return int64(req.Offset), int64(end), nil
+}
+
+// countRetrieve records a read of fragment idx as degraded when it is
+// parity: healthy reads take the m systematic data fragments.
+func (s *server) countRetrieve(fpcc *protocol.FPCC, idx uint32) {
+	if codec, err := s.codecOf(fpcc); err == nil {
+		if m, _ := codec.Shards(); int(idx) >= m {
+			degradedRetrieveTotal.Inc()
+		}
+	}
+}
+
+/* --- Stat --- */
+
+// Stat answers with the current version of an object, or with the version
+// whose FPCC digest the request names.
+func (s *server) Stat(ctx context.Context, req *protocol.StatRequest) (*protocol.StatResponse, error) {
+	if err := protocol.CheckID(req.ObjectId); err != nil {
+		return &protocol.StatResponse{Ok: false, Error: err.Error()}, nil
+	}
+	s.mu.Lock()
+	fpcc := s.fpccs[req.ObjectId]
+	if fpcc != nil && !s.committedLocked(req.ObjectId) {
+		fpcc = nil // a first round still running, or split by racing writers
+	}
+	s.mu.Unlock()
+	if fpcc == nil {
+		return &protocol.StatResponse{Ok: false, Error: "object not found"}, nil
+	}
+	var meta objectMeta
+	_ = s.metaDB.View(func(tx *bolt.Tx) error {
+		if len(req.Version) > 0 && !bytes.Equal(fpcc.Digest(), req.Version) {
+			fpcc = nil
+			for _, v := range s.versions(tx, req.ObjectId) {
+				if bytes.Equal(v.Fpcc.Digest(), req.Version) {
+					fpcc, meta.Created = v.Fpcc, v.Created
+				}
+			}
+			return nil
+		}
+		meta, _ = s.getMeta(tx, req.ObjectId)
+		return nil
+	})
+	if fpcc == nil {
+		return &protocol.StatResponse{Ok: false, Error: "version not found"}, nil
+	}
+	return &protocol.StatResponse{Ok: true, Fpcc: fpcc, CreatedUnix: meta.Created.Unix()}, nil
+}
+
+/* --- GetFPCC --- */
+
+// GetFPCC serves the FPCC of one round to a peer that saw only its digest.
+func (s *server) GetFPCC(ctx context.Context, req *protocol.GetFPCCRequest) (*protocol.GetFPCCResponse, error) {
+	if err := protocol.CheckID(req.ObjectId); err != nil {
+		return &protocol.GetFPCCResponse{Ok: false, Error: err.Error()}, nil
+	}
+	s.mu.Lock()
+	fpcc := s.roundFPCC(req.ObjectId, req.Generation)
+	if fpcc == nil {
+		fpcc = s.learned[learnedKey(req.ObjectId, req.Generation, req.Digest)]
+	}
+	s.mu.Unlock()
+	switch {
+	case fpcc == nil:
+		return &protocol.GetFPCCResponse{Ok: false, Error: "no FPCC for that round"}, nil
+	case len(req.Digest) > 0 && !bytes.Equal(fpcc.Digest(), req.Digest):
+		return &protocol.GetFPCCResponse{Ok: false, Error: "FPCC mismatch"}, nil
+	}
+	return &protocol.GetFPCCResponse{Ok: true, Fpcc: fpcc}, nil
+}
+
+/* --- Delete --- */
+
+func (s *server) Delete(ctx context.Context, req *protocol.DeleteRequest) (*protocol.DeleteResponse, error) {
+	if err := protocol.CheckID(req.ObjectId); err != nil {
+		return &protocol.DeleteResponse{Ok: false, Error: err.Error()}, nil
+	}
+	s.mu.Lock()
+	found := s.fpccs[req.ObjectId] != nil || s.pending[req.ObjectId] != nil
+	for k := range s.learned { // e.g. a manifest whose chunks this node holds
+		found = found || strings.HasPrefix(k, req.ObjectId+"@")
+	}
+	s.mu.Unlock()
+	if !found {
+		_ = s.metaDB.View(func(tx *bolt.Tx) error {
+			found = tx.Bucket([]byte(metaBucket)).Get([]byte(req.ObjectId)) != nil
+			return nil
+		})
+	}
+	if found {
+		log.Printf("[Delete] %s", req.ObjectId)
+		s.deleteObject(req.ObjectId)
+	}
+	return &protocol.DeleteResponse{Ok: true, Found: found}, nil
+}
+
+/* --- List --- */
+
+// listMax caps a List page.
+const listMax = 1000
+
+// List pages through the committed objects this node holds an FPCC for,
+// keeping those that carry every tag in req.Tags. Chunks of deduplicated
+// objects are internal and not listed.
+func (s *server) List(ctx context.Context, req *protocol.ListRequest) (*protocol.ListResponse, error) {
+	limit := int(req.Limit)
+	if limit == 0 || limit > listMax {
+		limit = listMax
+	}
+	var out []*protocol.ObjectEntry
+	s.mu.Lock()
+	for obj, fpcc := range s.fpccs {
+		if chunker.IsID(obj) || !strings.HasPrefix(obj, req.Prefix) || obj <= req.StartAfter || !s.committedLocked(obj) || !hasTags(fpcc, req.Tags) {
+			continue
+		}
+		out = append(out, &protocol.ObjectEntry{ObjectId: obj, Size: fpcc.ContentSize(), Generation: fpcc.GetGeneration(), Digest: fpcc.Digest()})
+	}
+	s.mu.Unlock()
+	slices.SortFunc(out, func(a, b *protocol.ObjectEntry) int { return strings.Compare(a.ObjectId, b.ObjectId) })
+	truncated := len(out) > limit
+	out = out[:min(len(out), limit)]
+	_ = s.metaDB.View(func(tx *bolt.Tx) error {
+		for _, e := range out {
+			if meta, ok := s.getMeta(tx, e.ObjectId); ok {
+				e.CreatedUnix, e.Metadata, e.Tags = meta.Created.Unix(), meta.Metadata, meta.Tags
+			}
+		}
+		return nil
+	})
+	return &protocol.ListResponse{Ok: true, Objects: out, Truncated: truncated}, nil
+}
+
+// hasTags reports whether fpcc carries every tag in want, with its value.
+func hasTags(fpcc *protocol.FPCC, want map[string]string) bool {
+	for k, v := range want {
+		if got, ok := fpcc.GetTags()[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
 func main() {
     // register metrics
-    prometheus.MustRegister(disperseTotal, disperseLatency, retrieveTotal, retrieveLatency)
+    prometheus.MustRegister(disperseTotal, disperseLatency, retrieveTotal, retrieveLatency, degradedRetrieveTotal)
 
     // ── Flags ────────────────────────────────────────────────────────────
     cfgPath       := flag.String("config", "", "YAML config file (required)")
     overridePeers := flag.String("peers", "", "comma‑separated peers – overrides YAML")
     snapshotDir   := flag.String("snapshot", "", "take on‑demand snapshot into this dir and exit")
+    rotateKey     := flag.Bool("rotate-key", false, "re‑seal all fragments and FPCCs under storage.key_file and exit (node must be stopped)")
     flag.Parse()
 
     // load configuration
@@ -400,6 +982,7 @@ func main() {
     // derive runtime vars
     port        := cfg.Server.GRPCPort
     metricsPort := cfg.Server.MetricsPort
+    httpPort    := cfg.Server.HTTPPort
     m, n        := cfg.Erasure.Data, cfg.Erasure.Total
     ttl         := cfg.Object.TTL
     dataDir     := cfg.Storage.Datadir
@@ -411,7 +994,30 @@ func main() {
         return
     }
 
-    self := fmt.Sprintf("localhost:%d", port)
+    vault, err := storage.OpenVault(cfg.Storage.KeyFile, cfg.Storage.OldKeyFiles)
+    if err != nil {
+        log.Fatalf("storage key: %v", err)
+    }
+    if *rotateKey {
+        if vault == nil {
+            log.Fatalf("-rotate-key needs storage.key_file")
+        }
+        runRotate(vault, dataDir, dbPath)
+        return
+    }
+
+    tlsConf, err := cfg.TLSConfig()
+    if err == nil && tlsConf != nil {
+        err = useTLS(tlsConf)
+    }
+    if err != nil {
+        log.Fatalf("tls: %v", err)
+    }
+
+    self := cfg.Cluster.Self
+    if self == "" {
+        self = fmt.Sprintf("localhost:%d", port)
+    }
     peers := append([]string{}, cfg.Cluster.Peers...)
 
     // ── /metrics endpoint ────────────────────────────────────────────────
@@ -433,25 +1039,47 @@ func main() {
         log.Fatalf("bolt.Open: %v", err)
     }
     defer db.Close()
-    db.Update(func(tx *bolt.Tx) error {
-        for _, b := range []string{fpccsBucket, echoBucket, readyBucket, metaBucket} {
-            tx.CreateBucketIfNotExists([]byte(b))
-        }
-        return nil
-    })
+    createBuckets(db)
 
     // start server
-    s := newServer(self, peers, m, n, db, dataDir, ttl)
-    go s.gcLoop()
+    initial := membership.Initial(peers, cfg.Labels())
+    initial.FailureDomain = cfg.Placement.FailureDomain
+    s := newServer(self, initial, m, n, db, dataDir, vault, ttl)
+    s.keepVersions, s.noncurrentTTL = cfg.Versioning.Keep, cfg.Versioning.NoncurrentTTL
+    if err := checkLifecycle(cfg.Lifecycle, cfg.Erasure.Codec); err != nil {
+        log.Fatalf("config: %v", err)
+    }
+    s.lifecycle = cfg.Lifecycle
+    time.AfterFunc(retireGrace, s.retireAll) // transcodes that committed before a restart
+    go s.viewSyncLoop()
+    go s.rebalanceLoop()
+    s.requestRebalance() // finish any move interrupted by a restart
+    s.maybeStartDrain()
+
+    // ── coordinator: PutObject / GetObject and the HTTP API ──────────────
+    if s.coord, err = newCoordinator(cfg, peers, self); err != nil {
+        log.Fatalf("coordinator: %v", err)
+    }
+    defer s.coord.Close()
+    go s.gcLoop() // lifecycle transcodes go through s.coord
+    if httpPort != 0 {
+        go func() {
+            log.Fatalf("HTTP API: %v", s.serveHTTPAPI(httpPort))
+        }()
+    }
 
     lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
     if err != nil {
         log.Fatalf("listen: %v", err)
     }
-    grpcServer := grpc.NewServer()
+    grpcServer := grpc.NewServer(serverOpts()...)
     protocol.RegisterDispersalServer(grpcServer, s)
-    log.Printf("node %s  m=%d n=%d f=%d data=%s peers=%v metrics=%d",
-        self, m, n, s.f, dataDir, peers, metricsPort)
+    protocol.RegisterMembershipServer(grpcServer, s)
+    log.Printf("node %s  m=%d n=%d f=%d data=%s epoch=%d peers=%v metrics=%d",
+        self, m, n, s.f, dataDir, s.view.Epoch, s.peers, metricsPort)
+    if vault != nil {
+        log.Printf("at‑rest encryption on, node key %s", vault.KeyID())
+    }
     grpcServer.Serve(lis)
 }
 
@@ -462,45 +1090,90 @@ func main() {
 /* ------------------------------------------------------------------------ */
 
 func (s *server) gcLoop() {
-    tick := time.NewTicker(s.ttl / 2)
+    tick := time.NewTicker(min(s.ttl/2, gcInterval))
     for range tick.C {
         s.gcExpired()
+        s.pruneAllVersions()
+        s.applyLifecycle()
     }
 }
 
+// gcExpired deletes the objects past their expiry (see lifecycle.go).
+// Chunks of deduplicated objects stay while a live manifest refers to
+// them; manifests this node only counted references for are forgotten
+// once those lapse.
 func (s *server) gcExpired() {
     now := time.Now()
+    live, released := s.sweepRefs(now)
+    s.mu.Lock()
+    fpccs := maps.Clone(s.fpccs)
+    s.mu.Unlock()
     var expired []string
     s.metaDB.View(func(tx *bolt.Tx) error {
         b := tx.Bucket([]byte(metaBucket))
         b.ForEach(func(k, v []byte) error {
-            var meta struct{ Created time.Time }
-            if json.Unmarshal(v, &meta) == nil && now.Sub(meta.Created) > s.ttl {
-                expired = append(expired, string(k))
+            obj := string(k)
+            if meta, ok := s.decodeMeta(obj, v); ok && now.After(s.expiry(obj, fpccs[obj], meta.Created)) && !live[obj].After(now) {
+                expired = append(expired, obj)
             }
             return nil
         })
+        for _, obj := range released {
+            if b.Get([]byte(obj)) == nil {
+                expired = append(expired, obj)
+            }
+        }
         return nil
     })
     for _, obj := range expired {
+        log.Printf("GC delete %s", obj)
         s.deleteObject(obj)
     }
 }
 
 func (s *server) deleteObject(obj string) {
-    log.Printf("GC delete %s", obj)
     os.RemoveAll(filepath.Join(s.dataDir, obj))
+    s.mu.Lock()
+    man := s.fpccs[obj].GetManifest()
+    delete(s.fpccs, obj)
+    delete(s.pending, obj)
+    delete(s.fences, obj)
+    for k, f := range s.learned {
+        if strings.HasPrefix(k, obj+"@") || strings.HasPrefix(k, obj+"#") {
+            if man == nil {
+                man = f.GetManifest() // a manifest this node only counts references for
+            }
+            delete(s.learned, k)
+        }
+    }
+    // forget its rounds too, so the ID can be dispersed afresh – as a
+    // chunk collected and uploaded again will be
+    round := func(rk string) bool { return rk == obj || strings.HasPrefix(rk, obj+"#") }
+    maps.DeleteFunc(s.commitChan, func(rk string, _ chan struct{}) bool { return round(rk) })
+    maps.DeleteFunc(s.echoSeen, func(rk string, _ map[string]bool) bool { return round(rk) })
+    maps.DeleteFunc(s.readySeen, func(rk string, _ map[string]bool) bool { return round(rk) })
+    maps.DeleteFunc(s.readySent, func(rk string, _ bool) bool { return round(rk) })
+    s.mu.Unlock()
     s.metaDB.Update(func(tx *bolt.Tx) error {
-        for _, b := range []string{fpccsBucket, echoBucket, readyBucket, metaBucket} {
+        if man != nil {
+            dropRefs(tx, obj, man)
+        }
+        for _, v := range s.versions(tx, obj) {
+            if vm := v.Fpcc.GetManifest(); vm != nil {
+                dropRefs(tx, obj, vm)
+            }
+        }
+        for _, b := range []string{fpccsBucket, echoBucket, readyBucket, metaBucket, versionsBucket} {
             bkt := tx.Bucket([]byte(b))
             if b == fpccsBucket || b == metaBucket {
                 bkt.Delete([]byte(obj))
                 continue
             }
-            prefix := obj + "|"
-            c := bkt.Cursor()
-            for k, _ := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, _ = c.Next() {
-                bkt.Delete(k)
+            for _, prefix := range []string{obj + "|", obj + "#"} { // first and later generations, versions
+                c := bkt.Cursor()
+                for k, _ := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, _ = c.Next() {
+                    bkt.Delete(k)
+                }
             }
         }
         return nil
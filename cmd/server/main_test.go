@@ -0,0 +1,1555 @@
+// cmd/server/main_test.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/blockhash"
+	"github.com/dattu/distributed_object_store/pkg/chunker"
+	"github.com/dattu/distributed_object_store/pkg/client"
+	"github.com/dattu/distributed_object_store/pkg/config"
+	"github.com/dattu/distributed_object_store/pkg/erasure"
+	"github.com/dattu/distributed_object_store/pkg/fingerprint"
+	"github.com/dattu/distributed_object_store/pkg/membership"
+	"github.com/dattu/distributed_object_store/pkg/merkle"
+	"github.com/dattu/distributed_object_store/pkg/placement"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+	"github.com/dattu/distributed_object_store/pkg/storage"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// testServer returns a server for self in a view of peers, backed by a
+// fresh database and data directory; nothing listens on its address.
+func testServer(t *testing.T, self string, peers []string, m, n int) *server {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := bolt.Open(filepath.Join(dir, "store.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	createBuckets(db)
+	return newServer(self, membership.Initial(peers, nil), m, n, db, filepath.Join(dir, "data"), nil, time.Hour)
+}
+
+func TestCommitView(t *testing.T) {
+	peers := []string{"a:1", "b:1", "c:1"}
+	s := testServer(t, "a:1", peers, 2, 3)
+	ctx := context.Background()
+	added, _ := membership.Add(s.view, "d:1", nil)
+
+	// a commit nobody proposed here is refused
+	if resp, _ := s.CommitView(ctx, &protocol.CommitViewRequest{View: added}); resp.Ok || s.view.Epoch != 0 {
+		t.Fatalf("unproposed view installed: %+v, epoch %d", resp, s.view.Epoch)
+	}
+	skip, _ := membership.Add(added, "e:1", nil)
+	if resp, _ := s.ProposeView(ctx, &protocol.ProposeViewRequest{View: skip, BaseEpoch: 0}); resp.Ok {
+		t.Fatal("proposal two epochs ahead accepted")
+	}
+
+	if resp, _ := s.ProposeView(ctx, &protocol.ProposeViewRequest{View: added, BaseEpoch: 0}); !resp.Ok {
+		t.Fatalf("ProposeView: %s", resp.Error)
+	}
+	other, _ := membership.Add(s.view, "x:1", nil)
+	if resp, _ := s.CommitView(ctx, &protocol.CommitViewRequest{View: other}); resp.Ok || s.view.Epoch != 0 {
+		t.Fatal("commit of a view other than the accepted one installed")
+	}
+	if resp, _ := s.CommitView(ctx, &protocol.CommitViewRequest{View: added}); !resp.Ok || !membership.Equal(s.view, added) {
+		t.Fatalf("accepted view not installed: %+v", resp)
+	}
+}
+
+func TestMajorityView(t *testing.T) {
+	cur := membership.Initial([]string{"a:1", "b:1", "c:1", "d:1", "e:1"}, nil)
+	next, _ := membership.Add(cur, "f:1", nil)
+	forged, _ := membership.Remove(cur, "b:1")
+	later, _ := membership.Add(next, "g:1", nil)
+
+	for _, tc := range []struct {
+		name  string
+		views []*protocol.View
+		want  *protocol.View
+	}{
+		{"none", nil, nil},
+		{"one peer", []*protocol.View{forged}, nil},
+		{"split", []*protocol.View{next, next, forged, forged}, nil},
+		{"majority", []*protocol.View{next, forged, next, next}, next},
+		{"newest majority", []*protocol.View{later, later, later, next, next}, later},
+	} {
+		if got := majorityView(cur, tc.views); got != tc.want {
+			t.Errorf("%s: got epoch %d, want %v", tc.name, got.GetEpoch(), tc.want)
+		}
+	}
+}
+
+func TestDrainChecksHeldProfiles(t *testing.T) {
+	s := testServer(t, "a:1", []string{"a:1", "b:1", "c:1", "d:1"}, 2, 3)
+	if _, err := s.drainView(s.view, "d:1"); err != nil {
+		t.Fatalf("3 nodes left for 2‑of‑3: %v", err)
+	}
+	// an object of its own, wider profile no longer fits on three nodes
+	s.fpccs["wide"] = testFPCC(erasure.RS, 3, 4)
+	if _, err := s.drainView(s.view, "d:1"); !errors.Is(err, placement.ErrUnsatisfiable) {
+		t.Fatalf("drain with a 3‑of‑4 object held: %v", err)
+	}
+}
+
+// testFPCC returns an FPCC with the given profile and placeholder hashes.
+func testFPCC(codec string, m, n int) *protocol.FPCC {
+	fpcc := &protocol.FPCC{Profile: &protocol.Profile{Codec: codec, Data: uint32(m), Total: uint32(n)}}
+	for i := 0; i < n; i++ {
+		fpcc.Hashes = append(fpcc.Hashes, []byte{byte(i)})
+		fpcc.Fps = append(fpcc.Fps, uint64(i))
+	}
+	return fpcc
+}
+
+func TestProfileRoundTrip(t *testing.T) {
+	s := testServer(t, "a:1", []string{"a:1", "b:1", "c:1"}, 2, 3)
+	legacy := testFPCC(erasure.RS, 2, 4)
+	legacy.Profile = nil // written before FPCCs recorded a profile
+	objs := map[string]*protocol.FPCC{
+		"rs":     testFPCC(erasure.RS, 3, 5),
+		"lrc":    testFPCC(erasure.LRC, 6, 10),
+		"legacy": legacy,
+	}
+	err := s.metaDB.Update(func(tx *bolt.Tx) error {
+		for obj, fpcc := range objs {
+			if err := s.putFPCC(tx, obj, fpcc); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a restarted node reads every object's profile back from its FPCC
+	r := newServer(s.selfAddr, s.view, s.m, s.n, s.metaDB, s.dataDir, nil, s.ttl)
+	for obj, want := range map[string][2]int{"rs": {3, 5}, "lrc": {7, 10}, "legacy": {2, 4}} {
+		fpcc := r.fpccs[obj]
+		if !eqFPCC(fpcc, objs[obj]) {
+			t.Errorf("%s: FPCC changed across restart", obj)
+		}
+		if m, n := r.profileOf(fpcc); m != want[0] || n != want[1] {
+			t.Errorf("%s: profile %d‑of‑%d, want %d‑of‑%d", obj, m, n, want[0], want[1])
+		}
+	}
+
+	for name, p := range map[string]*protocol.Profile{
+		"data > total":   {Data: 4, Total: 3},
+		"total ≠ hashes": {Data: 2, Total: 4},
+		"unknown codec":  {Codec: "xor", Data: 2, Total: 3},
+	} {
+		fpcc := testFPCC(erasure.RS, 2, 3)
+		fpcc.Profile = p
+		if validFPCC(fpcc) {
+			t.Errorf("%s: FPCC accepted", name)
+		}
+	}
+}
+
+// commitFPCC makes fpcc obj's current version, created at created, as if
+// its Ready round had completed here.
+func commitFPCC(t *testing.T, s *server, obj string, fpcc *protocol.FPCC, created time.Time) {
+	t.Helper()
+	s.mu.Lock()
+	s.fpccs[obj] = fpcc
+	rk := roundKey(obj, fpcc)
+	s.readySeen[rk] = make(map[string]bool)
+	for _, p := range s.peers {
+		s.readySeen[rk][p] = true
+	}
+	s.closeCommitLocked(rk)
+	s.mu.Unlock()
+	err := s.metaDB.Update(func(tx *bolt.Tx) error {
+		if err := s.putFPCC(tx, obj, fpcc); err != nil {
+			return err
+		}
+		meta := newMeta(fpcc, created)
+		meta.Committed = true
+		return s.putMeta(tx, obj, meta)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeFragments puts placeholder fragments of generation gen of obj on disk.
+func writeFragments(t *testing.T, s *server, obj string, gen uint64, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := s.persistFragment(obj, gen, uint32(i), []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// generations returns which generations of obj have fragments on disk.
+func generations(s *server, obj string, upTo uint64) []uint64 {
+	var out []uint64
+	for g := uint64(0); g <= upTo; g++ {
+		if _, err := os.Stat(s.fragPath(obj, g, 0)); err == nil {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+func TestPromoteAndRetire(t *testing.T) {
+	defer func(g time.Duration) { retireGrace = g }(retireGrace)
+	retireGrace = 50 * time.Millisecond
+	s := testServer(t, "a:1", []string{"a:1", "b:1", "c:1"}, 2, 3)
+	s.keepVersions = 1
+	created := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	// a transcode replaces generation 0 and keeps its creation time
+	commitFPCC(t, s, "t", testFPCC(erasure.RS, 2, 3), created)
+	writeFragments(t, s, "t", 0, 3)
+	next := testFPCC(erasure.RS, 3, 4)
+	next.Generation, next.Transcoded = 1, true
+	writeFragments(t, s, "t", 1, 4)
+	s.pending["t"] = next
+	if got := s.roundFPCC("t", 1); got != next {
+		t.Fatal("roundFPCC misses the pending generation")
+	}
+	s.promote("t", next)
+	if s.fpccs["t"] != next || s.pending["t"] != nil || s.roundFPCC("t", 0) != nil {
+		t.Fatal("transcode not promoted")
+	}
+	_ = s.metaDB.View(func(tx *bolt.Tx) error {
+		if meta, _ := s.getMeta(tx, "t"); !meta.Created.Equal(created) {
+			t.Errorf("transcode moved creation time to %v", meta.Created)
+		}
+		if v := s.versions(tx, "t"); len(v) != 0 {
+			t.Errorf("transcode recorded %d versions", len(v))
+		}
+		return nil
+	})
+	// readers in flight keep the old generation for the grace period
+	if got := generations(s, "t", 1); !slices.Equal(got, []uint64{0, 1}) {
+		t.Fatalf("generations on disk right after promote: %v", got)
+	}
+	waitFor(t, "generation 0 to retire", func() bool { return slices.Equal(generations(s, "t", 1), []uint64{1}) })
+
+	// a new version supersedes generation 0, which retire keeps
+	commitFPCC(t, s, "v", testFPCC(erasure.RS, 2, 3), created)
+	writeFragments(t, s, "v", 0, 3)
+	v1 := testFPCC(erasure.RS, 2, 3)
+	v1.Generation = 1
+	writeFragments(t, s, "v", 1, 3)
+	s.promote("v", v1)
+	if s.versionFPCC("v", 0) == nil {
+		t.Fatal("superseded version not kept")
+	}
+	s.retire("v")
+	if got := generations(s, "v", 1); !slices.Equal(got, []uint64{0, 1}) {
+		t.Fatalf("retire dropped a kept version: %v", got)
+	}
+
+	// a node that checked its fragment against one FPCC refuses another
+	p := testFPCC(erasure.RS, 2, 3)
+	p.Generation = 2
+	s.mu.Lock()
+	s.pending["v"] = p
+	s.mu.Unlock()
+	forged := testFPCC(erasure.RS, 2, 4)
+	forged.Generation = 2
+	s.promote("v", forged)
+	if s.generationFPCC("v", 1) != v1 || s.generationFPCC("v", 2) != p {
+		t.Fatal("promoted an FPCC other than the dispersed one")
+	}
+
+	// generation 2 commits while the node is down: retireAll sweeps
+	// generation 1 after a restart, and the kept version stays
+	v2 := testFPCC(erasure.RS, 2, 3)
+	v2.Generation = 2
+	writeFragments(t, s, "v", 2, 3)
+	s.promote("v", v2)
+	s.keepVersions = 0
+	s.pruneVersions("v", time.Now())
+	s.retireAll()
+	if got := generations(s, "v", 2); !slices.Equal(got, []uint64{2}) {
+		t.Fatalf("retireAll left %v", got)
+	}
+}
+
+// testVault returns a vault under a fresh node key.
+func testVault(t *testing.T) *storage.Vault {
+	t.Helper()
+	key := filepath.Join(t.TempDir(), "node.key")
+	if err := os.WriteFile(key, bytes.Repeat([]byte{7}, 32), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	v, err := storage.OpenVault(key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestLeavesSealed(t *testing.T) {
+	s := testServer(t, "a:1", []string{"a:1", "b:1", "c:1"}, 2, 3)
+	s.vault = testVault(t)
+	const stripe = 64
+	frag := bytes.Repeat([]byte("block"), 3*stripe)
+	w := blockhash.New(stripe)
+	w.Write(frag)
+	leaves := w.Sums()
+	fpcc := testFPCC(erasure.RS, 2, 3)
+	fpcc.Profile.Stripe = stripe
+	fpcc.Roots = [][]byte{merkle.Root(leaves), nil, nil}
+
+	if err := os.MkdirAll(filepath.Dir(s.fragPath("o", 0, 0)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.saveLeaves("o", 0, 0, leaves); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(s.leavesPath("o", 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, leaves[0]) {
+		t.Fatal("leaf hashes stored in the clear")
+	}
+	if info, _ := os.Stat(s.leavesPath("o", 0, 0)); info.Mode().Perm() != 0o600 {
+		t.Fatalf("sidecar mode %v", info.Mode().Perm())
+	}
+	// read back through the vault rather than rebuilt from the fragment
+	got, err := s.fragmentLeaves("o", fpcc, 0, nil)
+	if err != nil || len(got) != len(leaves) || !bytes.Equal(got[1], leaves[1]) {
+		t.Fatalf("fragmentLeaves: %d leaves, %v", len(got), err)
+	}
+}
+
+// from returns ctx as if it arrived over a connection from ip, carrying
+// cert as the peer's verified certificate if it is not nil.
+func from(ip string, cert *x509.Certificate) context.Context {
+	p := &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 40000}}
+	if cert != nil {
+		p.AuthInfo = credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}
+	}
+	return peer.NewContext(context.Background(), p)
+}
+
+func TestSenderAuthentication(t *testing.T) {
+	s := testServer(t, "127.0.0.1:1", []string{"127.0.0.1:1", "127.0.0.1:2", "10.0.0.3:1"}, 2, 3)
+	named := func(cn string) *x509.Certificate { return &x509.Certificate{Subject: pkix.Name{CommonName: cn}} }
+	for _, tc := range []struct {
+		name, claimed string
+		ctx           context.Context
+		ok            bool
+	}{
+		{"same host", "127.0.0.1:2", from("127.0.0.1", nil), true},
+		{"other host", "10.0.0.3:1", from("127.0.0.1", nil), false},
+		{"non‑member", "127.0.0.1:9", from("127.0.0.1", nil), false},
+		{"no claim", "", from("127.0.0.1", nil), false},
+		{"certificate", "10.0.0.3:1", from("127.0.0.1", named("10.0.0.3:1")), true},
+		{"someone else's name", "10.0.0.3:1", from("10.0.0.3", named("127.0.0.1:2")), false},
+		{"no certificate", "10.0.0.3:1", peer.NewContext(context.Background(), &peer.Peer{
+			Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.3")}, AuthInfo: credentials.TLSInfo{}}), false},
+	} {
+		if _, err := s.sender(tc.ctx, tc.claimed); (err == nil) != tc.ok {
+			t.Errorf("%s: err %v", tc.name, err)
+		}
+	}
+
+	// one member cannot echo for the others to make up a quorum
+	fpcc := testFPCC(erasure.RS, 2, 3)
+	s.fpccs["o"] = fpcc
+	resp, _ := s.Echo(from("127.0.0.1", nil), &protocol.EchoRequest{ObjectId: "o", Digest: fpcc.Digest(), Sender: "10.0.0.3:1"})
+	if resp.Ok || s.echoSeen["o"]["10.0.0.3:1"] {
+		t.Fatalf("forged Echo counted: %+v", resp)
+	}
+	if resp, _ := s.Echo(from("127.0.0.1", nil), &protocol.EchoRequest{ObjectId: "o", Digest: fpcc.Digest(), Sender: "127.0.0.1:2"}); !resp.Ok {
+		t.Fatalf("genuine Echo refused: %s", resp.Error)
+	}
+}
+
+// slowPeer answers GetFPCC from fpccs after delay.
+type slowPeer struct {
+	protocol.UnimplementedDispersalServer
+	delay time.Duration
+	fpccs map[string]*protocol.FPCC
+}
+
+func (p *slowPeer) GetFPCC(ctx context.Context, req *protocol.GetFPCCRequest) (*protocol.GetFPCCResponse, error) {
+	time.Sleep(p.delay)
+	return &protocol.GetFPCCResponse{Ok: true, Fpcc: p.fpccs[req.ObjectId]}, nil
+}
+
+func TestBatchFetchesFPCCsConcurrently(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &slowPeer{delay: 300 * time.Millisecond, fpccs: make(map[string]*protocol.FPCC)}
+	g := grpc.NewServer()
+	protocol.RegisterDispersalServer(g, p)
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+
+	addr := lis.Addr().String()
+	s := testServer(t, "127.0.0.1:1", []string{"127.0.0.1:1", addr, "127.0.0.1:3"}, 2, 3)
+	req := &protocol.EchoBatchRequest{}
+	for i := 0; i < 3*prefetchParallel; i++ {
+		obj := fmt.Sprintf("o%d", i)
+		fpcc := testFPCC(erasure.RS, 2, 3)
+		fpcc.Size = uint64(i)
+		p.fpccs[obj] = fpcc
+		req.Echoes = append(req.Echoes, &protocol.EchoRequest{ObjectId: obj, Digest: fpcc.Digest(), Sender: addr})
+	}
+	// the same round twice is fetched once
+	req.Echoes = append(req.Echoes, req.Echoes[0])
+
+	// one at a time the fetches would take 48 × 300ms
+	start := time.Now()
+	out, _ := s.EchoBatch(from("127.0.0.1", nil), req)
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("batch took %v", d)
+	}
+	for i, r := range out.Results {
+		if !r.Ok {
+			t.Fatalf("echo %d: %s", i, r.Error)
+		}
+	}
+
+	// a sender that fails authentication is not asked at all
+	bad := &protocol.ReadyBatchRequest{Readies: []*protocol.ReadyRequest{{ObjectId: "x", Digest: []byte("d"), Sender: "10.0.0.9:1"}}}
+	if out, _ := s.ReadyBatch(from("127.0.0.1", nil), bad); out.Results[0].Ok {
+		t.Fatal("ready from an unauthenticated sender accepted")
+	}
+}
+
+func TestOutboxDroppedWithMember(t *testing.T) {
+	peers := []string{"127.0.0.1:1", "127.0.0.1:2", "127.0.0.1:3"}
+	s := testServer(t, peers[0], peers, 2, 3)
+	for _, addr := range peers[1:] {
+		s.gossip(addr, gossipMsg{echo: &protocol.EchoRequest{ObjectId: "o", Sender: peers[0]}})
+	}
+	s.outboxMu.Lock()
+	gone := s.outboxes[peers[2]]
+	s.outboxMu.Unlock()
+
+	next := membership.Initial(peers[:2], nil)
+	next.Epoch = s.currentView().Epoch + 1
+	if !s.installView(next) {
+		t.Fatal("view not installed")
+	}
+	s.outboxMu.Lock()
+	_, kept := s.outboxes[peers[1]]
+	_, left := s.outboxes[peers[2]]
+	s.outboxMu.Unlock()
+	if !kept || left {
+		t.Fatalf("outboxes after the view change: kept %v, left %v", kept, left)
+	}
+	select {
+	case <-gone.done:
+	default:
+		t.Fatal("outbox of the departed member still running")
+	}
+	// the departed member can still be gossiped to; it gets a new outbox
+	s.gossip(peers[2], gossipMsg{echo: &protocol.EchoRequest{ObjectId: "o", Sender: peers[0]}})
+}
+
+// restart returns a fresh server over s's database and data directory.
+func restart(s *server) *server {
+	return newServer(s.selfAddr, s.currentView(), s.m, s.n, s.metaDB, s.dataDir, s.vault, s.ttl)
+}
+
+func (s *server) committed(obj string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.committedLocked(obj)
+}
+
+func TestCommittedMarker(t *testing.T) {
+	peers := []string{"127.0.0.1:1", "127.0.0.1:2", "127.0.0.1:3"}
+	s := testServer(t, peers[0], peers, 2, 3)
+
+	// an object handed off is committed, though no Ready was counted here
+	adopted := testFPCC(erasure.RS, 2, 3)
+	s.adoptFPCC("adopted", adopted)
+	if !s.committed("adopted") {
+		t.Fatal("adopted object not committed")
+	}
+	if st, _ := s.Stat(context.Background(), &protocol.StatRequest{ObjectId: "adopted"}); !st.Ok {
+		t.Errorf("Stat of an adopted object: %s", st.Error)
+	}
+	next := testFPCC(erasure.RS, 2, 3)
+	next.Generation, next.IfMatchVersion = 1, adopted.Digest()
+	s.mu.Lock()
+	err := s.preconditionLocked("adopted", next)
+	s.mu.Unlock()
+	if err != nil {
+		t.Errorf("IfMatchVersion write to an adopted object: %v", err)
+	}
+
+	// a dispersed first version commits with its Ready quorum
+	fpcc := testFPCC(erasure.RS, 2, 3)
+	fpcc.Size = 1
+	s.mu.Lock()
+	s.fpccs["o"] = fpcc
+	s.commitChan["o"] = make(chan struct{})
+	s.mu.Unlock()
+	s.metaDB.Update(func(tx *bolt.Tx) error {
+		s.putFPCC(tx, "o", fpcc)
+		return s.putMeta(tx, "o", newMeta(fpcc, time.Now()))
+	})
+	if s.committed("o") || restart(s).committed("o") {
+		t.Fatal("object committed before its Readies")
+	}
+	for _, p := range peers {
+		if r, _ := s.Ready(from("127.0.0.1", nil), &protocol.ReadyRequest{ObjectId: "o", Digest: fpcc.Digest(), Sender: p}); !r.Ok {
+			t.Fatalf("Ready from %s: %s", p, r.Error)
+		}
+	}
+	if !s.committed("o") {
+		t.Fatal("object not committed after a Ready quorum")
+	}
+
+	// both survive a restart
+	r := restart(s)
+	for _, obj := range []string{"adopted", "o"} {
+		if !r.committed(obj) {
+			t.Errorf("%s not committed after a restart", obj)
+		}
+	}
+}
+
+func TestDisperseRejectsPathIDs(t *testing.T) {
+	peers := []string{"127.0.0.1:1", "127.0.0.1:2", "127.0.0.1:3"}
+	s := testServer(t, peers[0], peers, 2, 3)
+	fpcc := testFPCC(erasure.RS, 2, 3)
+	for _, id := range []string{"../escape", "a/b", "..", ""} {
+		resp := s.disperse(&protocol.DisperseRequest{ObjectId: id, Fpcc: fpcc}, bytes.NewReader([]byte{0}))
+		if resp.Ok {
+			t.Errorf("Disperse of %q accepted", id)
+		}
+		h, _ := s.Handoff(from("127.0.0.1", nil), &protocol.HandoffRequest{ObjectId: id, Fpcc: fpcc, Sender: peers[1], Fragment: []byte{0}})
+		if h.Ok {
+			t.Errorf("Handoff of %q accepted", id)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(s.dataDir), "escape")); err == nil {
+		t.Error("a fragment was written outside the data directory")
+	}
+}
+
+// TestReadsRejectPathIDs checks the RPCs that name an existing object
+// refuse IDs that would reach outside the data directory.
+func TestReadsRejectPathIDs(t *testing.T) {
+	peers := []string{"127.0.0.1:1", "127.0.0.1:2", "127.0.0.1:3"}
+	s := testServer(t, peers[0], peers, 2, 3)
+	ctx := context.Background()
+	for _, id := range []string{"../escape", "a/b", "..", ""} {
+		if r, _ := s.Retrieve(ctx, &protocol.RetrieveRequest{ObjectId: id}); r.Ok || !strings.Contains(r.Error, "ID") {
+			t.Errorf("Retrieve of %q: %+v", id, r)
+		}
+		stream := &chunkSink{}
+		if err := s.RetrieveStream(&protocol.RetrieveRequest{ObjectId: id}, stream); err != nil || len(stream.chunks) != 1 || stream.chunks[0].Ok || !strings.Contains(stream.chunks[0].Error, "ID") {
+			t.Errorf("RetrieveStream of %q: %v %+v", id, err, stream.chunks)
+		}
+		if r, _ := s.Locate(ctx, &protocol.LocateRequest{ObjectId: id}); r.Ok {
+			t.Errorf("Locate of %q accepted", id)
+		}
+		if r, _ := s.Stat(ctx, &protocol.StatRequest{ObjectId: id}); r.Ok || !strings.Contains(r.Error, "ID") {
+			t.Errorf("Stat of %q: %+v", id, r)
+		}
+		if r, _ := s.Delete(ctx, &protocol.DeleteRequest{ObjectId: id}); r.Ok || r.Found {
+			t.Errorf("Delete of %q accepted", id)
+		}
+	}
+}
+
+// TestKeySeparatorIDs checks an ID holding "#" is refused everywhere: the
+// rounds, versions and echo keys of "c#1" would be those of generation 1
+// of "c".
+func TestKeySeparatorIDs(t *testing.T) {
+	peers := []string{"127.0.0.1:1", "127.0.0.1:2", "127.0.0.1:3"}
+	s := testServer(t, peers[0], peers, 2, 3)
+	ctx := from("127.0.0.1", nil)
+	fpcc := testFPCC(erasure.RS, 2, 3)
+	commitFPCC(t, s, "c", fpcc, time.Now())
+	next := testFPCC(erasure.RS, 2, 3)
+	next.Generation = 1
+	s.mu.Lock()
+	s.learned[learnedKey("c", 1, next.Digest())] = next // as if fetched for a transcode of c
+	s.mu.Unlock()
+
+	if resp := s.disperse(&protocol.DisperseRequest{ObjectId: "c#1", Fpcc: next}, bytes.NewReader([]byte{0})); resp.Ok {
+		t.Error("Disperse of c#1 accepted")
+	}
+	if r := s.echo(ctx, &protocol.EchoRequest{ObjectId: "c#1", Digest: next.Digest(), Sender: peers[1]}, false); r.Ok {
+		t.Error("Echo of c#1 accepted")
+	}
+	if r := s.ready(ctx, &protocol.ReadyRequest{ObjectId: "c#1", Digest: next.Digest(), Sender: peers[1]}, false); r.Ok {
+		t.Error("Ready of c#1 accepted")
+	}
+	if r, _ := s.GetFPCC(ctx, &protocol.GetFPCCRequest{ObjectId: "c#1"}); r.Ok {
+		t.Error("GetFPCC of c#1 accepted")
+	}
+	if r, _ := s.Versions(ctx, &protocol.VersionsRequest{ObjectId: "c#1"}); r.Ok {
+		t.Error("Versions of c#1 accepted")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.echoSeen[roundKey("c", next)]) > 0 || len(s.readySeen[roundKey("c", next)]) > 0 {
+		t.Error("a round of c#1 counted towards generation 1 of c")
+	}
+}
+
+// chunkSink collects what RetrieveStream sends.
+type chunkSink struct {
+	grpc.ServerStream
+	chunks []*protocol.RetrieveChunk
+}
+
+func (c *chunkSink) Send(m *protocol.RetrieveChunk) error {
+	c.chunks = append(c.chunks, m)
+	return nil
+}
+
+// dispersal is one writer's Disperse of content to s, run in the background.
+type dispersal struct {
+	fpcc *protocol.FPCC
+	done chan *protocol.DisperseResponse
+}
+
+// disperseTo sends s the fragment placed on it of a 2-of-3 object whose
+// fragments all hold content, as generation gen under the precondition
+// cond sets. The answer comes once the round commits, or at once if s
+// refuses it.
+func disperseTo(t *testing.T, s *server, obj string, gen uint64, content string, cond func(*protocol.FPCC)) dispersal {
+	t.Helper()
+	owners, err := placement.ForView(s.currentView(), obj, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fpcc := &protocol.FPCC{Generation: gen, Seed: 7, Profile: &protocol.Profile{Codec: erasure.RS, Data: 2, Total: 3}}
+	sum := sha256.Sum256([]byte(content))
+	for range owners {
+		fpcc.Hashes = append(fpcc.Hashes, sum[:])
+		fpcc.Fps = append(fpcc.Fps, fingerprint.NewWithSeed(fpcc.Seed).Eval([]byte(content)))
+	}
+	cond(fpcc)
+	d := dispersal{fpcc, make(chan *protocol.DisperseResponse, 1)}
+	req := &protocol.DisperseRequest{ObjectId: obj, FragmentIndex: uint32(slices.Index(owners, s.selfAddr)), Fpcc: fpcc}
+	go func() { d.done <- s.disperse(req, strings.NewReader(content)) }()
+	return d
+}
+
+// answer returns d's answer, failing if it does not come within wait.
+func (d dispersal) answer(t *testing.T, wait time.Duration) *protocol.DisperseResponse {
+	t.Helper()
+	select {
+	case resp := <-d.done:
+		return resp
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// opened waits until s has taken d's round up.
+func opened(t *testing.T, s *server, obj string, d dispersal) {
+	t.Helper()
+	waitFor(t, "the round to open", func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		f := s.roundFPCC(obj, d.fpcc.Generation)
+		return f != nil && eqFPCC(f, d.fpcc)
+	})
+}
+
+// gossipAll delivers an Echo, or a Ready, for d's round from every peer.
+func gossipAll(t *testing.T, s *server, obj string, d dispersal, ready bool) {
+	t.Helper()
+	for _, p := range s.peers {
+		var ok bool
+		var msg string
+		if ready {
+			r, _ := s.Ready(from("127.0.0.1", nil), &protocol.ReadyRequest{ObjectId: obj, Generation: d.fpcc.Generation, Digest: d.fpcc.Digest(), Sender: p})
+			ok, msg = r.Ok, r.Error
+		} else {
+			r, _ := s.Echo(from("127.0.0.1", nil), &protocol.EchoRequest{ObjectId: obj, Generation: d.fpcc.Generation, Digest: d.fpcc.Digest(), Sender: p})
+			ok, msg = r.Ok, r.Error
+		}
+		if !ok {
+			t.Fatalf("gossip from %s: %s", p, msg)
+		}
+	}
+}
+
+func TestConditionalDisperse(t *testing.T) {
+	peers := []string{"127.0.0.1:1", "127.0.0.1:2", "127.0.0.1:3"}
+	s := testServer(t, peers[0], peers, 2, 3)
+	ifNoneMatch := func(f *protocol.FPCC) { f.IfNoneMatch = true }
+	ifMatch := func(v *protocol.FPCC) func(*protocol.FPCC) {
+		return func(f *protocol.FPCC) { f.IfMatchVersion = v.Digest() }
+	}
+	refused := func(d dispersal, want string) {
+		t.Helper()
+		if resp := d.answer(t, time.Second); resp == nil || resp.Ok || !strings.Contains(resp.Error, want) {
+			t.Errorf("want a refusal containing %q, got %+v", want, resp)
+		}
+	}
+
+	// IfNoneMatch creates an object, then refuses to replace it
+	v0 := disperseTo(t, s, "o", 0, "first", ifNoneMatch)
+	opened(t, s, "o", v0)
+	gossipAll(t, s, "o", v0, true)
+	if resp := v0.answer(t, time.Second); resp == nil || !resp.Ok {
+		t.Fatalf("IfNoneMatch create: %+v", resp)
+	}
+	refused(disperseTo(t, s, "o", 1, "again", ifNoneMatch), "object exists")
+
+	// IfMatchVersion must name the current version
+	refused(disperseTo(t, s, "o", 1, "stale", ifMatch(&protocol.FPCC{Size: 9})), "is not current")
+	refused(disperseTo(t, s, "o", 0, "same gen", ifMatch(v0.fpcc)), "at generation 0")
+
+	// two writers racing for generation 1: the second one's FPCC loses
+	a := disperseTo(t, s, "o", 1, "writer a", ifMatch(v0.fpcc))
+	opened(t, s, "o", a)
+	refused(disperseTo(t, s, "o", 1, "writer b", ifMatch(v0.fpcc)), "FPCC mismatch")
+
+	// a writer may skip a generation nobody has sent Ready for, fencing it
+	c := disperseTo(t, s, "o", 2, "writer c", ifMatch(v0.fpcc))
+	opened(t, s, "o", c)
+	s.mu.Lock()
+	fence := s.fences["o"]
+	s.mu.Unlock()
+	if fence != 2 {
+		t.Fatalf("fence at %d, want 2", fence)
+	}
+	refused(disperseTo(t, s, "o", 1, "writer d", ifMatch(v0.fpcc)), "claimed 2")
+	// writer a's round is no longer pending here; its FPCC is known from
+	// its other Echoes, yet their quorum must not make this node Ready
+	s.mu.Lock()
+	s.learned[learnedKey("o", 1, a.fpcc.Digest())] = a.fpcc
+	s.mu.Unlock()
+	gossipAll(t, s, "o", a, false)
+	s.mu.Lock()
+	fenced := !s.readySent["o#1"]
+	s.mu.Unlock()
+	if !fenced {
+		t.Error("a fenced generation sent Ready")
+	}
+	gossipAll(t, s, "o", c, true)
+	if resp := c.answer(t, time.Second); resp == nil || !resp.Ok {
+		t.Fatalf("writer c: %+v", resp)
+	}
+	s.mu.Lock()
+	cur := s.fpccs["o"]
+	s.mu.Unlock()
+	if !eqFPCC(cur, c.fpcc) {
+		t.Fatalf("current version is generation %d, want writer c's", cur.GetGeneration())
+	}
+
+	// but not one this node has sent Ready for
+	p0 := disperseTo(t, s, "p", 0, "first", ifNoneMatch)
+	opened(t, s, "p", p0)
+	gossipAll(t, s, "p", p0, true)
+	if resp := p0.answer(t, time.Second); resp == nil || !resp.Ok {
+		t.Fatalf("create p: %+v", resp)
+	}
+	p1 := disperseTo(t, s, "p", 1, "p1", ifMatch(p0.fpcc))
+	opened(t, s, "p", p1)
+	gossipAll(t, s, "p", p1, false)
+	refused(disperseTo(t, s, "p", 2, "p2", ifMatch(p0.fpcc)), "generation 1 may still commit")
+}
+
+func TestMetaSealed(t *testing.T) {
+	s := testServer(t, "a:1", []string{"a:1", "b:1", "c:1"}, 2, 3)
+	s.vault = testVault(t)
+	fpcc := testFPCC(erasure.RS, 2, 3)
+	fpcc.Tags = map[string]string{"customer": "acme-secret"}
+	fpcc.ExpiresUnix = time.Now().Add(-time.Minute).Unix()
+	commitFPCC(t, s, "o", fpcc, time.Now())
+	_ = s.metaDB.Update(func(tx *bolt.Tx) error {
+		if raw := tx.Bucket([]byte(metaBucket)).Get([]byte("o")); bytes.Contains(raw, []byte("acme-secret")) {
+			t.Error("tags stored in the clear")
+		}
+		if meta, ok := s.getMeta(tx, "o"); !ok || meta.Tags["customer"] != "acme-secret" {
+			t.Errorf("sealed record reads back as %+v, %v", meta, ok)
+		}
+		// records from before the node had a key still read
+		tx.Bucket([]byte(metaBucket)).Put([]byte("plain"), []byte(`{"Tags":{"k":"v"}}`))
+		if meta, ok := s.getMeta(tx, "plain"); !ok || meta.Tags["k"] != "v" {
+			t.Errorf("plaintext record reads back as %+v, %v", meta, ok)
+		}
+		return nil
+	})
+
+	// expiry has to see through the seal
+	s.gcExpired()
+	s.mu.Lock()
+	gone := s.fpccs["o"] == nil
+	s.mu.Unlock()
+	if !gone {
+		t.Error("expired object with a sealed record not collected")
+	}
+}
+
+func TestLifecycleRules(t *testing.T) {
+	rules := []config.LifecycleRule{{Prefix: "logs:", Transcode: time.Hour, Data: 3, Total: 4}, {Prefix: "tmp:", Expire: time.Hour, Data: 9}}
+	if err := checkLifecycle(rules, erasure.RS); err != nil || rules[0].Codec != erasure.RS {
+		t.Fatalf("checkLifecycle: %v, codec %q", err, rules[0].Codec)
+	}
+	if err := checkLifecycle([]config.LifecycleRule{{Transcode: time.Hour, Data: 5, Total: 4}}, erasure.RS); err == nil {
+		t.Error("checkLifecycle accepted a 5-of-4 transcode")
+	}
+
+	s := testServer(t, "a:1", []string{"a:1"}, 2, 3)
+	s.lifecycle = []config.LifecycleRule{{Prefix: "", Expire: time.Hour}, {Prefix: "logs:debug:", Expire: 3 * time.Hour}, {Prefix: "logs:", Expire: 2 * time.Hour}}
+	for obj, want := range map[string]time.Duration{"x": time.Hour, "logs:x": 2 * time.Hour, "logs:debug:x": 3 * time.Hour} {
+		if r := s.rule(obj); r == nil || r.Expire != want {
+			t.Errorf("rule(%q) = %+v, want expire %v", obj, r, want)
+		}
+	}
+	if r := s.rule(chunkID("c")); r != nil {
+		t.Errorf("a chunk has rule %+v", r)
+	}
+}
+
+// chunkID is the ID of a chunk holding s.
+func chunkID(s string) string { return chunker.ID(chunker.Sum([]byte(s))) }
+
+func TestExpiry(t *testing.T) {
+	s := testServer(t, "a:1", []string{"a:1"}, 2, 3)
+	s.ttl = time.Hour
+	s.lifecycle = []config.LifecycleRule{{Prefix: "logs:", Expire: 2 * time.Hour}}
+	seen := time.Unix(1_800_000_000, 0)    // when this node saw the object
+	written := seen.Add(-30 * time.Minute) // when the client wrote it
+	at := time.Unix(1_900_000_000, 0)
+	fpcc := func(f func(*protocol.FPCC)) *protocol.FPCC {
+		p := testFPCC(erasure.RS, 2, 3)
+		p.CreatedUnix = written.Unix()
+		f(p)
+		return p
+	}
+	for _, c := range []struct {
+		name, obj string
+		fpcc      *protocol.FPCC
+		want      time.Time
+	}{
+		{"retain", "logs:a", fpcc(func(f *protocol.FPCC) { f.Retain = true }), never},
+		{"expires", "logs:a", fpcc(func(f *protocol.FPCC) { f.ExpiresUnix = at.Unix() }), at},
+		{"rule", "logs:a", fpcc(func(*protocol.FPCC) {}), written.Add(2 * time.Hour)},
+		{"ttl", "a", fpcc(func(*protocol.FPCC) {}), written.Add(time.Hour)},
+		{"unstamped", "a", fpcc(func(f *protocol.FPCC) { f.CreatedUnix = 0 }), seen.Add(time.Hour)},
+		{"no FPCC", "a", nil, seen.Add(time.Hour)},
+	} {
+		if got := s.expiry(c.obj, c.fpcc, seen); !got.Equal(c.want) {
+			t.Errorf("%s: expires %v, want %v", c.name, got, c.want)
+		}
+	}
+
+	// a node the object is handed to counts from the same time
+	s.adoptFPCC("logs:a", fpcc(func(*protocol.FPCC) {}))
+	_ = s.metaDB.View(func(tx *bolt.Tx) error {
+		if meta, _ := s.getMeta(tx, "logs:a"); !meta.Created.Equal(written) {
+			t.Errorf("adopted object created at %v, want %v", meta.Created, written)
+		}
+		return nil
+	})
+}
+
+func TestLifecycleDue(t *testing.T) {
+	s := testServer(t, "a:1", []string{"a:1"}, 2, 3)
+	s.lifecycle = []config.LifecycleRule{{Prefix: "logs:", Transcode: time.Hour, Codec: erasure.RS, Data: 3, Total: 4}}
+	now := time.Now()
+	stamped := func(m, n int, created time.Time) *protocol.FPCC {
+		f := testFPCC(erasure.RS, m, n)
+		f.CreatedUnix = created.Unix()
+		return f
+	}
+	commitFPCC(t, s, "logs:old", stamped(2, 3, now.Add(-2*time.Hour)), now)
+	commitFPCC(t, s, "logs:young", stamped(2, 3, now.Add(-time.Minute)), now.Add(-2*time.Hour))
+	commitFPCC(t, s, "logs:done", stamped(3, 4, now.Add(-2*time.Hour)), now)
+	commitFPCC(t, s, "other", stamped(2, 3, now.Add(-2*time.Hour)), now)
+	s.mu.Lock()
+	s.fpccs["logs:uncommitted"] = stamped(2, 3, now.Add(-2*time.Hour))
+	s.mu.Unlock()
+
+	var objs []string
+	for _, j := range s.lifecycleDue(now) {
+		objs = append(objs, j.obj)
+	}
+	if !slices.Equal(objs, []string{"logs:old"}) {
+		t.Errorf("due for transcode: %v", objs)
+	}
+}
+
+// liveCluster starts k servers on loopback ports, each serving gRPC in a
+// view of all of them with an m-of-n default profile, and returns them
+// with a client for the cluster.
+func liveCluster(t *testing.T, k, m, n int) ([]*server, *client.Client) {
+	t.Helper()
+	var lis []net.Listener
+	var addrs []string
+	for range k {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		lis = append(lis, l)
+		addrs = append(addrs, l.Addr().String())
+	}
+	var servers []*server
+	for i, l := range lis {
+		s := testServer(t, addrs[i], addrs, m, n)
+		g := grpc.NewServer(serverOpts()...)
+		protocol.RegisterDispersalServer(g, s)
+		protocol.RegisterMembershipServer(g, s)
+		go g.Serve(l)
+		t.Cleanup(g.Stop)
+		servers = append(servers, s)
+	}
+	c, err := client.New(client.Config{Peers: addrs, Data: m, Total: n, DialTimeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return servers, c
+}
+
+// get reads id back through c and checks it holds want.
+func get(t *testing.T, c *client.Client, id string, want []byte) *client.ObjectInfo {
+	t.Helper()
+	var out bytes.Buffer
+	info, err := c.Get(context.Background(), id, &out)
+	if err != nil {
+		t.Fatalf("Get %s: %v", id, err)
+	}
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Fatalf("Get %s returned %d bytes, want %d", id, out.Len(), len(want))
+	}
+	return info
+}
+
+func TestLiveVersions(t *testing.T) {
+	ctx := context.Background()
+	servers, c := liveCluster(t, 4, 2, 4)
+	for _, s := range servers {
+		s.keepVersions = 1
+	}
+	first := bytes.Repeat([]byte("first version "), 1000)
+	second := bytes.Repeat([]byte("second version "), 900)
+	old, err := c.Put(ctx, "doc", bytes.NewReader(first), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Put(ctx, "doc", bytes.NewReader(second), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if info := get(t, c, "doc", second); info.Generation != 1 {
+		t.Errorf("current generation %d, want 1", info.Generation)
+	}
+	versions, err := c.Versions(ctx, "doc")
+	if err != nil || len(versions) != 2 || versions[1].VersionID() != old.VersionID() || versions[1].Superseded.IsZero() {
+		t.Fatalf("Versions: %v %+v", err, versions)
+	}
+	var out bytes.Buffer
+	if _, err := c.GetVersion(ctx, "doc", old.VersionID(), &out, 0, 0); err != nil || !bytes.Equal(out.Bytes(), first) {
+		t.Fatalf("GetVersion of the first version: %v, %d bytes", err, out.Len())
+	}
+	if info, err := c.StatVersion(ctx, "doc", old.VersionID()); err != nil || info.Generation != 0 {
+		t.Errorf("StatVersion: %v %+v", err, info)
+	}
+}
+
+func TestLiveTranscode(t *testing.T) {
+	ctx := context.Background()
+	servers, c := liveCluster(t, 4, 2, 4)
+	data := bytes.Repeat([]byte("transcode me "), 3000)
+	if _, err := c.Put(ctx, "obj", bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Transcode(ctx, "obj", erasure.RS, 3, 4); err != nil {
+		t.Fatal(err)
+	}
+	info := get(t, c, "obj", data)
+	if info.Data != 3 || info.Total != 4 || info.Generation != 1 {
+		t.Errorf("after transcode: %d-of-%d generation %d", info.Data, info.Total, info.Generation)
+	}
+	if versions, err := c.Versions(ctx, "obj"); err != nil || len(versions) != 1 {
+		t.Errorf("a transcode added a version: %v %d", err, len(versions))
+	}
+	for _, s := range servers {
+		waitFor(t, s.selfAddr+" to promote generation 1", func() bool {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.fpccs["obj"].GetGeneration() == 1
+		})
+	}
+}
+
+func TestLiveConditionalPut(t *testing.T) {
+	ctx := context.Background()
+	_, c := liveCluster(t, 4, 2, 4)
+	first, err := c.Put(ctx, "cfg", strings.NewReader("one"), &client.PutOptions{IfNoneMatch: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Put(ctx, "cfg", strings.NewReader("two"), &client.PutOptions{IfNoneMatch: true}); !errors.Is(err, client.ErrPrecondition) {
+		t.Fatalf("IfNoneMatch on an existing object: %v", err)
+	}
+	second, err := c.Put(ctx, "cfg", strings.NewReader("two"), &client.PutOptions{IfMatchVersion: first.VersionID()})
+	if err != nil {
+		t.Fatalf("IfMatchVersion of the current version: %v", err)
+	}
+	if _, err := c.Put(ctx, "cfg", strings.NewReader("three"), &client.PutOptions{IfMatchVersion: first.VersionID()}); !errors.Is(err, client.ErrPrecondition) {
+		t.Fatalf("IfMatchVersion of a superseded version: %v", err)
+	}
+	if info := get(t, c, "cfg", []byte("two")); info.VersionID() != second.VersionID() {
+		t.Errorf("current version %s, want %s", info.VersionID(), second.VersionID())
+	}
+}
+
+// refs counts the chunk references s holds for manifest.
+func refs(s *server, manifest string) int {
+	n := 0
+	_ = s.metaDB.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(refsBucket)).ForEach(func(k, _ []byte) error {
+			if strings.HasSuffix(string(k), "|"+manifest) {
+				n++
+			}
+			return nil
+		})
+	})
+	return n
+}
+
+func TestLiveDedupRefs(t *testing.T) {
+	ctx := context.Background()
+	servers, c := liveCluster(t, 4, 2, 4)
+	data := make([]byte, 1<<20)
+	for i := range data {
+		data[i] = byte(i * 7919 >> 5)
+	}
+	a, err := c.Put(ctx, "a", bytes.NewReader(data), &client.PutOptions{Dedup: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Put(ctx, "b", bytes.NewReader(data), &client.PutOptions{Dedup: true}); err != nil {
+		t.Fatal(err)
+	}
+	// every node counts both manifests' references, whichever group it is in
+	for _, s := range servers {
+		waitFor(t, s.selfAddr+" to count the references", func() bool {
+			return refs(s, "a") == a.Chunks && refs(s, "b") == a.Chunks
+		})
+	}
+
+	if err := c.Delete(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range servers {
+		if n := refs(s, "a"); n != 0 {
+			t.Errorf("%s keeps %d references of the deleted manifest", s.selfAddr, n)
+		}
+		if n := refs(s, "b"); n != a.Chunks {
+			t.Errorf("%s holds %d references of b, want %d", s.selfAddr, n, a.Chunks)
+		}
+	}
+	get(t, c, "b", data)
+}
+
+func TestLiveObjectProfile(t *testing.T) {
+	ctx := context.Background()
+	servers, c := liveCluster(t, 4, 2, 4)
+	data := bytes.Repeat([]byte("wider profile "), 2000)
+	if _, err := c.Put(ctx, "wide", bytes.NewReader(data), &client.PutOptions{Data: 3, Total: 4}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := c.Stat(ctx, "wide")
+	if err != nil || info.Data != 3 || info.Total != 4 {
+		t.Fatalf("Stat: %v %+v", err, info)
+	}
+	get(t, c, "wide", data)
+
+	// the servers place, answer for and serve it under its own profile
+	owners, err := placement.ForView(servers[0].currentView(), "wide", 3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range servers {
+		idx := uint32(slices.Index(owners, s.selfAddr))
+		r, _ := s.Retrieve(ctx, &protocol.RetrieveRequest{ObjectId: "wide", FragmentIndex: idx})
+		if !r.Ok || r.Fpcc.GetProfile().GetData() != 3 || !fragmentMatches(r.Fpcc, idx, r.Fragment) {
+			t.Errorf("%s Retrieve of fragment %d: %s", s.selfAddr, idx, r.Error)
+		}
+		if m, n := s.profileOf(r.Fpcc); m != 3 || n != 4 {
+			t.Errorf("%s sizes the object %d-of-%d", s.selfAddr, m, n)
+		}
+	}
+}
+
+// TestLiveOnlineTranscode reads an object throughout its transcode: every
+// read sees the same content, and the old generation goes once the new
+// one has committed everywhere.
+func TestLiveOnlineTranscode(t *testing.T) {
+	defer func(g time.Duration) { retireGrace = g }(retireGrace)
+	retireGrace = 100 * time.Millisecond
+	ctx := context.Background()
+	servers, c := liveCluster(t, 4, 2, 4)
+	data := bytes.Repeat([]byte("read while transcoding "), 4000)
+	if _, err := c.Put(ctx, "live", bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	old := testFPCC(erasure.RS, 2, 4)
+	for _, s := range servers {
+		if len(s.heldIndices("live", old)) != 1 {
+			t.Fatalf("%s holds fragments %v of generation 0", s.selfAddr, s.heldIndices("live", old))
+		}
+	}
+
+	stop := make(chan struct{})
+	reads := make(chan error, 1)
+	go func() {
+		for n := 0; ; n++ {
+			select {
+			case <-stop:
+				reads <- nil
+				return
+			default:
+			}
+			var out bytes.Buffer
+			if _, err := c.Get(ctx, "live", &out); err != nil || !bytes.Equal(out.Bytes(), data) {
+				reads <- fmt.Errorf("read %d: %v, %d bytes", n, err, out.Len())
+				return
+			}
+		}
+	}()
+	if _, err := c.Transcode(ctx, "live", erasure.RS, 3, 4); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * retireGrace) // keep reading across the retirement
+	close(stop)
+	if err := <-reads; err != nil {
+		t.Fatal(err)
+	}
+
+	if info := get(t, c, "live", data); info.Data != 3 || info.Generation != 1 {
+		t.Errorf("after transcode: %d-of-%d generation %d", info.Data, info.Total, info.Generation)
+	}
+	for _, s := range servers {
+		waitFor(t, s.selfAddr+" to retire generation 0", func() bool {
+			return len(s.heldIndices("live", old)) == 0
+		})
+	}
+}
+
+// batchSink is a peer that records the Echo and Ready batches it gets.
+type batchSink struct {
+	protocol.UnimplementedDispersalServer
+	mu      sync.Mutex
+	echoes  []int // messages per EchoBatch
+	readies []int
+}
+
+func (b *batchSink) EchoBatch(_ context.Context, req *protocol.EchoBatchRequest) (*protocol.EchoBatchResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.echoes = append(b.echoes, len(req.Echoes))
+	return &protocol.EchoBatchResponse{}, nil
+}
+
+func (b *batchSink) ReadyBatch(_ context.Context, req *protocol.ReadyBatchRequest) (*protocol.ReadyBatchResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.readies = append(b.readies, len(req.Readies))
+	return &protocol.ReadyBatchResponse{}, nil
+}
+
+func (b *batchSink) batches() ([]int, []int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.echoes), slices.Clone(b.readies)
+}
+
+func TestGossipCoalesces(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &batchSink{}
+	g := grpc.NewServer()
+	protocol.RegisterDispersalServer(g, sink)
+	go g.Serve(l)
+	t.Cleanup(g.Stop)
+	peer := l.Addr().String()
+	s := testServer(t, "127.0.0.1:1", []string{"127.0.0.1:1", peer}, 1, 2)
+
+	// a burst within one window travels as one batch of each kind, echoes
+	// first
+	for i := range 10 {
+		obj := fmt.Sprintf("o%d", i)
+		s.gossip(peer, gossipMsg{echo: &protocol.EchoRequest{ObjectId: obj}})
+		s.gossip(peer, gossipMsg{ready: &protocol.ReadyRequest{ObjectId: obj}})
+	}
+	waitFor(t, "the first batches", func() bool {
+		_, readies := sink.batches()
+		return len(readies) > 0
+	})
+	if echoes, readies := sink.batches(); !slices.Equal(echoes, []int{10}) || !slices.Equal(readies, []int{10}) {
+		t.Fatalf("burst sent as echo batches %v, ready batches %v", echoes, readies)
+	}
+
+	// a burst past gossipMaxBatch is split rather than held back
+	for i := range gossipMaxBatch + 44 {
+		s.gossip(peer, gossipMsg{echo: &protocol.EchoRequest{ObjectId: fmt.Sprintf("p%d", i)}})
+	}
+	waitFor(t, "the large burst", func() bool {
+		echoes, _ := sink.batches()
+		sum := 0
+		for _, n := range echoes {
+			sum += n
+		}
+		return sum == 10+gossipMaxBatch+44
+	})
+	if echoes, _ := sink.batches(); !slices.Equal(echoes, []int{10, gossipMaxBatch, 44}) {
+		t.Errorf("large burst sent as echo batches %v", echoes[1:])
+	}
+}
+
+// uniformFPCC is an FPCC for generation gen of a 2-of-3 object whose
+// fragments all hold frag.
+func uniformFPCC(gen uint64, frag []byte) *protocol.FPCC {
+	fpcc := &protocol.FPCC{Generation: gen, Seed: 7, Profile: &protocol.Profile{Codec: erasure.RS, Data: 2, Total: 3}}
+	sum := sha256.Sum256(frag)
+	for range 3 {
+		fpcc.Hashes = append(fpcc.Hashes, sum[:])
+		fpcc.Fps = append(fpcc.Fps, fingerprint.NewWithSeed(fpcc.Seed).Eval(frag))
+	}
+	return fpcc
+}
+
+// byAddr returns the server of servers listening on addr.
+func byAddr(servers []*server, addr string) *server {
+	for _, s := range servers {
+		if s.selfAddr == addr {
+			return s
+		}
+	}
+	return nil
+}
+
+func TestHandoffNeedsVouchers(t *testing.T) {
+	ctx := context.Background()
+	servers, c := liveCluster(t, 4, 2, 3)
+	if _, err := c.Put(ctx, "obj", bytes.NewReader(bytes.Repeat([]byte("handed over "), 500)), nil); err != nil {
+		t.Fatal(err)
+	}
+	owners, _ := placement.ForView(servers[0].currentView(), "obj", 2, 3)
+	to, from := byAddr(servers, owners[0]), byAddr(servers, owners[1])
+	to.mu.Lock()
+	fpcc := to.fpccs["obj"]
+	to.mu.Unlock()
+	frag, err := to.loadFragment("obj", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a member cannot move a fragment to a node placement does not give it
+	if err := from.handoff(owners[2], "obj", 0, frag, fpcc); err == nil || !strings.Contains(err.Error(), "not placed") {
+		t.Errorf("handoff of fragment 0 to %s: %v", owners[2], err)
+	}
+
+	// nor hand over a generation nobody committed
+	forged := []byte("forged content")
+	if err := from.handoff(to.selfAddr, "obj", 0, forged, uniformFPCC(5, forged)); err == nil || !strings.Contains(err.Error(), "vouched") {
+		t.Errorf("forged generation 5: %v", err)
+	}
+	to.mu.Lock()
+	displaced := !eqFPCC(to.fpccs["obj"], fpcc) || to.pending["obj"] != nil
+	to.mu.Unlock()
+	if displaced {
+		t.Error("forged generation 5 displaced generation 0")
+	}
+
+	// nor an object nobody wrote
+	obj, idx := "", -1
+	for i := 0; idx < 0; i++ {
+		obj = fmt.Sprintf("new%d", i)
+		owners, _ := placement.ForView(to.currentView(), obj, 2, 3)
+		idx = slices.Index(owners, to.selfAddr)
+	}
+	if err := from.handoff(to.selfAddr, obj, uint32(idx), forged, uniformFPCC(0, forged)); err == nil || !strings.Contains(err.Error(), "vouched") {
+		t.Errorf("forged new object: %v", err)
+	}
+	if to.generationFPCC(obj, 0) != nil || len(to.heldIndices(obj, uniformFPCC(0, forged))) > 0 {
+		t.Error("forged new object adopted")
+	}
+
+	// the committed FPCC is vouched for by the other owners
+	to.deleteObject("obj")
+	if err := from.handoff(to.selfAddr, "obj", 0, frag, fpcc); err != nil {
+		t.Fatalf("handoff of the committed FPCC: %v", err)
+	}
+	if !to.committed("obj") || len(to.heldIndices("obj", fpcc)) != 1 {
+		t.Error("committed FPCC not adopted")
+	}
+}
+
+// drained waits for s to finish draining and reports whether it found
+// itself safe to remove.
+func drained(t *testing.T, s *server) bool {
+	t.Helper()
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		s.mu.Lock()
+		d := s.drain
+		s.mu.Unlock()
+		if !d.active {
+			return d.safe
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s still draining: %+v", s.selfAddr, d)
+		}
+	}
+}
+
+// placed reports whether every owner placement gives a fragment of fpcc
+// in s's view holds it.
+func placed(t *testing.T, servers []*server, s *server, obj string, fpcc *protocol.FPCC) bool {
+	t.Helper()
+	m, n := s.profileOf(fpcc)
+	owners, err := placement.ForView(s.currentView(), obj, m, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, owner := range owners {
+		if !slices.Contains(byAddr(servers, owner).heldIndices(obj, fpcc), uint32(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDrainMovesEveryGeneration(t *testing.T) {
+	ctx := context.Background()
+	servers, c := liveCluster(t, 4, 2, 3)
+	for _, s := range servers {
+		s.keepVersions = 1
+	}
+	old, err := c.Put(ctx, "doc", strings.NewReader("first version"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Put returns at quorum; let every owner commit before the next round
+	owners, _ := placement.ForView(servers[0].currentView(), "doc", 2, 3)
+	settle := func(fpcc *protocol.FPCC) {
+		for _, owner := range owners {
+			s := byAddr(servers, owner)
+			waitFor(t, "generation to commit on "+owner, func() bool {
+				s.mu.Lock()
+				defer s.mu.Unlock()
+				return eqFPCC(s.fpccs["doc"], fpcc) && s.pending["doc"] == nil
+			})
+		}
+	}
+	settle(old.FPCC)
+	cur, err := c.Put(ctx, "doc", strings.NewReader("second version"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settle(cur.FPCC)
+	for _, owner := range owners {
+		s := byAddr(servers, owner)
+		waitFor(t, "generation 0 to become a version on "+owner, func() bool { return s.versionFPCC("doc", 0) != nil })
+	}
+	// and a third round that has reached every owner but not committed yet
+	frag := []byte("round in progress")
+	open := uniformFPCC(2, frag)
+	for i, owner := range owners {
+		s := byAddr(servers, owner)
+		if err := s.persistFragment("doc", 2, uint32(i), frag); err != nil {
+			t.Fatal(err)
+		}
+		s.mu.Lock()
+		s.pending["doc"] = open
+		s.mu.Unlock()
+	}
+
+	d := byAddr(servers, owners[0])
+	if len(d.heldGenerations("doc")) != 3 {
+		t.Fatalf("%s holds %d generations of doc, want 3", d.selfAddr, len(d.heldGenerations("doc")))
+	}
+	if _, err := c.Drain(ctx, d.selfAddr); err != nil {
+		t.Fatal(err)
+	}
+	if !drained(t, d) {
+		t.Fatal("drain did not finish safe to remove")
+	}
+	for _, fpcc := range []*protocol.FPCC{old.FPCC, cur.FPCC, open} {
+		if !placed(t, servers, d, "doc", fpcc) {
+			t.Errorf("generation %d not re-homed", fpcc.GetGeneration())
+		}
+	}
+
+	// the new owner files each generation as what it is
+	owners, _ = placement.ForView(d.currentView(), "doc", 2, 3)
+	heir := byAddr(servers, owners[0])
+	heir.mu.Lock()
+	current, pending := heir.fpccs["doc"], heir.pending["doc"]
+	heir.mu.Unlock()
+	if !eqFPCC(current, cur.FPCC) || !eqFPCC(pending, open) || !eqFPCC(heir.versionFPCC("doc", 0), old.FPCC) {
+		t.Errorf("%s holds doc at generation %d, pending %d", heir.selfAddr, current.GetGeneration(), pending.GetGeneration())
+	}
+	var out bytes.Buffer
+	if _, err := c.GetVersion(ctx, "doc", old.VersionID(), &out, 0, 0); err != nil || out.String() != "first version" {
+		t.Errorf("GetVersion after the drain: %v %q", err, out.String())
+	}
+}
+
+func TestDrainRebuildsLostFragment(t *testing.T) {
+	ctx := context.Background()
+	servers, c := liveCluster(t, 4, 2, 3)
+	info, err := c.Put(ctx, "obj", strings.NewReader("rebuilt from the other owners"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owners, _ := placement.ForView(servers[0].currentView(), "obj", 2, 3)
+	d := byAddr(servers, owners[0])
+	waitFor(t, "obj to commit on "+d.selfAddr, func() bool { return d.committed("obj") })
+	// the draining node's copy has rotted, so it must decode a fresh one
+	if err := os.WriteFile(d.fragPath("obj", 0, 0), []byte("bit rot"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Drain(ctx, d.selfAddr); err != nil {
+		t.Fatal(err)
+	}
+	if !drained(t, d) {
+		t.Fatal("drain did not finish safe to remove")
+	}
+	if !placed(t, servers, d, "obj", info.FPCC) {
+		t.Fatal("fragments not placed after the drain")
+	}
+	owners, _ = placement.ForView(d.currentView(), "obj", 2, 3)
+	frag, err := byAddr(servers, owners[0]).loadFragment("obj", 0, 0)
+	if err != nil || !fragmentMatches(info.FPCC, 0, frag) {
+		t.Errorf("rebuilt fragment does not match the FPCC: %v", err)
+	}
+}
+
+func TestStatusKeepsDrainResult(t *testing.T) {
+	ctx := context.Background()
+	servers, c := liveCluster(t, 4, 2, 3)
+	if _, err := c.Put(ctx, "obj", strings.NewReader("payload"), nil); err != nil {
+		t.Fatal(err)
+	}
+	owners, _ := placement.ForView(servers[0].currentView(), "obj", 2, 3)
+	d := byAddr(servers, owners[0])
+	waitFor(t, "obj to commit on "+d.selfAddr, func() bool { return d.committed("obj") })
+	if d.status().(nodeStatus).Drain != nil {
+		t.Fatal("drain reported before any drain")
+	}
+	if _, err := c.Drain(ctx, d.selfAddr); err != nil {
+		t.Fatal(err)
+	}
+	if !drained(t, d) {
+		t.Fatal("drain did not finish safe to remove")
+	}
+	got := d.status().(nodeStatus).Drain
+	if got == nil || !got.Safe || got.Objects != 1 || got.Rehomed != 1 {
+		t.Errorf("status after the drain = %+v, want 1 of 1 re-homed and safe", got)
+	}
+	resp, err := d.DrainStatus(ctx, &protocol.DrainRequest{})
+	if err != nil || !resp.Draining || !resp.SafeToRemove || resp.Rehomed != 1 {
+		t.Errorf("DrainStatus after the drain = %+v, %v", resp, err)
+	}
+}
+
+func TestRemoveChecksPlacement(t *testing.T) {
+	s := testServer(t, "a:1", []string{"a:1", "b:1", "c:1", "d:1"}, 2, 3)
+	s.fpccs["wide"] = testFPCC(erasure.RS, 3, 4)
+	resp, _ := s.RemoveNode(context.Background(), &protocol.RemoveNodeRequest{Addr: "d:1"})
+	if resp.Ok || !strings.Contains(resp.Error, placement.ErrUnsatisfiable.Error()) || s.currentView().Epoch != 0 {
+		t.Fatalf("removal leaving three nodes for a 3-of-4 object: %+v", resp)
+	}
+}
+
+func TestRemoveRebuildsFragments(t *testing.T) {
+	ctx := context.Background()
+	servers, c := liveCluster(t, 4, 2, 3)
+	info, err := c.Put(ctx, "obj", strings.NewReader("outlives its owner"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owners, _ := placement.ForView(servers[0].currentView(), "obj", 2, 3)
+	gone := byAddr(servers, owners[0])
+	gone.deleteObject("obj") // lost with the node
+	if _, err := c.RemoveNode(ctx, gone.selfAddr); err != nil {
+		t.Fatal(err)
+	}
+	var rest []*server
+	for _, s := range servers {
+		if s != gone {
+			s.rebalance()
+			rest = append(rest, s)
+		}
+	}
+	if !placed(t, servers, rest[0], "obj", info.FPCC) {
+		t.Fatal("fragment of the removed node not rebuilt")
+	}
+	get(t, c, "obj", []byte("outlives its owner"))
+}
+
+func TestRemovedNodeHandsOff(t *testing.T) {
+	ctx := context.Background()
+	servers, c := liveCluster(t, 4, 2, 3)
+	info, err := c.Put(ctx, "obj", strings.NewReader("moved by its old owner"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owners, _ := placement.ForView(servers[0].currentView(), "obj", 2, 3)
+	gone := byAddr(servers, owners[0])
+	if _, err := c.RemoveNode(ctx, gone.selfAddr); err != nil {
+		t.Fatal(err)
+	}
+	if membership.Contains(gone.currentView(), gone.selfAddr) {
+		t.Fatal("removed node did not learn it is out")
+	}
+	// only the removed node moves anything: its handoffs are accepted
+	// though it has left the view
+	gone.rebalance()
+	owners, _ = placement.ForView(gone.currentView(), "obj", 2, 3)
+	if !slices.Contains(byAddr(servers, owners[0]).heldIndices("obj", info.FPCC), 0) {
+		t.Fatal("fragment of the removed node not handed on")
+	}
+	if len(gone.heldIndices("obj", info.FPCC)) != 0 {
+		t.Error("removed node kept its copy")
+	}
+}
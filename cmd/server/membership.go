@@ -0,0 +1,341 @@
+// cmd/server/membership.go – live cluster membership for the AVID‑FP store.
+// Views are epoch‑numbered; a change is proposed to the current members and
+// only committed once a majority has accepted it, so every node converges on
+// the same configuration without a restart.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/membership"
+	"github.com/dattu/distributed_object_store/pkg/placement"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	viewKey          = "view"
+	viewDialTimeout  = 5 * time.Second
+	viewSyncInterval = 10 * time.Second
+	proposalTTL      = 30 * time.Second // an uncommitted proposal blocks others this long
+)
+
+/* ------------------------------------------------------------------------ */
+/* persistence                                                              */
+/* ------------------------------------------------------------------------ */
+
+// loadView returns the last committed view, or initial (built from the static
+// peer list and topology) on first boot.
+func loadView(db *bolt.DB, initial *protocol.View) *protocol.View {
+	var v *protocol.View
+	_ = db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(viewBucket))
+		if b == nil {
+			return nil
+		}
+		if raw := b.Get([]byte(viewKey)); raw != nil {
+			stored := &protocol.View{}
+			if err := proto.Unmarshal(raw, stored); err == nil {
+				v = stored
+			}
+		}
+		return nil
+	})
+	if v == nil {
+		return initial
+	}
+	log.Printf("membership: restored epoch %d %v", v.Epoch, membership.Addrs(v))
+	return v
+}
+
+func (s *server) saveView(v *protocol.View) {
+	raw, err := proto.Marshal(v)
+	if err != nil {
+		return
+	}
+	_ = s.metaDB.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(viewBucket)).Put([]byte(viewKey), raw)
+	})
+}
+
+/* ------------------------------------------------------------------------ */
+/* helpers                                                                  */
+/* ------------------------------------------------------------------------ */
+
+func (s *server) currentView() *protocol.View {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.view
+}
+
+// quorumLocked returns the nodes placement assigns obj's fragments to in
+// the current epoch – the only participants of its Echo/Ready rounds – and
+// the thresholds for a group of that size. Caller must hold s.mu.
+func (s *server) quorumLocked(obj string, fpcc *protocol.FPCC) ([]string, membership.Quorum) {
+	m, n := s.profileOf(fpcc)
+	group := membership.Writable(s.view)
+	if owners, err := placement.ForView(s.view, obj, m, n); err == nil {
+		group = placement.Group(owners)
+	}
+	return group, membership.QuorumFor(len(group), m, n)
+}
+
+func (s *server) group(obj string, fpcc *protocol.FPCC) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	group, _ := s.quorumLocked(obj, fpcc)
+	return group
+}
+
+// installView adopts v if it is newer than the current view.
+func (s *server) installView(v *protocol.View) bool {
+	s.mu.Lock()
+	if v.Epoch <= s.view.Epoch {
+		s.mu.Unlock()
+		return false
+	}
+	s.prevView, s.view = s.view, v
+	s.peers = membership.Addrs(v)
+	clear(s.resolved)
+	if s.pendingView != nil && s.pendingView.Epoch <= v.Epoch {
+		s.pendingView = nil
+	}
+	s.mu.Unlock()
+
+	s.saveView(v)
+	s.dropOutboxes(v)
+	log.Printf("membership: installed epoch %d %v", v.Epoch, membership.Addrs(v))
+	s.maybeStartDrain()
+	s.requestRebalance()
+	return true
+}
+
+/* ------------------------------------------------------------------------ */
+/* RPC – admin: GetView, AddNode, RemoveNode, ReplaceNode, Drain            */
+/* ------------------------------------------------------------------------ */
+
+func (s *server) GetView(ctx context.Context, _ *protocol.GetViewRequest) (*protocol.View, error) {
+	return s.currentView(), nil
+}
+
+func (s *server) AddNode(ctx context.Context, req *protocol.AddNodeRequest) (*protocol.MembershipResponse, error) {
+	return s.changeView(ctx, func(v *protocol.View) (*protocol.View, error) {
+		return membership.Add(v, req.Addr, req.Labels)
+	}), nil
+}
+
+func (s *server) RemoveNode(ctx context.Context, req *protocol.RemoveNodeRequest) (*protocol.MembershipResponse, error) {
+	return s.changeView(ctx, func(v *protocol.View) (*protocol.View, error) {
+		return s.hosting(membership.Remove(v, req.Addr))
+	}), nil
+}
+
+func (s *server) ReplaceNode(ctx context.Context, req *protocol.ReplaceNodeRequest) (*protocol.MembershipResponse, error) {
+	return s.changeView(ctx, func(v *protocol.View) (*protocol.View, error) {
+		return s.hosting(membership.Replace(v, req.OldAddr, req.NewAddr, req.Labels))
+	}), nil
+}
+
+func (s *server) Drain(ctx context.Context, req *protocol.DrainRequest) (*protocol.MembershipResponse, error) {
+	return s.changeView(ctx, func(v *protocol.View) (*protocol.View, error) {
+		return s.drainView(v, req.Addr)
+	}), nil
+}
+
+// drainView returns the successor of v with addr draining, provided the
+// remaining nodes can still host every object.
+func (s *server) drainView(v *protocol.View, addr string) (*protocol.View, error) {
+	return s.hosting(membership.Drain(v, addr))
+}
+
+// hosting returns next, the view a change derived along with err, provided
+// its writable nodes can still host every object: those with the node's
+// default profile and those this node holds under a wider one, or under a
+// code that tolerates fewer fragments per failure domain.
+func (s *server) hosting(next *protocol.View, err error) (*protocol.View, error) {
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range s.profiles() {
+		if _, err := placement.ForView(next, "", p[0], p[1]); err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+// changeView derives the next view from the current one, collects a majority
+// of ProposeView acks from the current members and then commits everywhere.
+func (s *server) changeView(ctx context.Context, derive func(*protocol.View) (*protocol.View, error)) *protocol.MembershipResponse {
+	cur := s.currentView()
+	next, err := derive(cur)
+	if err != nil {
+		return &protocol.MembershipResponse{Ok: false, Error: err.Error(), View: cur}
+	}
+
+	acks := 0
+	for _, addr := range membership.Addrs(cur) {
+		resp, err := s.viewCall(ctx, addr, func(c viewPeer, cctx context.Context) (*protocol.ViewResponse, error) {
+			return c.ProposeView(cctx, &protocol.ProposeViewRequest{View: next, BaseEpoch: cur.Epoch})
+		})
+		if err == nil && resp.Ok {
+			acks++
+		}
+	}
+	if need := membership.Majority(cur); acks < need {
+		return &protocol.MembershipResponse{
+			Ok:    false,
+			Error: fmt.Sprintf("view change rejected: %d/%d acks", acks, need),
+			View:  cur,
+		}
+	}
+
+	// commit to the union of old and new members so removed nodes learn
+	// they are out and added nodes learn they are in
+	targets := membership.Addrs(cur)
+	for _, addr := range membership.Addrs(next) {
+		if !membership.Contains(cur, addr) {
+			targets = append(targets, addr)
+		}
+	}
+	for _, addr := range targets {
+		if _, err := s.viewCall(ctx, addr, func(c viewPeer, cctx context.Context) (*protocol.ViewResponse, error) {
+			return c.CommitView(cctx, &protocol.CommitViewRequest{View: next})
+		}); err != nil {
+			log.Printf("membership: commit epoch %d to %s: %v", next.Epoch, addr, err)
+		}
+	}
+	return &protocol.MembershipResponse{Ok: true, View: s.currentView()}
+}
+
+// viewCall runs fn against addr, short‑circuiting to the local server for self.
+func (s *server) viewCall(ctx context.Context, addr string, fn func(viewPeer, context.Context) (*protocol.ViewResponse, error)) (*protocol.ViewResponse, error) {
+	if addr == s.selfAddr {
+		return fn(localMembership{s}, ctx)
+	}
+	conn, err := dialPeer(addr, viewDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	cctx, cancel := context.WithTimeout(ctx, viewDialTimeout)
+	defer cancel()
+	return fn(protocol.NewMembershipClient(conn), cctx)
+}
+
+/* ------------------------------------------------------------------------ */
+/* RPC – peer: ProposeView, CommitView                                      */
+/* ------------------------------------------------------------------------ */
+
+func (s *server) ProposeView(ctx context.Context, req *protocol.ProposeViewRequest) (*protocol.ViewResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case req.View == nil || req.BaseEpoch != s.view.Epoch || req.View.Epoch != s.view.Epoch+1:
+		return &protocol.ViewResponse{Ok: false, Error: "stale proposal", View: s.view}, nil
+	case s.pendingView != nil && time.Since(s.pendingAt) < proposalTTL && !membership.Equal(s.pendingView, req.View):
+		return &protocol.ViewResponse{Ok: false, Error: "concurrent view change in progress", View: s.view}, nil
+	}
+	s.pendingView, s.pendingAt = req.View, time.Now()
+	return &protocol.ViewResponse{Ok: true, View: s.view}, nil
+}
+
+// CommitView installs the view this node accepted in ProposeView, and only
+// that one: a commit is a claim that a majority acked it, which a single
+// peer could make up. A node that took no part in the proposal – one just
+// added, say – checks with its peers instead.
+func (s *server) CommitView(ctx context.Context, req *protocol.CommitViewRequest) (*protocol.ViewResponse, error) {
+	if req.View == nil {
+		return &protocol.ViewResponse{Ok: false, Error: "missing view", View: s.currentView()}, nil
+	}
+	s.mu.Lock()
+	accepted := s.pendingView != nil && membership.Equal(s.pendingView, req.View) && req.View.Epoch == s.view.Epoch+1
+	s.mu.Unlock()
+	if !accepted {
+		go s.syncView()
+		return &protocol.ViewResponse{Ok: false, Error: "view was not proposed here", View: s.currentView()}, nil
+	}
+	s.installView(req.View)
+	return &protocol.ViewResponse{Ok: true, View: s.currentView()}, nil
+}
+
+// viewSyncLoop pulls committed views from peers so a node that missed a
+// CommitView (down, partitioned, freshly added) still catches up.
+func (s *server) viewSyncLoop() {
+	tick := time.NewTicker(viewSyncInterval)
+	for range tick.C {
+		s.syncView()
+	}
+}
+
+// syncView asks the members of the current view for theirs and installs the
+// newest later view that a majority of them report.
+func (s *server) syncView() {
+	cur := s.currentView()
+	var views []*protocol.View
+	for _, addr := range membership.Addrs(cur) {
+		if addr == s.selfAddr {
+			continue
+		}
+		conn, err := dialPeer(addr, viewDialTimeout)
+		if err != nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), viewDialTimeout)
+		v, err := protocol.NewMembershipClient(conn).GetView(ctx, &protocol.GetViewRequest{})
+		cancel()
+		conn.Close()
+		if err == nil && v.GetEpoch() > cur.Epoch {
+			views = append(views, v)
+		}
+	}
+	if v := majorityView(cur, views); v != nil {
+		s.installView(v)
+	}
+}
+
+// majorityView returns the newest of views that a majority of cur's members
+// reported, one view per member; nil if there is none.
+func majorityView(cur *protocol.View, views []*protocol.View) *protocol.View {
+	var best *protocol.View
+	for _, v := range views {
+		n := 0
+		for _, w := range views {
+			if membership.Equal(v, w) {
+				n++
+			}
+		}
+		if n >= membership.Majority(cur) && v.Epoch > best.GetEpoch() {
+			best = v
+		}
+	}
+	return best
+}
+
+/* ------------------------------------------------------------------------ */
+/* in‑process client                                                        */
+/* ------------------------------------------------------------------------ */
+
+// viewPeer is the slice of MembershipClient a view change needs.
+type viewPeer interface {
+	ProposeView(context.Context, *protocol.ProposeViewRequest, ...grpc.CallOption) (*protocol.ViewResponse, error)
+	CommitView(context.Context, *protocol.CommitViewRequest, ...grpc.CallOption) (*protocol.ViewResponse, error)
+}
+
+// localMembership lets changeView treat the local node like any other peer.
+type localMembership struct{ s *server }
+
+func (l localMembership) ProposeView(ctx context.Context, in *protocol.ProposeViewRequest, _ ...grpc.CallOption) (*protocol.ViewResponse, error) {
+	return l.s.ProposeView(ctx, in)
+}
+
+func (l localMembership) CommitView(ctx context.Context, in *protocol.CommitViewRequest, _ ...grpc.CallOption) (*protocol.ViewResponse, error) {
+	return l.s.CommitView(ctx, in)
+}
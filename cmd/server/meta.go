@@ -0,0 +1,76 @@
+// cmd/server/meta.go – what metaBucket keeps of an object.
+// The user metadata and tags of a version are part of its FPCC, so they
+// are bound into the digest every node echoes and cannot be changed by
+// one node alone; the copy kept here answers List without decoding FPCCs.
+// Records are sealed like FPCCs when the node has a key.
+
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+// objectMeta is the metaBucket record of an object's current version.
+// Created is when the client wrote it, as its FPCC says, or for objects
+// from before FPCCs carried that, when this node first saw it; a transcode
+// keeps it.
+// Committed is set once the version is known to have committed, by a
+// Ready quorum, a promotion or a handoff.
+type objectMeta struct {
+	Created   time.Time
+	Committed bool              `json:",omitempty"`
+	Metadata  map[string]string `json:",omitempty"`
+	Tags      map[string]string `json:",omitempty"`
+}
+
+// newMeta returns the record of fpcc, created at t unless fpcc says when.
+func newMeta(fpcc *protocol.FPCC, t time.Time) objectMeta {
+	return objectMeta{Created: createdAt(fpcc, t), Metadata: fpcc.GetMetadata(), Tags: fpcc.GetTags()}
+}
+
+// createdAt returns the creation time fpcc carries, which every node
+// agrees on, or local for an FPCC without one.
+func createdAt(fpcc *protocol.FPCC, local time.Time) time.Time {
+	if c := fpcc.GetCreatedUnix(); c != 0 {
+		return time.Unix(c, 0)
+	}
+	return local
+}
+
+// getMeta reads the record of obj; ok is false when there is none.
+func (s *server) getMeta(tx *bolt.Tx, obj string) (objectMeta, bool) {
+	raw := tx.Bucket([]byte(metaBucket)).Get([]byte(obj))
+	if raw == nil {
+		return objectMeta{}, false
+	}
+	return s.decodeMeta(obj, raw)
+}
+
+// decodeMeta opens the stored record raw of obj.
+func (s *server) decodeMeta(obj string, raw []byte) (m objectMeta, ok bool) {
+	plain, err := s.vault.Unseal(sealName(metaBucket, obj), raw)
+	return m, err == nil && json.Unmarshal(plain, &m) == nil
+}
+
+func (s *server) putMeta(tx *bolt.Tx, obj string, m objectMeta) error {
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket([]byte(metaBucket)).Put([]byte(obj), s.vault.Seal(sealName(metaBucket, obj), raw))
+}
+
+// markCommitted records that obj's current version committed.
+func (s *server) markCommitted(tx *bolt.Tx, obj string) error {
+	m, ok := s.getMeta(tx, obj)
+	if !ok || m.Committed {
+		return nil
+	}
+	m.Committed = true
+	return s.putMeta(tx, obj, m)
+}
@@ -0,0 +1,125 @@
+// cmd/server/rebalance.go – moves fragments after membership changes.
+// Placement is a rendezvous hash over the writable members, so a view change
+// only reassigns the fragments whose top choice changed; every node pushes
+// the ones it no longer owns to their new owner and then drops its copy.
+// A member that is removed or replaced may be gone with its fragments, so
+// one of the nodes that stays rebuilds them for their new owners.
+
+package main
+
+import (
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/membership"
+	"github.com/dattu/distributed_object_store/pkg/placement"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+// requestRebalance schedules a rebalance pass without blocking; requests that
+// arrive while one is pending collapse into it.
+func (s *server) requestRebalance() {
+	select {
+	case s.rebalanceCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *server) rebalanceLoop() {
+	for range s.rebalanceCh {
+		s.rebalance()
+	}
+}
+
+func (s *server) rebalance() {
+	s.mu.Lock()
+	view, prev := s.view, s.prevView
+	s.mu.Unlock()
+	if membership.IsDraining(view, s.selfAddr) {
+		return // the drain loop owns this node's fragments now
+	}
+
+	moved, rebuilt, failed := 0, 0, 0
+	for _, obj := range s.localObjects() {
+		for _, fpcc := range s.heldGenerations(obj) {
+			m, f := s.moveGeneration(view, obj, fpcc)
+			moved, failed = moved+m, failed+f
+			if !s.repairs(prev, view, obj, fpcc) {
+				continue
+			}
+			if err := s.rehomeGeneration(obj, fpcc); err != nil {
+				log.Printf("rebalance: rebuilding %s gen=%d: %v", obj, fpcc.GetGeneration(), err)
+				failed++
+				continue
+			}
+			rebuilt++
+		}
+	}
+	if moved > 0 {
+		log.Printf("rebalance: epoch %d moved %d fragments off %s", view.Epoch, moved, s.selfAddr)
+	}
+	if rebuilt > 0 {
+		log.Printf("rebalance: epoch %d re-placed %d generations that lost an owner", view.Epoch, rebuilt)
+	}
+	if failed > 0 {
+		// owners may still be installing the new view; try again shortly
+		time.AfterFunc(drainRetryInterval, s.requestRebalance)
+	}
+}
+
+// moveGeneration pushes the fragments of one generation of obj that this
+// node holds but no longer owns to their owners, dropping each copy once
+// its owner has it. It returns how many moved and how many could not.
+func (s *server) moveGeneration(view *protocol.View, obj string, fpcc *protocol.FPCC) (moved, failed int) {
+	m, n := s.profileOf(fpcc)
+	owners, err := placement.ForView(view, obj, m, n)
+	if err != nil {
+		return 0, 0
+	}
+	gen := fpcc.GetGeneration()
+	for _, idx := range s.heldIndices(obj, fpcc) {
+		owner := owners[idx]
+		if owner == s.selfAddr {
+			continue
+		}
+		if !s.locate(owner, obj, fpcc)[idx] {
+			frag, err := s.loadFragment(obj, gen, idx)
+			if err != nil || !fragmentMatches(fpcc, idx, frag) {
+				continue
+			}
+			if err := s.handoff(owner, obj, idx, frag, fpcc); err != nil {
+				log.Printf("rebalance: %s gen=%d idx=%d to %s: %v", obj, gen, idx, owner, err)
+				failed++
+				continue
+			}
+		}
+		if err := os.Remove(s.fragPath(obj, gen, idx)); err == nil {
+			os.Remove(s.leavesPath(obj, gen, idx))
+			moved++
+		}
+	}
+	return moved, failed
+}
+
+// repairs reports whether this node restores the fragments of one
+// generation of obj that a member removed or replaced between prev and
+// view held: nobody hands those on if it is gone for good, so the first
+// of the generation's previous owners to remain rebuilds them.
+func (s *server) repairs(prev, view *protocol.View, obj string, fpcc *protocol.FPCC) bool {
+	if prev == nil {
+		return false
+	}
+	m, n := s.profileOf(fpcc)
+	owners, err := placement.ForView(prev, obj, m, n)
+	if err != nil || !slices.ContainsFunc(owners, func(o string) bool { return !membership.Contains(view, o) }) {
+		return false
+	}
+	for _, o := range owners {
+		if membership.Contains(view, o) {
+			return o == s.selfAddr
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+// cmd/server/rest.go – the node's plain HTTP/JSON API (pkg/httpapi) on
+// server.http_port. The node stores and fetches objects for HTTP clients
+// the way cmd/client would, through the coordinator's Client (see
+// coordinator.go).
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/httpapi"
+	"github.com/dattu/distributed_object_store/pkg/membership"
+)
+
+// nodeStatus is the JSON served at /status.
+type nodeStatus struct {
+	Self    string       `json:"self"`
+	Epoch   uint64       `json:"epoch"`
+	Members []memberInfo `json:"members"`
+	Data    int          `json:"m"`
+	Total   int          `json:"n"`
+	Faults  int          `json:"f"`
+	Objects int          `json:"objects"` // committed here
+	Drain   *drainInfo   `json:"drain,omitempty"`
+}
+
+type memberInfo struct {
+	Addr   string            `json:"addr"`
+	State  string            `json:"state"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type drainInfo struct {
+	Objects int  `json:"objects"`
+	Rehomed int  `json:"rehomed"`
+	Safe    bool `json:"safe"`
+}
+
+func (s *server) status() any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := nodeStatus{Self: s.selfAddr, Epoch: s.view.GetEpoch(), Data: s.m, Total: s.n, Faults: s.f, Objects: len(s.fpccs)}
+	for _, mem := range s.view.GetMembers() {
+		st.Members = append(st.Members, memberInfo{Addr: mem.Addr, State: mem.State.String(), Labels: mem.Labels})
+	}
+	// a finished drain keeps reporting its result until the node is removed
+	if d := s.drain; d.active || membership.IsDraining(s.view, s.selfAddr) {
+		st.Drain = &drainInfo{Objects: d.objects, Rehomed: d.rehomed, Safe: d.safe}
+	}
+	return st
+}
+
+// serveHTTPAPI serves the HTTP API on port until it fails.
+func (s *server) serveHTTPAPI(port int) error {
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           httpapi.New(s.coord, httpapi.Config{Status: s.status, Logf: log.Printf}),
+		ReadHeaderTimeout: 30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+	log.Printf("HTTP API on %s/objects", srv.Addr)
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,84 @@
+// cmd/server/rotate.go – offline node-key rotation.
+// Point storage.key_file at the new key, move the old one to
+// storage.old_key_files and run `server -config … -rotate-key` with the node
+// stopped. Fragments and their block‑hash sidecars only get a new header,
+// FPCCs – current ones and those of noncurrent versions – and object
+// records are re-sealed, and any plaintext left from before encryption was
+// enabled is sealed as well; afterwards the old key can be dropped from the
+// config.
+
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/storage"
+	bolt "go.etcd.io/bbolt"
+)
+
+func runRotate(vault *storage.Vault, dataDir, dbPath string) {
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{Timeout: 2 * time.Second})
+	if err != nil {
+		log.Fatalf("bolt.Open: %v (is the node still running?)", err)
+	}
+	defer db.Close()
+
+	var files, rotated int
+	err = filepath.WalkDir(dataDir, func(path string, d fs.DirEntry, err error) error {
+		if ext := filepath.Ext(path); err != nil || d.IsDir() || (ext != ".bin" && ext != ".leaves") {
+			return err
+		}
+		files++
+		changed, err := vault.Rotate(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if changed {
+			rotated++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("rotate fragments: %v", err)
+	}
+
+	var resealed int
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bkt := range []string{fpccsBucket, versionsBucket, metaBucket} {
+			b := tx.Bucket([]byte(bkt))
+			if b == nil {
+				continue
+			}
+			updates := make(map[string][]byte)
+			err := b.ForEach(func(k, v []byte) error {
+				if vault.Current(v) {
+					return nil
+				}
+				plain, err := vault.Unseal(sealName(bkt, string(k)), v)
+				if err != nil {
+					return fmt.Errorf("%s: %w", sealName(bkt, string(k)), err)
+				}
+				updates[string(k)] = vault.Seal(sealName(bkt, string(k)), plain)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			for k, v := range updates { // bolt forbids writes while iterating
+				if err := b.Put([]byte(k), v); err != nil {
+					return err
+				}
+			}
+			resealed += len(updates)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("rotate FPCCs and records: %v", err)
+	}
+	log.Printf("key rotation: %d/%d fragments and %d FPCCs and records re‑sealed under node key %s", rotated, files, resealed, vault.KeyID())
+}
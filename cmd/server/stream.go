@@ -0,0 +1,99 @@
+// cmd/server/stream.go – streaming Disperse / Retrieve.
+// Fragments move in chunks instead of one message, so their size is bounded
+// by disk rather than by the gRPC message limit or the node's memory.
+
+package main
+
+import (
+	"io"
+
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// streamChunk is the payload size of each streamed message.
+const streamChunk = 256 << 10
+
+// chunkReader presents a sequence of chunk payloads as one io.Reader.
+type chunkReader struct {
+	buf  []byte
+	next func() ([]byte, error)
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		data, err := r.next()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = data
+	}
+	k := copy(p, r.buf)
+	r.buf = r.buf[k:]
+	return k, nil
+}
+
+func (s *server) DisperseStream(stream protocol.Dispersal_DisperseStreamServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	body := &chunkReader{buf: first.Data, next: func() ([]byte, error) {
+		c, err := stream.Recv()
+		if err != nil {
+			return nil, err // io.EOF once the client closes its side
+		}
+		return c.Data, nil
+	}}
+	req := &protocol.DisperseRequest{ObjectId: first.ObjectId, FragmentIndex: first.FragmentIndex, Fpcc: first.Fpcc}
+	return stream.SendAndClose(s.disperse(req, body))
+}
+
+func (s *server) RetrieveStream(req *protocol.RetrieveRequest, stream protocol.Dispersal_RetrieveStreamServer) error {
+	timer := prometheus.NewTimer(retrieveLatency)
+	defer timer.ObserveDuration()
+	retrieveTotal.Inc()
+
+	if err := protocol.CheckID(req.ObjectId); err != nil {
+		return stream.Send(&protocol.RetrieveChunk{Ok: false, Error: err.Error()})
+	}
+	f, err := s.vault.Open(s.fragPath(req.ObjectId, req.Generation, req.FragmentIndex))
+	if err != nil {
+		return stream.Send(&protocol.RetrieveChunk{Ok: false, Error: "fragment missing"})
+	}
+	defer f.Close()
+	lo, hi, err := fragmentRange(f, req)
+	if err != nil {
+		return stream.Send(&protocol.RetrieveChunk{Ok: false, Error: err.Error()})
+	}
+	if _, err := f.Seek(lo, io.SeekStart); err != nil {
+		return stream.Send(&protocol.RetrieveChunk{Ok: false, Error: "fragment unreadable"})
+	}
+	body := io.LimitReader(f, hi-lo)
+	fpcc := s.generationFPCC(req.ObjectId, req.Generation)
+	s.countRetrieve(fpcc, req.FragmentIndex)
+
+	msg := &protocol.RetrieveChunk{Ok: true, Fpcc: fpcc, Length: uint64(f.Size())}
+	if req.Proofs {
+		if msg.Proofs, err = s.blockProofs(req.ObjectId, fpcc, req.FragmentIndex, f, lo, hi); err != nil {
+			return stream.Send(&protocol.RetrieveChunk{Ok: false, Error: err.Error()})
+		}
+	}
+	buf := make([]byte, streamChunk)
+	for {
+		k, err := io.ReadFull(body, buf)
+		if k > 0 || msg.Ok {
+			msg.Data = buf[:k]
+			if serr := stream.Send(msg); serr != nil {
+				return serr
+			}
+			msg = &protocol.RetrieveChunk{}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
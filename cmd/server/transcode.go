@@ -0,0 +1,147 @@
+// cmd/server/transcode.go – generations of an object under one ID.
+// A transcode disperses a re‑encoded copy as generation g+1 of the same ID,
+// and so does writing the ID again (see versions.go). Either runs its own
+// Echo/Ready round next to the committed generation, which keeps serving
+// reads; once the round commits every node promotes it, and old fragments
+// no version keeps are dropped after a grace period for in‑flight readers.
+
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+	bolt "go.etcd.io/bbolt"
+)
+
+// roundFPCC returns the FPCC of generation gen of obj, whether committed or
+// still pending; nil if this node knows neither. Caller holds s.mu.
+func (s *server) roundFPCC(obj string, gen uint64) *protocol.FPCC {
+	if cur := s.fpccs[obj]; cur != nil && cur.GetGeneration() == gen {
+		return cur
+	}
+	if p := s.pending[obj]; p != nil && p.GetGeneration() == gen {
+		return p
+	}
+	return nil
+}
+
+// promote makes a committed generation the object's current FPCC. A node
+// that dispersed the generation itself only accepts the FPCC it checked its
+// fragment against.
+func (s *server) promote(obj string, fpcc *protocol.FPCC) {
+	s.mu.Lock()
+	cur := s.fpccs[obj]
+	if cur != nil && cur.GetGeneration() >= fpcc.GetGeneration() {
+		s.mu.Unlock()
+		return
+	}
+	if p := s.pending[obj]; p != nil && p.GetGeneration() == fpcc.GetGeneration() {
+		if !eqFPCC(p, fpcc) {
+			s.mu.Unlock()
+			log.Printf("[Transcode] %s gen=%d: committed FPCC differs from the dispersed one", obj, fpcc.GetGeneration())
+			return
+		}
+		delete(s.pending, obj)
+	}
+	if cur != nil && !s.committedLocked(obj) {
+		cur = nil // a first round that never committed, skipped by this one
+	}
+	s.fpccs[obj] = fpcc
+	s.closeCommitLocked(roundKey(obj, fpcc))
+	if s.fences[obj] <= fpcc.GetGeneration() {
+		delete(s.fences, obj)
+	}
+	s.mu.Unlock()
+
+	// a new version supersedes cur, which joins the object's history; a
+	// transcode replaces it
+	version := cur != nil && !fpcc.GetTranscoded()
+	now := time.Now()
+	_ = s.metaDB.Update(func(tx *bolt.Tx) error {
+		if err := s.putFPCC(tx, obj, fpcc); err != nil {
+			return err
+		}
+		meta := newMeta(fpcc, now)
+		meta.Committed = true
+		if version {
+			if err := s.supersede(tx, obj, cur, now); err != nil {
+				return err
+			}
+		} else if old, ok := s.getMeta(tx, obj); ok {
+			meta.Created = old.Created
+		}
+		return s.putMeta(tx, obj, meta)
+	})
+	switch {
+	case version:
+		log.Printf("[Version] %s: version %d supersedes %d", obj, fpcc.GetGeneration(), cur.GetGeneration())
+		s.pruneVersions(obj, now)
+	case fpcc.GetTranscoded():
+		log.Printf("[Transcode] %s now at generation %d (%d‑of‑%d)", obj, fpcc.GetGeneration(),
+			fpcc.GetProfile().GetData(), fpcc.GetProfile().GetTotal())
+	default:
+		log.Printf("[Version] %s: first version committed at generation %d", obj, fpcc.GetGeneration())
+	}
+	// older generations, and any skipped ones, go once readers have moved on
+	time.AfterFunc(retireGrace, func() { s.retire(obj) })
+}
+
+// retire deletes the fragments of every generation older than obj's
+// current one that is not kept as a version.
+func (s *server) retire(obj string) {
+	s.mu.Lock()
+	cur := s.fpccs[obj]
+	s.mu.Unlock()
+	if cur.GetGeneration() == 0 {
+		return
+	}
+	kept := s.retained(obj)
+
+	entries, err := os.ReadDir(filepath.Join(s.dataDir, obj))
+	if err != nil {
+		return
+	}
+	removed := 0
+	for _, e := range entries {
+		var gen uint64 // plain N.bin files are generation 0
+		if e.IsDir() {
+			digits, ok := strings.CutPrefix(e.Name(), "g")
+			g, err := strconv.ParseUint(digits, 10, 64)
+			if !ok || err != nil {
+				continue
+			}
+			gen = g
+		}
+		if gen >= cur.GetGeneration() || kept[gen] {
+			continue
+		}
+		if os.RemoveAll(filepath.Join(s.dataDir, obj, e.Name())) == nil {
+			removed++
+		}
+	}
+	if removed > 0 {
+		log.Printf("[Transcode] %s: retired %d entries older than generation %d", obj, removed, cur.GetGeneration())
+	}
+}
+
+// retireAll sweeps objects whose transcode or new version committed while
+// this node was down or before its grace timer fired.
+func (s *server) retireAll() {
+	s.mu.Lock()
+	var objs []string
+	for obj, fpcc := range s.fpccs {
+		if fpcc.GetGeneration() > 0 {
+			objs = append(objs, obj)
+		}
+	}
+	s.mu.Unlock()
+	for _, obj := range objs {
+		s.retire(obj)
+	}
+}
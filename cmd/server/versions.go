@@ -0,0 +1,259 @@
+// cmd/server/versions.go – version history of an object.
+// Writing an ID again disperses the new content as the next generation,
+// whose own Echo/Ready round agrees on its number just as a transcode's
+// does. When it commits, the version it supersedes is recorded in
+// versionsBucket instead of being retired, and stays readable under its
+// FPCC digest until retention drops it: beyond the newest keepVersions
+// noncurrent versions, superseded more than noncurrentTTL ago, or once the
+// object TTL has run from its own creation. A transcode (FPCC.Transcoded)
+// replaces the generation it re‑encodes rather than adding a version.
+// A write may be conditional on the version it replaces (FPCC.IfMatchVersion)
+// or on there being none (FPCC.IfNoneMatch); each node checks before it
+// echoes, so a write whose condition fails cannot gather a quorum. Racing
+// conditional writes can split a generation so that neither commits; a
+// retry may then skip it, but only on nodes that never sent Ready for it,
+// and those fence it off for good.
+
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/chunker"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+	bolt "go.etcd.io/bbolt"
+)
+
+// version is what versionsBucket keeps of a noncurrent version.
+type version struct {
+	Fpcc       *protocol.FPCC
+	Created    time.Time
+	Superseded time.Time
+}
+
+// versionKey names generation gen of obj in versionsBucket. Every
+// generation is numbered, so one object's versions share the prefix obj#,
+// which no other object's can start with: IDs hold no "#".
+func versionKey(obj string, gen uint64) string {
+	return fmt.Sprintf("%s#%d", obj, gen)
+}
+
+func (s *server) putVersion(tx *bolt.Tx, obj string, v version) error {
+	key := versionKey(obj, v.Fpcc.GetGeneration())
+	raw, _ := json.Marshal(v)
+	return tx.Bucket([]byte(versionsBucket)).Put([]byte(key), s.vault.Seal(sealName(versionsBucket, key), raw))
+}
+
+// versions returns the noncurrent versions of obj this node keeps, newest
+// first.
+func (s *server) versions(tx *bolt.Tx, obj string) []version {
+	var out []version
+	prefix := []byte(obj + "#")
+	c := tx.Bucket([]byte(versionsBucket)).Cursor()
+	for k, raw := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, raw = c.Next() {
+		plain, err := s.vault.Unseal(sealName(versionsBucket, string(k)), raw)
+		var v version
+		if err == nil {
+			err = json.Unmarshal(plain, &v)
+		}
+		if err != nil {
+			log.Printf("[Version] %s: %v", k, err)
+			continue
+		}
+		out = append(out, v)
+	}
+	slices.SortFunc(out, func(a, b version) int {
+		return cmp.Compare(b.Fpcc.GetGeneration(), a.Fpcc.GetGeneration())
+	})
+	return out
+}
+
+// versionFPCC returns the FPCC of noncurrent generation gen of obj; nil if
+// this node keeps no such version.
+func (s *server) versionFPCC(obj string, gen uint64) *protocol.FPCC {
+	var fpcc *protocol.FPCC
+	_ = s.metaDB.View(func(tx *bolt.Tx) error {
+		for _, v := range s.versions(tx, obj) {
+			if v.Fpcc.GetGeneration() == gen {
+				fpcc = v.Fpcc
+			}
+		}
+		return nil
+	})
+	return fpcc
+}
+
+// supersede records cur, the version a newly committed one replaces, with
+// the creation time kept for it in metaBucket.
+func (s *server) supersede(tx *bolt.Tx, obj string, cur *protocol.FPCC, now time.Time) error {
+	meta, _ := s.getMeta(tx, obj)
+	return s.putVersion(tx, obj, version{Fpcc: cur, Created: meta.Created, Superseded: now})
+}
+
+// expiredVersion reports whether retention drops v, the i‑th newest
+// noncurrent version of obj. Past keepVersions and noncurrentTTL even a
+// retained version goes; otherwise it expires like the object would.
+func (s *server) expiredVersion(obj string, i int, v version, now time.Time) bool {
+	return i >= s.keepVersions ||
+		(s.noncurrentTTL > 0 && now.Sub(v.Superseded) > s.noncurrentTTL) ||
+		now.After(s.expiry(obj, v.Fpcc, v.Created))
+}
+
+// pruneVersions forgets the versions of obj that retention drops and
+// reports how many went; their fragments go with the next retire. A
+// deduplicated version releases the chunks no version left refers to.
+func (s *server) pruneVersions(obj string, now time.Time) int {
+	s.mu.Lock()
+	current := s.fpccs[obj].GetManifest()
+	s.mu.Unlock()
+	pruned := 0
+	_ = s.metaDB.Update(func(tx *bolt.Tx) error {
+		kept := make(map[string]bool)
+		for _, c := range current.GetChunks() {
+			kept[chunker.ID(c.Hash)] = true
+		}
+		var gone []*protocol.ChunkRef
+		b := tx.Bucket([]byte(versionsBucket))
+		for i, v := range s.versions(tx, obj) {
+			if !s.expiredVersion(obj, i, v, now) {
+				for _, c := range v.Fpcc.GetManifest().GetChunks() {
+					kept[chunker.ID(c.Hash)] = true
+				}
+				continue
+			}
+			if err := b.Delete([]byte(versionKey(obj, v.Fpcc.GetGeneration()))); err != nil {
+				return err
+			}
+			gone = append(gone, v.Fpcc.GetManifest().GetChunks()...)
+			pruned++
+		}
+		gone = slices.DeleteFunc(gone, func(c *protocol.ChunkRef) bool { return kept[chunker.ID(c.Hash)] })
+		dropRefs(tx, obj, &protocol.Manifest{Chunks: gone})
+		return nil
+	})
+	if pruned > 0 {
+		log.Printf("[Version] %s: dropped %d noncurrent versions", obj, pruned)
+	}
+	return pruned
+}
+
+// pruneAllVersions applies retention to every object with a history, for
+// versions that age out rather than being pushed out by a newer one.
+func (s *server) pruneAllVersions() {
+	objs := make(map[string]bool)
+	_ = s.metaDB.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(versionsBucket)).ForEach(func(k, _ []byte) error {
+			if i := strings.LastIndexByte(string(k), '#'); i > 0 {
+				objs[string(k[:i])] = true
+			}
+			return nil
+		})
+	})
+	now := time.Now()
+	for obj := range objs {
+		if s.pruneVersions(obj, now) > 0 {
+			s.retire(obj)
+		}
+	}
+}
+
+// retained returns the generations of obj that are kept as versions.
+func (s *server) retained(obj string) map[uint64]bool {
+	out := make(map[uint64]bool)
+	_ = s.metaDB.View(func(tx *bolt.Tx) error {
+		for _, v := range s.versions(tx, obj) {
+			out[v.Fpcc.GetGeneration()] = true
+		}
+		return nil
+	})
+	return out
+}
+
+// preconditionLocked refuses a conditional write that does not hold here:
+// the object must be committed at the version an IfMatchVersion write
+// names, or not at all for an IfNoneMatch one. A write past the next
+// generation skips ones an earlier race left without a quorum; it is
+// refused if this node sent Ready for any of them, as that one may still
+// commit, and otherwise fences them so it never will from here on. A
+// quorum of nodes must admit the write, and every Ready quorum meets it,
+// so a skipped generation and the write never both commit. Caller holds
+// s.mu.
+func (s *server) preconditionLocked(obj string, fpcc *protocol.FPCC) error {
+	want := fpcc.GetIfMatchVersion()
+	if !fpcc.GetIfNoneMatch() && len(want) == 0 {
+		return nil
+	}
+	if fpcc.GetIfNoneMatch() && len(want) > 0 {
+		return errors.New("precondition failed: if_none_match and if_match_version both set")
+	}
+	cur := s.fpccs[obj]
+	if cur != nil && !s.committedLocked(obj) {
+		cur = nil
+	}
+	gen := fpcc.GetGeneration()
+	from := uint64(0) // first generation the write may skip
+	switch {
+	case cur != nil && eqFPCC(cur, fpcc):
+		return nil // a retry of this write, already committed
+	case fpcc.GetIfNoneMatch() && cur != nil:
+		return errors.New("precondition failed: object exists")
+	case fpcc.GetIfNoneMatch():
+	case cur == nil:
+		return errors.New("precondition failed: object not found")
+	case !bytes.Equal(cur.Digest(), want):
+		return fmt.Errorf("precondition failed: version %x is not current", want)
+	case gen <= cur.GetGeneration():
+		return fmt.Errorf("precondition failed: object is at generation %d", cur.GetGeneration())
+	default:
+		from = cur.GetGeneration() + 1
+	}
+	for g := from; g < gen; g++ {
+		if rk := roundKey(obj, &protocol.FPCC{Generation: g}); s.readySent[rk] || s.readySeen[rk][s.selfAddr] {
+			return fmt.Errorf("precondition failed: generation %d may still commit", g)
+		}
+	}
+	if gen > from {
+		s.fences[obj] = max(s.fences[obj], gen)
+	}
+	return nil
+}
+
+/* --- Versions --- */
+
+// Versions lists the current version of an object, if committed here, and
+// the noncurrent ones this node keeps.
+func (s *server) Versions(ctx context.Context, req *protocol.VersionsRequest) (*protocol.VersionsResponse, error) {
+	if err := protocol.CheckID(req.ObjectId); err != nil {
+		return &protocol.VersionsResponse{Ok: false, Error: err.Error()}, nil
+	}
+	s.mu.Lock()
+	cur := s.fpccs[req.ObjectId]
+	if cur != nil && !s.committedLocked(req.ObjectId) {
+		cur = nil
+	}
+	s.mu.Unlock()
+	var out []*protocol.VersionEntry
+	_ = s.metaDB.View(func(tx *bolt.Tx) error {
+		if cur != nil {
+			meta, _ := s.getMeta(tx, req.ObjectId)
+			out = append(out, &protocol.VersionEntry{Fpcc: cur, CreatedUnix: meta.Created.Unix()})
+		}
+		for _, v := range s.versions(tx, req.ObjectId) {
+			out = append(out, &protocol.VersionEntry{Fpcc: v.Fpcc, CreatedUnix: v.Created.Unix(), SupersededUnix: v.Superseded.Unix()})
+		}
+		return nil
+	})
+	if len(out) == 0 {
+		return &protocol.VersionsResponse{Ok: false, Error: "object not found"}, nil
+	}
+	return &protocol.VersionsResponse{Ok: true, Versions: out}, nil
+}
@@ -0,0 +1,69 @@
+// pkg/blockhash/blockhash.go
+// Package blockhash hashes a byte stream in fixed-size blocks, the unit in
+// which range reads verify fragment data.
+package blockhash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"hash"
+)
+
+// Writer computes the SHA-256 of every size-byte block written to it.
+type Writer struct {
+	size int
+	h    hash.Hash
+	fill int
+	sums [][]byte
+}
+
+// New returns a Writer for blocks of size bytes.
+func New(size int) *Writer {
+	return &Writer{size: size, h: sha256.New()}
+}
+
+// Write hashes p into the current block, closing blocks as they fill; it
+// never fails.
+func (w *Writer) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		k := min(w.size-w.fill, len(p))
+		w.h.Write(p[:k])
+		w.fill += k
+		p = p[k:]
+		if w.fill == w.size {
+			w.sums = append(w.sums, w.h.Sum(nil))
+			w.h.Reset()
+			w.fill = 0
+		}
+	}
+	return n, nil
+}
+
+// Sums returns the hash of every block written so far, including a final
+// partial block.
+func (w *Writer) Sums() [][]byte {
+	if w.fill == 0 {
+		return w.sums
+	}
+	return append(w.sums[:len(w.sums):len(w.sums)], w.h.Sum(nil))
+}
+
+// Sum returns the hash of a single block.
+func Sum(block []byte) []byte {
+	h := sha256.Sum256(block)
+	return h[:]
+}
+
+// Equal reports whether a and b list the same hashes.
+func Equal(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
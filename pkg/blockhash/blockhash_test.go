@@ -0,0 +1,28 @@
+// pkg/blockhash/blockhash_test.go
+package blockhash
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriterMatchesBlocks(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 25) // 250 bytes: 3 full blocks + 10
+	w := New(80)
+	for _, k := range []int{1, 79, 100, 70} { // writes straddle block edges
+		w.Write(data[:k])
+		data = data[k:]
+	}
+	data = bytes.Repeat([]byte("0123456789"), 25)
+
+	var want [][]byte
+	for off := 0; off < len(data); off += 80 {
+		want = append(want, Sum(data[off:min(off+80, len(data))]))
+	}
+	if got := w.Sums(); !Equal(got, want) {
+		t.Fatalf("got %d sums, want %d matching the blocks", len(got), len(want))
+	}
+	if !Equal(w.Sums(), want) {
+		t.Fatalf("Sums is not repeatable")
+	}
+}
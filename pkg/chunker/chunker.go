@@ -0,0 +1,128 @@
+// pkg/chunker/chunker.go
+// Package chunker splits a stream into content-defined chunks for
+// deduplication. Cut points are chosen by a gear rolling hash over the
+// bytes themselves, not by offset, so an insertion or deletion only moves
+// the chunks around it: two builds that differ by a few MB share almost
+// every other chunk.
+package chunker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"math/bits"
+	"strings"
+)
+
+// DefaultAverage is the chunk size aimed for. Chunks are never shorter
+// than a quarter of the average (except the last) nor longer than four
+// times it.
+const DefaultAverage = 1 << 20
+
+// gear maps every byte value to a fixed pseudo-random word. It is part of
+// the chunk format: changing it moves every cut point and defeats
+// deduplication against data stored before.
+var gear = func() (t [256]uint64) {
+	x := uint64(0x6176_6964_2d66_7063) // splitmix64 seeded with "avid-fpc"
+	for i := range t {
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+		z = (z ^ z>>27) * 0x94d049bb133111eb
+		t[i] = z ^ z>>31
+	}
+	return
+}()
+
+// Chunker cuts the bytes of a reader into chunks.
+type Chunker struct {
+	r             io.Reader
+	min, avg, max int
+	strict, loose uint64 // cut masks before and after the average
+	buf           []byte
+	start, end    int
+	eof           bool
+}
+
+// New returns a Chunker reading r that aims for avg-byte chunks; avg must
+// be a power of two of at least 64.
+func New(r io.Reader, avg int) *Chunker {
+	b := bits.Len(uint(avg)) - 1
+	// normalised chunking: a harder cut condition before the average and
+	// an easier one after it pull chunk sizes towards avg
+	return &Chunker{
+		r:      r,
+		min:    avg / 4,
+		avg:    avg,
+		max:    avg * 4,
+		strict: ^uint64(0) << (64 - b - 2),
+		loose:  ^uint64(0) << (64 - b + 2),
+		buf:    make([]byte, avg*4),
+	}
+}
+
+// Next returns the next chunk, or io.EOF after the last one. The slice is
+// only valid until the following call.
+func (c *Chunker) Next() ([]byte, error) {
+	c.end = copy(c.buf, c.buf[c.start:c.end])
+	c.start = 0
+	if !c.eof {
+		k, err := io.ReadFull(c.r, c.buf[c.end:])
+		c.end += k
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			c.eof = true
+		default:
+			return nil, err
+		}
+	}
+	if c.end == 0 {
+		return nil, io.EOF
+	}
+	c.start = c.cut(c.buf[:c.end])
+	return c.buf[:c.start], nil
+}
+
+// cut returns the length of the chunk at the start of data, which holds
+// either max bytes or everything left.
+func (c *Chunker) cut(data []byte) int {
+	if len(data) <= c.min {
+		return len(data)
+	}
+	var h uint64
+	i, normal, end := c.min, min(c.avg, len(data)), min(c.max, len(data))
+	for ; i < normal; i++ {
+		h = h<<1 + gear[data[i]]
+		if h&c.strict == 0 {
+			return i + 1
+		}
+	}
+	for ; i < end; i++ {
+		h = h<<1 + gear[data[i]]
+		if h&c.loose == 0 {
+			return i + 1
+		}
+	}
+	return end
+}
+
+// Prefix starts the ID of every chunk object; the rest is the hex SHA-256
+// of the chunk's content.
+const Prefix = "chunk:"
+
+// Sum is the content hash that identifies a chunk.
+func Sum(chunk []byte) []byte {
+	s := sha256.Sum256(chunk)
+	return s[:]
+}
+
+// ID is the object ID a chunk with content hash sum is stored under.
+func ID(sum []byte) string {
+	return Prefix + hex.EncodeToString(sum)
+}
+
+// IsID reports whether obj names a chunk object.
+func IsID(obj string) bool {
+	return strings.HasPrefix(obj, Prefix)
+}
@@ -0,0 +1,85 @@
+// pkg/chunker/chunker_test.go
+package chunker
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func chunks(t *testing.T, data []byte, avg int) [][]byte {
+	t.Helper()
+	c := New(bytes.NewReader(data), avg)
+	var out [][]byte
+	for {
+		chunk, err := c.Next()
+		if errors.Is(err, io.EOF) {
+			return out
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, bytes.Clone(chunk))
+	}
+}
+
+func TestChunkBounds(t *testing.T) {
+	const avg = 4096
+	data := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(data)
+	got := chunks(t, data, avg)
+	if !bytes.Equal(bytes.Join(got, nil), data) {
+		t.Fatalf("chunks do not add up to the input")
+	}
+	for i, c := range got {
+		if len(c) > 4*avg || (len(c) < avg/4 && i != len(got)-1) {
+			t.Errorf("chunk %d is %d bytes", i, len(c))
+		}
+	}
+	if mean := len(data) / len(got); mean < avg/2 || mean > 2*avg {
+		t.Errorf("mean chunk size %d, want about %d", mean, avg)
+	}
+	// a run of zeros never hits a cut point and falls back to max
+	if got := chunks(t, make([]byte, 10*avg), avg); len(got) != 3 || len(got[0]) != 4*avg {
+		t.Errorf("zeros cut into %d chunks", len(got))
+	}
+	if got := chunks(t, nil, avg); len(got) != 0 {
+		t.Errorf("empty input gave %d chunks", len(got))
+	}
+}
+
+func TestChunksSurviveEdits(t *testing.T) {
+	const avg = 4096
+	rng := rand.New(rand.NewSource(2))
+	old := make([]byte, 1<<20)
+	rng.Read(old)
+	patch := make([]byte, 3000)
+	rng.Read(patch)
+	// insert a few KB near the start and overwrite some in the middle
+	edited := append(append(bytes.Clone(old[:1000]), patch...), old[1000:]...)
+	copy(edited[500_000:], patch)
+
+	seen := make(map[string]bool)
+	for _, c := range chunks(t, old, avg) {
+		seen[ID(Sum(c))] = true
+	}
+	var shared, total int
+	for _, c := range chunks(t, edited, avg) {
+		total += len(c)
+		if seen[ID(Sum(c))] {
+			shared += len(c)
+		}
+	}
+	if shared < total*9/10 {
+		t.Errorf("only %d/%d bytes of the edited input reuse old chunks", shared, total)
+	}
+}
+
+func TestID(t *testing.T) {
+	id := ID(Sum([]byte("abc")))
+	if id != "chunk:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" || !IsID(id) || IsID("build.tar") {
+		t.Errorf("ID = %q", id)
+	}
+}
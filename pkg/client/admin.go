@@ -0,0 +1,101 @@
+// pkg/client/admin.go – membership administration: the view, adding,
+// removing, replacing and draining nodes.
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dattu/distributed_object_store/pkg/membership"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+// admin runs a membership call against the first server that answers.
+func (c *Client) admin(ctx context.Context, op string, call func(protocol.MembershipClient) (*protocol.MembershipResponse, error)) (*protocol.View, error) {
+	var last error = ErrUnavailable
+	for _, addr := range membership.Addrs(c.liveView(ctx)) {
+		cc, err := c.pool.conn(ctx, addr)
+		if err != nil {
+			last = fmt.Errorf("%w: %s: %v", ErrUnavailable, addr, err)
+			continue
+		}
+		resp, err := call(protocol.NewMembershipClient(cc))
+		if err != nil {
+			last = fmt.Errorf("%w: %s: %v", ErrUnavailable, addr, err)
+			c.logf("%s via %s failed: %v", op, addr, err)
+			continue
+		}
+		if !resp.Ok {
+			return nil, wrap(op, "", fmt.Errorf("%w: %s", ErrRejected, resp.Error))
+		}
+		c.mu.Lock()
+		if resp.View != nil && resp.View.Epoch >= c.view.Epoch {
+			c.view = resp.View
+		}
+		c.mu.Unlock()
+		return resp.View, nil
+	}
+	if err := ctx.Err(); err != nil {
+		last = err
+	}
+	return nil, wrap(op, "", last)
+}
+
+// Members returns the committed membership view.
+func (c *Client) Members(ctx context.Context) (*protocol.View, error) {
+	return c.admin(ctx, "members", func(mc protocol.MembershipClient) (*protocol.MembershipResponse, error) {
+		v, err := mc.GetView(ctx, &protocol.GetViewRequest{})
+		if err != nil {
+			return nil, err
+		}
+		return &protocol.MembershipResponse{Ok: true, View: v}, nil
+	})
+}
+
+// AddNode admits node, with its topology labels, and returns the new view.
+func (c *Client) AddNode(ctx context.Context, node string, labels map[string]string) (*protocol.View, error) {
+	return c.admin(ctx, "add-node", func(mc protocol.MembershipClient) (*protocol.MembershipResponse, error) {
+		return mc.AddNode(ctx, &protocol.AddNodeRequest{Addr: node, Labels: labels})
+	})
+}
+
+// RemoveNode drops node from the view; drain it first to keep its objects
+// at full redundancy.
+func (c *Client) RemoveNode(ctx context.Context, node string) (*protocol.View, error) {
+	return c.admin(ctx, "remove-node", func(mc protocol.MembershipClient) (*protocol.MembershipResponse, error) {
+		return mc.RemoveNode(ctx, &protocol.RemoveNodeRequest{Addr: node})
+	})
+}
+
+// ReplaceNode swaps node for replacement in one view change.
+func (c *Client) ReplaceNode(ctx context.Context, node, replacement string, labels map[string]string) (*protocol.View, error) {
+	return c.admin(ctx, "replace-node", func(mc protocol.MembershipClient) (*protocol.MembershipResponse, error) {
+		return mc.ReplaceNode(ctx, &protocol.ReplaceNodeRequest{OldAddr: node, NewAddr: replacement, Labels: labels})
+	})
+}
+
+// Drain marks node as draining: it takes no new fragments and hands the
+// ones it holds to their new owners. DrainStatus follows the progress.
+func (c *Client) Drain(ctx context.Context, node string) (*protocol.View, error) {
+	return c.admin(ctx, "drain", func(mc protocol.MembershipClient) (*protocol.MembershipResponse, error) {
+		return mc.Drain(ctx, &protocol.DrainRequest{Addr: node})
+	})
+}
+
+// DrainStatus asks a draining node how many of its objects are back at
+// full redundancy elsewhere.
+func (c *Client) DrainStatus(ctx context.Context, node string) (*protocol.DrainStatusResponse, error) {
+	cc, err := c.pool.conn(ctx, node)
+	if err != nil {
+		return nil, wrap("drain-status", "", fmt.Errorf("%w: %s: %v", ErrUnavailable, node, err))
+	}
+	st, err := protocol.NewMembershipClient(cc).DrainStatus(ctx, &protocol.DrainRequest{Addr: node})
+	if err != nil {
+		return nil, wrap("drain-status", "", fmt.Errorf("%w: %s: %v", ErrUnavailable, node, err))
+	}
+	if !st.Ok {
+		return nil, wrap("drain-status", "", fmt.Errorf("%w: %s", ErrRejected, st.Error))
+	}
+	return st, nil
+}
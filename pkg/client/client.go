@@ -0,0 +1,487 @@
+// pkg/client/client.go
+// Package client is the Go SDK of the object store: it erasure codes,
+// fingerprints and disperses objects on the caller's side and reads them
+// back from verified fragments, as cmd/client does on the command line.
+// A Client is safe for concurrent use and keeps one connection per server
+// for its lifetime. Failures come back as errors – *Error, wrapping one of
+// the sentinel errors below where the cause is known – and every call
+// stops when its context is done.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/chunker"
+	"github.com/dattu/distributed_object_store/pkg/config"
+	"github.com/dattu/distributed_object_store/pkg/envelope"
+	"github.com/dattu/distributed_object_store/pkg/erasure"
+	"github.com/dattu/distributed_object_store/pkg/membership"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+var (
+	// ErrNotFound means no server knows the object.
+	ErrNotFound = errors.New("object not found")
+	// ErrExists means another write took the version being written, and
+	// kept taking the next one while Put retried.
+	ErrExists = errors.New("object exists with different content")
+	// ErrUnavailable means too few servers answered to complete the call.
+	ErrUnavailable = errors.New("not enough servers reachable")
+	// ErrCorrupt means too few fragments verified to decode the object.
+	ErrCorrupt = errors.New("object cannot be decoded from verified fragments")
+	// ErrInvalid means the request itself is wrong: a bad ID, range or option.
+	ErrInvalid = errors.New("invalid request")
+	// ErrPrecondition means a conditional Put found the object in another
+	// state than it asked for: existing, or at another version.
+	ErrPrecondition = errors.New("precondition failed")
+	// ErrRejected means the cluster refused a membership change, e.g. for
+	// removing a node that is not a member; the error says why.
+	ErrRejected = errors.New("membership change rejected")
+)
+
+// Error records the operation and object a failure belongs to.
+// Decryption failures wrap envelope.ErrNoKey or envelope.ErrAuth.
+type Error struct {
+	Op  string // "put", "get", "stat", …
+	ID  string // object ID, if any
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.ID == "" {
+		return e.Op + ": " + e.Err.Error()
+	}
+	return fmt.Sprintf("%s %q: %v", e.Op, e.ID, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+func wrap(op, id string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return &Error{Op: op, ID: id, Err: err}
+}
+
+// Config is what a Client needs to know about the cluster and about how
+// to write new objects. Readers take the erasure profile and layers of an
+// object from its FPCC.
+type Config struct {
+	Peers         []string                     // seed list; the live view is fetched from them
+	Labels        map[string]map[string]string // topology per peer, for the fallback view
+	FailureDomain string
+
+	Codec       string // erasure codec for new objects; default rs
+	Data, Total int    // erasure profile for new objects
+
+	Encrypt     bool     // seal new objects by default
+	KeyFiles    []string // first one seals, all of them open
+	Passphrase  string   // alternative or additional KEK
+	Compression string   // codec for new objects; "" = off
+	Dedup       bool     // store new objects as manifests of shared chunks
+
+	DialTimeout time.Duration // per server; default 10s
+	TLS         *tls.Config   // nil = plaintext; see config.TLSConfig
+	// Logf, if set, receives progress messages: shards dispersed,
+	// degraded reads, chunks reused.
+	Logf func(format string, args ...any)
+}
+
+// FromConfig fills a Config from a loaded YAML config; the passphrase is
+// read from the environment variable the config names.
+func FromConfig(cfg *config.Config) (Config, error) {
+	c := Config{
+		Peers:         append([]string{}, cfg.Cluster.Peers...),
+		Labels:        cfg.Labels(),
+		FailureDomain: cfg.Placement.FailureDomain,
+		Codec:         cfg.Erasure.Codec,
+		Data:          cfg.Erasure.Data,
+		Total:         cfg.Erasure.Total,
+		Encrypt:       cfg.Encryption.Encrypt,
+		KeyFiles:      cfg.Encryption.KeyFiles,
+		Compression:   cfg.Compression.Codec,
+		Dedup:         cfg.Dedup.Enabled,
+	}
+	tlsConf, err := cfg.TLSConfig()
+	if err != nil {
+		return c, fmt.Errorf("tls: %w", err)
+	}
+	c.TLS = tlsConf
+	if env := cfg.Encryption.PassphraseEnv; env != "" {
+		if c.Passphrase = os.Getenv(env); c.Passphrase == "" {
+			return c, fmt.Errorf("passphrase variable %s is empty", env)
+		}
+	}
+	return c, nil
+}
+
+// Client talks to one cluster.
+type Client struct {
+	cfg  Config
+	keys *envelope.Keyring
+	pool *connPool
+
+	mu   sync.Mutex
+	view *protocol.View // last view a server reported
+}
+
+// New returns a Client for cfg. It does not contact the cluster yet.
+func New(cfg Config) (*Client, error) {
+	if len(cfg.Peers) == 0 {
+		return nil, &Error{Op: "new", Err: fmt.Errorf("%w: no peers", ErrInvalid)}
+	}
+	if cfg.Codec == "" {
+		cfg.Codec = erasure.RS
+	}
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = 10 * time.Second
+	}
+	keys := envelope.NewKeyring()
+	for _, path := range cfg.KeyFiles {
+		if path == "" {
+			continue
+		}
+		if _, err := keys.AddFile(path); err != nil {
+			return nil, &Error{Op: "new", Err: fmt.Errorf("key file: %w", err)}
+		}
+	}
+	if cfg.Passphrase != "" {
+		keys.SetPassphrase(cfg.Passphrase)
+	}
+	fallback := membership.Initial(cfg.Peers, cfg.Labels)
+	fallback.FailureDomain = cfg.FailureDomain
+	return &Client{cfg: cfg, keys: keys, pool: newConnPool(cfg.DialTimeout, cfg.TLS), view: fallback}, nil
+}
+
+// Close drops the client's connections.
+func (c *Client) Close() error {
+	c.pool.close()
+	return nil
+}
+
+func (c *Client) logf(format string, args ...any) {
+	if c.cfg.Logf != nil {
+		c.cfg.Logf(format, args...)
+	}
+}
+
+// liveView asks the servers for the committed membership view, keeping
+// the last one known – at first the configured peer list – when nobody
+// answers.
+func (c *Client) liveView(ctx context.Context) *protocol.View {
+	c.mu.Lock()
+	known := c.view
+	c.mu.Unlock()
+	addrs := membership.Addrs(known)
+	for _, p := range c.cfg.Peers {
+		if !slices.Contains(addrs, p) {
+			addrs = append(addrs, p)
+		}
+	}
+	for _, addr := range addrs {
+		vctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		var v *protocol.View
+		cc, err := c.pool.conn(vctx, addr)
+		if err == nil {
+			v, err = protocol.NewMembershipClient(cc).GetView(vctx, &protocol.GetViewRequest{})
+		}
+		cancel()
+		if err == nil && len(v.Members) > 0 {
+			c.mu.Lock()
+			if v.Epoch >= c.view.Epoch {
+				c.view = v
+			}
+			known = c.view
+			c.mu.Unlock()
+			return known
+		}
+	}
+	return known
+}
+
+// reachable counts the servers that accept a TCP connection.
+func (c *Client) reachable(ctx context.Context, peers []string) int {
+	var d net.Dialer
+	cnt := 0
+	for _, p := range peers {
+		dctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		if conn, err := d.DialContext(dctx, "tcp", strings.TrimSpace(p)); err == nil {
+			cnt++
+			conn.Close()
+		}
+		cancel()
+	}
+	return cnt
+}
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	ID          string
+	Size        int64  // content length
+	Stored      int64  // bytes erasure coded, after compression and encryption
+	Codec       string // erasure codec
+	Data, Total int
+	Generation  uint64    // the version number, also bumped by a transcode
+	Created     time.Time // when this version was written, or for older ones when the answering server first saw it
+	Superseded  time.Time // when a newer version replaced it; zero for the current one
+	Compression string    // "" when stored uncompressed
+	KeyID       string    // KEK of an encrypted object
+	Chunks      int       // chunks of a deduplicated object
+	Digest      []byte    // FPCC.Digest(): changes whenever the object does
+	Metadata    map[string]string
+	Tags        map[string]string
+	Expires     time.Time // set on the version; zero = the servers' TTL and lifecycle rules
+	Retain      bool      // kept until deleted
+	FPCC        *protocol.FPCC
+}
+
+// VersionID names the version info describes, for StatVersion and
+// GetVersion: its digest in hex.
+func (info *ObjectInfo) VersionID() string {
+	return hex.EncodeToString(info.Digest)
+}
+
+func objectInfo(id string, fpcc *protocol.FPCC, created int64) *ObjectInfo {
+	info := &ObjectInfo{
+		ID:          id,
+		Size:        int64(fpcc.ContentSize()),
+		Stored:      int64(fpcc.GetSize()),
+		Codec:       erasure.RS,
+		Total:       len(fpcc.GetHashes()),
+		Generation:  fpcc.GetGeneration(),
+		Compression: fpcc.GetCompression().GetCodec(),
+		KeyID:       fpcc.GetEnvelope().GetKeyId(),
+		Chunks:      len(fpcc.GetManifest().GetChunks()),
+		Digest:      fpcc.Digest(),
+		Metadata:    fpcc.GetMetadata(),
+		Tags:        fpcc.GetTags(),
+		Retain:      fpcc.GetRetain(),
+		FPCC:        fpcc,
+	}
+	if e := fpcc.GetExpiresUnix(); e != 0 {
+		info.Expires = time.Unix(e, 0)
+	}
+	if p := fpcc.GetProfile(); p != nil {
+		info.Codec, info.Data, info.Total = p.Codec, int(p.Data), int(p.Total)
+	}
+	if c := fpcc.GetCreatedUnix(); c != 0 {
+		created = c
+	}
+	if created != 0 {
+		info.Created = time.Unix(created, 0)
+	}
+	return info
+}
+
+// Stat returns the committed FPCC of an object's current version and what
+// it says about the object.
+func (c *Client) Stat(ctx context.Context, id string) (*ObjectInfo, error) {
+	st, err := c.stat(ctx, membership.Addrs(c.liveView(ctx)), id, nil)
+	if err != nil {
+		return nil, wrap("stat", id, err)
+	}
+	return objectInfo(id, st.Fpcc, st.CreatedUnix), nil
+}
+
+// statTimeout bounds how long stat waits for one server.
+const statTimeout = 2 * time.Second
+
+// stat asks the peers for an object's committed FPCC. The current version
+// is the newest any of them knows, since one that missed a commit still
+// serves the version before; a chunk never changes, and a version named
+// by its FPCC digest is the same everywhere, so for those the first
+// answer will do – once its digest is checked, as a server could answer
+// for a version with another.
+func (c *Client) stat(ctx context.Context, peers []string, id string, version []byte) (*protocol.StatResponse, error) {
+	first := version != nil || chunker.IsID(id)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	answers := make(chan *protocol.StatResponse, len(peers))
+	for _, addr := range peers {
+		go func() {
+			pctx, pcancel := context.WithTimeout(ctx, statTimeout)
+			defer pcancel()
+			var st *protocol.StatResponse
+			dc, err := c.pool.client(pctx, addr)
+			if err == nil {
+				st, err = dc.Stat(pctx, &protocol.StatRequest{ObjectId: id, Version: version})
+			}
+			if err != nil {
+				st = nil
+			}
+			answers <- st
+		}()
+	}
+	var best *protocol.StatResponse
+	asked := 0
+	for range peers {
+		st := <-answers
+		if st == nil {
+			continue
+		}
+		asked++
+		if !st.Ok || st.Fpcc == nil {
+			continue
+		}
+		if version != nil && !bytes.Equal(st.Fpcc.Digest(), version) {
+			c.logf("Stat of %s version %x: a server answered with version %x", id, version, st.Fpcc.Digest())
+			continue
+		}
+		if first {
+			return st, nil
+		}
+		if best == nil || st.Fpcc.GetGeneration() > best.Fpcc.GetGeneration() {
+			best = st
+		}
+	}
+	switch {
+	case best != nil:
+		return best, nil
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case asked == 0:
+		return nil, ErrUnavailable
+	}
+	return nil, ErrNotFound
+}
+
+// ListOptions selects a page of List.
+type ListOptions struct {
+	Prefix     string
+	StartAfter string            // an ID, typically the last one of the previous page
+	Limit      int               // 0 = up to 1000
+	Tags       map[string]string // only objects carrying every one of these tags
+}
+
+// List returns, in ID order, the objects whose IDs start with
+// opts.Prefix, and the StartAfter of the next page – "" after the last.
+// Every server is asked, since each knows only the objects placed on it.
+// Listed objects carry their ID, Size, Generation, Created, Digest,
+// Metadata and Tags; Stat has the rest.
+func (c *Client) List(ctx context.Context, opts ListOptions) ([]*ObjectInfo, string, error) {
+	limit := opts.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		merged    = make(map[string]*protocol.ObjectEntry)
+		answered  int
+		truncated bool
+	)
+	for _, addr := range membership.Addrs(c.liveView(ctx)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dc, err := c.pool.client(ctx, addr)
+			if err != nil {
+				return
+			}
+			resp, err := dc.List(ctx, &protocol.ListRequest{Prefix: opts.Prefix, StartAfter: opts.StartAfter, Limit: uint32(limit), Tags: opts.Tags})
+			if err != nil || !resp.Ok {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			answered++
+			truncated = truncated || resp.Truncated
+			for _, e := range resp.Objects {
+				if cur := merged[e.ObjectId]; cur == nil || e.Generation > cur.Generation ||
+					(e.Generation == cur.Generation && e.CreatedUnix < cur.CreatedUnix) {
+					merged[e.ObjectId] = e
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, "", wrap("list", "", err)
+	}
+	if answered == 0 {
+		return nil, "", wrap("list", "", ErrUnavailable)
+	}
+	ids := make([]string, 0, len(merged))
+	for id := range merged {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	// each server sent its first limit IDs, so the first limit of the
+	// union are complete
+	if len(ids) > limit {
+		ids, truncated = ids[:limit], true
+	}
+	out := make([]*ObjectInfo, len(ids))
+	for i, id := range ids {
+		e := merged[id]
+		out[i] = &ObjectInfo{ID: id, Size: int64(e.Size), Generation: e.Generation, Digest: e.Digest, Metadata: e.Metadata, Tags: e.Tags}
+		if e.CreatedUnix != 0 {
+			out[i].Created = time.Unix(e.CreatedUnix, 0)
+		}
+	}
+	next := ""
+	if truncated && len(ids) > 0 {
+		next = ids[len(ids)-1]
+	}
+	return out, next, nil
+}
+
+// Delete removes an object from every server. A server that cannot be
+// reached keeps its copy, and Delete reports ErrUnavailable; calling it
+// again finishes the job. The chunks of a deduplicated object go once no
+// other object refers to them.
+func (c *Client) Delete(ctx context.Context, id string) error {
+	if chunker.IsID(id) {
+		return wrap("delete", id, fmt.Errorf("%w: chunks are deleted with the last object using them", ErrInvalid))
+	}
+	peers := membership.Addrs(c.liveView(ctx))
+	var (
+		mu            sync.Mutex
+		wg            sync.WaitGroup
+		found, failed int
+	)
+	for _, addr := range peers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var resp *protocol.DeleteResponse
+			dc, err := c.pool.client(ctx, addr)
+			if err == nil {
+				resp, err = dc.Delete(ctx, &protocol.DeleteRequest{ObjectId: id})
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil || !resp.Ok:
+				failed++
+			case resp.Found:
+				found++
+			}
+		}()
+	}
+	wg.Wait()
+	switch {
+	case ctx.Err() != nil:
+		return wrap("delete", id, ctx.Err())
+	case failed > 0:
+		return wrap("delete", id, fmt.Errorf("%w: %d of %d servers did not answer", ErrUnavailable, failed, len(peers)))
+	case found == 0:
+		return wrap("delete", id, ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,792 @@
+// pkg/client/client_test.go
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/blockhash"
+	"github.com/dattu/distributed_object_store/pkg/chunker"
+	"github.com/dattu/distributed_object_store/pkg/envelope"
+	"github.com/dattu/distributed_object_store/pkg/erasure"
+	"github.com/dattu/distributed_object_store/pkg/membership"
+	"github.com/dattu/distributed_object_store/pkg/merkle"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+	"google.golang.org/grpc"
+)
+
+// fakeNode stores fragments in memory and commits an object as soon as it
+// holds a fragment of it: enough to exercise the client without quorums.
+type fakeNode struct {
+	protocol.UnimplementedDispersalServer
+	protocol.UnimplementedMembershipServer
+	view *protocol.View
+
+	mu    sync.Mutex
+	frags map[string]map[uint32][]byte
+	fpccs map[string]*protocol.FPCC
+	old   map[string][]fakeVersion // superseded versions, oldest first
+	coord *Client                  // serves PutObject / GetObject
+
+	lengthSkew uint64 // added to the fragment length RetrieveStream reports
+	vouchAll   bool   // HasChunks claims every chunk
+	anyVersion bool   // Stat answers with the current version whatever is asked
+}
+
+type fakeVersion struct {
+	fpcc  *protocol.FPCC
+	frags map[uint32][]byte
+}
+
+// version returns the generation gen of id, current or kept.
+func (n *fakeNode) version(id string, gen uint64) (*protocol.FPCC, map[uint32][]byte) {
+	if f := n.fpccs[id]; f != nil && f.Generation == gen {
+		return f, n.frags[id]
+	}
+	for _, v := range n.old[id] {
+		if v.fpcc.Generation == gen {
+			return v.fpcc, v.frags
+		}
+	}
+	return nil, nil
+}
+
+func (n *fakeNode) DisperseStream(stream protocol.Dispersal_DisperseStreamServer) error {
+	var head *protocol.DisperseChunk
+	var data []byte
+	for {
+		c, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if head == nil {
+			head = c
+		}
+		data = append(data, c.Data...)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	cur := n.fpccs[head.ObjectId]
+	if cur != nil && !bytes.Equal(cur.Digest(), head.Fpcc.Digest()) && cur.Generation == head.Fpcc.Generation {
+		return stream.SendAndClose(&protocol.DisperseResponse{Error: "FPCC mismatch"})
+	}
+	if f := head.Fpcc; (f.IfNoneMatch && cur != nil && !bytes.Equal(cur.Digest(), f.Digest())) ||
+		(f.IfMatchVersion != nil && (cur == nil || cur.Generation == f.Generation-1 && !bytes.Equal(cur.Digest(), f.IfMatchVersion))) {
+		return stream.SendAndClose(&protocol.DisperseResponse{Error: "precondition failed"})
+	}
+	if cur := n.fpccs[head.ObjectId]; cur == nil || cur.Generation != head.Fpcc.Generation {
+		if cur != nil && !head.Fpcc.Transcoded {
+			n.old[head.ObjectId] = append(n.old[head.ObjectId], fakeVersion{cur, n.frags[head.ObjectId]})
+		}
+		n.frags[head.ObjectId] = make(map[uint32][]byte)
+	}
+	n.frags[head.ObjectId][head.FragmentIndex] = data
+	n.fpccs[head.ObjectId] = head.Fpcc
+	return stream.SendAndClose(&protocol.DisperseResponse{Ok: true})
+}
+
+func (n *fakeNode) RetrieveStream(req *protocol.RetrieveRequest, stream protocol.Dispersal_RetrieveStreamServer) error {
+	n.mu.Lock()
+	fpcc, frags := n.version(req.ObjectId, req.Generation)
+	frag, ok := frags[req.FragmentIndex]
+	n.mu.Unlock()
+	if !ok {
+		return stream.Send(&protocol.RetrieveChunk{Error: "fragment missing"})
+	}
+	lo, hi := req.Offset, uint64(len(frag))
+	if req.Length != 0 {
+		hi = min(lo+req.Length, hi)
+	}
+	msg := &protocol.RetrieveChunk{Ok: true, Fpcc: fpcc, Length: uint64(len(frag)) + n.lengthSkew, Data: frag[lo:hi]}
+	if req.Proofs {
+		stripe := uint64(fpcc.Profile.Stripe)
+		var leaves [][]byte
+		for off := uint64(0); off < uint64(len(frag)); off += stripe {
+			leaves = append(leaves, blockhash.Sum(frag[off:off+stripe]))
+		}
+		for b := lo / stripe; b*stripe < hi; b++ {
+			msg.Proofs = append(msg.Proofs, &protocol.BlockProof{Block: b, Path: merkle.Proof(leaves, int(b))})
+		}
+	}
+	return stream.Send(msg)
+}
+
+func (n *fakeNode) Stat(_ context.Context, req *protocol.StatRequest) (*protocol.StatResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f := n.fpccs[req.ObjectId]
+	if f != nil && req.Version != nil && !bytes.Equal(f.Digest(), req.Version) && !n.anyVersion {
+		f = nil
+		for _, v := range n.old[req.ObjectId] {
+			if bytes.Equal(v.fpcc.Digest(), req.Version) {
+				f = v.fpcc
+			}
+		}
+		if f == nil {
+			return &protocol.StatResponse{Error: "version not found"}, nil
+		}
+	}
+	if f != nil {
+		return &protocol.StatResponse{Ok: true, Fpcc: f, CreatedUnix: 1700000000}, nil
+	}
+	return &protocol.StatResponse{Error: "object not found"}, nil
+}
+
+func (n *fakeNode) Versions(_ context.Context, req *protocol.VersionsRequest) (*protocol.VersionsResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f := n.fpccs[req.ObjectId]
+	if f == nil {
+		return &protocol.VersionsResponse{Error: "object not found"}, nil
+	}
+	resp := &protocol.VersionsResponse{Ok: true, Versions: []*protocol.VersionEntry{{Fpcc: f, CreatedUnix: 1700000000}}}
+	for _, v := range slices.Backward(n.old[req.ObjectId]) {
+		resp.Versions = append(resp.Versions, &protocol.VersionEntry{Fpcc: v.fpcc, CreatedUnix: 1700000000, SupersededUnix: 1700000001})
+	}
+	return resp, nil
+}
+
+func (n *fakeNode) HasChunks(_ context.Context, req *protocol.HasChunksRequest) (*protocol.HasChunksResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	held := make([]bool, len(req.Hashes))
+	for i, h := range req.Hashes {
+		held[i] = n.vouchAll || n.fpccs[chunker.ID(h)] != nil
+	}
+	return &protocol.HasChunksResponse{Ok: true, Held: held}, nil
+}
+
+func (n *fakeNode) Delete(_ context.Context, req *protocol.DeleteRequest) (*protocol.DeleteResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, found := n.fpccs[req.ObjectId]
+	delete(n.fpccs, req.ObjectId)
+	delete(n.frags, req.ObjectId)
+	delete(n.old, req.ObjectId)
+	return &protocol.DeleteResponse{Ok: true, Found: found}, nil
+}
+
+func (n *fakeNode) List(_ context.Context, req *protocol.ListRequest) (*protocol.ListResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	resp := &protocol.ListResponse{Ok: true}
+	for id, f := range n.fpccs {
+		tagged := true
+		for k, v := range req.Tags {
+			tagged = tagged && f.Tags[k] == v
+		}
+		if !chunker.IsID(id) && strings.HasPrefix(id, req.Prefix) && id > req.StartAfter && tagged {
+			resp.Objects = append(resp.Objects, &protocol.ObjectEntry{ObjectId: id, Size: f.ContentSize(), Generation: f.Generation, Digest: f.Digest(),
+				Metadata: f.Metadata, Tags: f.Tags})
+		}
+	}
+	slices.SortFunc(resp.Objects, func(a, b *protocol.ObjectEntry) int { return strings.Compare(a.ObjectId, b.ObjectId) })
+	if len(resp.Objects) > int(req.Limit) {
+		resp.Objects, resp.Truncated = resp.Objects[:req.Limit], true
+	}
+	return resp, nil
+}
+
+func (n *fakeNode) PutObject(stream protocol.Dispersal_PutObjectServer) error {
+	n.mu.Lock()
+	c := n.coord
+	n.mu.Unlock()
+	return c.ServePutObject(stream)
+}
+
+func (n *fakeNode) GetObject(req *protocol.GetObjectRequest, stream protocol.Dispersal_GetObjectServer) error {
+	n.mu.Lock()
+	c := n.coord
+	n.mu.Unlock()
+	return c.ServeGetObject(req, stream)
+}
+
+func (n *fakeNode) GetView(context.Context, *protocol.GetViewRequest) (*protocol.View, error) {
+	return n.view, nil
+}
+
+// cluster starts k fake nodes and returns a Client for them, 2-of-4 by
+// default.
+func cluster(t *testing.T, k int, cfg Config) (*Client, []*fakeNode) {
+	t.Helper()
+	var lis []net.Listener
+	for range k {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		lis = append(lis, l)
+		cfg.Peers = append(cfg.Peers, l.Addr().String())
+	}
+	view := membership.Initial(cfg.Peers, nil)
+	var nodes []*fakeNode
+	for _, l := range lis {
+		n := &fakeNode{view: view, frags: make(map[string]map[uint32][]byte), fpccs: make(map[string]*protocol.FPCC), old: make(map[string][]fakeVersion)}
+		srv := grpc.NewServer()
+		protocol.RegisterDispersalServer(srv, n)
+		protocol.RegisterMembershipServer(srv, n)
+		go srv.Serve(l)
+		t.Cleanup(srv.Stop)
+		nodes = append(nodes, n)
+	}
+	if cfg.Data == 0 {
+		cfg.Data, cfg.Total = 2, 4
+	}
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c, nodes
+}
+
+func randomBytes(seed int64, n int) []byte {
+	b := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(b)
+	return b
+}
+
+func TestPutGetRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	c, _ := cluster(t, 4, Config{Passphrase: "correct horse"})
+	text := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog\n"), 50000)
+	cases := []struct {
+		name string
+		data []byte
+		opts *PutOptions
+	}{
+		{"plain", randomBytes(1, 3<<20+17), nil},
+		{"empty", nil, nil},
+		{"compressed", text, &PutOptions{Compression: "flate"}},
+		{"encrypted", randomBytes(8, 1<<20+5), &PutOptions{Encrypt: true}},
+		{"compressed+encrypted", text, &PutOptions{Compression: "flate", Encrypt: true}},
+		{"dedup", randomBytes(2, 5<<20), &PutOptions{Dedup: true, Compression: "flate"}},
+	}
+	for _, tc := range cases {
+		info, err := c.Put(ctx, tc.name, bytes.NewReader(tc.data), tc.opts)
+		if err != nil {
+			t.Fatalf("Put %s: %v", tc.name, err)
+		}
+		if info.Size != int64(len(tc.data)) {
+			t.Errorf("Put %s: size %d, want %d", tc.name, info.Size, len(tc.data))
+		}
+		var out bytes.Buffer
+		if _, err := c.Get(ctx, tc.name, &out); err != nil {
+			t.Fatalf("Get %s: %v", tc.name, err)
+		}
+		if !bytes.Equal(out.Bytes(), tc.data) {
+			t.Errorf("Get %s: %d bytes differ from the %d put", tc.name, out.Len(), len(tc.data))
+		}
+		if len(tc.data) < 100 {
+			continue
+		}
+		out.Reset()
+		off, length := int64(len(tc.data)/3), int64(len(tc.data)/2)
+		if _, err := c.GetRange(ctx, tc.name, &out, off, length); err != nil {
+			t.Fatalf("GetRange %s: %v", tc.name, err)
+		}
+		if !bytes.Equal(out.Bytes(), tc.data[off:off+length]) {
+			t.Errorf("GetRange %s: wrong bytes", tc.name)
+		}
+	}
+	if info, _ := c.Stat(ctx, "compressed"); info.Compression != "flate" || info.Stored >= info.Size {
+		t.Errorf("compressed object stored as %+v", info)
+	}
+	if info, _ := c.Stat(ctx, "dedup"); info.Chunks < 2 {
+		t.Errorf("dedup object has %d chunks", info.Chunks)
+	}
+	keyless, err := New(Config{Peers: c.cfg.Peers})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer keyless.Close()
+	if _, err := keyless.Get(ctx, "encrypted", io.Discard); !errors.Is(err, envelope.ErrNoKey) {
+		t.Errorf("Get without the key: %v", err)
+	}
+}
+
+func TestBlockReaderChecksLength(t *testing.T) {
+	ctx := context.Background()
+	c, nodes := cluster(t, 4, Config{})
+	if _, err := c.Put(ctx, "blocks", bytes.NewReader(randomBytes(3, 1<<20)), nil); err != nil {
+		t.Fatal(err)
+	}
+	n := nodes[0]
+	n.mu.Lock()
+	fpcc := n.fpccs["blocks"]
+	var idx uint32
+	for i := range n.frags["blocks"] {
+		idx = i
+	}
+	n.mu.Unlock()
+	enc, err := erasure.Lookup(fpcc.Profile.Codec, int(fpcc.Profile.Data), int(fpcc.Profile.Total))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripe := int(fpcc.Profile.Stripe)
+	br := &blockReader{ctx: ctx, enc: enc, stripe: stripe, id: "blocks", fpcc: fpcc, pool: c.pool,
+		candidates: func(int) []string { return c.cfg.Peers[:1] },
+		length:     uint64(erasure.FragmentSize(enc, stripe, int64(fpcc.Size)))}
+	if br.block(int(idx), 0) == nil {
+		t.Fatal("honest block rejected")
+	}
+	// one byte more leaves the leaf count as it was, so only the FPCC
+	// shows the server lied
+	n.mu.Lock()
+	n.lengthSkew = 1
+	n.mu.Unlock()
+	if br.block(int(idx), 0) != nil {
+		t.Fatal("block accepted from a server misreporting the fragment length")
+	}
+}
+
+func TestPutFromFileAndIntoFile(t *testing.T) {
+	ctx := context.Background()
+	c, _ := cluster(t, 4, Config{})
+	data := randomBytes(3, 1<<20)
+	path := filepath.Join(t.TempDir(), "in")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	in, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	in.Seek(1000, io.SeekStart) // Put reads from the current offset
+	if _, err := c.Put(ctx, "file", in, nil); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	if _, err := c.Get(ctx, "file", out); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(out.Name())
+	if !bytes.Equal(got, data[1000:]) {
+		t.Errorf("got %d bytes back, want %d", len(got), len(data)-1000)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+	c, _ := cluster(t, 4, Config{})
+	var e *Error
+	if _, err := c.Get(ctx, "missing", io.Discard); !errors.Is(err, ErrNotFound) || !errors.As(err, &e) || e.Op != "get" || e.ID != "missing" {
+		t.Errorf("Get missing: %v", err)
+	}
+	if _, err := c.Put(ctx, chunker.ID(chunker.Sum(nil)), bytes.NewReader(nil), nil); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Put chunk ID: %v", err)
+	}
+	if _, err := c.Put(ctx, "a/../../b", bytes.NewReader(nil), nil); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Put path ID: %v", err)
+	}
+	if _, err := c.Put(ctx, "x", bytes.NewReader(nil), &PutOptions{Dedup: true, Encrypt: true}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Put dedup+encrypt: %v", err)
+	}
+	if _, err := c.Put(ctx, "x", bytes.NewReader(nil), &PutOptions{Encrypt: true}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Put encrypted without keys: %v", err)
+	}
+	if _, err := c.Put(ctx, "taken", strings.NewReader("one"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetVersion(ctx, "taken", "zz", io.Discard, 0, 0); !errors.Is(err, ErrInvalid) {
+		t.Errorf("GetVersion with a bad version: %v", err)
+	}
+	if _, err := c.StatVersion(ctx, "taken", "00"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("StatVersion of an unknown version: %v", err)
+	}
+	if _, err := c.GetRange(ctx, "taken", io.Discard, 2, 5); !errors.Is(err, ErrInvalid) {
+		t.Errorf("GetRange past the end: %v", err)
+	}
+}
+
+func TestCorruptFragmentsAreSkipped(t *testing.T) {
+	ctx := context.Background()
+	c, nodes := cluster(t, 4, Config{})
+	data := randomBytes(4, 100_000)
+	if _, err := c.Put(ctx, "obj", bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	flip := func(idxs ...uint32) {
+		for _, n := range nodes {
+			n.mu.Lock()
+			for _, idx := range idxs {
+				if frag := n.frags["obj"][idx]; len(frag) > 0 {
+					frag[0] ^= 0xff
+				}
+			}
+			n.mu.Unlock()
+		}
+	}
+	flip(0, 2) // 2-of-4: two bad fragments still leave enough
+	var out bytes.Buffer
+	if _, err := c.Get(ctx, "obj", &out); err != nil || !bytes.Equal(out.Bytes(), data) {
+		t.Fatalf("Get with 2 bad fragments: %v", err)
+	}
+	flip(1, 3)
+	if _, err := c.Get(ctx, "obj", io.Discard); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Get with no good fragments: %v", err)
+	}
+}
+
+func TestListAndDelete(t *testing.T) {
+	ctx := context.Background()
+	c, _ := cluster(t, 4, Config{})
+	for _, id := range []string{"logs:a", "logs:b", "logs:c", "other"} {
+		if _, err := c.Put(ctx, id, strings.NewReader(id), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.Put(ctx, "deduped", bytes.NewReader(randomBytes(5, 1<<20)), &PutOptions{Dedup: true}); err != nil {
+		t.Fatal(err)
+	}
+	objs, next, err := c.List(ctx, ListOptions{Prefix: "logs:", Limit: 2})
+	if err != nil || len(objs) != 2 || objs[0].ID != "logs:a" || objs[1].Size != 6 || next != "logs:b" {
+		t.Fatalf("first page: %v %q %v", objs, next, err)
+	}
+	objs, next, err = c.List(ctx, ListOptions{Prefix: "logs:", StartAfter: next, Limit: 2})
+	if err != nil || len(objs) != 1 || objs[0].ID != "logs:c" || next != "" {
+		t.Fatalf("second page: %v %q %v", objs, next, err)
+	}
+	if objs, _, _ := c.List(ctx, ListOptions{}); len(objs) != 5 { // chunks are not listed
+		t.Errorf("listed %d objects, want 5", len(objs))
+	}
+
+	if err := c.Delete(ctx, "logs:b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Stat(ctx, "logs:b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat after Delete: %v", err)
+	}
+	if err := c.Delete(ctx, "logs:b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete: %v", err)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	ctx := context.Background()
+	c, _ := cluster(t, 4, Config{})
+	one, err := c.Put(ctx, "doc", strings.NewReader("first draft"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	two, err := c.Put(ctx, "doc", bytes.NewReader(randomBytes(10, 300_000)), &PutOptions{Dedup: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if two.Generation != one.Generation+1 || two.VersionID() == one.VersionID() {
+		t.Fatalf("second Put: generation %d after %d", two.Generation, one.Generation)
+	}
+	var out bytes.Buffer
+	if _, err := c.Get(ctx, "doc", &out); err != nil || out.Len() != 300_000 {
+		t.Fatalf("Get returned %d bytes: %v", out.Len(), err)
+	}
+	out.Reset()
+	if _, err := c.GetVersion(ctx, "doc", one.VersionID(), &out, 6, 5); err != nil || out.String() != "draft" {
+		t.Errorf("GetVersion of the first version: %q, %v", out.String(), err)
+	}
+	if info, err := c.StatVersion(ctx, "doc", one.VersionID()); err != nil || info.Generation != one.Generation {
+		t.Errorf("StatVersion of the first version: %+v, %v", info, err)
+	}
+	vs, err := c.Versions(ctx, "doc")
+	if err != nil || len(vs) != 2 || vs[0].VersionID() != two.VersionID() || vs[1].Superseded.IsZero() {
+		t.Fatalf("Versions: %v, %v", vs, err)
+	}
+	if err := c.Delete(ctx, "doc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Versions(ctx, "doc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Versions after Delete: %v", err)
+	}
+}
+
+func TestConditionalPut(t *testing.T) {
+	ctx := context.Background()
+	c, _ := cluster(t, 4, Config{})
+	one, err := c.Put(ctx, "lock", strings.NewReader("held by a"), &PutOptions{IfNoneMatch: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Put(ctx, "lock", strings.NewReader("held by b"), &PutOptions{IfNoneMatch: true}); !errors.Is(err, ErrPrecondition) {
+		t.Errorf("IfNoneMatch on an existing object: %v", err)
+	}
+	two, err := c.Put(ctx, "lock", strings.NewReader("released"), &PutOptions{IfMatchVersion: one.VersionID()})
+	if err != nil || two.Generation != one.Generation+1 {
+		t.Fatalf("IfMatchVersion of the current version: %+v, %v", two, err)
+	}
+	if _, err := c.Put(ctx, "lock", strings.NewReader("stale"), &PutOptions{IfMatchVersion: one.VersionID()}); !errors.Is(err, ErrPrecondition) {
+		t.Errorf("IfMatchVersion of a superseded version: %v", err)
+	}
+	if _, err := c.Put(ctx, "absent", strings.NewReader("x"), &PutOptions{IfMatchVersion: one.VersionID()}); !errors.Is(err, ErrPrecondition) {
+		t.Errorf("IfMatchVersion of a missing object: %v", err)
+	}
+	if _, err := c.Put(ctx, "lock", strings.NewReader("x"), &PutOptions{IfNoneMatch: true, IfMatchVersion: two.VersionID()}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("both preconditions: %v", err)
+	}
+
+	// a write whose precondition stopped holding after the client checked
+	// it: the servers refuse it
+	enc, _ := c.codec(c.PutOptions())
+	view := c.liveView(ctx)
+	content := io.NewSectionReader(strings.NewReader("late"), 0, 4)
+	_, err = c.disperseLayers(ctx, view, content, "lock", enc, "", false, two.Generation+1, func(f *protocol.FPCC) { f.IfMatchVersion = one.Digest })
+	if err = shardError(err); !errors.Is(err, ErrPrecondition) {
+		t.Errorf("conditional write the servers refuse: %v", err)
+	}
+	var out bytes.Buffer
+	if _, err := c.Get(ctx, "lock", &out); err != nil || out.String() != "released" {
+		t.Errorf("Get after the refused writes: %q, %v", out.String(), err)
+	}
+}
+
+func TestMetadataAndTags(t *testing.T) {
+	ctx := context.Background()
+	c, _ := cluster(t, 4, Config{})
+	meta := map[string]string{"content-type": "text/plain", "owner": "ci"}
+	info, err := c.Put(ctx, "report", strings.NewReader("hello"), &PutOptions{Metadata: meta, Tags: map[string]string{"env": "prod"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Put(ctx, "draft", bytes.NewReader(randomBytes(6, 1<<20)), &PutOptions{Dedup: true, Tags: map[string]string{"env": "dev"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Put(ctx, "plain", strings.NewReader("x"), nil); err != nil {
+		t.Fatal(err)
+	}
+	st, err := c.Stat(ctx, "report")
+	if err != nil || !maps.Equal(st.Metadata, meta) || st.Tags["env"] != "prod" || !bytes.Equal(st.Digest, info.Digest) {
+		t.Fatalf("Stat: %+v, %v", st, err)
+	}
+	objs, _, err := c.List(ctx, ListOptions{Tags: map[string]string{"env": "prod"}})
+	if err != nil || len(objs) != 1 || objs[0].ID != "report" || objs[0].Metadata["owner"] != "ci" {
+		t.Errorf("List by tag: %v, %v", objs, err)
+	}
+	if objs, _, _ := c.List(ctx, ListOptions{Tags: map[string]string{"env": "dev"}}); len(objs) != 1 || objs[0].ID != "draft" {
+		t.Errorf("List of a deduplicated object by tag: %v", objs)
+	}
+	if tc, err := c.Transcode(ctx, "report", "", 3, 4); err != nil || !maps.Equal(tc.Metadata, meta) || tc.Tags["env"] != "prod" {
+		t.Errorf("Transcode dropped metadata: %+v, %v", tc, err)
+	}
+	if _, err := c.Put(ctx, "bad", strings.NewReader("x"), &PutOptions{Tags: map[string]string{"a=b": "c"}}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Put with a bad tag: %v", err)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	ctx := context.Background()
+	c, _ := cluster(t, 4, Config{})
+	before := time.Now()
+	info, err := c.Put(ctx, "hourly", strings.NewReader("x"), &PutOptions{TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := info.Expires; e.Before(before.Add(time.Hour).Truncate(time.Second)) || e.After(time.Now().Add(time.Hour)) {
+		t.Errorf("Put with a TTL expires %v", e)
+	}
+	if st, err := c.Stat(ctx, "hourly"); err != nil || !st.Expires.Equal(info.Expires) {
+		t.Errorf("Stat: %+v, %v", st, err)
+	}
+	if info.Created.Before(before.Truncate(time.Second)) || info.Created.After(time.Now()) {
+		t.Errorf("Put stamped creation time %v", info.Created)
+	}
+	if tc, err := c.Transcode(ctx, "hourly", "", 3, 4); err != nil || !tc.Expires.Equal(info.Expires) || !tc.Created.Equal(info.Created) {
+		t.Errorf("Transcode dropped the expiry or creation time: %+v, %v", tc, err)
+	}
+	if st, err := c.Put(ctx, "forever", strings.NewReader("x"), &PutOptions{Retain: true}); err != nil || !st.Retain || !st.Expires.IsZero() {
+		t.Errorf("Put with Retain: %+v, %v", st, err)
+	}
+	for name, opts := range map[string]PutOptions{
+		"TTL and Retain":  {TTL: time.Hour, Retain: true},
+		"past expiry":     {Expires: time.Now().Add(-time.Minute)},
+		"negative TTL":    {TTL: -time.Second},
+		"TTL and Expires": {TTL: time.Hour, Expires: time.Now().Add(time.Hour)},
+	} {
+		if _, err := c.Put(ctx, "bad", strings.NewReader("x"), &opts); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Put with %s: %v", name, err)
+		}
+	}
+}
+
+func TestCoordinator(t *testing.T) {
+	ctx := context.Background()
+	c, nodes := cluster(t, 4, Config{Compression: "flate"})
+	nodes[1].mu.Lock()
+	nodes[1].coord = c
+	nodes[1].mu.Unlock()
+	co := NewCoordinator(c.cfg.Peers[1], 0, nil)
+	defer co.Close()
+
+	data := randomBytes(9, 2<<20+3)
+	info, err := co.Put(ctx, "thin", bytes.NewReader(data), &PutOptions{Data: 3, Total: 4, Compression: "none"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size != int64(len(data)) || info.Data != 3 || info.Total != 4 || info.Compression != "" {
+		t.Errorf("Put through the coordinator: %+v", info)
+	}
+	var out bytes.Buffer
+	if _, err := c.Get(ctx, "thin", &out); err != nil || !bytes.Equal(out.Bytes(), data) {
+		t.Fatalf("Get of a coordinated Put: %v", err)
+	}
+	out.Reset()
+	got, err := co.Get(ctx, "thin", &out)
+	if err != nil || !bytes.Equal(out.Bytes(), data) {
+		t.Fatalf("Get through the coordinator: %v", err)
+	}
+	if !bytes.Equal(got.Digest, info.Digest) {
+		t.Errorf("Get digest %x, Put digest %x", got.Digest, info.Digest)
+	}
+	out.Reset()
+	if _, err := co.GetRange(ctx, "thin", &out, 1000, 300000); err != nil || !bytes.Equal(out.Bytes(), data[1000:301000]) {
+		t.Errorf("GetRange through the coordinator: %v", err)
+	}
+	if _, err := co.Put(ctx, "empty", bytes.NewReader(nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if info, err := co.Get(ctx, "empty", &out); err != nil || info.Size != 0 || out.Len() != 0 {
+		t.Errorf("Get of an empty object: %v, %+v", err, info)
+	}
+
+	if info, err := co.Put(ctx, "thin", strings.NewReader("other"), &PutOptions{Metadata: map[string]string{"content-type": "text/plain"}}); err != nil || info.Metadata["content-type"] != "text/plain" {
+		t.Fatalf("Put with metadata through the coordinator: %+v, %v", info, err)
+	}
+	out.Reset()
+	if got, err := co.GetVersion(ctx, "thin", info.VersionID(), &out, 0, 0); err != nil || !bytes.Equal(out.Bytes(), data) || got.Generation != info.Generation {
+		t.Errorf("Get of the first version through the coordinator: %v", err)
+	}
+	if info, err := co.Put(ctx, "kept", strings.NewReader("x"), &PutOptions{Retain: true}); err != nil || !info.Retain {
+		t.Errorf("Put with Retain through the coordinator: %+v, %v", info, err)
+	}
+	if _, err := co.Put(ctx, "thin", strings.NewReader("other"), &PutOptions{IfNoneMatch: true}); !errors.Is(err, ErrPrecondition) {
+		t.Errorf("IfNoneMatch Put through the coordinator: %v", err)
+	}
+	if _, err := co.Get(ctx, "missing", io.Discard); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get missing: %v", err)
+	}
+	if _, err := co.Put(ctx, "../x", strings.NewReader("x"), nil); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Put with a path through the coordinator: %v", err)
+	}
+	if _, err := co.Put(ctx, "", strings.NewReader("x"), nil); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Put with an empty ID: %v", err)
+	}
+	if _, err := co.GetRange(ctx, "thin", io.Discard, int64(len(data)), 1); !errors.Is(err, ErrInvalid) {
+		t.Errorf("GetRange past the end: %v", err)
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	c, _ := cluster(t, 4, Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Put(ctx, "late", bytes.NewReader(randomBytes(6, 1<<20)), nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Put with a cancelled context: %v", err)
+	}
+
+	if _, err := c.Put(context.Background(), "obj", bytes.NewReader(randomBytes(7, 4<<20)), nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	time.Sleep(2 * time.Millisecond)
+	if _, err := c.Get(ctx, "obj", io.Discard); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get past its deadline: %v", err)
+	}
+}
+
+func TestUnreachableCluster(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close() // nobody listens there any more
+	c, err := New(Config{Peers: []string{addr}, Data: 1, Total: 3, DialTimeout: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	if _, err := c.Put(ctx, "x", strings.NewReader("x"), nil); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Put: %v", err)
+	}
+	if _, err := c.Stat(ctx, "x"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Stat: %v", err)
+	}
+}
+
+func TestHasChunksNeedsVouchers(t *testing.T) {
+	ctx := context.Background()
+	c, nodes := cluster(t, 4, Config{})
+	nodes[0].vouchAll = true
+	data := randomBytes(11, 1<<20)
+
+	// one server claiming chunks nobody stored must not get them skipped
+	if _, err := c.Put(ctx, "a", bytes.NewReader(data), &PutOptions{Dedup: true}); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if _, err := c.Get(ctx, "a", &out); err != nil || !bytes.Equal(out.Bytes(), data) {
+		t.Fatalf("Get returned %d bytes: %v", out.Len(), err)
+	}
+
+	// chunks the honest servers hold are still reused
+	nodes[1].mu.Lock()
+	before := len(nodes[1].fpccs)
+	nodes[1].mu.Unlock()
+	if _, err := c.Put(ctx, "b", bytes.NewReader(data), &PutOptions{Dedup: true}); err != nil {
+		t.Fatal(err)
+	}
+	nodes[1].mu.Lock()
+	after := len(nodes[1].fpccs)
+	nodes[1].mu.Unlock()
+	if after != before+1 {
+		t.Errorf("second Put stored %d objects, want only its manifest", after-before)
+	}
+}
+
+func TestStatVersionChecksDigest(t *testing.T) {
+	ctx := context.Background()
+	c, nodes := cluster(t, 4, Config{})
+	one, err := c.Put(ctx, "doc", strings.NewReader("first"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Put(ctx, "doc", strings.NewReader("second"), nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range nodes[1:] {
+		n.mu.Lock()
+		n.anyVersion = true
+		n.mu.Unlock()
+	}
+	for range 5 {
+		info, err := c.StatVersion(ctx, "doc", one.VersionID())
+		if err != nil || info.VersionID() != one.VersionID() {
+			t.Fatalf("StatVersion answered %+v, %v", info, err)
+		}
+	}
+	var out bytes.Buffer
+	if _, err := c.GetVersion(ctx, "doc", one.VersionID(), &out, 0, 0); err != nil || out.String() != "first" {
+		t.Errorf("GetVersion returned %q, %v", out.String(), err)
+	}
+}
@@ -0,0 +1,357 @@
+// pkg/client/coordinator.go – coordinator mode: the PutObject / GetObject
+// RPCs. A Coordinator is a thin client that streams whole objects to one
+// node; the node serves them with ServePutObject / ServeGetObject, doing
+// the encoding, fingerprinting, dispersal and verified reads with a Client
+// of its own, so only the object itself crosses the thin client's link.
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/envelope"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Coordinator stores and fetches objects through one node. It needs no
+// view of the cluster, no keys and no codecs.
+type Coordinator struct {
+	addr string
+	pool *connPool
+}
+
+// NewCoordinator returns a Coordinator for the node at addr; dialTimeout
+// 0 means 10s, and a nil tlsConf plaintext. It does not contact the node
+// yet.
+func NewCoordinator(addr string, dialTimeout time.Duration, tlsConf *tls.Config) *Coordinator {
+	if dialTimeout == 0 {
+		dialTimeout = 10 * time.Second
+	}
+	return &Coordinator{addr: addr, pool: newConnPool(dialTimeout, tlsConf)}
+}
+
+// Close drops the connection to the node.
+func (co *Coordinator) Close() error {
+	co.pool.close()
+	return nil
+}
+
+// Put streams r to the node, which stores it as object id. Options left
+// zero (or nil options) take the node's config; Compression "none" turns
+// compression off.
+func (co *Coordinator) Put(ctx context.Context, id string, r io.Reader, opts *PutOptions) (*ObjectInfo, error) {
+	info, err := co.put(ctx, id, r, opts)
+	return info, wrap("put", id, err)
+}
+
+func (co *Coordinator) put(ctx context.Context, id string, r io.Reader, opts *PutOptions) (*ObjectInfo, error) {
+	cl, err := co.pool.client(ctx, co.addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // aborts the stream if r fails
+	stream, err := cl.PutObject(ctx)
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	msg := &protocol.PutObjectRequest{ObjectId: id}
+	if opts != nil {
+		msg.Options = &protocol.PutOptions{Codec: opts.Codec, Data: uint32(opts.Data), Total: uint32(opts.Total)}
+		if opts.Compression != "" {
+			msg.Options.Compression = &opts.Compression
+		}
+		if opts.Dedup {
+			msg.Options.Dedup = &opts.Dedup
+		}
+		if opts.Encrypt {
+			msg.Options.Encrypt = &opts.Encrypt
+		}
+		msg.Options.IfNoneMatch, msg.Options.IfMatchVersion = opts.IfNoneMatch, opts.IfMatchVersion
+		msg.Options.Metadata, msg.Options.Tags = opts.Metadata, opts.Tags
+		// the coordinator stamps an absolute expiry: a TTL runs from now
+		expires, err := opts.expiry()
+		if err != nil {
+			return nil, err
+		}
+		if !expires.IsZero() {
+			msg.Options.ExpiresUnix = expires.Unix()
+		}
+		msg.Options.Retain = opts.Retain
+	}
+	buf := make([]byte, streamChunk)
+	for {
+		k, rerr := io.ReadFull(r, buf)
+		if k > 0 || msg.ObjectId != "" {
+			msg.Data = buf[:k]
+			if err := stream.Send(msg); err != nil {
+				// the node ended the stream: its status says why
+				_, err = stream.CloseAndRecv()
+				return nil, fromStatus(err)
+			}
+			msg = &protocol.PutObjectRequest{}
+		}
+		if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+			break
+		}
+		if rerr != nil {
+			return nil, rerr
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	return objectInfo(id, resp.Fpcc, resp.CreatedUnix), nil
+}
+
+// Get writes the content of object id to w. The node only sends bytes it
+// has verified.
+func (co *Coordinator) Get(ctx context.Context, id string, w io.Writer) (*ObjectInfo, error) {
+	info, err := co.get(ctx, id, "", w, 0, 0)
+	return info, wrap("get", id, err)
+}
+
+// GetRange writes bytes [off, off+length) of object id to w; length 0
+// reads to the end.
+func (co *Coordinator) GetRange(ctx context.Context, id string, w io.Writer, off, length int64) (*ObjectInfo, error) {
+	info, err := co.get(ctx, id, "", w, off, length)
+	return info, wrap("get", id, err)
+}
+
+// GetVersion is Get, or GetRange when off or length is set, for the
+// version of id named version; "" is the current one.
+func (co *Coordinator) GetVersion(ctx context.Context, id, version string, w io.Writer, off, length int64) (*ObjectInfo, error) {
+	info, err := co.get(ctx, id, version, w, off, length)
+	return info, wrap("get", id, err)
+}
+
+func (co *Coordinator) get(ctx context.Context, id, version string, w io.Writer, off, length int64) (*ObjectInfo, error) {
+	if off < 0 || length < 0 {
+		return nil, fmt.Errorf("%w: range %d+%d", ErrInvalid, off, length)
+	}
+	cl, err := co.pool.client(ctx, co.addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := cl.GetObject(ctx, &protocol.GetObjectRequest{ObjectId: id, Offset: uint64(off), Length: uint64(length), Version: version})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	var info *ObjectInfo
+	for {
+		c, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fromStatus(err)
+		}
+		if info == nil {
+			if c.Fpcc == nil {
+				return nil, errors.New("coordinator sent no FPCC")
+			}
+			info = objectInfo(id, c.Fpcc, c.CreatedUnix)
+		}
+		if _, err := w.Write(c.Data); err != nil {
+			return nil, err
+		}
+	}
+	if info == nil {
+		return nil, errors.New("coordinator closed the stream without an answer")
+	}
+	return info, nil
+}
+
+// ServePutObject implements the PutObject RPC with c: the stream's first
+// message names the object, its options override c's and the data of
+// all messages is the content.
+func (c *Client) ServePutObject(stream protocol.Dispersal_PutObjectServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	opts := c.PutOptions()
+	if o := first.Options; o != nil {
+		if o.Codec != "" {
+			opts.Codec = o.Codec
+		}
+		if o.Data != 0 {
+			opts.Data = int(o.Data)
+		}
+		if o.Total != 0 {
+			opts.Total = int(o.Total)
+		}
+		if o.Compression != nil {
+			opts.Compression = *o.Compression
+		}
+		if o.Dedup != nil {
+			opts.Dedup = *o.Dedup
+		}
+		if o.Encrypt != nil {
+			opts.Encrypt = *o.Encrypt
+		}
+		opts.IfNoneMatch, opts.IfMatchVersion = o.IfNoneMatch, o.IfMatchVersion
+		opts.Metadata, opts.Tags = o.Metadata, o.Tags
+		if o.ExpiresUnix != 0 {
+			opts.Expires = time.Unix(o.ExpiresUnix, 0)
+		}
+		opts.Retain = o.Retain
+	}
+	body := &chunkReader{buf: first.Data, next: func() ([]byte, error) {
+		m, err := stream.Recv()
+		if err != nil {
+			return nil, err // io.EOF once the client closes its side
+		}
+		return m.Data, nil
+	}}
+	info, err := c.Put(stream.Context(), first.ObjectId, body, &opts)
+	if err != nil {
+		return toStatus(err)
+	}
+	resp := &protocol.PutObjectResponse{Fpcc: info.FPCC}
+	if !info.Created.IsZero() {
+		resp.CreatedUnix = info.Created.Unix()
+	}
+	return stream.SendAndClose(resp)
+}
+
+// ServeGetObject implements the GetObject RPC with c. The first message
+// carries the FPCC c found; data follows as GetVersion releases it, which
+// is only once verified. The read stays on the version stat found, even if
+// a newer one commits meanwhile.
+func (c *Client) ServeGetObject(req *protocol.GetObjectRequest, stream protocol.Dispersal_GetObjectServer) error {
+	ctx := stream.Context()
+	st, err := c.StatVersion(ctx, req.ObjectId, req.Version)
+	if err != nil {
+		return toStatus(err)
+	}
+	first := &protocol.GetObjectChunk{Fpcc: st.FPCC}
+	if !st.Created.IsZero() {
+		first.CreatedUnix = st.Created.Unix()
+	}
+	w := &chunkWriter{send: stream.Send, first: first}
+	_, err = c.GetVersion(ctx, req.ObjectId, st.VersionID(), w, int64(req.Offset), int64(req.Length))
+	if err == nil {
+		err = w.Flush()
+	}
+	return toStatus(err)
+}
+
+// chunkWriter sends what is written to it as GetObjectChunks of up to
+// streamChunk bytes, the first of them carrying first.
+type chunkWriter struct {
+	send  func(*protocol.GetObjectChunk) error
+	first *protocol.GetObjectChunk
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		k := min(len(p), streamChunk)
+		msg := &protocol.GetObjectChunk{}
+		if w.first != nil {
+			msg, w.first = w.first, nil
+		}
+		msg.Data = p[:k]
+		if err := w.send(msg); err != nil {
+			return n, err
+		}
+		n += k
+		p = p[k:]
+	}
+	return n, nil
+}
+
+// Flush sends the first message if nothing was written: an empty object.
+func (w *chunkWriter) Flush() error {
+	if w.first == nil {
+		return nil
+	}
+	msg := w.first
+	w.first = nil
+	return w.send(msg)
+}
+
+// toStatus turns an error of c into the gRPC status a Coordinator maps
+// back.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := codes.Unknown
+	switch {
+	case errors.Is(err, ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, ErrExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, ErrPrecondition):
+		code = codes.FailedPrecondition
+	case errors.Is(err, ErrInvalid):
+		code = codes.InvalidArgument
+	case errors.Is(err, ErrUnavailable):
+		code = codes.Unavailable
+	case errors.Is(err, ErrCorrupt), errors.Is(err, envelope.ErrAuth):
+		code = codes.DataLoss
+	case errors.Is(err, envelope.ErrNoKey):
+		code = codes.PermissionDenied
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		err = e.Err // the Coordinator wraps its own op and ID around it
+	}
+	return status.Error(code, err.Error())
+}
+
+// fromStatus maps a coordinator's status back onto the errors of this
+// package.
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || err == nil {
+		return err
+	}
+	var base error
+	switch st.Code() {
+	case codes.NotFound:
+		base = ErrNotFound
+	case codes.AlreadyExists:
+		base = ErrExists
+	case codes.FailedPrecondition:
+		base = ErrPrecondition
+	case codes.InvalidArgument:
+		base = ErrInvalid
+	case codes.Unavailable:
+		base = ErrUnavailable
+	case codes.DataLoss:
+		base = ErrCorrupt
+	case codes.PermissionDenied:
+		base = envelope.ErrNoKey
+	case codes.Canceled:
+		base = context.Canceled
+	case codes.DeadlineExceeded:
+		base = context.DeadlineExceeded
+	default:
+		return err
+	}
+	if detail, ok := strings.CutPrefix(st.Message(), base.Error()); ok {
+		return fmt.Errorf("%w%s", base, detail)
+	}
+	return fmt.Errorf("%w: %s", base, st.Message())
+}
@@ -0,0 +1,46 @@
+// pkg/client/crypt.go – client-side envelope encryption.
+// Objects are sealed before erasure coding (pkg/envelope), so servers store,
+// hash and repair ciphertext only; the wrapped data key travels in the FPCC.
+
+package client
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/dattu/distributed_object_store/pkg/envelope"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+type sealedFile struct {
+	*envelope.Reader
+	io.Closer
+}
+
+// sealedSource encrypts src under dk as it is read. Sealing is
+// deterministic for a given data key, so both passes see the same
+// ciphertext.
+func sealedSource(src source, dk []byte, segment int) source {
+	return func() (io.ReadCloser, error) {
+		f, err := src()
+		if err != nil {
+			return nil, err
+		}
+		return sealedFile{envelope.NewReader(f, dk, segment), f}, nil
+	}
+}
+
+// dataKey unwraps the data key of an encrypted object; nil for plaintext.
+// The error wraps envelope.ErrNoKey when no KEK of the client's keyring
+// wrapped it, envelope.ErrAuth when the envelope was tampered with.
+func (c *Client) dataKey(id string, fpcc *protocol.FPCC) ([]byte, error) {
+	env := fpcc.GetEnvelope()
+	if env == nil {
+		return nil, nil
+	}
+	dk, err := c.keys.Open(id, env)
+	if err != nil {
+		return nil, fmt.Errorf("object is encrypted: %w", err)
+	}
+	return dk, nil
+}
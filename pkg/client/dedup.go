@@ -0,0 +1,246 @@
+// pkg/client/dedup.go – deduplicated Put and Get.
+// With dedup on, the input is cut into content‑defined chunks (pkg/chunker).
+// Chunks the cluster already holds are referenced instead of being sent
+// again, new ones are dispersed as objects of their own, and the object
+// itself becomes a manifest: an FPCC listing its chunks, over empty
+// fragments. Servers count the manifests referring to each chunk and keep
+// it until the last one expires.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/dattu/distributed_object_store/pkg/chunker"
+	"github.com/dattu/distributed_object_store/pkg/compression"
+	"github.com/dattu/distributed_object_store/pkg/erasure"
+	"github.com/dattu/distributed_object_store/pkg/membership"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+const (
+	chunkParallel = 4    // chunks dispersed at once
+	hasChunksMax  = 4096 // hashes per HasChunks call
+)
+
+// chunkSeed derives the fingerprint seed of a chunk from its ID.
+func chunkSeed(id string) uint64 {
+	sum := sha256.Sum256([]byte("avid-fp/chunk-seed/v1\x00" + id))
+	return binary.BigEndian.Uint64(sum[:]) | 1 // never 0
+}
+
+// chunkStripe fits the stripe to a size-byte chunk. With the default stripe
+// the last one is padded to m MiB, which would cost more than the chunk.
+func chunkStripe(size int64, enc erasure.Codec) int {
+	m, _ := enc.Shards()
+	per := (size + int64(m) - 1) / int64(m)
+	return int(min((per+4095)/4096*4096, erasure.DefaultStripe))
+}
+
+// dedupe disperses content as a manifest of its chunks, sending only the
+// chunks no server vouches for, each compressed with the codec named, if
+// any, and the manifest as generation gen of id under the precondition
+// cond stamps. It returns the manifest's FPCC.
+func (c *Client) dedupe(ctx context.Context, view *protocol.View, content *io.SectionReader, id string, enc erasure.Codec, compress string, gen uint64, cond func(*protocol.FPCC)) (*protocol.FPCC, error) {
+	man := &protocol.Manifest{}
+	first := make(map[string]int64) // chunk ID → offset of its first occurrence
+	var unique []*protocol.ChunkRef
+	var off int64
+	for ch := chunker.New(io.NewSectionReader(content, 0, content.Size()), chunker.DefaultAverage); ; {
+		data, err := ch.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("chunk: %w", err)
+		}
+		ref := &protocol.ChunkRef{Hash: chunker.Sum(data), Size: uint64(len(data))}
+		man.Chunks = append(man.Chunks, ref)
+		if _, seen := first[chunker.ID(ref.Hash)]; !seen {
+			first[chunker.ID(ref.Hash)] = off
+			unique = append(unique, ref)
+		}
+		off += int64(len(data))
+	}
+
+	held := c.hasChunks(ctx, view, unique, enc)
+	ctx, cancel := context.WithCancel(ctx) // the first chunk to fail stops the rest
+	defer cancel()
+	var (
+		wg            sync.WaitGroup
+		mu            sync.Mutex
+		failed        error
+		sem           = make(chan struct{}, chunkParallel)
+		sent          int
+		sentB, reused int64
+	)
+	for i, ref := range unique {
+		if held[i] {
+			reused += int64(ref.Size)
+			continue
+		}
+		sent++
+		sentB += int64(ref.Size)
+		cid := chunker.ID(ref.Hash)
+		src := sectionSource(content, first[cid], int64(ref.Size))
+		stamp := func(*protocol.FPCC) {}
+		if compress != "" {
+			codec, _ := compression.Lookup(compress)
+			raw := new(int64)
+			src = compressedSource(src, codec, raw)
+			stamp = func(f *protocol.FPCC) {
+				f.Compression = &protocol.Compression{Codec: compress, Size: uint64(*raw)}
+			}
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			if _, err := c.disperse(ctx, view, src, cid, enc, chunkStripe(int64(ref.Size), enc), 0, stamp); err != nil {
+				mu.Lock()
+				if failed == nil {
+					failed = fmt.Errorf("chunk %s: %w", cid, err)
+				}
+				mu.Unlock()
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	if failed != nil {
+		return nil, failed
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.logf("Chunked %q into %d chunks (%d distinct): dispersed %d (%d bytes), %d bytes already stored",
+		id, len(man.Chunks), len(unique), sent, sentB, reused)
+
+	return c.disperse(ctx, view, func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(nil)), nil }, id, enc, erasure.DefaultStripe, gen,
+		func(f *protocol.FPCC) {
+			f.Manifest = man
+			cond(f)
+		})
+}
+
+// hasChunks reports, for each chunk, whether the cluster holds it and will
+// keep it long enough to be referenced. Every member is asked: a chunk may
+// have been stored under another erasure profile, and so another group,
+// than this client would pick. A chunk counts as held once f+1 servers
+// vouch for it, f being the faults enc tolerates in this view, so no
+// faulty server can make the client drop a chunk on its own. A server that
+// does not answer only costs re‑sending chunks it might have vouched for.
+func (c *Client) hasChunks(ctx context.Context, view *protocol.View, refs []*protocol.ChunkRef, enc erasure.Codec) []bool {
+	m, n := enc.Shards()
+	need := membership.QuorumFor(len(view.GetMembers()), m, n).F + 1
+	votes := make([]int, len(refs))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, addr := range membership.Addrs(view) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dc, err := c.pool.client(ctx, addr)
+			if err != nil {
+				c.logf("HasChunks: dial %s failed: %v", addr, err)
+				return
+			}
+			for lo := 0; lo < len(refs); lo += hasChunksMax {
+				batch := refs[lo:min(lo+hasChunksMax, len(refs))]
+				req := &protocol.HasChunksRequest{Hashes: make([][]byte, len(batch))}
+				for i, ref := range batch {
+					req.Hashes[i] = ref.Hash
+				}
+				resp, err := dc.HasChunks(ctx, req)
+				if err == nil && !resp.Ok {
+					err = fmt.Errorf("%s", resp.Error)
+				}
+				if err == nil && len(resp.Held) != len(batch) {
+					err = fmt.Errorf("%d answers for %d chunks", len(resp.Held), len(batch))
+				}
+				if err != nil {
+					c.logf("HasChunks via %s failed: %v", addr, err)
+					return
+				}
+				mu.Lock()
+				for i, h := range resp.Held {
+					if h {
+						votes[lo+i]++
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	held := make([]bool, len(refs))
+	for i, v := range votes {
+		held[i] = v >= need
+	}
+	return held
+}
+
+// fetchChunks writes bytes [off, off+length) of the deduplicated object man
+// describes to w, fetching every chunk the range touches through a scratch
+// file and checking it against its hash. It returns how many chunks it
+// fetched.
+func (c *Client) fetchChunks(ctx context.Context, view *protocol.View, w io.Writer, man *protocol.Manifest, off, length int64) (int, error) {
+	scratch, err := os.CreateTemp("", "chunk-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(scratch.Name())
+	defer scratch.Close()
+	peers := membership.Addrs(view)
+	fetched := 0
+	var pos int64
+	for _, ref := range man.Chunks {
+		lo, hi := pos, pos+int64(ref.Size)
+		pos = hi
+		from, to := max(off, lo), min(off+length, hi)
+		if from >= to {
+			continue
+		}
+		cid := chunker.ID(ref.Hash)
+		st, err := c.stat(ctx, peers, cid, nil)
+		if errors.Is(err, ErrNotFound) {
+			return fetched, fmt.Errorf("%w: chunk %s is missing", ErrCorrupt, cid)
+		}
+		if err != nil {
+			return fetched, err
+		}
+		dk, err := c.dataKey(cid, st.Fpcc)
+		if err != nil {
+			return fetched, err
+		}
+		if err := c.fetch(ctx, view, cid, st.Fpcc, scratch, unpack(st.Fpcc, dk)); err != nil {
+			return fetched, fmt.Errorf("chunk %s: %w", cid, err)
+		}
+		fetched++
+		h := sha256.New()
+		if _, err := scratch.Seek(0, io.SeekStart); err != nil {
+			return fetched, err
+		}
+		if n, err := io.Copy(h, scratch); err != nil || n != int64(ref.Size) || !bytes.Equal(h.Sum(nil), ref.Hash) {
+			return fetched, fmt.Errorf("%w: chunk %s does not match the manifest (%d bytes, %v)", ErrCorrupt, cid, n, err)
+		}
+		if _, err := io.Copy(w, io.NewSectionReader(scratch, from-lo, to-from)); err != nil {
+			return fetched, err
+		}
+	}
+	return fetched, nil
+}
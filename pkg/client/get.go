@@ -0,0 +1,273 @@
+// pkg/client/get.go – reading objects: Get, GetRange and the verified
+// fragment fetch under both.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dattu/distributed_object_store/pkg/envelope"
+	"github.com/dattu/distributed_object_store/pkg/erasure"
+	"github.com/dattu/distributed_object_store/pkg/fingerprint"
+	"github.com/dattu/distributed_object_store/pkg/membership"
+	"github.com/dattu/distributed_object_store/pkg/placement"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+// Get writes the content of object id's current version to w, undoing
+// compression and encryption and reassembling a deduplicated object from
+// its chunks. Decoding may restart when a fragment fails verification, so
+// the bytes are staged: an *os.File at offset 0 is written and truncated
+// in place, any other writer only receives the object once all of it has
+// verified.
+func (c *Client) Get(ctx context.Context, id string, w io.Writer) (*ObjectInfo, error) {
+	info, err := c.get(ctx, id, nil, w)
+	return info, wrap("get", id, err)
+}
+
+// get reads the version of id whose FPCC digest is version; nil is the
+// current one.
+func (c *Client) get(ctx context.Context, id string, version []byte, w io.Writer) (*ObjectInfo, error) {
+	view := c.liveView(ctx)
+	st, err := c.stat(ctx, membership.Addrs(view), id, version)
+	if err != nil {
+		return nil, err
+	}
+	fpcc := st.Fpcc
+	info := objectInfo(id, fpcc, st.CreatedUnix)
+	if man := fpcc.GetManifest(); man != nil {
+		// chunks verify one at a time against the manifest before they are written
+		if _, err := c.fetchChunks(ctx, view, w, man, 0, info.Size); err != nil {
+			return nil, err
+		}
+		return info, nil
+	}
+	dk, err := c.dataKey(id, fpcc)
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := w.(*os.File); ok {
+		if off, err := f.Seek(0, io.SeekCurrent); err == nil && off == 0 {
+			return info, c.fetch(ctx, view, id, fpcc, f, unpack(fpcc, dk))
+		}
+	}
+	tmp, err := os.CreateTemp("", "get-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	if err := c.fetch(ctx, view, id, fpcc, tmp, unpack(fpcc, dk)); err != nil {
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(w, tmp); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// GetRange writes bytes [off, off+length) of object id to w; length 0
+// reads to the end. Striped objects carry a Merkle root per fragment, so
+// only the blocks holding the range are fetched, each verified by an
+// inclusion proof – from the data fragments when they are healthy,
+// otherwise m blocks of the same stripe are decoded. An encrypted object's
+// range maps onto the sealed segments holding it, which are fetched the
+// same way and opened on the fly. Offsets into a compressed object are
+// only known once it is decompressed, so it is fetched whole. A
+// deduplicated object only fetches the chunks the range touches.
+func (c *Client) GetRange(ctx context.Context, id string, w io.Writer, off, length int64) (*ObjectInfo, error) {
+	info, err := c.getRange(ctx, id, nil, w, off, length)
+	return info, wrap("get", id, err)
+}
+
+func (c *Client) getRange(ctx context.Context, id string, version []byte, w io.Writer, off, length int64) (*ObjectInfo, error) {
+	view := c.liveView(ctx)
+	st, err := c.stat(ctx, membership.Addrs(view), id, version)
+	if err != nil {
+		return nil, err
+	}
+	fpcc := st.Fpcc
+	info := objectInfo(id, fpcc, st.CreatedUnix)
+	size := info.Size
+	if length == 0 {
+		length = size - off
+	}
+	if off < 0 || length < 0 || off+length > size {
+		return nil, fmt.Errorf("%w: range %d+%d outside the %d‑byte object", ErrInvalid, off, length, size)
+	}
+	if man := fpcc.GetManifest(); man != nil {
+		n, err := c.fetchChunks(ctx, view, w, man, off, length)
+		if err != nil {
+			return nil, err
+		}
+		c.logf("Fetched %d of %d chunks for a %d‑byte range", n, len(man.Chunks), length)
+		return info, nil
+	}
+	enc, err := c.codecOf(fpcc)
+	if err != nil {
+		return nil, err
+	}
+	dk, err := c.dataKey(id, fpcc)
+	if err != nil {
+		return nil, err
+	}
+	env, comp := fpcc.GetEnvelope(), fpcc.GetCompression()
+	want, wantLen := off, length // object bytes to read: the ciphertext, if sealed
+	var plain *envelope.Writer
+	if env != nil && comp == nil {
+		if plain, err = envelope.NewWriter(w, dk, env, off, length); err != nil {
+			return nil, fmt.Errorf("decrypt: %w", err)
+		}
+		w = plain
+		want, wantLen = envelope.Range(env, off, length)
+	}
+	stripe := int(fpcc.GetProfile().GetStripe())
+	if stripe == 0 || len(fpcc.Roots) == 0 || comp != nil {
+		// unstriped object: its only block is the whole fragment
+		whole, err := os.CreateTemp("", "range-*")
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(whole.Name())
+		defer whole.Close()
+		var open opener // nil: the stored bytes
+		if comp != nil {
+			open = unpack(fpcc, dk)
+		}
+		if err := c.fetch(ctx, view, id, fpcc, whole, open); err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(w, io.NewSectionReader(whole, want, wantLen)); err != nil {
+			return nil, err
+		}
+	} else {
+		br := &blockReader{ctx: ctx, enc: enc, stripe: stripe, id: id, fpcc: fpcc, candidates: fragmentCandidates(view, id, enc), pool: c.pool,
+			length: uint64(erasure.FragmentSize(enc, stripe, int64(fpcc.Size)))}
+		for _, sp := range erasure.Spans(enc, stripe, want, wantLen) {
+			data, err := br.span(sp)
+			if err != nil {
+				if cerr := ctx.Err(); cerr != nil {
+					return nil, cerr
+				}
+				return nil, fmt.Errorf("%w: stripe %d: %v", ErrCorrupt, sp.Stripe, err)
+			}
+			if _, err := w.Write(data); err != nil {
+				return nil, err
+			}
+		}
+		c.logf("Fetched %d blocks (%d bytes) for a %d‑byte range", br.blocks, br.fetched, length)
+	}
+	if plain != nil {
+		if err := plain.Close(); err != nil {
+			return nil, fmt.Errorf("decrypt: %w", err)
+		}
+	}
+	return info, nil
+}
+
+// codecOf returns the codec an object was written with. Objects from
+// before profiles were recorded are Reed–Solomon with the configured data
+// shards.
+func (c *Client) codecOf(fpcc *protocol.FPCC) (erasure.Codec, error) {
+	name, m, n := erasure.RS, c.cfg.Data, len(fpcc.GetHashes())
+	if p := fpcc.GetProfile(); p != nil {
+		name, m, n = p.Codec, int(p.Data), int(p.Total)
+	} else if m == 0 {
+		return nil, fmt.Errorf("%w: object has no erasure profile; configure the data shards", ErrInvalid)
+	}
+	enc, err := erasure.Lookup(name, m, n)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	return enc, nil
+}
+
+// fragmentCandidates lists, per fragment index, the placement owner and then
+// every other server: the object may predate the current view.
+func fragmentCandidates(view *protocol.View, id string, enc erasure.Codec) func(idx int) []string {
+	servers := membership.Addrs(view)
+	owners, _ := placement.ForCodec(view, id, enc) // nil owners: ask everybody
+	return func(idx int) []string {
+		if idx >= len(owners) {
+			return servers
+		}
+		out := []string{owners[idx]}
+		for _, s := range servers {
+			if s != owners[idx] {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+}
+
+// fetch streams the generation of id described by fpcc into f, verifying
+// every fragment it reads against it. Fragments are checked once fully
+// read, so a bad one costs a restart: it is excluded and f is rewritten
+// from the remaining fragments. The decoded bytes pass through open, which
+// undoes the object's layers; with a nil open f receives them as stored.
+func (c *Client) fetch(ctx context.Context, view *protocol.View, id string, fpcc *protocol.FPCC, f *os.File, open opener) error {
+	enc, err := c.codecOf(fpcc)
+	if err != nil {
+		return err
+	}
+	m, n := enc.Shards()
+	candidates := fragmentCandidates(view, id, enc)
+	fpGen := fingerprint.NewWithSeed(fpcc.Seed)
+
+	// the m data shards come first, so a healthy read is a plain copy of
+	// them; parity is only fetched in place of a fragment that failed, and
+	// a codec that cannot decode from a set gets one more fragment to work with
+	bad := make([]bool, n)
+	corrupt := false // some fragment was read but did not verify
+	for want := m; ; {
+		var pick []int
+		for idx := 0; idx < n && len(pick) < want; idx++ {
+			if !bad[idx] {
+				pick = append(pick, idx)
+			}
+		}
+		if len(pick) < m {
+			if !corrupt {
+				return fmt.Errorf("%w: only %d/%d fragments readable", ErrUnavailable, len(pick), m)
+			}
+			return fmt.Errorf("%w: only %d/%d good shards", ErrCorrupt, len(pick), m)
+		}
+		failed, err := decodeInto(ctx, f, c.pool, enc, fpGen, id, fpcc, open, pick, candidates)
+		if cerr := ctx.Err(); cerr != nil {
+			return cerr
+		}
+		if err == nil {
+			if pick[len(pick)-1] >= m {
+				c.logf("Degraded read of %q: decoded from fragments %v", id, pick)
+			}
+			break
+		}
+		corrupt = corrupt || errors.Is(err, ErrCorrupt)
+		for _, idx := range failed {
+			bad[idx] = true
+		}
+		if len(failed) == 0 {
+			if le := (*layerError)(nil); errors.As(err, &le) {
+				return fmt.Errorf("unpack: %w", le.err) // verified bytes: more fragments will not help
+			}
+			if len(pick) < want {
+				return fmt.Errorf("%w: decode with %d shards: %v", ErrCorrupt, len(pick), err)
+			}
+			want++
+		}
+	}
+	if fpcc.Size == 0 {
+		if err := trimZeros(f); err != nil { // pre‑profile object: size unknown
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// pkg/client/pipeline.go – what happens to an object's bytes around erasure
+// coding. On the way in: compress, then encrypt, then encode; on the way out
+// the layers are peeled off in reverse as the decoded bytes stream past.
+// The FPCC records every layer, so Get needs no options to undo them.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dattu/distributed_object_store/pkg/compression"
+	"github.com/dattu/distributed_object_store/pkg/envelope"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+// source opens the bytes to disperse; disperse reads them twice.
+type source func() (io.ReadCloser, error)
+
+// sectionSource reads bytes [off, off+n) of r. An *os.File serves any
+// number of sources at once.
+func sectionSource(r io.ReaderAt, off, n int64) source {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(io.NewSectionReader(r, off, n)), nil
+	}
+}
+
+// countedFile counts what is read through it into *n.
+type countedFile struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (f countedFile) Read(p []byte) (int, error) {
+	k, err := f.ReadCloser.Read(p)
+	*f.n += int64(k)
+	return k, err
+}
+
+type compressedFile struct {
+	io.ReadCloser // compressed stream
+	src           io.Closer
+}
+
+func (f compressedFile) Close() error {
+	f.ReadCloser.Close() // stops the compressor if we quit early
+	return f.src.Close()
+}
+
+// compressedSource compresses src with c as it is read, counting the
+// uncompressed bytes of the latest pass into *size.
+func compressedSource(src source, c compression.Codec, size *int64) source {
+	return func() (io.ReadCloser, error) {
+		f, err := src()
+		if err != nil {
+			return nil, err
+		}
+		*size = 0
+		return compressedFile{c.Compress(countedFile{f, size}), f}, nil
+	}
+}
+
+// chooseCompression returns name unless a sample from the start of r
+// says compressing it would not pay; "" means store it as it is.
+func chooseCompression(r io.ReaderAt, name string) (string, error) {
+	if name == "" || name == "none" {
+		return "", nil
+	}
+	if _, err := compression.Lookup(name); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	sample := make([]byte, compression.SampleSize)
+	k, err := r.ReadAt(sample, 0)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if !compression.Worthwhile(sample[:k]) {
+		return "", nil
+	}
+	return name, nil
+}
+
+// layerError marks a failure in undoing a layer rather than in decoding:
+// the bytes were verified, so fetching other fragments will not help.
+type layerError struct{ err error }
+
+func (e *layerError) Error() string { return e.err.Error() }
+func (e *layerError) Unwrap() error { return e.err }
+
+// opener wraps w so that the stored bytes written to it come out as the
+// object's content; the returned close flushes and checks every layer.
+type opener func(w io.Writer) (io.Writer, func() error, error)
+
+// unpack undoes the layers fpcc records, decrypting with data key dk.
+func unpack(fpcc *protocol.FPCC, dk []byte) opener {
+	return func(w io.Writer) (io.Writer, func() error, error) {
+		var closers []func() error // outermost layer first
+		if c := fpcc.GetCompression(); c != nil {
+			codec, err := compression.Lookup(c.Codec)
+			if err != nil {
+				return nil, nil, err
+			}
+			d := codec.Decompress(w, int64(c.Size))
+			w, closers = d, append(closers, d.Close)
+		}
+		if env := fpcc.GetEnvelope(); env != nil {
+			d, err := envelope.NewWriter(w, dk, env, 0, int64(env.Size))
+			if err != nil {
+				return nil, nil, err
+			}
+			w, closers = d, append([]func() error{d.Close}, closers...)
+		}
+		return layerWriter{w}, func() error {
+			var err error
+			for _, c := range closers {
+				if cerr := c(); err == nil && cerr != nil {
+					err = &layerError{cerr}
+				}
+			}
+			return err
+		}, nil
+	}
+}
+
+type layerWriter struct{ w io.Writer }
+
+func (l layerWriter) Write(p []byte) (int, error) {
+	n, err := l.w.Write(p)
+	if err != nil && !errors.As(err, new(*layerError)) {
+		err = &layerError{err}
+	}
+	return n, err
+}
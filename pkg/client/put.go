@@ -0,0 +1,545 @@
+// pkg/client/put.go – writing objects: Put and Transcode.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/chunker"
+	"github.com/dattu/distributed_object_store/pkg/compression"
+	"github.com/dattu/distributed_object_store/pkg/envelope"
+	"github.com/dattu/distributed_object_store/pkg/erasure"
+	"github.com/dattu/distributed_object_store/pkg/membership"
+	"github.com/dattu/distributed_object_store/pkg/placement"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+// PutOptions say how Put stores one object. Put with nil options uses the
+// Config's; a zero Codec, Data or Total also falls back to the Config.
+type PutOptions struct {
+	Codec       string
+	Data, Total int
+	Encrypt     bool
+	Compression string // "" = off; skipped when a sample does not compress
+	Dedup       bool   // cannot be combined with Encrypt
+
+	// Preconditions, checked by every server the write goes to: Put fails
+	// with ErrPrecondition unless id has no version (IfNoneMatch), or
+	// unless the current version is the one named (IfMatchVersion, a
+	// VersionID).
+	IfNoneMatch    bool
+	IfMatchVersion string
+
+	// Metadata (e.g. "content-type") and Tags travel with the version and
+	// are bound into its digest; List can filter on Tags. Both are limited
+	// to protocol.MaxMetadata bytes, see protocol.CheckMetadata.
+	Metadata map[string]string
+	Tags     map[string]string
+
+	// Expiry, bound into the FPCC too: the version expires TTL after the
+	// write, or at Expires, instead of by the servers' object.ttl and
+	// lifecycle rules; Retain keeps it until it is deleted. At most one of
+	// the three may be set.
+	TTL     time.Duration
+	Expires time.Time
+	Retain  bool
+}
+
+// expiry returns the expiry opts ask for, zero for none.
+func (opts *PutOptions) expiry() (time.Time, error) {
+	set := 0
+	for _, on := range []bool{opts.TTL != 0, !opts.Expires.IsZero(), opts.Retain} {
+		if on {
+			set++
+		}
+	}
+	switch {
+	case set > 1:
+		return time.Time{}, fmt.Errorf("%w: only one of TTL, Expires and Retain can be set", ErrInvalid)
+	case opts.TTL < 0:
+		return time.Time{}, fmt.Errorf("%w: negative TTL", ErrInvalid)
+	case opts.TTL > 0:
+		return time.Now().Add(opts.TTL), nil
+	case !opts.Expires.IsZero() && !opts.Expires.After(time.Now()):
+		return time.Time{}, fmt.Errorf("%w: expiry %s is in the past", ErrInvalid, opts.Expires.Format(time.RFC3339))
+	}
+	return opts.Expires, nil
+}
+
+// PutOptions returns the options Put uses when given none.
+func (c *Client) PutOptions() PutOptions {
+	return PutOptions{
+		Codec: c.cfg.Codec, Data: c.cfg.Data, Total: c.cfg.Total,
+		Encrypt: c.cfg.Encrypt, Compression: c.cfg.Compression, Dedup: c.cfg.Dedup,
+	}
+}
+
+// codec resolves the erasure profile of opts.
+func (c *Client) codec(opts PutOptions) (erasure.Codec, error) {
+	name, m, n := opts.Codec, opts.Data, opts.Total
+	if name == "" {
+		name = c.cfg.Codec
+	}
+	if m == 0 {
+		m = c.cfg.Data
+	}
+	if n == 0 {
+		n = c.cfg.Total
+	}
+	if m == 0 || n == 0 {
+		return nil, fmt.Errorf("%w: no erasure profile (data/total shards)", ErrInvalid)
+	}
+	enc, err := erasure.Lookup(name, m, n)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return enc, nil
+}
+
+// writable returns the live view once enough of it answers for enc's
+// quorums to be reachable.
+func (c *Client) writable(ctx context.Context, enc erasure.Codec) (*protocol.View, error) {
+	view := c.liveView(ctx)
+	if f := enc.Tolerance(); c.reachable(ctx, membership.Addrs(view)) < 2*f {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: quorum needs ≥%d reachable servers", ErrUnavailable, 2*f)
+	}
+	return view, nil
+}
+
+// Put stores the bytes of r as object id and returns what was committed.
+// r is read twice – once to fingerprint the fragments, once to send them –
+// so an *os.File is read in place from its current offset and any other
+// reader is first spooled to a temporary file. Putting an ID that exists
+// adds a version: the servers agree on its number, one past the current
+// version's, and keep the old one as retention allows. A concurrent write
+// may take that number first; Put then retries with the next, and fails
+// with ErrExists after putAttempts. A conditional Put checks its
+// precondition again before each retry, so it fails with ErrPrecondition
+// once another write has committed, and after putAttempts.
+func (c *Client) Put(ctx context.Context, id string, r io.Reader, opts *PutOptions) (*ObjectInfo, error) {
+	o := c.PutOptions()
+	if opts != nil {
+		o = *opts
+	}
+	info, err := c.put(ctx, id, r, o)
+	return info, wrap("put", id, err)
+}
+
+func (c *Client) put(ctx context.Context, id string, r io.Reader, opts PutOptions) (*ObjectInfo, error) {
+	if err := protocol.CheckID(id); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	switch {
+	case chunker.IsID(id):
+		return nil, fmt.Errorf("%w: IDs starting with %q are reserved for deduplicated chunks", ErrInvalid, chunker.Prefix)
+	case opts.Dedup && opts.Encrypt:
+		// chunks are shared between objects, data keys are not
+		return nil, fmt.Errorf("%w: deduplication cannot be combined with encryption", ErrInvalid)
+	case opts.Encrypt && c.keys.Empty():
+		return nil, fmt.Errorf("%w: encryption needs a key file or passphrase", ErrInvalid)
+	case opts.IfNoneMatch && opts.IfMatchVersion != "":
+		return nil, fmt.Errorf("%w: IfNoneMatch and IfMatchVersion cannot both be set", ErrInvalid)
+	}
+	if err := protocol.CheckMetadata(opts.Metadata, opts.Tags); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	match, err := parseVersion(opts.IfMatchVersion)
+	if err != nil {
+		return nil, err
+	}
+	expires, err := opts.expiry()
+	if err != nil {
+		return nil, err
+	}
+	enc, err := c.codec(opts)
+	if err != nil {
+		return nil, err
+	}
+	view, err := c.writable(ctx, enc)
+	if err != nil {
+		return nil, err
+	}
+	ra, off, size, done, err := spool(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	content := io.NewSectionReader(ra, off, size)
+
+	name, err := chooseCompression(content, opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" && opts.Compression != "" && opts.Compression != "none" {
+		c.logf("Storing %q uncompressed: it does not compress", id)
+	}
+	created := time.Now().Unix()
+	cond := func(f *protocol.FPCC) {
+		f.IfNoneMatch, f.IfMatchVersion = opts.IfNoneMatch, match
+		f.Metadata, f.Tags = opts.Metadata, opts.Tags
+		if !expires.IsZero() {
+			f.ExpiresUnix = expires.Unix()
+		}
+		f.Retain = opts.Retain
+		f.CreatedUnix = created
+	}
+	write := func(gen uint64) (*protocol.FPCC, error) {
+		if opts.Dedup {
+			return c.dedupe(ctx, view, content, id, enc, name, gen, cond)
+		}
+		return c.disperseLayers(ctx, view, content, id, enc, name, opts.Encrypt, gen, cond)
+	}
+	next := func() (uint64, error) {
+		if opts.IfNoneMatch || match != nil {
+			return c.conditionalGeneration(ctx, view, id, opts.IfNoneMatch, match)
+		}
+		return c.nextGeneration(ctx, view, id)
+	}
+	gen, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 1; ; attempt++ {
+		fpcc, err := write(gen)
+		if err == nil {
+			return objectInfo(id, fpcc, 0), nil
+		}
+		if !errors.Is(err, ErrExists) || attempt == putAttempts {
+			if errors.Is(err, ErrExists) && (opts.IfNoneMatch || match != nil) {
+				err = fmt.Errorf("%w: %v", ErrPrecondition, err)
+			}
+			return nil, err
+		}
+		// racing writers that split a version between them both lose it;
+		// a random pause keeps them from splitting the next one too
+		c.logf("Version %d of %q was taken by another write; retrying", gen, id)
+		if err := sleep(ctx, rand.N(time.Duration(attempt)*putBackoff)); err != nil {
+			return nil, err
+		}
+		n, err := next()
+		if err != nil {
+			return nil, err
+		}
+		gen = max(n, gen+1)
+	}
+}
+
+const (
+	putAttempts = 3                      // version numbers Put tries before giving up
+	putBackoff  = 200 * time.Millisecond // most Put waits before its second attempt
+)
+
+// nextGeneration returns the number of the version a write of id adds:
+// one past the current version's, or 0 for a new object.
+func (c *Client) nextGeneration(ctx context.Context, view *protocol.View, id string) (uint64, error) {
+	st, err := c.stat(ctx, membership.Addrs(view), id, nil)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return 0, nil
+	case err != nil:
+		return 0, err
+	}
+	return st.Fpcc.GetGeneration() + 1, nil
+}
+
+// conditionalGeneration is nextGeneration for a conditional write of id,
+// which fails unless id does not exist (noneMatch) or its current version
+// is match. The servers check again as the write commits.
+func (c *Client) conditionalGeneration(ctx context.Context, view *protocol.View, id string, noneMatch bool, match []byte) (uint64, error) {
+	st, err := c.stat(ctx, membership.Addrs(view), id, nil)
+	switch {
+	case noneMatch && errors.Is(err, ErrNotFound):
+		return 0, nil // or past a version a race left uncommitted
+	case errors.Is(err, ErrNotFound):
+		return 0, fmt.Errorf("%w: object not found", ErrPrecondition)
+	case err != nil:
+		return 0, err
+	case noneMatch:
+		return 0, fmt.Errorf("%w: object exists", ErrPrecondition)
+	case !bytes.Equal(st.Fpcc.Digest(), match):
+		return 0, fmt.Errorf("%w: current version is %x", ErrPrecondition, st.Fpcc.Digest())
+	}
+	return st.Fpcc.GetGeneration() + 1, nil
+}
+
+// disperseLayers compresses content with the codec named, if any, seals it
+// when encrypt is set and disperses the result as generation gen of id
+// under the precondition cond stamps.
+func (c *Client) disperseLayers(ctx context.Context, view *protocol.View, content *io.SectionReader, id string, enc erasure.Codec, name string, encrypt bool, gen uint64, cond func(*protocol.FPCC)) (*protocol.FPCC, error) {
+	// layers apply inner first – compress, then encrypt – and each
+	// records itself in the FPCC once the first pass has sized it
+	src := sectionSource(content, 0, content.Size())
+	stamps := []func(*protocol.FPCC){cond}
+	if name != "" {
+		codec, _ := compression.Lookup(name)
+		raw := new(int64)
+		src = compressedSource(src, codec, raw)
+		stamps = append(stamps, func(f *protocol.FPCC) {
+			f.Compression = &protocol.Compression{Codec: name, Size: uint64(*raw)}
+		})
+	}
+	if encrypt {
+		env, dk, err := c.keys.Seal(id)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt: %w", err)
+		}
+		src = sealedSource(src, dk, int(env.Segment))
+		stamps = append(stamps, func(f *protocol.FPCC) {
+			env.Size = uint64(envelope.OpenedSize(int64(f.Size), int(env.Segment)))
+			f.Envelope = env
+		})
+	}
+	return c.disperse(ctx, view, src, id, enc, erasure.DefaultStripe, gen, func(f *protocol.FPCC) {
+		for _, stamp := range stamps {
+			stamp(f)
+		}
+	})
+}
+
+// spool returns r as a section of an io.ReaderAt, copying it to a
+// temporary file unless it is a seekable file already; done releases the
+// copy.
+func spool(ctx context.Context, r io.Reader) (ra io.ReaderAt, off, size int64, done func(), err error) {
+	if f, ok := r.(*os.File); ok {
+		if info, serr := f.Stat(); serr == nil && info.Mode().IsRegular() {
+			if off, err = f.Seek(0, io.SeekCurrent); err == nil {
+				return f, off, info.Size() - off, func() {}, nil
+			}
+		}
+	}
+	tmp, err := os.CreateTemp("", "put-*")
+	if err != nil {
+		return nil, 0, 0, nil, err
+	}
+	done = func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	if size, err = io.Copy(tmp, ctxReader{ctx, r}); err != nil {
+		done()
+		return nil, 0, 0, nil, err
+	}
+	return tmp, 0, size, done, nil
+}
+
+// ctxReader stops reading once ctx is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// sleep waits d, or less if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shardError classifies a server's refusal of a shard: another FPCC holds
+// the round, or the object has moved past the generation written, or the
+// write's precondition does not hold there.
+func shardError(err error) error {
+	switch {
+	case err == nil:
+	case strings.Contains(err.Error(), "FPCC mismatch"), strings.Contains(err.Error(), "stale generation"):
+		return fmt.Errorf("%w (%v)", ErrExists, err)
+	case strings.Contains(err.Error(), "precondition failed"):
+		return fmt.Errorf("%w (%v)", ErrPrecondition, err)
+	}
+	return err
+}
+
+// disperse encodes the bytes of src with enc, in stripes of stripe bytes
+// per fragment, and streams each shard to its owner as generation gen of
+// the object; stamp records in the FPCC how src was transformed, once the
+// first pass has run. src is read twice – once to fingerprint the
+// fragments, once to send them – so memory use is a few stripes whatever
+// the object size. It returns the FPCC once every owner has committed.
+func (c *Client) disperse(ctx context.Context, view *protocol.View, src source, id string, enc erasure.Codec, stripe int, gen uint64, stamp func(*protocol.FPCC)) (*protocol.FPCC, error) {
+	// validate placement before doing any encoding work
+	owners, err := placement.ForCodec(view, id, enc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: placement: %v", ErrUnavailable, err)
+	}
+	// a chunk is named by its content, and so is its FPCC: two clients
+	// storing the same chunk at once disperse the same fragments under the
+	// same FPCC. dedupe reports on chunks in bulk rather than per shard.
+	var seed uint64
+	chunk := chunker.IsID(id)
+	if chunk {
+		seed = chunkSeed(id)
+	}
+	fpcc, err := fingerprintFile(src, enc, stripe, seed)
+	if err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+	fpcc.Generation = gen
+	stamp(fpcc)
+
+	// every owner must hold its fragment before any can commit, so all
+	// shards go out in one pass; a shard that fails is retried on its own
+	// while the others wait for their quorum
+	m, n := enc.Shards()
+	all := make([]int, n)
+	for i := range all {
+		all[i] = i
+	}
+	// a shard refused for another FPCC or a failed precondition dooms the
+	// write, so the others stop waiting for a commit that cannot come
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		first error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if first == nil {
+			first = err
+		}
+		mu.Unlock()
+		if errors.Is(err, ErrExists) || errors.Is(err, ErrPrecondition) {
+			cancel()
+		}
+	}
+	streamShards(ctx, c.pool, src, enc, owners, id, fpcc, all, func(idx int, err error) {
+		if err == nil {
+			if !chunk {
+				c.logf("Shard %d/%d dispersed to %s", idx+1, n, owners[idx])
+			}
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for attempt := 2; attempt <= 3 && err != nil && ctx.Err() == nil; attempt++ {
+				if serr := shardError(err); errors.Is(serr, ErrExists) || errors.Is(serr, ErrPrecondition) {
+					break // retrying cannot change the other FPCC
+				}
+				c.logf("disperse to %s failed (%d/3): %v", owners[idx], attempt-1, err)
+				if serr := sleep(ctx, 2*time.Second); serr != nil {
+					err = serr
+					break
+				}
+				streamShards(ctx, c.pool, src, enc, owners, id, fpcc, []int{idx}, func(_ int, e error) { err = e })
+			}
+			if err != nil {
+				if err = shardError(err); !errors.Is(err, ErrExists) && !errors.Is(err, ErrPrecondition) && ctx.Err() == nil {
+					err = fmt.Errorf("%w: shard %d → %s: %v", ErrUnavailable, idx, owners[idx], err)
+				}
+				fail(err)
+				return
+			}
+			if !chunk {
+				c.logf("Shard %d/%d dispersed to %s", idx+1, n, owners[idx])
+			}
+		}()
+	})
+	wg.Wait()
+	if err := parent.Err(); err != nil {
+		return nil, err
+	}
+	if first != nil {
+		return nil, first
+	}
+	switch {
+	case chunk:
+	case fpcc.Manifest != nil:
+		c.logf("Disperse complete for %q (manifest of %d chunks, %d‑of‑%d)", id, len(fpcc.Manifest.Chunks), m, n)
+	default:
+		c.logf("Disperse complete for %q (%d bytes, %d‑of‑%d)", id, fpcc.Size, m, n)
+	}
+	return fpcc, nil
+}
+
+// Transcode re‑encodes a stored object with another erasure profile and
+// disperses it as the next generation of the same ID, marked as replacing
+// the current version rather than adding one. Servers keep serving the old
+// generation until the new one commits, so readers are never left without
+// a decodable copy. The object passes through a temporary file
+// rather than memory, and keeps its layers: compressed or encrypted bytes
+// are re‑encoded as they are stored, with the same metadata. A
+// deduplicated object only re‑encodes its manifest; its chunks are shared
+// and keep their own profile.
+func (c *Client) Transcode(ctx context.Context, id, codec string, data, total int) (*ObjectInfo, error) {
+	info, err := c.transcode(ctx, id, PutOptions{Codec: codec, Data: data, Total: total})
+	return info, wrap("transcode", id, err)
+}
+
+func (c *Client) transcode(ctx context.Context, id string, opts PutOptions) (*ObjectInfo, error) {
+	enc, err := c.codec(opts)
+	if err != nil {
+		return nil, err
+	}
+	view, err := c.writable(ctx, enc)
+	if err != nil {
+		return nil, err
+	}
+	st, err := c.stat(ctx, membership.Addrs(view), id, nil)
+	if err != nil {
+		return nil, err
+	}
+	fpcc := st.Fpcc
+	m, n := enc.Shards()
+	old, err := c.codecOf(fpcc)
+	if err != nil {
+		return nil, err
+	}
+	if om, on := old.Shards(); old.Name() == enc.Name() && om == m && on == n {
+		return nil, fmt.Errorf("%w: already %s %d‑of‑%d", ErrInvalid, enc.Name(), m, n)
+	}
+	tmp, err := os.CreateTemp("", "transcode-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	if err := c.fetch(ctx, view, id, fpcc, tmp, nil); err != nil {
+		return nil, err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		return nil, err
+	}
+	stripe := int(fpcc.GetProfile().GetStripe())
+	if stripe == 0 {
+		stripe = erasure.DefaultStripe
+	}
+	next, err := c.disperse(ctx, view, sectionSource(tmp, 0, info.Size()), id, enc, stripe, fpcc.Generation+1, func(f *protocol.FPCC) {
+		f.Envelope, f.Compression, f.Manifest = fpcc.Envelope, fpcc.Compression, fpcc.Manifest
+		f.Metadata, f.Tags = fpcc.Metadata, fpcc.Tags
+		f.ExpiresUnix, f.Retain = fpcc.ExpiresUnix, fpcc.Retain
+		f.CreatedUnix = fpcc.CreatedUnix
+		f.Transcoded = true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objectInfo(id, next, st.CreatedUnix), nil
+}
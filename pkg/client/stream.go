@@ -0,0 +1,486 @@
+// pkg/client/stream.go – streaming fragment transfer.
+// Objects are striped (erasure.EncodeStream) and fragments move over the
+// DisperseStream / RetrieveStream RPCs in chunks, so neither side ever holds
+// a whole object or fragment in memory.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/blockhash"
+	"github.com/dattu/distributed_object_store/pkg/erasure"
+	"github.com/dattu/distributed_object_store/pkg/fingerprint"
+	"github.com/dattu/distributed_object_store/pkg/merkle"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// streamChunk is the payload size of each streamed message.
+const streamChunk = 256 << 10
+
+// connPool dials each server once and shares the connection between
+// streams and calls for as long as the Client lives.
+type connPool struct {
+	timeout time.Duration // per dial
+	creds   credentials.TransportCredentials
+	mu      sync.Mutex
+	conns   map[string]*grpc.ClientConn
+}
+
+// newConnPool returns a pool dialing with tlsConf, or in plaintext if it is
+// nil.
+func newConnPool(timeout time.Duration, tlsConf *tls.Config) *connPool {
+	creds := insecure.NewCredentials()
+	if tlsConf != nil {
+		creds = credentials.NewTLS(tlsConf)
+	}
+	return &connPool{timeout: timeout, creds: creds, conns: make(map[string]*grpc.ClientConn)}
+}
+
+func (p *connPool) conn(ctx context.Context, addr string) (*grpc.ClientConn, error) {
+	addr = strings.TrimSpace(addr)
+	p.mu.Lock()
+	c, ok := p.conns[addr]
+	p.mu.Unlock()
+	if ok {
+		return c, nil
+	}
+	// dial without the lock, so one dead server does not hold up the rest
+	dctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	c, err := grpc.DialContext(dctx, addr, grpc.WithTransportCredentials(p.creds), grpc.WithBlock())
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cur, ok := p.conns[addr]; ok { // lost a race with another dial
+		c.Close()
+		return cur, nil
+	}
+	p.conns[addr] = c
+	return c, nil
+}
+
+func (p *connPool) client(ctx context.Context, addr string) (protocol.DispersalClient, error) {
+	c, err := p.conn(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	return protocol.NewDispersalClient(c), nil
+}
+
+func (p *connPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for addr, c := range p.conns {
+		c.Close()
+		delete(p.conns, addr)
+	}
+}
+
+// fragmentSum accumulates the FPCC entry of one fragment as it streams past.
+type fragmentSum struct {
+	h      hash.Hash
+	fp     *fingerprint.Stream
+	blocks *blockhash.Writer // nil unless block hashes are wanted
+}
+
+func newFragmentSum(fpGen *fingerprint.Fingerprint) *fragmentSum {
+	return &fragmentSum{h: sha256.New(), fp: fpGen.Stream()}
+}
+
+func (s *fragmentSum) Write(p []byte) (int, error) {
+	s.h.Write(p)
+	if s.blocks != nil {
+		s.blocks.Write(p)
+	}
+	return s.fp.Write(p)
+}
+
+func (s *fragmentSum) matches(fpcc *protocol.FPCC, idx int) bool {
+	return bytes.Equal(s.h.Sum(nil), fpcc.Hashes[idx]) && s.fp.Sum64() == fpcc.Fps[idx]
+}
+
+// fingerprintFile encodes the bytes of src without keeping any shard and
+// returns the FPCC of the resulting fragments, fingerprinted at seed – or
+// at a random point when seed is 0.
+func fingerprintFile(src source, enc erasure.Codec, stripe int, seed uint64) (*protocol.FPCC, error) {
+	f, err := src()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fpGen := fingerprint.NewWithSeed(seed)
+	if seed == 0 {
+		if fpGen, err = fingerprint.NewRandom(); err != nil {
+			return nil, err
+		}
+	}
+	m, n := enc.Shards()
+	sums := make([]*fragmentSum, n)
+	dst := make([]io.Writer, n)
+	for i := range sums {
+		sums[i] = newFragmentSum(fpGen)
+		sums[i].blocks = blockhash.New(stripe)
+		dst[i] = sums[i]
+	}
+	size, err := erasure.EncodeStream(enc, stripe, f, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	fpcc := &protocol.FPCC{
+		Hashes:  make([][]byte, n),
+		Fps:     make([]uint64, n),
+		Seed:    fpGen.Seed(),
+		Profile: &protocol.Profile{Data: uint32(m), Total: uint32(n), Codec: enc.Name(), Stripe: uint32(stripe)},
+		Size:    uint64(size),
+		Roots:   make([][]byte, n),
+	}
+	for i, s := range sums {
+		fpcc.Hashes[i] = s.h.Sum(nil)
+		fpcc.Fps[i] = s.fp.Sum64()
+		fpcc.Roots[i] = merkle.Root(s.blocks.Sums())
+	}
+	return fpcc, nil
+}
+
+// shardSender chunks one fragment onto a DisperseStream. After the first
+// failure it swallows further writes, so one bad owner does not stop the
+// encoder feeding the others.
+type shardSender struct {
+	stream protocol.Dispersal_DisperseStreamClient
+	head   *protocol.DisperseChunk // sent with the first chunk only
+	err    error
+}
+
+func (w *shardSender) Write(p []byte) (int, error) {
+	for off := 0; off < len(p) && w.err == nil; off += streamChunk {
+		msg := &protocol.DisperseChunk{Data: p[off:min(off+streamChunk, len(p))]}
+		if w.head != nil {
+			msg.ObjectId, msg.FragmentIndex, msg.Fpcc = w.head.ObjectId, w.head.FragmentIndex, w.head.Fpcc
+			w.head = nil
+		}
+		w.err = w.stream.Send(msg)
+	}
+	return len(p), nil
+}
+
+// streamShards encodes the bytes of src once and streams the shards listed
+// in idxs to their owners. report is called, possibly concurrently, with
+// each shard's outcome as soon as its owner answers; streamShards returns
+// after the last one.
+func streamShards(ctx context.Context, pool *connPool, src source, enc erasure.Codec, owners []string, id string, fpcc *protocol.FPCC, idxs []int, report func(idx int, err error)) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	_, n := enc.Shards()
+	senders := make([]*shardSender, n)
+	dst := make([]io.Writer, n)
+	for _, idx := range idxs {
+		w := &shardSender{head: &protocol.DisperseChunk{ObjectId: id, FragmentIndex: uint32(idx), Fpcc: fpcc}}
+		c, err := pool.client(ctx, owners[idx])
+		if err == nil {
+			w.stream, err = c.DisperseStream(ctx)
+		}
+		if err != nil {
+			report(idx, err)
+			continue
+		}
+		senders[idx], dst[idx] = w, w
+	}
+
+	f, err := src()
+	if err == nil {
+		_, err = erasure.EncodeStream(enc, int(fpcc.Profile.Stripe), f, dst)
+		f.Close()
+	}
+	for _, w := range senders { // an empty fragment still needs its header
+		if w != nil && w.err == nil && w.head != nil {
+			w.err = w.stream.Send(w.head)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for idx, w := range senders {
+		if w == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(idx int, w *shardSender) {
+			defer wg.Done()
+			switch {
+			case err != nil:
+				report(idx, fmt.Errorf("encode: %w", err))
+			case w.err != nil && w.err != io.EOF:
+				report(idx, w.err)
+			default:
+				// Send fails with io.EOF once the server has answered
+				// early, e.g. to refuse the FPCC: the answer says why
+				resp, rerr := w.stream.CloseAndRecv()
+				if rerr == nil && !resp.Ok {
+					rerr = errors.New(resp.Error)
+				}
+				report(idx, rerr)
+			}
+		}(idx, w)
+	}
+	wg.Wait()
+}
+
+// openFragment opens a RetrieveStream for fragment idx on the first of
+// addrs that holds it, returning the requested range (all of it when length
+// is 0) and the stream's first chunk, which carries the whole fragment's
+// length and any block proofs asked for.
+func openFragment(ctx context.Context, pool *connPool, addrs []string, id string, idx uint32, gen, offset, length uint64, proofs bool) (io.Reader, *protocol.RetrieveChunk, error) {
+	err := errors.New("no servers")
+	for _, addr := range addrs {
+		c, derr := pool.client(ctx, addr)
+		if derr != nil {
+			err = derr
+			continue
+		}
+		stream, serr := c.RetrieveStream(ctx, &protocol.RetrieveRequest{ObjectId: id, FragmentIndex: idx, Generation: gen, Offset: offset, Length: length, Proofs: proofs})
+		if serr != nil {
+			err = serr
+			continue
+		}
+		first, rerr := stream.Recv()
+		if rerr != nil {
+			err = rerr
+			continue
+		}
+		if !first.Ok {
+			err = fmt.Errorf("%s: %s", addr, first.Error)
+			continue
+		}
+		body := &chunkReader{buf: first.Data, next: func() ([]byte, error) {
+			c, err := stream.Recv()
+			if err != nil {
+				return nil, err // io.EOF at the end of the fragment
+			}
+			return c.Data, nil
+		}}
+		return body, first, nil
+	}
+	return nil, nil, err
+}
+
+// chunkReader presents a sequence of chunk payloads as one io.Reader.
+type chunkReader struct {
+	buf  []byte
+	next func() ([]byte, error)
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		data, err := r.next()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = data
+	}
+	k := copy(p, r.buf)
+	r.buf = r.buf[k:]
+	return k, nil
+}
+
+// decodeInto rewrites f with the object decoded from the fragments in pick,
+// passed through open unless it is nil. It returns the fragments that could
+// not be read or failed verification; an error with none means the codec
+// could not decode from pick, or a *layerError that open's layers could not
+// be undone.
+func decodeInto(ctx context.Context, f *os.File, pool *connPool, enc erasure.Codec, fpGen *fingerprint.Fingerprint, id string, fpcc *protocol.FPCC, open opener,
+	pick []int, candidates func(int) []string) ([]int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	m, n := enc.Shards()
+	src := make([]io.Reader, n)
+	sums := make([]*fragmentSum, n)
+	var length uint64
+	for _, idx := range pick {
+		r, first, err := openFragment(ctx, pool, candidates(idx), id, uint32(idx), fpcc.Generation, 0, 0, false)
+		if err != nil {
+			return []int{idx}, err
+		}
+		sums[idx] = newFragmentSum(fpGen)
+		src[idx] = io.TeeReader(r, sums[idx])
+		length = first.Length
+	}
+
+	stripe, size := int(fpcc.GetProfile().GetStripe()), int64(fpcc.Size)
+	if stripe == 0 {
+		stripe = int(length) // encoded in one piece: a single stripe
+		if size == 0 {
+			size = int64(length) * int64(m) // pre‑profile object: size unknown
+		}
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(0); err != nil {
+		return nil, err
+	}
+	var w io.Writer = f
+	closeLayers := func() error { return nil }
+	if open != nil {
+		var err error
+		if w, closeLayers, err = open(f); err != nil {
+			return nil, &layerError{err}
+		}
+	}
+	var decErr error
+	if size > 0 {
+		decErr = erasure.DecodeStream(enc, stripe, src, size, w)
+	}
+	if err := closeLayers(); decErr == nil { // closed either way: layers may run goroutines
+		decErr = err
+	}
+	var se *erasure.ShardError
+	if errors.As(decErr, &se) {
+		return []int{se.Index}, decErr
+	}
+
+	// a fragment only verifies once all of it has been read; a bad one is
+	// also the likeliest reason for a layer failing, so verify before
+	// blaming the codec or the layers
+	var failed []int
+	for _, idx := range pick {
+		if _, err := io.Copy(io.Discard, src[idx]); err != nil || !sums[idx].matches(fpcc, idx) {
+			failed = append(failed, idx)
+		}
+	}
+	if len(failed) > 0 {
+		return failed, fmt.Errorf("%w: fragments %v failed verification", ErrCorrupt, failed)
+	}
+	return nil, decErr
+}
+
+// blockReader serves range reads of a striped object one stripe at a time,
+// verifying every block it fetches by its inclusion proof under the
+// fragment's Merkle root.
+type blockReader struct {
+	ctx        context.Context
+	enc        erasure.Codec
+	stripe     int
+	id         string
+	fpcc       *protocol.FPCC
+	candidates func(idx int) []string
+	pool       *connPool
+	length     uint64 // of every fragment, by the FPCC's size and profile
+
+	blocks, fetched int64 // transfer accounting
+}
+
+// block fetches block s of fragment idx from the first server whose copy
+// verifies; nil when none does.
+func (r *blockReader) block(idx int, s int64) []byte {
+	root := r.fpcc.Roots[idx]
+	for _, addr := range r.candidates(idx) {
+		ctx, cancel := context.WithTimeout(r.ctx, 30*time.Second)
+		body, first, err := openFragment(ctx, r.pool, []string{addr}, r.id, uint32(idx), r.fpcc.Generation, uint64(s)*uint64(r.stripe), uint64(r.stripe), true)
+		var data []byte
+		if err == nil {
+			data, err = io.ReadAll(body)
+		}
+		cancel()
+		// the leaf count comes from the FPCC, not the length the server claims
+		if err != nil || first.Length != r.length || len(first.Proofs) != 1 || first.Proofs[0].Block != uint64(s) {
+			continue
+		}
+		r.blocks++
+		r.fetched += int64(len(data))
+		leaves := int(r.length / uint64(r.stripe))
+		if merkle.Verify(root, blockhash.Sum(data), int(s), leaves, first.Proofs[0].Path) {
+			return data
+		}
+	}
+	return nil
+}
+
+// span returns the object bytes sp covers. The data blocks holding them are
+// read directly; if one is lost or bad, any m verified blocks of the stripe
+// are decoded instead.
+func (r *blockReader) span(sp erasure.Span) ([]byte, error) {
+	m, n := r.enc.Shards()
+	first, last := sp.Shards(r.stripe)
+	shards := make([][]byte, n)
+	tried := make([]bool, n)
+	direct := true
+	for j := first; j <= last; j++ {
+		shards[j], tried[j] = r.block(j, sp.Stripe), true
+		direct = direct && shards[j] != nil
+	}
+	if direct {
+		out := make([]byte, 0, sp.Length)
+		for j := first; j <= last; j++ {
+			lo := max(sp.Offset-int64(j*r.stripe), 0)
+			hi := min(sp.Offset+sp.Length-int64(j*r.stripe), int64(r.stripe))
+			out = append(out, shards[j][lo:hi]...)
+		}
+		return out, nil
+	}
+
+	have := 0
+	for _, sh := range shards {
+		if sh != nil {
+			have++
+		}
+	}
+	for idx := 0; idx < n; idx++ {
+		if !tried[idx] {
+			if shards[idx] = r.block(idx, sp.Stripe); shards[idx] != nil {
+				have++
+			}
+		}
+		if have >= m {
+			// decode a copy, so a failed attempt leaves shards untouched
+			if data, err := r.enc.Decode(append([][]byte(nil), shards...), r.stripe*m); err == nil {
+				return data[sp.Offset : sp.Offset+sp.Length], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("only %d/%d good blocks; cannot decode", have, m)
+}
+
+// trimZeros truncates f after its last non‑zero byte.
+func trimZeros(f *os.File) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 64<<10)
+	end := info.Size()
+	for end > 0 {
+		off := max(end-int64(len(buf)), 0)
+		blk := buf[:end-off]
+		if _, err := f.ReadAt(blk, off); err != nil {
+			return err
+		}
+		for len(blk) > 0 && blk[len(blk)-1] == 0 {
+			blk = blk[:len(blk)-1]
+		}
+		end = off + int64(len(blk))
+		if len(blk) > 0 {
+			break
+		}
+	}
+	return f.Truncate(end)
+}
@@ -0,0 +1,118 @@
+// pkg/client/versions.go – reading older versions of an object. Every Put
+// of an ID adds a version; servers keep the ones before the current as
+// their retention allows. A version is named by its VersionID, the hex
+// digest of its FPCC.
+
+package client
+
+import (
+	"cmp"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/membership"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+// parseVersion decodes a VersionID; "" is the current version.
+func parseVersion(version string) ([]byte, error) {
+	if version == "" {
+		return nil, nil
+	}
+	digest, err := hex.DecodeString(version)
+	if err != nil || len(digest) == 0 {
+		return nil, fmt.Errorf("%w: bad version %q", ErrInvalid, version)
+	}
+	return digest, nil
+}
+
+// StatVersion is Stat for the version of id named version; "" is the
+// current one.
+func (c *Client) StatVersion(ctx context.Context, id, version string) (*ObjectInfo, error) {
+	digest, err := parseVersion(version)
+	if err != nil {
+		return nil, wrap("stat", id, err)
+	}
+	st, err := c.stat(ctx, membership.Addrs(c.liveView(ctx)), id, digest)
+	if err != nil {
+		return nil, wrap("stat", id, err)
+	}
+	return objectInfo(id, st.Fpcc, st.CreatedUnix), nil
+}
+
+// GetVersion is Get, or GetRange when off or length is set, for the
+// version of id named version; "" is the current one.
+func (c *Client) GetVersion(ctx context.Context, id, version string, w io.Writer, off, length int64) (*ObjectInfo, error) {
+	digest, err := parseVersion(version)
+	if err != nil {
+		return nil, wrap("get", id, err)
+	}
+	var info *ObjectInfo
+	if off == 0 && length == 0 {
+		info, err = c.get(ctx, id, digest, w)
+	} else {
+		info, err = c.getRange(ctx, id, digest, w, off, length)
+	}
+	return info, wrap("get", id, err)
+}
+
+// Versions returns the versions of id the servers keep, newest first: the
+// current one, then those it superseded. Every server is asked and their
+// answers merged, since each only keeps the versions placed on it.
+func (c *Client) Versions(ctx context.Context, id string) ([]*ObjectInfo, error) {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		merged   = make(map[string]*protocol.VersionEntry)
+		answered int
+	)
+	for _, addr := range membership.Addrs(c.liveView(ctx)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dc, err := c.pool.client(ctx, addr)
+			if err != nil {
+				return
+			}
+			resp, err := dc.Versions(ctx, &protocol.VersionsRequest{ObjectId: id})
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			answered++
+			for _, e := range resp.Versions {
+				// a server that missed a commit still counts its version current
+				key := string(e.Fpcc.Digest())
+				if cur := merged[key]; cur == nil || e.SupersededUnix > cur.SupersededUnix {
+					merged[key] = e
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, wrap("versions", id, err)
+	}
+	switch {
+	case answered == 0:
+		return nil, wrap("versions", id, ErrUnavailable)
+	case len(merged) == 0:
+		return nil, wrap("versions", id, ErrNotFound)
+	}
+	out := make([]*ObjectInfo, 0, len(merged))
+	for _, e := range merged {
+		info := objectInfo(id, e.Fpcc, e.CreatedUnix)
+		if e.SupersededUnix != 0 {
+			info.Superseded = time.Unix(e.SupersededUnix, 0)
+		}
+		out = append(out, info)
+	}
+	slices.SortFunc(out, func(a, b *ObjectInfo) int { return cmp.Compare(b.Generation, a.Generation) })
+	return out, nil
+}
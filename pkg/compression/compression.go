@@ -0,0 +1,134 @@
+// pkg/compression/compression.go
+// Package compression squeezes objects before they are erasure coded, so
+// compressible data costs n/m times its compressed size rather than its raw
+// size. Codecs are looked up by the name recorded in the object's FPCC.
+// The standard library only offers DEFLATE; a zstd codec can be added with
+// Register where the dependency is available.
+package compression
+
+import (
+	"compress/flate"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Flate is raw DEFLATE (RFC 1951) from the standard library.
+const Flate = "flate"
+
+// Codec builds streaming compressors and decompressors. Compression must be
+// deterministic – disperse compresses the input twice and both passes must
+// yield the same bytes.
+type Codec struct {
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+var registry = map[string]Codec{
+	Flate: {
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) { return flate.NewWriter(w, flate.DefaultCompression) },
+		NewReader: func(r io.Reader) (io.ReadCloser, error) { return flate.NewReader(r), nil },
+	},
+}
+
+// Register adds a codec under name.
+func Register(name string, c Codec) {
+	registry[name] = c
+}
+
+// Names lists the registered codecs.
+func Names() []string {
+	out := make([]string, 0, len(registry))
+	for name := range registry {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Lookup returns the codec registered under name.
+func Lookup(name string) (Codec, error) {
+	c, ok := registry[name]
+	if !ok {
+		return Codec{}, fmt.Errorf("unknown compression %q", name)
+	}
+	return c, nil
+}
+
+// SampleSize is how much of an object Worthwhile wants to see.
+const SampleSize = 256 << 10
+
+// Worthwhile guesses from a sample of an object whether compressing it
+// pays: already-compressed formats (archives, images, video, encrypted
+// data) shrink by a few percent at best and are stored as they are.
+func Worthwhile(sample []byte) bool {
+	if len(sample) == 0 {
+		return false
+	}
+	var n countWriter
+	w, _ := flate.NewWriter(&n, flate.BestSpeed)
+	w.Write(sample)
+	w.Close()
+	return int64(n) < int64(len(sample))*9/10
+}
+
+type countWriter int64
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	*c += countWriter(len(p))
+	return len(p), nil
+}
+
+// Compress returns a reader of r compressed with c.
+func (c Codec) Compress(r io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		w, err := c.NewWriter(pw)
+		if err == nil {
+			if _, err = io.Copy(w, r); err == nil {
+				err = w.Close()
+			}
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// Decompress returns a writer that decompresses what it is given into w.
+// Close fails unless the stream decompressed to exactly size bytes.
+func (c Codec) Decompress(w io.Writer, size int64) io.WriteCloser {
+	pr, pw := io.Pipe()
+	d := &decompressor{pw: pw, done: make(chan error, 1)}
+	go func() {
+		r, err := c.NewReader(pr)
+		if err != nil {
+			pr.CloseWithError(err)
+			d.done <- err
+			return
+		}
+		n, err := io.Copy(w, io.LimitReader(r, size+1))
+		if err == nil && n != size {
+			err = fmt.Errorf("decompressed %d bytes, want %d", n, size)
+		}
+		if err == nil {
+			_, err = io.Copy(io.Discard, pr) // let the writer finish
+		}
+		pr.CloseWithError(err)
+		d.done <- err
+	}()
+	return d
+}
+
+type decompressor struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func (d *decompressor) Write(p []byte) (int, error) {
+	return d.pw.Write(p)
+}
+
+func (d *decompressor) Close() error {
+	d.pw.Close()
+	return <-d.done
+}
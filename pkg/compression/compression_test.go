@@ -0,0 +1,72 @@
+// pkg/compression/compression_test.go
+package compression
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFlateRoundTrip(t *testing.T) {
+	c, err := Lookup(Flate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := []byte(strings.Repeat(`{"level":"info","msg":"request served","status":200}`+"\n", 5000))
+	packed, err := io.ReadAll(c.Compress(bytes.NewReader(plain)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packed) >= len(plain)/5 {
+		t.Errorf("log lines compressed only to %d/%d bytes", len(packed), len(plain))
+	}
+	again, _ := io.ReadAll(c.Compress(bytes.NewReader(plain)))
+	if !bytes.Equal(packed, again) {
+		t.Fatalf("compression is not deterministic")
+	}
+
+	var out bytes.Buffer
+	w := c.Decompress(&out, int64(len(plain)))
+	for p := packed; len(p) > 0; p = p[min(1000, len(p)):] {
+		if _, err := w.Write(p[:min(1000, len(p))]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil || !bytes.Equal(out.Bytes(), plain) {
+		t.Fatalf("round trip: %v", err)
+	}
+
+	w = c.Decompress(io.Discard, int64(len(plain))+1)
+	w.Write(packed)
+	if err := w.Close(); err == nil {
+		t.Errorf("size mismatch went unnoticed")
+	}
+	w = c.Decompress(io.Discard, int64(len(plain)))
+	w.Write(packed[:len(packed)/2])
+	if err := w.Close(); err == nil {
+		t.Errorf("truncated stream went unnoticed")
+	}
+}
+
+func TestWorthwhile(t *testing.T) {
+	random := make([]byte, SampleSize)
+	rand.Read(random)
+	if Worthwhile(random) {
+		t.Errorf("random bytes judged compressible")
+	}
+	packed, _ := io.ReadAll(registry[Flate].Compress(bytes.NewReader(bytes.Repeat([]byte("abc"), SampleSize))))
+	if Worthwhile(append(packed, random[:SampleSize-len(packed)]...)) {
+		t.Errorf("compressed data judged compressible")
+	}
+	if !Worthwhile([]byte(strings.Repeat("GET /index.html 200\n", 1000))) {
+		t.Errorf("log lines judged incompressible")
+	}
+	if Worthwhile(nil) {
+		t.Errorf("empty sample judged compressible")
+	}
+	if _, err := Lookup("lz77"); err == nil {
+		t.Errorf("unknown codec found")
+	}
+}
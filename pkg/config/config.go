@@ -1,6 +1,10 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/spf13/viper"
@@ -8,28 +12,137 @@ import (
 
 type Config struct {
     Cluster struct {
-        Peers []string `mapstructure:"peers"`
-        Self  string   `mapstructure:"self"`
+        Peers    []string   `mapstructure:"peers"`
+        Self     string     `mapstructure:"self"`
+        Topology []NodeSpec `mapstructure:"topology"`
     } `mapstructure:"cluster"`
 
+    Placement struct {
+        FailureDomain string `mapstructure:"failure_domain"` // label key, e.g. "zone"
+    } `mapstructure:"placement"`
+
     Erasure struct {
-        Data  int `mapstructure:"data"`
-        Total int `mapstructure:"total"`
+        Data  int    `mapstructure:"data"`
+        Total int    `mapstructure:"total"`
+        Codec string `mapstructure:"codec"` // "rs" or "lrc"; see erasure.Names
     } `mapstructure:"erasure"`
 
     Object struct {
         TTL time.Duration `mapstructure:"ttl"`
     } `mapstructure:"object"`
 
+    // Lifecycle rules, applied by the servers' GC to the objects under each
+    // prefix; an object's own expiry (FPCC.expires_unix, retain) wins
+    Lifecycle []LifecycleRule `mapstructure:"lifecycle"`
+
+    Versioning struct { // what servers keep of an object written again under its ID
+        Keep          int           `mapstructure:"keep"`           // noncurrent versions kept per object; 0 = none
+        NoncurrentTTL time.Duration `mapstructure:"noncurrent_ttl"` // drop versions superseded longer ago; 0 = only object.ttl
+    } `mapstructure:"versioning"`
+
     Storage struct {
         Datadir string `mapstructure:"datadir"`
         DB      string `mapstructure:"db"`
+        // at-rest encryption: fragments and FPCCs are sealed under KeyFile;
+        // OldKeyFiles still open data sealed before a rotation
+        KeyFile     string   `mapstructure:"key_file"`
+        OldKeyFiles []string `mapstructure:"old_key_files"`
     } `mapstructure:"storage"`
 
+    Encryption struct { // client side only; servers never see the keys
+        Encrypt       bool     `mapstructure:"encrypt"`        // seal new objects by default
+        KeyFiles      []string `mapstructure:"key_files"`      // first one seals, all of them open
+        PassphraseEnv string   `mapstructure:"passphrase_env"` // env var holding a passphrase
+    } `mapstructure:"encryption"`
+
+    Compression struct { // client side: compress new objects with this codec
+        Codec string `mapstructure:"codec"` // "" = off; see compression.Names
+    } `mapstructure:"compression"`
+
+    Dedup struct { // client side: store new objects as manifests of shared chunks
+        Enabled bool `mapstructure:"enabled"`
+    } `mapstructure:"dedup"`
+
+    TLS struct { // gRPC between nodes and from clients; off unless CAFile is set
+        CAFile   string `mapstructure:"ca_file"`   // signs every node's certificate
+        CertFile string `mapstructure:"cert_file"` // required on nodes: common name host:port, SAN host
+        KeyFile  string `mapstructure:"key_file"`
+    } `mapstructure:"tls"`
+
     Server struct {
         GRPCPort    int `mapstructure:"grpc_port"`
         MetricsPort int `mapstructure:"metrics_port"`
+        HTTPPort    int `mapstructure:"http_port"` // REST/JSON object API; 0 = off
     } `mapstructure:"server"`
+
+    S3 struct { // cmd/s3gw: S3 REST gateway in front of the cluster
+        Listen      string   `mapstructure:"listen"`      // host:port of the HTTP endpoint
+        Credentials string   `mapstructure:"credentials"` // AWS-style credentials file
+        Region      string   `mapstructure:"region"`
+        Buckets     []string `mapstructure:"buckets"`
+        Domain      string   `mapstructure:"domain"` // <bucket>.<domain> for virtual-hosted-style requests
+    } `mapstructure:"s3"`
+}
+
+// LifecycleRule acts on the objects whose IDs start with Prefix; where
+// several rules match, the one with the longest prefix applies. Expire
+// replaces object.ttl for them; Transcode re‑encodes them to Codec
+// Data‑of‑Total once they are that old. A zero duration leaves the action
+// out.
+type LifecycleRule struct {
+    Prefix    string        `mapstructure:"prefix"`
+    Expire    time.Duration `mapstructure:"expire"`
+    Transcode time.Duration `mapstructure:"transcode"`
+    Codec     string        `mapstructure:"codec"` // "" = erasure.codec
+    Data      int           `mapstructure:"data"`
+    Total     int           `mapstructure:"total"`
+}
+
+// TLSConfig returns the TLS settings for gRPC, nil when tls.ca_file is
+// unset. One config serves both ends: a node presents its certificate and
+// checks those its peers present, which name the sender of every Echo and
+// Ready; a client without a certificate of its own still checks the nodes'.
+func (c *Config) TLSConfig() (*tls.Config, error) {
+    if c.TLS.CAFile == "" {
+        return nil, nil
+    }
+    pem, err := os.ReadFile(c.TLS.CAFile)
+    if err != nil {
+        return nil, err
+    }
+    pool := x509.NewCertPool()
+    if !pool.AppendCertsFromPEM(pem) {
+        return nil, fmt.Errorf("tls.ca_file %s: no certificates", c.TLS.CAFile)
+    }
+    tc := &tls.Config{
+        RootCAs:    pool,
+        ClientCAs:  pool,
+        ClientAuth: tls.VerifyClientCertIfGiven, // clients need none; Echo / Ready do
+        MinVersion: tls.VersionTLS12,
+    }
+    if c.TLS.CertFile != "" {
+        cert, err := tls.LoadX509KeyPair(c.TLS.CertFile, c.TLS.KeyFile)
+        if err != nil {
+            return nil, err
+        }
+        tc.Certificates = []tls.Certificate{cert}
+    }
+    return tc, nil
+}
+
+// NodeSpec attaches topology labels (zone, rack, host, …) to one peer.
+type NodeSpec struct {
+    Addr   string            `mapstructure:"addr"`
+    Labels map[string]string `mapstructure:"labels"`
+}
+
+// Labels returns the topology labels keyed by peer address.
+func (c *Config) Labels() map[string]map[string]string {
+    out := make(map[string]map[string]string, len(c.Cluster.Topology))
+    for _, n := range c.Cluster.Topology {
+        out[n.Addr] = n.Labels
+    }
+    return out
 }
 
 func Load(path string) (*Config, error) {
@@ -49,13 +162,33 @@ func Load(path string) (*Config, error) {
 
     // ➌ Hard defaults (match old behaviour)
     v.SetDefault("cluster.peers", []string{})
+    v.SetDefault("placement.failure_domain", "")
     v.SetDefault("erasure.data", 3)
     v.SetDefault("erasure.total", 5)
+    v.SetDefault("erasure.codec", "rs")
     v.SetDefault("object.ttl", "24h")
+    v.SetDefault("versioning.keep", 10)
+    v.SetDefault("versioning.noncurrent_ttl", "0s")
     v.SetDefault("storage.datadir", "data")
     v.SetDefault("storage.db", "store.db")
+    v.SetDefault("storage.key_file", "")
+    v.SetDefault("storage.old_key_files", []string{})
+    v.SetDefault("encryption.encrypt", false)
+    v.SetDefault("encryption.key_files", []string{})
+    v.SetDefault("encryption.passphrase_env", "")
+    v.SetDefault("compression.codec", "")
+    v.SetDefault("dedup.enabled", false)
+    v.SetDefault("tls.ca_file", "")
+    v.SetDefault("tls.cert_file", "")
+    v.SetDefault("tls.key_file", "")
     v.SetDefault("server.grpc_port", 50051)
     v.SetDefault("server.metrics_port", 9102)
+    v.SetDefault("server.http_port", 0)
+    v.SetDefault("s3.listen", ":9000")
+    v.SetDefault("s3.credentials", "")
+    v.SetDefault("s3.region", "us-east-1")
+    v.SetDefault("s3.buckets", []string{})
+    v.SetDefault("s3.domain", "")
 
     var cfg Config
     if err := v.Unmarshal(&cfg); err != nil {
@@ -0,0 +1,196 @@
+// pkg/envelope/envelope.go
+// Package envelope encrypts objects on the client before they are erasure
+// coded. Each object is sealed with its own random AES-256-GCM data key;
+// the data key is stored in the FPCC wrapped by a key-encryption key (KEK)
+// that only clients hold – a local key file or a passphrase.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+const (
+	// KeySize is the length of data keys and KEKs (AES-256).
+	KeySize = 32
+	// PassphraseID is the key ID of passphrase-derived KEKs.
+	PassphraseID = "passphrase"
+	// Iterations is the PBKDF2-HMAC-SHA256 round count for new passphrase
+	// envelopes; older envelopes record their own.
+	Iterations = 600_000
+
+	wrapDomain = "avid-fp/envelope/v1"
+	kekDomain  = "avid-fp/kek/v1"
+	saltSize   = 16
+)
+
+var (
+	// ErrNoKey means the keyring holds no KEK for an envelope.
+	ErrNoKey = errors.New("no key for envelope")
+	// ErrAuth means a wrapped key or a segment failed authentication:
+	// the wrong KEK, or data that was tampered with.
+	ErrAuth = errors.New("authentication failed")
+)
+
+// Keyring holds the KEKs a client can wrap and unwrap data keys with.
+type Keyring struct {
+	keys    map[string][]byte // key-file KEKs by ID
+	primary string            // key file new objects are sealed under
+	pass    []byte
+}
+
+// NewKeyring returns an empty keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string][]byte)}
+}
+
+// KeyID names a KEK without revealing it: a truncated hash of the key.
+func KeyID(kek []byte) string {
+	h := sha256.Sum256(append([]byte(kekDomain), kek...))
+	return hex.EncodeToString(h[:8])
+}
+
+// AddFile loads the KEK in path – 32 raw bytes or 64 hex digits – and
+// returns its ID. The first file added seals new objects; the others only
+// open existing ones, which is how a KEK is rotated out.
+func (k *Keyring) AddFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	kek := data
+	if len(data) != KeySize {
+		if kek, err = hex.DecodeString(strings.TrimSpace(string(data))); err != nil || len(kek) != KeySize {
+			return "", fmt.Errorf("%s: want %d raw bytes or %d hex digits", path, KeySize, 2*KeySize)
+		}
+	}
+	id := KeyID(kek)
+	k.keys[id] = kek
+	if k.primary == "" {
+		k.primary = id
+	}
+	return id, nil
+}
+
+// SetPassphrase adds a passphrase; new objects are sealed under it only
+// when no key file was added.
+func (k *Keyring) SetPassphrase(pass string) {
+	k.pass = []byte(pass)
+}
+
+// Empty reports whether the keyring can neither seal nor open anything.
+func (k *Keyring) Empty() bool {
+	return len(k.keys) == 0 && len(k.pass) == 0
+}
+
+// Seal creates a fresh data key for objectID and returns it with the
+// envelope recording it; the envelope's Size is left for the caller.
+func (k *Keyring) Seal(objectID string) (*protocol.Envelope, []byte, error) {
+	env := &protocol.Envelope{KeyId: k.primary, Segment: DefaultSegment}
+	kek := k.keys[k.primary]
+	if kek == nil {
+		if len(k.pass) == 0 {
+			return nil, nil, ErrNoKey
+		}
+		env.KeyId, env.Iterations, env.Salt = PassphraseID, Iterations, random(saltSize)
+		kek = pbkdf2(k.pass, env.Salt, int(env.Iterations))
+	}
+	dk := random(KeySize)
+	aead := newGCM(kek)
+	nonce := random(aead.NonceSize())
+	env.WrappedKey = aead.Seal(nonce, nonce, dk, wrapAAD(objectID, env.KeyId))
+	return env, dk, nil
+}
+
+// Open unwraps the data key of objectID's envelope.
+func (k *Keyring) Open(objectID string, env *protocol.Envelope) ([]byte, error) {
+	var kek []byte
+	switch {
+	case env.KeyId == PassphraseID && len(k.pass) > 0:
+		kek = pbkdf2(k.pass, env.Salt, int(env.Iterations))
+	case k.keys[env.KeyId] != nil:
+		kek = k.keys[env.KeyId]
+	default:
+		return nil, fmt.Errorf("%w %q", ErrNoKey, env.KeyId)
+	}
+	aead := newGCM(kek)
+	w := env.WrappedKey
+	if len(w) < aead.NonceSize() {
+		return nil, fmt.Errorf("wrapped key: %w", ErrAuth)
+	}
+	dk, err := aead.Open(nil, w[:aead.NonceSize()], w[aead.NonceSize():], wrapAAD(objectID, env.KeyId))
+	if err != nil || len(dk) != KeySize {
+		return nil, fmt.Errorf("wrapped key: %w", ErrAuth)
+	}
+	return dk, nil
+}
+
+// GenerateKey writes a new random KEK to path as hex, refusing to
+// overwrite an existing file, and returns its ID.
+func GenerateKey(path string) (string, error) {
+	kek := random(KeySize)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := fmt.Fprintln(f, hex.EncodeToString(kek)); err != nil {
+		f.Close()
+		return "", err
+	}
+	return KeyID(kek), f.Close()
+}
+
+// wrapAAD binds a wrapped key to its object and KEK, so an envelope copied
+// onto another object does not open.
+func wrapAAD(objectID, keyID string) []byte {
+	return []byte(wrapDomain + "\x00" + objectID + "\x00" + keyID)
+}
+
+func newGCM(key []byte) cipher.AEAD {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err) // keys are always KeySize
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return aead
+}
+
+func random(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// pbkdf2 derives a KEK from a passphrase with PBKDF2-HMAC-SHA256 (RFC 8018);
+// one SHA-256 block is exactly KeySize.
+func pbkdf2(pass, salt []byte, iter int) []byte {
+	prf := hmac.New(sha256.New, pass)
+	prf.Write(salt)
+	prf.Write([]byte{0, 0, 0, 1})
+	u := prf.Sum(nil)
+	t := append([]byte(nil), u...)
+	for i := 1; i < iter; i++ {
+		prf.Reset()
+		prf.Write(u)
+		u = prf.Sum(u[:0])
+		for j := range t {
+			t[j] ^= u[j]
+		}
+	}
+	return t
+}
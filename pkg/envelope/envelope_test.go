@@ -0,0 +1,175 @@
+// pkg/envelope/envelope_test.go
+package envelope
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+func TestPBKDF2Vectors(t *testing.T) {
+	for _, v := range []struct {
+		pass, salt string
+		iter       int
+		want       string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56"},
+	} {
+		if got := hex.EncodeToString(pbkdf2([]byte(v.pass), []byte(v.salt), v.iter)); got != v.want {
+			t.Errorf("pbkdf2(%q, %q, %d) = %s, want %s", v.pass, v.salt, v.iter, got, v.want)
+		}
+	}
+}
+
+func TestKeyringWrapsPerObject(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kek")
+	id, err := GenerateKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GenerateKey(path); err == nil {
+		t.Fatalf("GenerateKey overwrote an existing key")
+	}
+	k := NewKeyring()
+	if got, err := k.AddFile(path); err != nil || got != id {
+		t.Fatalf("AddFile = %q, %v; want %q", got, err, id)
+	}
+
+	env, dk, err := k.Seal("obj")
+	if err != nil || env.KeyId != id {
+		t.Fatalf("Seal: %v (key %q)", err, env.GetKeyId())
+	}
+	if got, err := k.Open("obj", env); err != nil || !bytes.Equal(got, dk) {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := k.Open("other", env); !errors.Is(err, ErrAuth) {
+		t.Errorf("envelope opened for another object: %v", err)
+	}
+	if _, err := NewKeyring().Open("obj", env); !errors.Is(err, ErrNoKey) {
+		t.Errorf("empty keyring: %v", err)
+	}
+
+	// a raw 32-byte key file works too, and only the first file seals
+	raw := filepath.Join(dir, "raw")
+	os.WriteFile(raw, bytes.Repeat([]byte{7}, KeySize), 0600)
+	if _, err := k.AddFile(raw); err != nil {
+		t.Fatal(err)
+	}
+	if env2, _, _ := k.Seal("obj"); env2.KeyId != id {
+		t.Errorf("second key file became primary")
+	}
+}
+
+func TestPassphraseEnvelope(t *testing.T) {
+	k := NewKeyring()
+	k.SetPassphrase("correct horse")
+	env, dk, err := k.Seal("obj")
+	if err != nil || env.KeyId != PassphraseID || len(env.Salt) == 0 {
+		t.Fatalf("Seal: %v %+v", err, env)
+	}
+	env.Iterations = 1000 // keep the test fast: re-wrap under fewer rounds
+	aead := newGCM(pbkdf2([]byte("correct horse"), env.Salt, 1000))
+	nonce := random(aead.NonceSize())
+	env.WrappedKey = aead.Seal(nonce, nonce, dk, wrapAAD("obj", PassphraseID))
+	if got, err := k.Open("obj", env); err != nil || !bytes.Equal(got, dk) {
+		t.Fatalf("Open: %v", err)
+	}
+	k.SetPassphrase("wrong")
+	if _, err := k.Open("obj", env); !errors.Is(err, ErrAuth) {
+		t.Errorf("wrong passphrase: %v", err)
+	}
+}
+
+func seal(t *testing.T, plain []byte, segment int) ([]byte, []byte, *protocol.Envelope) {
+	t.Helper()
+	dk := random(KeySize)
+	r := NewReader(bytes.NewReader(plain), dk, segment)
+	sealed, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if OpenedSize(int64(len(sealed)), segment) != int64(len(plain)) {
+		t.Fatalf("OpenedSize(%d) = %d, want %d", len(sealed), OpenedSize(int64(len(sealed)), segment), len(plain))
+	}
+	if int64(len(sealed)) != SealedSize(int64(len(plain)), segment) || r.Size() != int64(len(plain)) {
+		t.Fatalf("%d bytes sealed to %d, want %d", len(plain), len(sealed), SealedSize(int64(len(plain)), segment))
+	}
+	return sealed, dk, &protocol.Envelope{Segment: uint32(segment), Size: uint64(len(plain))}
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	const segment = 100
+	for _, size := range []int{0, 1, 99, 100, 101, 1000, 1234} {
+		plain := make([]byte, size)
+		rand.Read(plain)
+		sealed, dk, env := seal(t, plain, segment)
+
+		var out bytes.Buffer
+		w, err := NewWriter(&out, dk, env, 0, int64(size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for p := sealed; len(p) > 0; p = p[min(37, len(p)):] { // odd write sizes
+			if _, err := w.Write(p[:min(37, len(p))]); err != nil {
+				t.Fatalf("size %d: %v", size, err)
+			}
+		}
+		if err := w.Close(); err != nil || !bytes.Equal(out.Bytes(), plain) {
+			t.Fatalf("size %d: round trip failed: %v", size, err)
+		}
+	}
+}
+
+func TestStreamRanges(t *testing.T) {
+	plain := make([]byte, 1234)
+	rand.Read(plain)
+	sealed, dk, env := seal(t, plain, 100)
+	for _, r := range [][2]int64{{0, 1}, {99, 2}, {150, 0}, {200, 100}, {1200, 34}, {0, 1234}, {1234, 0}} {
+		lo, n := Range(env, r[0], r[1])
+		var out bytes.Buffer
+		w, err := NewWriter(&out, dk, env, r[0], r[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(sealed[lo : lo+n])
+		if err := w.Close(); err != nil || !bytes.Equal(out.Bytes(), plain[r[0]:r[0]+r[1]]) {
+			t.Errorf("range %v: %v", r, err)
+		}
+	}
+}
+
+func TestStreamTampering(t *testing.T) {
+	plain := make([]byte, 450)
+	rand.Read(plain)
+	sealed, dk, env := seal(t, plain, 100)
+	open := func(c []byte) error {
+		w, _ := NewWriter(io.Discard, dk, env, 0, int64(len(plain)))
+		if _, err := w.Write(c); err != nil {
+			return err
+		}
+		return w.Close()
+	}
+	flipped := bytes.Clone(sealed)
+	flipped[150] ^= 1
+	swapped := append(bytes.Clone(sealed[116:232]), sealed[:116]...)
+	swapped = append(swapped, sealed[232:]...)
+	for name, c := range map[string][]byte{
+		"flipped bit":       flipped,
+		"swapped segments":  swapped,
+		"dropped last":      sealed[:4*116],
+		"truncated segment": sealed[:len(sealed)-1],
+	} {
+		if err := open(c); !errors.Is(err, ErrAuth) {
+			t.Errorf("%s: got %v, want ErrAuth", name, err)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// pkg/envelope/stream.go
+package envelope
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+const (
+	// DefaultSegment is the plaintext sealed per GCM call. Segments keep
+	// encryption streaming and let a range read open only the segments it
+	// covers.
+	DefaultSegment = 64 << 10
+	// Overhead is the GCM tag each segment adds.
+	Overhead = 16
+)
+
+// Segment i is sealed under nonce i‖final with final set on the last one
+// only, so segments cannot be reordered, dropped or cut off at the end
+// without failing authentication. Data keys are never reused across
+// objects, which makes a counter nonce safe.
+func nonce(i uint64, final bool) []byte {
+	n := make([]byte, 12)
+	binary.BigEndian.PutUint64(n, i)
+	if final {
+		n[8] = 1
+	}
+	return n
+}
+
+// segments is the number of segments a size-byte plaintext seals into; an
+// empty one is still one (empty, final) segment.
+func segments(size int64, segment int) int64 {
+	return max((size+int64(segment)-1)/int64(segment), 1)
+}
+
+// SealedSize is the ciphertext length of a size-byte plaintext.
+func SealedSize(size int64, segment int) int64 {
+	return size + segments(size, segment)*Overhead
+}
+
+// OpenedSize is the plaintext length of a sealed size-byte ciphertext.
+func OpenedSize(sealed int64, segment int) int64 {
+	per := int64(segment) + Overhead
+	return sealed - (sealed+per-1)/per*Overhead
+}
+
+// Range returns the ciphertext bytes [off, off+length) of the plaintext
+// live in: the whole segments holding them.
+func Range(env *protocol.Envelope, off, length int64) (int64, int64) {
+	seg := int64(env.Segment)
+	last := segments(int64(env.Size), int(seg)) - 1
+	first := min(off/seg, last)
+	end := min(max(off+length-1, off)/seg, last)
+	lo := first * (seg + Overhead)
+	hi := min((end+1)*(seg+Overhead), SealedSize(int64(env.Size), int(seg)))
+	return lo, hi - lo
+}
+
+// Reader seals the plaintext it reads from r.
+type Reader struct {
+	r       io.Reader
+	aead    cipher.AEAD
+	segment int
+	idx     uint64
+	plain   []byte // next segment plus one byte of lookahead
+	fill    int
+	sealed  []byte
+	out     []byte // sealed bytes not yet read
+	size    int64
+	done    bool
+}
+
+// NewReader returns a Reader sealing r under data key dk in segments of
+// segment bytes.
+func NewReader(r io.Reader, dk []byte, segment int) *Reader {
+	return &Reader{r: r, aead: newGCM(dk), segment: segment, plain: make([]byte, segment+1)}
+}
+
+// Size is the plaintext read so far.
+func (r *Reader) Size() int64 { return r.size }
+
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.seal(); err != nil {
+			return 0, err
+		}
+	}
+	k := copy(p, r.out)
+	r.out = r.out[k:]
+	return k, nil
+}
+
+// seal reads up to the next segment and seals it; the lookahead byte tells
+// whether it is the last.
+func (r *Reader) seal() error {
+	k, err := io.ReadFull(r.r, r.plain[r.fill:])
+	r.fill += k
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	body := r.plain[:min(r.fill, r.segment)]
+	final := r.fill <= r.segment
+	r.sealed = r.aead.Seal(r.sealed[:0], nonce(r.idx, final), body, nil)
+	r.out = r.sealed
+	r.size += int64(len(body))
+	r.fill = copy(r.plain, r.plain[len(body):r.fill])
+	r.idx++
+	r.done = final
+	return nil
+}
+
+// Writer opens the ciphertext written to it and writes plaintext to w.
+type Writer struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	segment int
+	idx     uint64 // next segment to open
+	last    uint64
+	skip    int64 // plaintext to drop before the range
+	left    int64 // plaintext of the range still to write
+	buf     []byte
+	plain   []byte
+}
+
+// NewWriter returns a Writer for plaintext bytes [off, off+length) of the
+// object env describes. It expects exactly the ciphertext Range reports for
+// them; Close checks that all of it arrived.
+func NewWriter(w io.Writer, dk []byte, env *protocol.Envelope, off, length int64) (*Writer, error) {
+	size, seg := int64(env.Size), int64(env.Segment)
+	if seg <= 0 {
+		return nil, fmt.Errorf("invalid segment size %d", seg)
+	}
+	if off < 0 || length < 0 || off+length > size {
+		return nil, fmt.Errorf("range %d+%d outside the %d-byte plaintext", off, length, size)
+	}
+	first := min(off/seg, segments(size, int(seg))-1)
+	return &Writer{
+		w:       w,
+		aead:    newGCM(dk),
+		segment: int(seg),
+		idx:     uint64(first),
+		last:    uint64(segments(size, int(seg)) - 1),
+		skip:    off - first*seg,
+		left:    length,
+		buf:     make([]byte, 0, int(seg)+Overhead),
+	}, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		k := min(cap(w.buf)-len(w.buf), len(p))
+		w.buf = append(w.buf, p[:k]...)
+		p = p[k:]
+		if len(w.buf) == cap(w.buf) {
+			if err := w.open(); err != nil {
+				return n - len(p), err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close opens a final short segment and fails if the ciphertext ended
+// before the range did.
+func (w *Writer) Close() error {
+	if len(w.buf) > 0 {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+	if w.left > 0 {
+		return fmt.Errorf("ciphertext ends %d bytes short: %w", w.left, ErrAuth)
+	}
+	return nil
+}
+
+func (w *Writer) open() error {
+	if w.idx > w.last {
+		return fmt.Errorf("ciphertext runs past the last segment: %w", ErrAuth)
+	}
+	plain, err := w.aead.Open(w.plain[:0], nonce(w.idx, w.idx == w.last), w.buf, nil)
+	if err != nil {
+		return fmt.Errorf("segment %d: %w", w.idx, ErrAuth)
+	}
+	w.plain, w.buf = plain, w.buf[:0]
+	w.idx++
+
+	drop := min(w.skip, int64(len(plain)))
+	w.skip -= drop
+	plain = plain[drop:]
+	plain = plain[:min(int64(len(plain)), w.left)]
+	w.left -= int64(len(plain))
+	_, err = w.w.Write(plain)
+	return err
+}
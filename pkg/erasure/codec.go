@@ -0,0 +1,132 @@
+// pkg/erasure/codec.go
+package erasure
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Registered codec names.
+const (
+	RS  = "rs"  // Reed-Solomon, MDS: any m of n shards decode
+	LRC = "lrc" // locally repairable: one lost shard rebuilds from its group
+)
+
+// Codec is an erasure code over equal-length shards. Implementations are
+// immutable and safe for concurrent use.
+type Codec interface {
+	// Name is the registry name recorded in each object's profile.
+	Name() string
+	// Shards returns the data (m) and total (n) shard counts.
+	Shards() (data, total int)
+	// Tolerance is the number of lost shards the code always survives:
+	// n-m for MDS codes, less for codes that trade it for locality.
+	Tolerance() int
+
+	// Encode splits input into n shards and returns them with len(input).
+	Encode(input []byte) ([][]byte, int, error)
+	// Decode rebuilds the original outSize bytes; nil shards are missing.
+	Decode(shards [][]byte, outSize int) ([]byte, error)
+	// Reconstruct fills in every nil shard in place.
+	Reconstruct(shards [][]byte) error
+	// ReconstructSome fills in at least the nil shards marked in required.
+	ReconstructSome(shards [][]byte, required []bool) error
+	// Verify reports whether the parity shards match the data shards.
+	Verify(shards [][]byte) (bool, error)
+	// RepairSet lists the shards that suffice to rebuild shard idx alone.
+	RepairSet(idx int) []int
+}
+
+// Systematic reports whether every data shard of c is present in shards.
+// All codecs here are systematic, so the object is then just the data
+// shards joined and reading it needs no Galois-field work; a read that has
+// to touch parity is a degraded one.
+func Systematic(c Codec, shards [][]byte) bool {
+	m, _ := c.Shards()
+	if len(shards) < m {
+		return false
+	}
+	for _, sh := range shards[:m] {
+		if sh == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Factory builds a codec with data data shards out of total.
+type Factory func(data, total int) (Codec, error)
+
+var (
+	regMu    sync.RWMutex
+	registry = map[string]Factory{}
+	cache    = map[string]Codec{}
+)
+
+func init() {
+	Register(RS, func(data, total int) (Codec, error) { return New(data, total) })
+	Register(LRC, func(data, total int) (Codec, error) { return NewLRC(data, total) })
+}
+
+// Register makes a codec available under name; registering a name twice
+// replaces the earlier factory.
+func Register(name string, f Factory) {
+	regMu.Lock()
+	defer regMu.Unlock()
+	registry[name] = f
+	for k := range cache {
+		delete(cache, k)
+	}
+}
+
+// Known reports whether name (or "" for the default) is registered.
+func Known(name string) bool {
+	if name == "" {
+		name = RS
+	}
+	regMu.RLock()
+	defer regMu.RUnlock()
+	_, ok := registry[name]
+	return ok
+}
+
+// Names lists the registered codecs.
+func Names() []string {
+	regMu.RLock()
+	defer regMu.RUnlock()
+	out := make([]string, 0, len(registry))
+	for name := range registry {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Lookup returns the codec called name for data-of-total shards; "" means
+// Reed-Solomon, the codec of objects written before codecs were recorded.
+// Codecs are cached, so repeated lookups are cheap.
+func Lookup(name string, data, total int) (Codec, error) {
+	if name == "" {
+		name = RS
+	}
+	key := fmt.Sprintf("%s/%d/%d", name, data, total)
+	regMu.RLock()
+	c, ok := cache[key]
+	f := registry[name]
+	regMu.RUnlock()
+	if ok {
+		return c, nil
+	}
+	if f == nil {
+		return nil, fmt.Errorf("unknown erasure codec %q", name)
+	}
+	c, err := f(data, total)
+	if err != nil {
+		return nil, err
+	}
+	regMu.Lock()
+	cache[key] = c
+	regMu.Unlock()
+	return c, nil
+}
@@ -40,13 +40,70 @@ func (e *Encoder) Encode(input []byte) ([][]byte, int, error) {
     return shards, len(input), nil
 }
 
+// Reconstruct fills in the nil entries of shards (data and parity) in place.
+// At least 'data' shards must be present.
+func (e *Encoder) Reconstruct(shards [][]byte) error {
+    if len(shards) != e.total {
+        return fmt.Errorf("expected %d shards, got %d", e.total, len(shards))
+    }
+    if err := e.re.Reconstruct(shards); err != nil {
+        return fmt.Errorf("reconstruct shards: %w", err)
+    }
+    return nil
+}
+
+// ReconstructSome fills in only the nil shards marked in required; others may
+// be left nil.
+func (e *Encoder) ReconstructSome(shards [][]byte, required []bool) error {
+    if len(shards) != e.total {
+        return fmt.Errorf("expected %d shards, got %d", e.total, len(shards))
+    }
+    if err := e.re.ReconstructSome(shards, required); err != nil {
+        return fmt.Errorf("reconstruct shards: %w", err)
+    }
+    return nil
+}
+
+// Verify reports whether the parity shards are consistent with the data shards.
+func (e *Encoder) Verify(shards [][]byte) (bool, error) {
+    if len(shards) != e.total {
+        return false, fmt.Errorf("expected %d shards, got %d", e.total, len(shards))
+    }
+    return e.re.Verify(shards)
+}
+
+// Name returns the registry name of the Reed-Solomon codec.
+func (e *Encoder) Name() string { return RS }
+
+// Shards returns the data and total shard counts.
+func (e *Encoder) Shards() (int, int) { return e.data, e.total }
+
+// Tolerance is total-data: Reed-Solomon decodes from any 'data' shards.
+func (e *Encoder) Tolerance() int { return e.total - e.data }
+
+// RepairSet returns the first 'data' shards other than idx; any 'data' shards
+// rebuild a lost one.
+func (e *Encoder) RepairSet(idx int) []int {
+    out := make([]int, 0, e.data)
+    for i := 0; i < e.total && len(out) < e.data; i++ {
+        if i != idx {
+            out = append(out, i)
+        }
+    }
+    return out
+}
+
 // Decode reconstructs the original data of length 'outSize' from shards (nil entries allowed).
+// When every data shard is present they are simply joined; otherwise only
+// the missing data shards are rebuilt, never the parity.
 func (e *Encoder) Decode(shards [][]byte, outSize int) ([]byte, error) {
     if len(shards) != e.total {
         return nil, fmt.Errorf("expected %d shards, got %d", e.total, len(shards))
     }
-    if err := e.re.Reconstruct(shards); err != nil {
-        return nil, fmt.Errorf("reconstruct shards: %w", err)
+    if !Systematic(e, shards) {
+        if err := e.re.ReconstructData(shards); err != nil {
+            return nil, fmt.Errorf("reconstruct shards: %w", err)
+        }
     }
     buf := &bytes.Buffer{}
     if err := e.re.Join(buf, shards, outSize); err != nil {
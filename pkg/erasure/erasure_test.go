@@ -3,6 +3,8 @@ package erasure
 
 import (
     "bytes"
+    "errors"
+    "io"
     "testing"
 )
 
@@ -30,3 +32,245 @@ func TestEncodeDecodeRoundTrip(t *testing.T) {
         t.Errorf("Recovered mismatch: got %q, want %q", recovered, input)
     }
 }
+
+func TestReconstructParity(t *testing.T) {
+    enc, err := New(3, 5)
+    if err != nil {
+        t.Fatalf("New: %v", err)
+    }
+    shards, _, err := enc.Encode([]byte("lost parity must come back bit-for-bit"))
+    if err != nil {
+        t.Fatalf("Encode: %v", err)
+    }
+    want := append([]byte{}, shards[4]...)
+    shards[0], shards[4] = nil, nil
+
+    if err := enc.Reconstruct(shards); err != nil {
+        t.Fatalf("Reconstruct: %v", err)
+    }
+    if !bytes.Equal(shards[4], want) {
+        t.Errorf("parity shard mismatch after Reconstruct")
+    }
+}
+
+func TestSystematicDecodeSkipsParity(t *testing.T) {
+    input := bytes.Repeat([]byte("systematic "), 50)
+    for _, name := range []string{RS, LRC} {
+        c, err := Lookup(name, 4, 7)
+        if err != nil {
+            t.Fatalf("Lookup(%s): %v", name, err)
+        }
+        shards, size, err := c.Encode(input)
+        if err != nil {
+            t.Fatalf("%s Encode: %v", name, err)
+        }
+        // garbage parity must not matter while every data shard is there
+        shards[4] = bytes.Repeat([]byte{0xff}, len(shards[4]))
+        shards[5], shards[6] = nil, nil
+        if !Systematic(c, shards) {
+            t.Fatalf("%s: Systematic = false with all data shards", name)
+        }
+        out, err := c.Decode(shards, size)
+        if err != nil || !bytes.Equal(out, input) {
+            t.Fatalf("%s fast path: %v", name, err)
+        }
+        if shards[5] != nil {
+            t.Errorf("%s: fast path rebuilt parity", name)
+        }
+
+        shards, _, _ = c.Encode(input)
+        shards[1] = nil
+        if Systematic(c, shards) {
+            t.Fatalf("%s: Systematic = true with a data shard missing", name)
+        }
+        if out, err := c.Decode(shards, size); err != nil || !bytes.Equal(out, input) {
+            t.Fatalf("%s degraded: %v", name, err)
+        }
+    }
+}
+
+func TestLookup(t *testing.T) {
+    c, err := Lookup("", 3, 5)
+    if err != nil || c.Name() != RS {
+        t.Fatalf("Lookup(\"\") = %v, %v; want Reed-Solomon", c, err)
+    }
+    if _, err := Lookup("nope", 3, 5); err == nil {
+        t.Errorf("unknown codec should fail")
+    }
+    for _, name := range Names() {
+        c, err := Lookup(name, 4, 6)
+        if err != nil {
+            t.Fatalf("Lookup(%s): %v", name, err)
+        }
+        shards, _, _ := c.Encode([]byte("verify me, then flip a bit"))
+        if ok, err := c.Verify(shards); !ok || err != nil {
+            t.Errorf("%s: Verify on fresh shards = %v, %v", name, ok, err)
+        }
+        shards[5][0] ^= 1
+        if ok, _ := c.Verify(shards); ok {
+            t.Errorf("%s: Verify missed a corrupted parity", name)
+        }
+    }
+}
+
+func TestLRCSurvivesTolerance(t *testing.T) {
+    input := bytes.Repeat([]byte("locally repairable "), 37)
+    for _, p := range [][2]int{{4, 6}, {6, 10}, {5, 8}} {
+        c, err := NewLRC(p[0], p[1])
+        if err != nil {
+            t.Fatalf("NewLRC%v: %v", p, err)
+        }
+        // every way of losing Tolerance() shards must decode
+        var lose func(from int, lost []int)
+        lose = func(from int, lost []int) {
+            if len(lost) == c.Tolerance() {
+                shards, size, _ := c.Encode(input)
+                for _, i := range lost {
+                    shards[i] = nil
+                }
+                got, err := c.Decode(shards, size)
+                if err != nil || !bytes.Equal(got, input) {
+                    t.Errorf("LRC%v losing %v: err=%v", p, lost, err)
+                }
+                return
+            }
+            for i := from; i < p[1]; i++ {
+                lose(i+1, append(lost, i))
+            }
+        }
+        lose(0, nil)
+    }
+}
+
+func TestLRCLocalRepair(t *testing.T) {
+    c, err := NewLRC(6, 10)
+    if err != nil {
+        t.Fatalf("NewLRC: %v", err)
+    }
+    shards, _, err := c.Encode([]byte("one lost shard should only cost its group"))
+    if err != nil {
+        t.Fatalf("Encode: %v", err)
+    }
+    for _, idx := range []int{1, 4, 7} { // data in each group, and a local parity
+        set := c.RepairSet(idx)
+        if len(set) != 3 {
+            t.Errorf("RepairSet(%d) = %v, want the 3 other members of its group", idx, set)
+        }
+        partial := make([][]byte, len(shards))
+        for _, j := range set {
+            partial[j] = shards[j]
+        }
+        required := make([]bool, len(shards))
+        required[idx] = true
+        if err := c.ReconstructSome(partial, required); err != nil {
+            t.Fatalf("ReconstructSome(%d) from %v: %v", idx, set, err)
+        }
+        if !bytes.Equal(partial[idx], shards[idx]) {
+            t.Errorf("shard %d rebuilt wrongly from its group", idx)
+        }
+    }
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+    input := bytes.Repeat([]byte("0123456789abcdef"), 1000) // 16000 bytes
+    input = append(input, 0, 0, 0) // trailing zeros must survive
+    const stripe = 1024
+    for _, name := range Names() {
+        c, err := Lookup(name, 4, 6)
+        if err != nil {
+            t.Fatalf("Lookup(%s): %v", name, err)
+        }
+        frags := make([]*bytes.Buffer, 6)
+        dst := make([]io.Writer, 6)
+        for i := range frags {
+            frags[i] = &bytes.Buffer{}
+            dst[i] = frags[i]
+        }
+        size, err := EncodeStream(c, stripe, bytes.NewReader(input), dst)
+        if err != nil || size != int64(len(input)) {
+            t.Fatalf("%s: EncodeStream = %d, %v", name, size, err)
+        }
+        if want := FragmentSize(c, stripe, size); int64(frags[0].Len()) != want {
+            t.Errorf("%s: fragment is %d bytes, FragmentSize says %d", name, frags[0].Len(), want)
+        }
+
+        // whole fragments of a striped object are still valid shards
+        whole := make([][]byte, 6)
+        for i := range whole {
+            whole[i] = frags[i].Bytes()
+        }
+        if ok, err := c.Verify(whole); !ok || err != nil {
+            t.Errorf("%s: striped fragments fail Verify: %v", name, err)
+        }
+
+        src := make([]io.Reader, 6)
+        for _, i := range []int{0, 2, 4, 5} { // lose two data shards
+            src[i] = bytes.NewReader(frags[i].Bytes())
+        }
+        var out bytes.Buffer
+        if err := DecodeStream(c, stripe, src, size, &out); err != nil {
+            t.Fatalf("%s: DecodeStream: %v", name, err)
+        }
+        if !bytes.Equal(out.Bytes(), input) {
+            t.Errorf("%s: streamed round trip mismatch", name)
+        }
+    }
+}
+
+func TestDecodeStreamWholeObject(t *testing.T) {
+    // objects encoded in one piece decode as a single stripe
+    enc, _ := New(3, 5)
+    input := []byte("written before striping existed")
+    shards, size, _ := enc.Encode(input)
+    src := make([]io.Reader, 5)
+    for _, i := range []int{1, 3, 4} {
+        src[i] = bytes.NewReader(shards[i])
+    }
+    var out bytes.Buffer
+    if err := DecodeStream(enc, len(shards[0]), src, int64(size), &out); err != nil {
+        t.Fatalf("DecodeStream: %v", err)
+    }
+    if out.String() != string(input) {
+        t.Errorf("got %q, want %q", out.String(), input)
+    }
+
+    src[1] = bytes.NewReader(shards[1][:3])
+    var se *ShardError
+    if err := DecodeStream(enc, len(shards[0]), src, int64(size), &out); !errors.As(err, &se) || se.Index != 1 {
+        t.Errorf("short shard 1: want ShardError{Index: 1}, got %v", err)
+    }
+}
+
+func TestSpansCoverRange(t *testing.T) {
+    c, _ := Lookup(RS, 3, 5)
+    input := make([]byte, 1000)
+    for i := range input {
+        input[i] = byte(i * 7)
+    }
+    var frags [5]bytes.Buffer
+    dst := make([]io.Writer, 5)
+    for i := range dst {
+        dst[i] = &frags[i]
+    }
+    const stripe = 64
+    if _, err := EncodeStream(c, stripe, bytes.NewReader(input), dst); err != nil {
+        t.Fatalf("EncodeStream: %v", err)
+    }
+
+    // rebuild [off, off+length) from the data fragments alone
+    for _, r := range [][2]int64{{0, 1}, {63, 2}, {100, 500}, {191, 1}, {0, 1000}} {
+        var got []byte
+        for _, sp := range Spans(c, stripe, r[0], r[1]) {
+            first, last := sp.Shards(stripe)
+            for j := first; j <= last; j++ {
+                block := frags[j].Bytes()[sp.Stripe*stripe : (sp.Stripe+1)*stripe]
+                lo := max(sp.Offset-int64(j*stripe), 0)
+                hi := min(sp.Offset+sp.Length-int64(j*stripe), stripe)
+                got = append(got, block[lo:hi]...)
+            }
+        }
+        if want := input[r[0] : r[0]+r[1]]; !bytes.Equal(got, want) {
+            t.Errorf("range %v: got %d bytes, mismatch", r, len(got))
+        }
+    }
+}
@@ -0,0 +1,457 @@
+// pkg/erasure/lrc.go
+package erasure
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// LRCCodec is a locally repairable code built as a Pyramid code: take an MDS
+// code with m data and g+1 parity shards whose first parity is the plain sum
+// of the data, then split that parity into l local parities, one per group
+// of data shards. A lost data shard or local parity is rebuilt from its
+// group alone, and any g+1 lost shards can still be recovered.
+//
+// Shard layout: data 0..m-1, local parities m..m+l-1, global parities after.
+// The n-m parities are split evenly, with the extra one going local:
+// 4-of-6 has one local and one global parity, 6-of-10 has two of each.
+type LRCCodec struct {
+	data, total int
+	local       int      // l: local groups, one parity each
+	groups      [][]int  // data shard indices of each local group
+	rows        [][]byte // generator row of every shard over the data shards
+}
+
+// NewLRC builds the LRC for data-of-total shards.
+func NewLRC(data, total int) (*LRCCodec, error) {
+	parity := total - data
+	if data <= 0 || parity < 0 || total > 256 {
+		return nil, fmt.Errorf("invalid shard parameters: data=%d, total=%d", data, total)
+	}
+	local := min((parity+1)/2, data)
+	global := parity - local
+
+	c := &LRCCodec{data: data, total: total, local: local}
+	for q := 0; q < local; q++ {
+		var grp []int
+		for j := q * data / local; j < (q+1)*data/local; j++ {
+			grp = append(grp, j)
+		}
+		c.groups = append(c.groups, grp)
+	}
+
+	// Cauchy rows over distinct points x_i, y_j, each column scaled so the
+	// first row is all ones; scaling keeps every square submatrix invertible
+	cauchy := make([][]byte, global+1)
+	for i := range cauchy {
+		cauchy[i] = make([]byte, data)
+		for j := range cauchy[i] {
+			cauchy[i][j] = gfInv(byte(i) ^ byte(global+1+j))
+		}
+	}
+	for j := 0; j < data; j++ {
+		scale := gfInv(cauchy[0][j])
+		for i := range cauchy {
+			cauchy[i][j] = gfMul(cauchy[i][j], scale)
+		}
+	}
+
+	c.rows = make([][]byte, total)
+	for j := 0; j < data; j++ {
+		c.rows[j] = make([]byte, data)
+		c.rows[j][j] = 1
+	}
+	for q, grp := range c.groups {
+		row := make([]byte, data)
+		for _, j := range grp {
+			row[j] = cauchy[0][j]
+		}
+		c.rows[data+q] = row
+	}
+	for i := 1; i <= global; i++ {
+		c.rows[data+local+i-1] = cauchy[i]
+	}
+	return c, nil
+}
+
+// Name returns the registry name of the LRC.
+func (c *LRCCodec) Name() string { return LRC }
+
+// Shards returns the data and total shard counts.
+func (c *LRCCodec) Shards() (int, int) { return c.data, c.total }
+
+// Tolerance is g+1: the MDS code the local parities were split from.
+func (c *LRCCodec) Tolerance() int {
+	if c.local == 0 {
+		return 0
+	}
+	return c.total - c.data - c.local + 1
+}
+
+// group returns the local group shard idx belongs to, or -1 for a global
+// parity.
+func (c *LRCCodec) group(idx int) int {
+	if idx >= c.data {
+		if q := idx - c.data; q < c.local {
+			return q
+		}
+		return -1
+	}
+	for q, grp := range c.groups {
+		if idx >= grp[0] && idx <= grp[len(grp)-1] {
+			return q
+		}
+	}
+	return -1
+}
+
+// RepairSet returns the rest of idx's local group for data shards and local
+// parities, and all data shards for a global parity.
+func (c *LRCCodec) RepairSet(idx int) []int {
+	q := c.group(idx)
+	if q < 0 {
+		out := make([]int, c.data)
+		for j := range out {
+			out[j] = j
+		}
+		return out
+	}
+	var out []int
+	for _, j := range append(append([]int{}, c.groups[q]...), c.data+q) {
+		if j != idx {
+			out = append(out, j)
+		}
+	}
+	return out
+}
+
+// Encode splits input into data shards, zero-padding the last, and computes
+// the local and global parities.
+func (c *LRCCodec) Encode(input []byte) ([][]byte, int, error) {
+	if len(input) == 0 {
+		return nil, 0, errors.New("split data into shards: no data to encode")
+	}
+	size := (len(input) + c.data - 1) / c.data
+	padded := make([]byte, size*c.total)
+	copy(padded, input)
+	shards := make([][]byte, c.total)
+	for i := range shards {
+		shards[i] = padded[i*size : (i+1)*size : (i+1)*size]
+	}
+	for i := c.data; i < c.total; i++ {
+		c.combine(shards[i], c.rows[i], shards[:c.data])
+	}
+	return shards, len(input), nil
+}
+
+// combine sets dst to the GF(2^8) combination of data with coefficients row.
+func (c *LRCCodec) combine(dst, row []byte, data [][]byte) {
+	clear(dst)
+	for j, coef := range row {
+		if coef != 0 {
+			gfMulAdd(dst, data[j], coef)
+		}
+	}
+}
+
+// Reconstruct fills in every nil shard.
+func (c *LRCCodec) Reconstruct(shards [][]byte) error {
+	required := make([]bool, len(shards))
+	for i := range required {
+		required[i] = true
+	}
+	return c.ReconstructSome(shards, required)
+}
+
+// ReconstructSome repairs required shards from their local group where it
+// can and falls back to decoding the data shards from any independent set.
+func (c *LRCCodec) ReconstructSome(shards [][]byte, required []bool) error {
+	if len(shards) != c.total || len(required) != c.total {
+		return fmt.Errorf("expected %d shards, got %d", c.total, len(shards))
+	}
+	size, err := shardSize(shards)
+	if err != nil {
+		return err
+	}
+
+	pending := false
+	for idx := range shards {
+		if !required[idx] || shards[idx] != nil {
+			continue
+		}
+		if !c.repairLocal(shards, idx, size) {
+			pending = true
+		}
+	}
+	if !pending {
+		return nil
+	}
+
+	if err := c.decodeData(shards, size); err != nil {
+		return err
+	}
+	for idx := c.data; idx < c.total; idx++ {
+		if required[idx] && shards[idx] == nil {
+			shards[idx] = make([]byte, size)
+			c.combine(shards[idx], c.rows[idx], shards[:c.data])
+		}
+	}
+	return nil
+}
+
+// repairLocal rebuilds shards[idx] by XOR-ing the scaled rest of its group;
+// false when idx is a global parity or another group member is missing too.
+func (c *LRCCodec) repairLocal(shards [][]byte, idx, size int) bool {
+	q := c.group(idx)
+	if q < 0 {
+		return false
+	}
+	for _, j := range c.RepairSet(idx) {
+		if shards[j] == nil {
+			return false
+		}
+	}
+	// local parity p = Σ w_j d_j, so d_k = (p + Σ_{j≠k} w_j d_j) / w_k
+	row := c.rows[c.data+q]
+	out := make([]byte, size)
+	if idx >= c.data {
+		c.combine(out, row, shards[:c.data])
+	} else {
+		copy(out, shards[c.data+q])
+		for _, j := range c.groups[q] {
+			if j != idx {
+				gfMulAdd(out, shards[j], row[j])
+			}
+		}
+		if w := row[idx]; w != 1 {
+			gfScale(out, gfInv(w))
+		}
+	}
+	shards[idx] = out
+	return true
+}
+
+// decodeData fills in missing data shards by picking m shards whose
+// generator rows are independent and inverting them.
+func (c *LRCCodec) decodeData(shards [][]byte, size int) error {
+	missing := false
+	for j := 0; j < c.data; j++ {
+		if shards[j] == nil {
+			missing = true
+		}
+	}
+	if !missing {
+		return nil
+	}
+
+	var picked []int
+	var basis [][]byte
+	for idx := 0; idx < c.total && len(picked) < c.data; idx++ {
+		if shards[idx] == nil {
+			continue
+		}
+		if reduced, ok := independent(basis, c.rows[idx]); ok {
+			basis = append(basis, reduced)
+			picked = append(picked, idx)
+		}
+	}
+	if len(picked) < c.data {
+		return fmt.Errorf("reconstruct shards: too few independent shards (%d of %d)", len(picked), c.data)
+	}
+
+	matrix := make([][]byte, c.data)
+	for k, idx := range picked {
+		matrix[k] = c.rows[idx]
+	}
+	inv, err := gfInvert(matrix)
+	if err != nil {
+		return fmt.Errorf("reconstruct shards: %w", err)
+	}
+	src := make([][]byte, c.data)
+	for k, idx := range picked {
+		src[k] = shards[idx]
+	}
+	for j := 0; j < c.data; j++ {
+		if shards[j] == nil {
+			shards[j] = make([]byte, size)
+			c.combine(shards[j], inv[j], src)
+		}
+	}
+	return nil
+}
+
+// Decode rebuilds the data shards and joins the first outSize bytes.
+func (c *LRCCodec) Decode(shards [][]byte, outSize int) ([]byte, error) {
+	if len(shards) != c.total {
+		return nil, fmt.Errorf("expected %d shards, got %d", c.total, len(shards))
+	}
+	size, err := shardSize(shards)
+	if err != nil {
+		return nil, err
+	}
+	if outSize > size*c.data {
+		return nil, fmt.Errorf("join shards: want %d bytes, shards hold %d", outSize, size*c.data)
+	}
+	if err := c.decodeData(shards, size); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, size*c.data)
+	for j := 0; j < c.data; j++ {
+		out = append(out, shards[j]...)
+	}
+	return out[:outSize], nil
+}
+
+// Verify recomputes every parity from the data shards.
+func (c *LRCCodec) Verify(shards [][]byte) (bool, error) {
+	if len(shards) != c.total {
+		return false, fmt.Errorf("expected %d shards, got %d", c.total, len(shards))
+	}
+	size, err := shardSize(shards)
+	if err != nil {
+		return false, err
+	}
+	for _, sh := range shards {
+		if len(sh) != size {
+			return false, errors.New("verify: shards missing or of unequal size")
+		}
+	}
+	want := make([]byte, size)
+	for idx := c.data; idx < c.total; idx++ {
+		c.combine(want, c.rows[idx], shards[:c.data])
+		if !bytes.Equal(want, shards[idx]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// shardSize returns the common length of the present shards.
+func shardSize(shards [][]byte) (int, error) {
+	size := -1
+	for _, sh := range shards {
+		if sh == nil {
+			continue
+		}
+		if size >= 0 && len(sh) != size {
+			return 0, errors.New("shards of unequal size")
+		}
+		size = len(sh)
+	}
+	if size <= 0 {
+		return 0, errors.New("no shards present")
+	}
+	return size, nil
+}
+
+/* ---- GF(2^8) arithmetic, polynomial x^8+x^4+x^3+x^2+1 as in reedsolomon ---- */
+
+var (
+	gfExp [510]byte
+	gfLog [256]int
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i], gfExp[i+255] = byte(x), byte(x)
+		gfLog[x] = i
+		if x <<= 1; x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[gfLog[a]+gfLog[b]]
+}
+
+func gfInv(a byte) byte {
+	return gfExp[255-gfLog[a]]
+}
+
+// gfMulAdd sets dst ^= coef·src byte by byte.
+func gfMulAdd(dst, src []byte, coef byte) {
+	if coef == 1 {
+		for k, b := range src {
+			dst[k] ^= b
+		}
+		return
+	}
+	var table [256]byte
+	for b := range table {
+		table[b] = gfMul(coef, byte(b))
+	}
+	for k, b := range src {
+		dst[k] ^= table[b]
+	}
+}
+
+// gfScale sets buf = coef·buf.
+func gfScale(buf []byte, coef byte) {
+	var table [256]byte
+	for b := range table {
+		table[b] = gfMul(coef, byte(b))
+	}
+	for k, b := range buf {
+		buf[k] = table[b]
+	}
+}
+
+// independent reduces row against an echelon basis and reports whether
+// anything is left, returning the reduced row for the basis.
+func independent(basis [][]byte, row []byte) ([]byte, bool) {
+	r := append([]byte{}, row...)
+	for _, b := range basis {
+		pivot := leading(b)
+		if coef := r[pivot]; coef != 0 {
+			gfMulAdd(r, b, gfMul(coef, gfInv(b[pivot])))
+		}
+	}
+	return r, leading(r) >= 0
+}
+
+func leading(row []byte) int {
+	for i, v := range row {
+		if v != 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// gfInvert inverts a square matrix by Gauss-Jordan elimination.
+func gfInvert(m [][]byte) ([][]byte, error) {
+	n := len(m)
+	work := make([][]byte, n)
+	for i := range m {
+		work[i] = make([]byte, 2*n)
+		copy(work[i], m[i])
+		work[i][n+i] = 1
+	}
+	for col := 0; col < n; col++ {
+		p := col
+		for p < n && work[p][col] == 0 {
+			p++
+		}
+		if p == n {
+			return nil, errors.New("singular decode matrix")
+		}
+		work[col], work[p] = work[p], work[col]
+		gfScale(work[col], gfInv(work[col][col]))
+		for r := 0; r < n; r++ {
+			if r != col && work[r][col] != 0 {
+				gfMulAdd(work[r], work[col], work[r][col])
+			}
+		}
+	}
+	out := make([][]byte, n)
+	for i := range work {
+		out[i] = work[i][n:]
+	}
+	return out, nil
+}
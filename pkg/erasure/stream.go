@@ -0,0 +1,159 @@
+// pkg/erasure/stream.go
+package erasure
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultStripe is the block each fragment receives per stripe. A stripe
+// holds m such blocks of input, so encoding and decoding keep about n blocks
+// in memory whatever the object size.
+const DefaultStripe = 1 << 20
+
+// ShardError reports the source or destination shard that failed a stream.
+type ShardError struct {
+	Index int
+	Err   error
+}
+
+func (e *ShardError) Error() string { return fmt.Sprintf("shard %d: %v", e.Index, e.Err) }
+func (e *ShardError) Unwrap() error { return e.Err }
+
+// FragmentSize is the length of every fragment when size bytes are striped
+// with c in blocks of stripe bytes.
+func FragmentSize(c Codec, stripe int, size int64) int64 {
+	m, _ := c.Shards()
+	per := int64(stripe) * int64(m)
+	return (size + per - 1) / per * int64(stripe)
+}
+
+// Span is the part of one stripe that a byte range of the object covers.
+// Stripe s is block s of every fragment, holding object bytes
+// [s*stripe*m, (s+1)*stripe*m) with data shard j carrying the j-th stripe
+// bytes of them.
+type Span struct {
+	Stripe int64 // stripe (and fragment block) index
+	Offset int64 // start of the range within the stripe's data
+	Length int64
+}
+
+// Shards returns the first and last data shards the span touches.
+func (sp Span) Shards(stripe int) (first, last int) {
+	return int(sp.Offset / int64(stripe)), int((sp.Offset + sp.Length - 1) / int64(stripe))
+}
+
+// Spans splits object bytes [off, off+length) by stripe.
+func Spans(c Codec, stripe int, off, length int64) []Span {
+	m, _ := c.Shards()
+	per := int64(stripe) * int64(m)
+	var out []Span
+	for end := off + length; off < end; {
+		sp := Span{Stripe: off / per, Offset: off % per}
+		sp.Length = min(per-sp.Offset, end-off)
+		out = append(out, sp)
+		off += sp.Length
+	}
+	return out
+}
+
+// EncodeStream reads r to EOF in stripes of stripe*m bytes, zero-padding the
+// last, encodes each stripe with c and appends block i to dst[i]. A nil
+// destination is skipped. It returns the number of input bytes.
+//
+// Because the codes are byte-wise linear, fragment i is still a valid shard
+// of the whole object: whole-fragment Reconstruct works on striped objects.
+func EncodeStream(c Codec, stripe int, r io.Reader, dst []io.Writer) (int64, error) {
+	m, n := c.Shards()
+	if len(dst) != n {
+		return 0, fmt.Errorf("expected %d destinations, got %d", n, len(dst))
+	}
+	if stripe <= 0 {
+		return 0, fmt.Errorf("invalid stripe size %d", stripe)
+	}
+	buf := make([]byte, stripe*m)
+	var total int64
+	for {
+		k, err := io.ReadFull(r, buf)
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return total, err
+		}
+		total += int64(k)
+		clear(buf[k:])
+		shards, _, encErr := c.Encode(buf)
+		if encErr != nil {
+			return total, encErr
+		}
+		for i, w := range dst {
+			if w == nil {
+				continue
+			}
+			if _, werr := w.Write(shards[i]); werr != nil {
+				return total, &ShardError{Index: i, Err: werr}
+			}
+		}
+		if err == io.ErrUnexpectedEOF {
+			return total, nil
+		}
+	}
+}
+
+// DecodeStream reads one block per stripe from every non-nil src, rebuilds
+// the data blocks with c and writes the first size bytes of the object to w.
+// Objects encoded in one piece are a single stripe of the fragment length.
+// With all m data sources present the blocks are copied straight through.
+func DecodeStream(c Codec, stripe int, src []io.Reader, size int64, w io.Writer) error {
+	m, n := c.Shards()
+	if len(src) != n {
+		return fmt.Errorf("expected %d sources, got %d", n, len(src))
+	}
+	if stripe <= 0 {
+		return fmt.Errorf("invalid stripe size %d", stripe)
+	}
+	blocks := make([][]byte, n)
+	for i, r := range src {
+		if r != nil {
+			blocks[i] = make([]byte, stripe)
+		}
+	}
+	fast := Systematic(c, blocks)
+	for size > 0 {
+		shards := make([][]byte, n)
+		for i, r := range src {
+			if r == nil {
+				continue
+			}
+			if _, err := io.ReadFull(r, blocks[i]); err != nil {
+				if errors.Is(err, io.EOF) {
+					err = io.ErrUnexpectedEOF // a fragment may not end before the object does
+				}
+				return &ShardError{Index: i, Err: err}
+			}
+			shards[i] = blocks[i]
+		}
+		if fast {
+			for _, b := range shards[:m] {
+				k := min(int64(len(b)), size)
+				if _, err := w.Write(b[:k]); err != nil {
+					return err
+				}
+				size -= k
+			}
+			continue
+		}
+		out, err := c.Decode(shards, stripe*m)
+		if err != nil {
+			return err
+		}
+		k := min(int64(len(out)), size)
+		if _, err := w.Write(out[:k]); err != nil {
+			return err
+		}
+		size -= k
+	}
+	return nil
+}
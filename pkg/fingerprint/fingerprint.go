@@ -45,3 +45,27 @@ func (f *Fingerprint) Eval(data []byte) uint64 {
   }
   return res
 }
+
+// Stream evaluates a fingerprint over data written in pieces; Sum64 equals
+// Eval of everything written so far.
+type Stream struct {
+  r, res uint64
+}
+
+// Stream returns an empty streaming evaluator with f's seed.
+func (f *Fingerprint) Stream() *Stream {
+  return &Stream{r: f.r}
+}
+
+// Write folds p into the running fingerprint; it never fails.
+func (s *Stream) Write(p []byte) (int, error) {
+  for _, b := range p {
+    s.res = s.res*s.r + uint64(b)
+  }
+  return len(p), nil
+}
+
+// Sum64 returns the fingerprint of the data written so far.
+func (s *Stream) Sum64() uint64 {
+  return s.res
+}
@@ -41,3 +41,16 @@ func TestEvalHomomorphic(t *testing.T) {
         t.Errorf("Homomorphic property failed: Eval(sum)=%d, Eval(a)+Eval(b)=%d", fs, fa+fb)
     }
 }
+
+func TestStreamMatchesEval(t *testing.T) {
+    fp := NewWithSeed(12345)
+    data := []byte("fingerprints must not depend on how the bytes arrive")
+
+    s := fp.Stream()
+    for i := 0; i < len(data); i += 7 {
+        s.Write(data[i:min(i+7, len(data))])
+    }
+    if got, want := s.Sum64(), fp.Eval(data); got != want {
+        t.Errorf("Stream = %d, Eval = %d", got, want)
+    }
+}
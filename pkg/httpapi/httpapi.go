@@ -0,0 +1,534 @@
+// pkg/httpapi/httpapi.go
+// Package httpapi is the plain HTTP/JSON face of a node, for curl and
+// browsers: PUT, GET, HEAD and DELETE on /objects/{id}, its versions at
+// /objects/{id}/versions, a listing at /objects and the node's status at
+// /status. The node does the work of a
+// client – erasure coding, fingerprinting and dispersal on PUT, collecting
+// and verifying fragments on GET – through pkg/client.
+package httpapi
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/client"
+	"github.com/dattu/distributed_object_store/pkg/envelope"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+// Store is the part of *client.Client the API uses.
+type Store interface {
+	PutOptions() client.PutOptions
+	Put(ctx context.Context, id string, r io.Reader, opts *client.PutOptions) (*client.ObjectInfo, error)
+	GetVersion(ctx context.Context, id, version string, w io.Writer, off, length int64) (*client.ObjectInfo, error)
+	StatVersion(ctx context.Context, id, version string) (*client.ObjectInfo, error)
+	Versions(ctx context.Context, id string) ([]*client.ObjectInfo, error)
+	List(ctx context.Context, opts client.ListOptions) ([]*client.ObjectInfo, string, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// Config configures the API.
+type Config struct {
+	// Status returns the JSON document served at /status.
+	Status func() any
+	Logf   func(format string, args ...any)
+}
+
+type api struct {
+	store Store
+	cfg   Config
+}
+
+// New returns the handler serving store.
+func New(store Store, cfg Config) http.Handler {
+	a := &api{store: store, cfg: cfg}
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /objects/{id}", a.put)
+	mux.HandleFunc("GET /objects/{id}", a.get) // and HEAD
+	mux.HandleFunc("GET /objects/{id}/stat", a.stat)
+	mux.HandleFunc("GET /objects/{id}/versions", a.versions)
+	mux.HandleFunc("DELETE /objects/{id}", a.delete)
+	mux.HandleFunc("GET /objects", a.list)
+	mux.HandleFunc("GET /status", a.status)
+	return mux
+}
+
+func (a *api) logf(format string, args ...any) {
+	if a.cfg.Logf != nil {
+		a.cfg.Logf(format, args...)
+	}
+}
+
+// objectID returns the {id} of r if it is safe as a directory name on the
+// nodes.
+func objectID(r *http.Request) (string, error) {
+	id := r.PathValue("id")
+	if err := protocol.CheckID(id); err != nil {
+		return "", fmt.Errorf("%w: %v", client.ErrInvalid, err)
+	}
+	return id, nil
+}
+
+// Object is the JSON form of client.ObjectInfo. Digest names the version,
+// for ?version=.
+type Object struct {
+	ID          string            `json:"id"`
+	Size        int64             `json:"size"`
+	Stored      int64             `json:"stored,omitempty"`
+	Codec       string            `json:"codec,omitempty"`
+	Data        int               `json:"data,omitempty"`
+	Total       int               `json:"total,omitempty"`
+	Generation  uint64            `json:"generation"`
+	Created     *time.Time        `json:"created,omitempty"`
+	Superseded  *time.Time        `json:"superseded,omitempty"`
+	Compression string            `json:"compression,omitempty"`
+	KeyID       string            `json:"key_id,omitempty"`
+	Chunks      int               `json:"chunks,omitempty"`
+	Digest      string            `json:"digest"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Expires     *time.Time        `json:"expires,omitempty"`
+	Retain      bool              `json:"retain,omitempty"`
+}
+
+func object(info *client.ObjectInfo) Object {
+	o := Object{
+		ID: info.ID, Size: info.Size, Stored: info.Stored,
+		Codec: info.Codec, Data: info.Data, Total: info.Total,
+		Generation: info.Generation, Compression: info.Compression,
+		KeyID: info.KeyID, Chunks: info.Chunks, Digest: hex.EncodeToString(info.Digest),
+		Metadata: info.Metadata, Tags: info.Tags, Retain: info.Retain,
+	}
+	if !info.Expires.IsZero() {
+		t := info.Expires.UTC()
+		o.Expires = &t
+	}
+	if !info.Created.IsZero() {
+		t := info.Created.UTC()
+		o.Created = &t
+	}
+	if !info.Superseded.IsZero() {
+		t := info.Superseded.UTC()
+		o.Superseded = &t
+	}
+	return o
+}
+
+// put stores the body as object id, as a new version if it exists. Query
+// parameters override the node's defaults: codec, m and n pick the erasure
+// profile, compress a codec (or "none"), dedup=true stores
+// content-defined chunks; ttl (a duration), expires (RFC 3339) or
+// retain=true set when the version expires. Content-Type and
+// X-Object-Meta-* headers become the object's metadata, X-Object-Tags
+// (k=v&k2=v2) its tags.
+func (a *api) put(w http.ResponseWriter, r *http.Request) {
+	id, err := objectID(r)
+	if err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	opts := a.store.PutOptions()
+	q := r.URL.Query()
+	if v := q.Get("codec"); v != "" {
+		opts.Codec = v
+	}
+	for param, dst := range map[string]*int{"m": &opts.Data, "n": &opts.Total} {
+		if v := q.Get(param); v != "" {
+			if *dst, err = strconv.Atoi(v); err != nil || *dst <= 0 {
+				a.fail(w, r, fmt.Errorf("%w: %s=%q", client.ErrInvalid, param, v))
+				return
+			}
+		}
+	}
+	switch v := q.Get("compress"); v {
+	case "":
+	case "none":
+		opts.Compression = ""
+	default:
+		opts.Compression = v
+	}
+	if v := q.Get("dedup"); v != "" {
+		if opts.Dedup, err = strconv.ParseBool(v); err != nil {
+			a.fail(w, r, fmt.Errorf("%w: dedup=%q", client.ErrInvalid, v))
+			return
+		}
+	}
+	if v := q.Get("ttl"); v != "" {
+		if opts.TTL, err = time.ParseDuration(v); err != nil {
+			a.fail(w, r, fmt.Errorf("%w: ttl=%q", client.ErrInvalid, v))
+			return
+		}
+	}
+	if v := q.Get("expires"); v != "" {
+		if opts.Expires, err = time.Parse(time.RFC3339, v); err != nil {
+			a.fail(w, r, fmt.Errorf("%w: expires=%q", client.ErrInvalid, v))
+			return
+		}
+	}
+	if v := q.Get("retain"); v != "" {
+		if opts.Retain, err = strconv.ParseBool(v); err != nil {
+			a.fail(w, r, fmt.Errorf("%w: retain=%q", client.ErrInvalid, v))
+			return
+		}
+	}
+	// conditional writes: If-None-Match: * creates, If-Match replaces the
+	// version its ETag names
+	switch v := r.Header.Get("If-None-Match"); v {
+	case "":
+	case "*":
+		opts.IfNoneMatch = true
+	default:
+		a.fail(w, r, fmt.Errorf("%w: PUT takes only If-None-Match: *", client.ErrInvalid))
+		return
+	}
+	opts.IfMatchVersion = strings.Trim(r.Header.Get("If-Match"), `"`)
+	opts.Metadata = metadata(r.Header)
+	if opts.Tags, err = parseTags(r.Header.Values("X-Object-Tags")); err != nil {
+		a.fail(w, r, err)
+		return
+	}
+
+	info, err := a.store.Put(r.Context(), id, r.Body, &opts)
+	if err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	w.Header().Set("Location", "/objects/"+id)
+	w.Header().Set("ETag", etag(info))
+	writeJSON(w, http.StatusCreated, object(info))
+}
+
+func etag(info *client.ObjectInfo) string {
+	return `"` + hex.EncodeToString(info.Digest) + `"`
+}
+
+// metaPrefix starts the headers carrying an object's metadata.
+const metaPrefix = "X-Object-Meta-"
+
+// metadata collects the metadata a PUT sends, under lower-case keys; the
+// Content-Type is kept as "content-type".
+func metadata(h http.Header) map[string]string {
+	meta := make(map[string]string)
+	for k, vs := range h {
+		if key, ok := strings.CutPrefix(k, metaPrefix); ok && key != "" {
+			meta[strings.ToLower(key)] = strings.Join(vs, ",")
+		}
+	}
+	if v := h.Get("Content-Type"); v != "" {
+		meta["content-type"] = v
+	}
+	if len(meta) == 0 {
+		return nil
+	}
+	return meta
+}
+
+// parseTags reads tags in the form k=v&k2=v2, from X-Object-Tags headers
+// or ?tag= parameters.
+func parseTags(vs []string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, v := range vs {
+		q, err := url.ParseQuery(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: tags %q: %v", client.ErrInvalid, v, err)
+		}
+		for k, kv := range q {
+			tags[k] = kv[len(kv)-1]
+		}
+	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	return tags, nil
+}
+
+// setMetadata describes info's metadata and tags in the headers of a GET.
+func setMetadata(h http.Header, info *client.ObjectInfo) {
+	h.Set("Content-Type", "application/octet-stream")
+	for k, v := range info.Metadata {
+		if k == "content-type" {
+			h.Set("Content-Type", v)
+		} else {
+			h.Set(metaPrefix+k, v)
+		}
+	}
+	if len(info.Tags) > 0 {
+		tags := make(url.Values, len(info.Tags))
+		for k, v := range info.Tags {
+			tags.Set(k, v)
+		}
+		h.Set("X-Object-Tags", tags.Encode())
+	}
+}
+
+// get serves GET and HEAD of an object, with a single Range if asked:
+// its current version, or the one ?version= names. The body is read from
+// the version the headers describe.
+func (a *api) get(w http.ResponseWriter, r *http.Request) {
+	id, err := objectID(r)
+	if err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	ctx := r.Context()
+	info, err := a.store.StatVersion(ctx, id, r.URL.Query().Get("version"))
+	if err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	h := w.Header()
+	tag := etag(info)
+	h.Set("ETag", tag)
+	if r.Header.Get("If-None-Match") == tag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.Set("Accept-Ranges", "bytes")
+	setMetadata(h, info)
+	h.Set("X-Object-Generation", strconv.FormatUint(info.Generation, 10))
+	if !info.Created.IsZero() {
+		h.Set("Last-Modified", info.Created.UTC().Format(http.TimeFormat))
+	}
+	if !info.Expires.IsZero() {
+		h.Set("X-Object-Expires", info.Expires.UTC().Format(http.TimeFormat))
+	}
+
+	status, off, length := http.StatusOK, int64(0), info.Size
+	if spec := r.Header.Get("Range"); spec != "" {
+		o, l, ok, satisfiable := parseRange(spec, info.Size)
+		if !satisfiable {
+			h.Set("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+			a.fail(w, r, errRange)
+			return
+		}
+		if ok {
+			status, off, length = http.StatusPartialContent, o, l
+			h.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", off, off+length-1, info.Size))
+		}
+	}
+	h.Set("Content-Length", strconv.FormatInt(length, 10))
+	if r.Method == http.MethodHead || length == 0 {
+		w.WriteHeader(status)
+		return
+	}
+
+	lw := &lazyWriter{w: w, status: status}
+	if status == http.StatusOK {
+		off, length = 0, 0 // the whole object
+	}
+	_, err = a.store.GetVersion(ctx, id, info.VersionID(), lw, off, length)
+	switch {
+	case err == nil && !lw.wrote:
+		w.WriteHeader(status)
+	case err != nil && !lw.wrote:
+		for k := range h {
+			if strings.HasPrefix(k, metaPrefix) || slices.Contains([]string{"Content-Length", "Content-Range", "Content-Type", "Accept-Ranges", "ETag", "Last-Modified", "X-Object-Generation", "X-Object-Tags", "X-Object-Expires"}, k) {
+				h.Del(k)
+			}
+		}
+		a.fail(w, r, err)
+	case err != nil:
+		// the status line is out: all that is left is to cut the body short
+		a.logf("HTTP GET %s: %v", id, err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// lazyWriter sends the status line with the first byte of the body, so
+// that a read failing before then can still answer with an error.
+type lazyWriter struct {
+	w      http.ResponseWriter
+	status int
+	wrote  bool
+}
+
+func (l *lazyWriter) Write(p []byte) (int, error) {
+	if !l.wrote {
+		l.wrote = true
+		l.w.WriteHeader(l.status)
+	}
+	return l.w.Write(p)
+}
+
+// parseRange reads a single-range Range header against an object of size
+// bytes. ok is false for a header to ignore (other units, several
+// ranges, bad syntax); satisfiable is false for a range outside the
+// object.
+func parseRange(spec string, size int64) (off, length int64, ok, satisfiable bool) {
+	spec, found := strings.CutPrefix(spec, "bytes=")
+	first, last, dash := strings.Cut(spec, "-")
+	if !found || !dash || strings.Contains(spec, ",") {
+		return 0, 0, false, true
+	}
+	if first == "" { // the last n bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		switch {
+		case err != nil || n < 0:
+			return 0, 0, false, true
+		case n == 0 || size == 0:
+			return 0, 0, false, false
+		}
+		n = min(n, size)
+		return size - n, n, true, true
+	}
+	off, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || off < 0 {
+		return 0, 0, false, true
+	}
+	end := size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < off {
+			return 0, 0, false, true
+		}
+		end = min(end, size-1)
+	}
+	if off >= size {
+		return 0, 0, false, false
+	}
+	return off, end - off + 1, true, true
+}
+
+func (a *api) stat(w http.ResponseWriter, r *http.Request) {
+	id, err := objectID(r)
+	if err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	info, err := a.store.StatVersion(r.Context(), id, r.URL.Query().Get("version"))
+	if err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, object(info))
+}
+
+// versions serves GET /objects/{id}/versions: the versions the nodes
+// keep, newest first.
+func (a *api) versions(w http.ResponseWriter, r *http.Request) {
+	id, err := objectID(r)
+	if err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	infos, err := a.store.Versions(r.Context(), id)
+	if err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	res := struct {
+		Versions []Object `json:"versions"`
+	}{make([]Object, 0, len(infos))}
+	for _, info := range infos {
+		res.Versions = append(res.Versions, object(info))
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (a *api) delete(w http.ResponseWriter, r *http.Request) {
+	id, err := objectID(r)
+	if err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	if err := a.store.Delete(r.Context(), id); err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listing is the JSON answer of GET /objects; Next is the start_after of
+// the following page, "" after the last.
+type listing struct {
+	Objects []Object `json:"objects"`
+	Next    string   `json:"next,omitempty"`
+}
+
+// list serves GET /objects?prefix=…&start_after=…&limit=…; repeated
+// tag=k=v parameters keep the objects carrying all those tags.
+func (a *api) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts := client.ListOptions{Prefix: q.Get("prefix"), StartAfter: q.Get("start_after")}
+	var err error
+	if opts.Tags, err = parseTags(q["tag"]); err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			a.fail(w, r, fmt.Errorf("%w: limit=%q", client.ErrInvalid, v))
+			return
+		}
+		opts.Limit = n
+	}
+	infos, next, err := a.store.List(r.Context(), opts)
+	if err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	res := listing{Objects: make([]Object, 0, len(infos)), Next: next}
+	for _, info := range infos {
+		res.Objects = append(res.Objects, object(info))
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (a *api) status(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Status == nil {
+		a.fail(w, r, errors.New("no status"))
+		return
+	}
+	writeJSON(w, http.StatusOK, a.cfg.Status())
+}
+
+var errRange = errors.New("range outside the object")
+
+// fail answers with {"error": …} and the status err maps to.
+func (a *api) fail(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, client.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, client.ErrExists):
+		status = http.StatusConflict
+	case errors.Is(err, client.ErrPrecondition):
+		status = http.StatusPreconditionFailed
+	case errors.Is(err, client.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, errRange):
+		status = http.StatusRequestedRangeNotSatisfiable
+	case errors.Is(err, envelope.ErrNoKey):
+		status = http.StatusForbidden
+	case errors.Is(err, client.ErrUnavailable):
+		status = http.StatusServiceUnavailable
+	case errors.Is(err, context.Canceled):
+		status = 499 // client closed the request; nobody reads this
+	}
+	if status >= 500 {
+		a.logf("HTTP %s %s: %v", r.Method, r.URL.Path, err)
+	}
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
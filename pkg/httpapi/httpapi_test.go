@@ -0,0 +1,450 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/client"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+var _ Store = (*client.Client)(nil)
+
+// memStore keeps objects in memory, each Put adding a version like the
+// cluster does.
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string][]memVersion // oldest first
+	opts    client.PutOptions       // of the last Put
+}
+
+type memVersion struct {
+	data []byte
+	info *client.ObjectInfo
+}
+
+func newMemStore() *memStore {
+	return &memStore{objects: make(map[string][]memVersion)}
+}
+
+func (s *memStore) PutOptions() client.PutOptions {
+	return client.PutOptions{Codec: "rs", Data: 3, Total: 5, Compression: "zstd"}
+}
+
+func (s *memStore) Put(ctx context.Context, id string, r io.Reader, opts *client.PutOptions) (*client.ObjectInfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.opts = *opts
+	vs := s.objects[id]
+	switch {
+	case opts.IfNoneMatch && len(vs) > 0,
+		opts.IfMatchVersion != "" && (len(vs) == 0 || vs[len(vs)-1].info.VersionID() != opts.IfMatchVersion):
+		return nil, &client.Error{Op: "put", ID: id, Err: client.ErrPrecondition}
+	}
+	sum := sha256.Sum256(data)
+	fpcc := &protocol.FPCC{Seed: rand.Uint64(), Size: uint64(len(data)), Hashes: [][]byte{sum[:]}, Generation: uint64(len(vs))}
+	info := &client.ObjectInfo{
+		ID: id, Size: int64(len(data)), Codec: opts.Codec, Data: opts.Data, Total: opts.Total,
+		Generation: fpcc.Generation, Created: time.Now(), Digest: fpcc.Digest(), FPCC: fpcc,
+		Metadata: opts.Metadata, Tags: opts.Tags, Expires: opts.Expires, Retain: opts.Retain,
+	}
+	if opts.TTL > 0 {
+		info.Expires = time.Now().Add(opts.TTL)
+	}
+	if len(vs) > 0 {
+		prev := *vs[len(vs)-1].info
+		prev.Superseded = info.Created
+		vs[len(vs)-1].info = &prev
+	}
+	s.objects[id] = append(vs, memVersion{data, info})
+	return info, nil
+}
+
+// find returns the version of id named version, "" for the newest.
+func (s *memStore) find(op, id, version string) (memVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vs := s.objects[id]
+	for i := len(vs) - 1; i >= 0; i-- {
+		if version == "" || version == vs[i].info.VersionID() {
+			return vs[i], nil
+		}
+	}
+	return memVersion{}, &client.Error{Op: op, ID: id, Err: client.ErrNotFound}
+}
+
+func (s *memStore) GetVersion(ctx context.Context, id, version string, w io.Writer, off, length int64) (*client.ObjectInfo, error) {
+	v, err := s.find("get", id, version)
+	if err != nil {
+		return nil, err
+	}
+	if length == 0 {
+		length = int64(len(v.data)) - off
+	}
+	_, err = w.Write(v.data[off : off+length])
+	return v.info, err
+}
+
+func (s *memStore) StatVersion(ctx context.Context, id, version string) (*client.ObjectInfo, error) {
+	v, err := s.find("stat", id, version)
+	return v.info, err
+}
+
+func (s *memStore) Versions(ctx context.Context, id string) ([]*client.ObjectInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vs := s.objects[id]
+	if len(vs) == 0 {
+		return nil, &client.Error{Op: "versions", ID: id, Err: client.ErrNotFound}
+	}
+	var out []*client.ObjectInfo
+	for i := len(vs) - 1; i >= 0; i-- {
+		out = append(out, vs[i].info)
+	}
+	return out, nil
+}
+
+// List pages by 2.
+func (s *memStore) List(ctx context.Context, opts client.ListOptions) ([]*client.ObjectInfo, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	for id, vs := range s.objects {
+		tagged := true
+		for k, v := range opts.Tags {
+			tagged = tagged && vs[len(vs)-1].info.Tags[k] == v
+		}
+		if strings.HasPrefix(id, opts.Prefix) && id > opts.StartAfter && tagged {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	var next string
+	if len(ids) > 2 {
+		ids, next = ids[:2], ids[1]
+	}
+	var out []*client.ObjectInfo
+	for _, id := range ids {
+		vs := s.objects[id]
+		out = append(out, vs[len(vs)-1].info)
+	}
+	return out, next, nil
+}
+
+func (s *memStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.objects[id]; !ok {
+		return &client.Error{Op: "delete", ID: id, Err: client.ErrNotFound}
+	}
+	delete(s.objects, id)
+	return nil
+}
+
+func newServer(t *testing.T) (*memStore, *httptest.Server) {
+	t.Helper()
+	store := newMemStore()
+	srv := httptest.NewServer(New(store, Config{
+		Status: func() any { return map[string]any{"self": "node1", "epoch": 7} },
+		Logf:   t.Logf,
+	}))
+	t.Cleanup(srv.Close)
+	return store, srv
+}
+
+func do(t *testing.T, method, url string, body io.Reader, header ...string) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, b
+}
+
+func TestObjects(t *testing.T) {
+	store, srv := newServer(t)
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+
+	resp, body := do(t, "PUT", srv.URL+"/objects/photo.jpg?m=2&n=4&compress=none&dedup=true", bytes.NewReader(data))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("PUT = %d %s", resp.StatusCode, body)
+	}
+	var obj Object
+	if err := json.Unmarshal(body, &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj.ID != "photo.jpg" || obj.Size != int64(len(data)) || obj.Data != 2 || obj.Total != 4 {
+		t.Errorf("PUT answered %+v", obj)
+	}
+	if want := (client.PutOptions{Codec: "rs", Data: 2, Total: 4, Dedup: true}); !reflect.DeepEqual(store.opts, want) {
+		t.Errorf("Put options %+v, want %+v", store.opts, want)
+	}
+	tag := `"` + obj.Digest + `"`
+	if resp.Header.Get("ETag") != tag || resp.Header.Get("Location") != "/objects/photo.jpg" {
+		t.Errorf("PUT headers %v", resp.Header)
+	}
+
+	resp, body = do(t, "GET", srv.URL+"/objects/photo.jpg", nil)
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(body, data) {
+		t.Fatalf("GET = %d, %d bytes", resp.StatusCode, len(body))
+	}
+	if resp.Header.Get("ETag") != tag || resp.Header.Get("Content-Length") != "10000" {
+		t.Errorf("GET headers %v", resp.Header)
+	}
+
+	if resp, _ := do(t, "GET", srv.URL+"/objects/photo.jpg", nil, "If-None-Match", tag); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("conditional GET = %d, want 304", resp.StatusCode)
+	}
+
+	resp, body = do(t, "GET", srv.URL+"/objects/photo.jpg", nil, "Range", "bytes=15-24")
+	if resp.StatusCode != http.StatusPartialContent || string(body) != "5678901234" ||
+		resp.Header.Get("Content-Range") != "bytes 15-24/10000" {
+		t.Errorf("range GET = %d %q %v", resp.StatusCode, body, resp.Header)
+	}
+	resp, _ = do(t, "GET", srv.URL+"/objects/photo.jpg", nil, "Range", "bytes=10000-")
+	if resp.StatusCode != http.StatusRequestedRangeNotSatisfiable || resp.Header.Get("Content-Range") != "bytes */10000" {
+		t.Errorf("unsatisfiable range GET = %d %v", resp.StatusCode, resp.Header)
+	}
+
+	resp, body = do(t, "HEAD", srv.URL+"/objects/photo.jpg", nil)
+	if resp.StatusCode != http.StatusOK || len(body) != 0 || resp.ContentLength != 10000 {
+		t.Errorf("HEAD = %d, length %d", resp.StatusCode, resp.ContentLength)
+	}
+
+	resp, body = do(t, "GET", srv.URL+"/objects/photo.jpg/stat?version="+obj.Digest, nil)
+	var stat Object
+	if err := json.Unmarshal(body, &stat); err != nil || resp.StatusCode != http.StatusOK || stat.Digest != obj.Digest {
+		t.Errorf("stat = %d %s", resp.StatusCode, body)
+	}
+
+	// a second PUT adds a version; the first stays readable by its digest
+	resp, body = do(t, "PUT", srv.URL+"/objects/photo.jpg", strings.NewReader("again"))
+	var next Object
+	if json.Unmarshal(body, &next); resp.StatusCode != http.StatusCreated || next.Generation != obj.Generation+1 {
+		t.Fatalf("second PUT = %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, "GET", srv.URL+"/objects/photo.jpg", nil); string(body) != "again" || resp.Header.Get("X-Object-Generation") != "1" {
+		t.Errorf("GET after second PUT = %d %q", resp.StatusCode, body)
+	}
+	if resp, body := do(t, "GET", srv.URL+"/objects/photo.jpg?version="+obj.Digest, nil); !bytes.Equal(body, data) || resp.Header.Get("ETag") != tag {
+		t.Errorf("GET of the first version = %d, %d bytes", resp.StatusCode, len(body))
+	}
+	if resp, _ := do(t, "GET", srv.URL+"/objects/photo.jpg?version=00", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET of an unknown version = %d, want 404", resp.StatusCode)
+	}
+	resp, body = do(t, "GET", srv.URL+"/objects/photo.jpg/versions", nil)
+	var vl struct{ Versions []Object }
+	if err := json.Unmarshal(body, &vl); err != nil || resp.StatusCode != http.StatusOK || len(vl.Versions) != 2 ||
+		vl.Versions[0].Digest != next.Digest || vl.Versions[1].Digest != obj.Digest || vl.Versions[1].Superseded == nil {
+		t.Errorf("versions = %d %s", resp.StatusCode, body)
+	}
+
+	// conditional PUTs
+	if resp, body := do(t, "PUT", srv.URL+"/objects/photo.jpg", strings.NewReader("x"), "If-None-Match", "*"); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("PUT If-None-Match over an object = %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, "PUT", srv.URL+"/objects/photo.jpg", strings.NewReader("x"), "If-Match", tag); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("PUT If-Match of a superseded version = %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, "PUT", srv.URL+"/objects/photo.jpg", strings.NewReader("x"), "If-Match", `"`+next.Digest+`"`); resp.StatusCode != http.StatusCreated {
+		t.Errorf("PUT If-Match of the current version = %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, "PUT", srv.URL+"/objects/fresh", strings.NewReader("x"), "If-None-Match", "*"); resp.StatusCode != http.StatusCreated {
+		t.Errorf("PUT If-None-Match of a new object = %d %s", resp.StatusCode, body)
+	}
+
+	if resp, _ := do(t, "DELETE", srv.URL+"/objects/photo.jpg", nil); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE = %d, want 204", resp.StatusCode)
+	}
+	resp, body = do(t, "GET", srv.URL+"/objects/photo.jpg", nil)
+	var e struct{ Error string }
+	if json.Unmarshal(body, &e); resp.StatusCode != http.StatusNotFound || e.Error == "" {
+		t.Errorf("GET after DELETE = %d %s", resp.StatusCode, body)
+	}
+	if resp, _ := do(t, "HEAD", srv.URL+"/objects/photo.jpg", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("HEAD after DELETE = %d, want 404", resp.StatusCode)
+	}
+	if resp, _ := do(t, "DELETE", srv.URL+"/objects/photo.jpg", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("second DELETE = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestBadRequests(t *testing.T) {
+	store, srv := newServer(t)
+	for _, path := range []string{
+		"/objects/%2E%2E",
+		"/objects/%2E",
+		"/objects/a%2Fb",
+		"/objects/a%5Cb",
+		"/objects/" + strings.Repeat("x", protocol.MaxID+1),
+		"/objects/ok?m=zero",
+		"/objects/ok?dedup=maybe",
+	} {
+		if resp, body := do(t, "PUT", srv.URL+path, strings.NewReader("x")); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("PUT %s = %d %s, want 400", path, resp.StatusCode, body)
+		}
+	}
+	if len(store.objects) != 0 {
+		t.Errorf("bad PUTs stored %d objects", len(store.objects))
+	}
+	if resp, _ := do(t, "GET", srv.URL+"/objects?limit=-1", nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("GET /objects?limit=-1 = %d, want 400", resp.StatusCode)
+	}
+	if resp, _ := do(t, "POST", srv.URL+"/objects/ok", nil); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST = %d, want 405", resp.StatusCode)
+	}
+}
+
+func TestList(t *testing.T) {
+	_, srv := newServer(t)
+	for _, id := range []string{"a1", "a2", "a3", "b1"} {
+		if resp, body := do(t, "PUT", srv.URL+"/objects/"+id, strings.NewReader(id)); resp.StatusCode != http.StatusCreated {
+			t.Fatalf("PUT %s = %d %s", id, resp.StatusCode, body)
+		}
+	}
+	var got []string
+	after := ""
+	for {
+		resp, body := do(t, "GET", srv.URL+"/objects?prefix=a&start_after="+after, nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("list = %d %s", resp.StatusCode, body)
+		}
+		var l listing
+		if err := json.Unmarshal(body, &l); err != nil {
+			t.Fatal(err)
+		}
+		for _, o := range l.Objects {
+			if _, err := hex.DecodeString(o.Digest); err != nil || o.Digest == "" {
+				t.Errorf("%s: digest %q", o.ID, o.Digest)
+			}
+			got = append(got, o.ID)
+		}
+		if l.Next == "" {
+			break
+		}
+		after = l.Next
+	}
+	if want := []string{"a1", "a2", "a3"}; !slices.Equal(got, want) {
+		t.Errorf("listed %v, want %v", got, want)
+	}
+
+	resp, body := do(t, "GET", srv.URL+"/status", nil)
+	var st map[string]any
+	if err := json.Unmarshal(body, &st); err != nil || resp.StatusCode != http.StatusOK || st["self"] != "node1" {
+		t.Errorf("status = %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	store, srv := newServer(t)
+	resp, body := do(t, "PUT", srv.URL+"/objects/notes.txt", strings.NewReader("hello"),
+		"Content-Type", "text/plain", "X-Object-Meta-Owner", "ci", "X-Object-Tags", "env=prod&team=a")
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("PUT = %d %s", resp.StatusCode, body)
+	}
+	if want := map[string]string{"content-type": "text/plain", "owner": "ci"}; !reflect.DeepEqual(store.opts.Metadata, want) {
+		t.Errorf("Put metadata %v, want %v", store.opts.Metadata, want)
+	}
+	if want := map[string]string{"env": "prod", "team": "a"}; !reflect.DeepEqual(store.opts.Tags, want) {
+		t.Errorf("Put tags %v, want %v", store.opts.Tags, want)
+	}
+	if resp, body := do(t, "PUT", srv.URL+"/objects/other", strings.NewReader("x"), "X-Object-Tags", "env=dev"); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("PUT = %d %s", resp.StatusCode, body)
+	}
+
+	resp, _ = do(t, "HEAD", srv.URL+"/objects/notes.txt", nil)
+	if h := resp.Header; h.Get("Content-Type") != "text/plain" || h.Get("X-Object-Meta-Owner") != "ci" || h.Get("X-Object-Tags") != "env=prod&team=a" {
+		t.Errorf("HEAD headers %v", h)
+	}
+	resp, body = do(t, "GET", srv.URL+"/objects/notes.txt/stat", nil)
+	var obj Object
+	if err := json.Unmarshal(body, &obj); err != nil || obj.Metadata["owner"] != "ci" || obj.Tags["team"] != "a" {
+		t.Errorf("stat = %d %s", resp.StatusCode, body)
+	}
+
+	resp, body = do(t, "GET", srv.URL+"/objects?tag=env=prod", nil)
+	var l listing
+	if err := json.Unmarshal(body, &l); err != nil || len(l.Objects) != 1 || l.Objects[0].ID != "notes.txt" {
+		t.Errorf("list by tag = %d %s", resp.StatusCode, body)
+	}
+	if resp, _ := do(t, "PUT", srv.URL+"/objects/bad", strings.NewReader("x"), "X-Object-Tags", "a=%zz"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("PUT with malformed tags = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	store, srv := newServer(t)
+	if resp, body := do(t, "PUT", srv.URL+"/objects/hourly?ttl=1h", strings.NewReader("x")); resp.StatusCode != http.StatusCreated || store.opts.TTL != time.Hour {
+		t.Fatalf("PUT ?ttl = %d %s, TTL %v", resp.StatusCode, body, store.opts.TTL)
+	}
+	resp, _ := do(t, "HEAD", srv.URL+"/objects/hourly", nil)
+	if e, err := http.ParseTime(resp.Header.Get("X-Object-Expires")); err != nil || e.Before(time.Now().Add(59*time.Minute)) {
+		t.Errorf("HEAD X-Object-Expires %q", resp.Header.Get("X-Object-Expires"))
+	}
+	resp, body := do(t, "PUT", srv.URL+"/objects/kept?retain=true", strings.NewReader("x"))
+	var obj Object
+	if err := json.Unmarshal(body, &obj); err != nil || !obj.Retain || obj.Expires != nil {
+		t.Errorf("PUT ?retain = %d %s", resp.StatusCode, body)
+	}
+	if _, body := do(t, "PUT", srv.URL+"/objects/dated?expires=2100-01-02T03:04:05Z", strings.NewReader("x")); !store.opts.Expires.Equal(time.Date(2100, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("PUT ?expires: %s, Expires %v", body, store.opts.Expires)
+	}
+	for _, q := range []string{"ttl=soon", "expires=tomorrow", "retain=maybe"} {
+		if resp, _ := do(t, "PUT", srv.URL+"/objects/bad?"+q, strings.NewReader("x")); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("PUT ?%s = %d, want 400", q, resp.StatusCode)
+		}
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	for _, tc := range []struct {
+		spec            string
+		off, length     int64
+		ok, satisfiable bool
+	}{
+		{"bytes=0-9", 0, 10, true, true},
+		{"bytes=95-", 95, 5, true, true},
+		{"bytes=90-200", 90, 10, true, true},
+		{"bytes=-10", 90, 10, true, true},
+		{"bytes=-500", 0, 100, true, true},
+		{"bytes=100-", 0, 0, false, false},
+		{"bytes=-0", 0, 0, false, false},
+		{"bytes=5-1", 0, 0, false, true},
+		{"bytes=0-1,5-6", 0, 0, false, true},
+		{"items=0-1", 0, 0, false, true},
+	} {
+		off, length, ok, sat := parseRange(tc.spec, 100)
+		if off != tc.off || length != tc.length || ok != tc.ok || sat != tc.satisfiable {
+			t.Errorf("parseRange(%q) = %d, %d, %v, %v", tc.spec, off, length, ok, sat)
+		}
+	}
+}
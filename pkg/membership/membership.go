@@ -0,0 +1,191 @@
+// pkg/membership/membership.go
+package membership
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+// Initial builds the epoch‑0 view from a static peer list (YAML / -peers)
+// and optional per‑node topology labels keyed by address.
+func Initial(peers []string, labels map[string]map[string]string) *protocol.View {
+	v := &protocol.View{}
+	for _, p := range peers {
+		if p != "" && !Contains(v, p) {
+			v.Members = append(v.Members, &protocol.Member{Addr: p, Labels: labels[p]})
+		}
+	}
+	return v
+}
+
+// Addrs returns the member addresses of v in view order.
+func Addrs(v *protocol.View) []string {
+	out := make([]string, 0, len(v.GetMembers()))
+	for _, m := range v.GetMembers() {
+		out = append(out, m.Addr)
+	}
+	return out
+}
+
+// Writable returns the members that accept new fragments, i.e. everyone
+// except nodes being drained.
+func Writable(v *protocol.View) []string {
+	out := make([]string, 0, len(v.GetMembers()))
+	for _, m := range v.GetMembers() {
+		if m.State == protocol.MemberState_ACTIVE {
+			out = append(out, m.Addr)
+		}
+	}
+	return out
+}
+
+// IsDraining reports whether addr is a member of v that is being drained.
+func IsDraining(v *protocol.View, addr string) bool {
+	for _, m := range v.GetMembers() {
+		if m.Addr == addr {
+			return m.State == protocol.MemberState_DRAINING
+		}
+	}
+	return false
+}
+
+// Contains reports whether addr is a member of v.
+func Contains(v *protocol.View, addr string) bool {
+	for _, m := range v.GetMembers() {
+		if m.Addr == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// Equal reports whether a and b describe the same epoch and member list.
+func Equal(a, b *protocol.View) bool {
+	if a.GetEpoch() != b.GetEpoch() || len(a.GetMembers()) != len(b.GetMembers()) ||
+		a.GetFailureDomain() != b.GetFailureDomain() {
+		return false
+	}
+	for i := range a.Members {
+		x, y := a.Members[i], b.Members[i]
+		if x.Addr != y.Addr || x.State != y.State || !maps.Equal(x.Labels, y.Labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// next copies v with the epoch bumped; member structs are shared read‑only.
+func next(v *protocol.View) *protocol.View {
+	return &protocol.View{
+		Epoch:         v.GetEpoch() + 1,
+		Members:       append([]*protocol.Member{}, v.GetMembers()...),
+		FailureDomain: v.GetFailureDomain(),
+	}
+}
+
+// Add returns the successor of v with addr appended.
+func Add(v *protocol.View, addr string, labels map[string]string) (*protocol.View, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("empty node address")
+	}
+	if Contains(v, addr) {
+		return nil, fmt.Errorf("%s is already a member", addr)
+	}
+	nv := next(v)
+	nv.Members = append(nv.Members, &protocol.Member{Addr: addr, Labels: labels})
+	return nv, nil
+}
+
+// Remove returns the successor of v without addr.
+func Remove(v *protocol.View, addr string) (*protocol.View, error) {
+	if !Contains(v, addr) {
+		return nil, fmt.Errorf("%s is not a member", addr)
+	}
+	if len(v.Members) == 1 {
+		return nil, fmt.Errorf("cannot remove the last member")
+	}
+	nv := next(v)
+	nv.Members = nv.Members[:0]
+	for _, m := range v.Members {
+		if m.Addr != addr {
+			nv.Members = append(nv.Members, m)
+		}
+	}
+	return nv, nil
+}
+
+// Replace returns the successor of v with oldAddr swapped for newAddr in place,
+// so the replacement inherits the old node's position in the view and, unless
+// labels are given, its topology labels.
+func Replace(v *protocol.View, oldAddr, newAddr string, labels map[string]string) (*protocol.View, error) {
+	if !Contains(v, oldAddr) {
+		return nil, fmt.Errorf("%s is not a member", oldAddr)
+	}
+	if newAddr == "" || Contains(v, newAddr) {
+		return nil, fmt.Errorf("invalid replacement %q", newAddr)
+	}
+	nv := next(v)
+	for i, m := range nv.Members {
+		if m.Addr == oldAddr {
+			if labels == nil {
+				labels = m.Labels
+			}
+			nv.Members[i] = &protocol.Member{Addr: newAddr, Labels: labels}
+		}
+	}
+	return nv, nil
+}
+
+// Drain returns the successor of v with addr marked read‑only.
+func Drain(v *protocol.View, addr string) (*protocol.View, error) {
+	if !Contains(v, addr) {
+		return nil, fmt.Errorf("%s is not a member", addr)
+	}
+	if IsDraining(v, addr) {
+		return nil, fmt.Errorf("%s is already draining", addr)
+	}
+	if len(Writable(v)) == 1 {
+		return nil, fmt.Errorf("cannot drain the last writable member")
+	}
+	nv := next(v)
+	for i, m := range nv.Members {
+		if m.Addr == addr {
+			nv.Members[i] = &protocol.Member{Addr: addr, State: protocol.MemberState_DRAINING, Labels: m.Labels}
+		}
+	}
+	return nv, nil
+}
+
+// Majority is the number of acknowledgements a view change needs from the
+// members of the view it replaces.
+func Majority(v *protocol.View) int {
+	return len(v.GetMembers())/2 + 1
+}
+
+// Quorum holds the Bracha thresholds in force for one epoch.
+type Quorum struct {
+	F     int // tolerated faulty nodes
+	Echo  int // distinct Echoes needed before sending Ready
+	Ready int // distinct Readies needed before committing
+}
+
+// QuorumFor derives thresholds for a cluster of size nodes running an
+// m‑of‑n code. With size == n this is the classic f = n‑m, Echo = m+f,
+// Ready = 2f+1; smaller or larger clusters clamp f so both thresholds stay
+// reachable by the nodes that actually exist. A group with fewer nodes than
+// m stacks fragments, so there every node must echo.
+func QuorumFor(size, m, n int) Quorum {
+	f := n - m
+	if size-m < f {
+		f = size - m
+	}
+	if (size-1)/2 < f {
+		f = (size - 1) / 2
+	}
+	if f < 0 {
+		f = 0
+	}
+	return Quorum{F: f, Echo: min(m+f, size), Ready: 2*f + 1}
+}
@@ -0,0 +1,80 @@
+// pkg/membership/membership_test.go
+package membership
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestViewChanges(t *testing.T) {
+	v := Initial([]string{"a:1", "b:2", "c:3", "a:1"}, map[string]map[string]string{"b:2": {"zone": "z2"}})
+	if v.Epoch != 0 || !reflect.DeepEqual(Addrs(v), []string{"a:1", "b:2", "c:3"}) {
+		t.Fatalf("Initial: epoch=%d members=%v", v.Epoch, Addrs(v))
+	}
+
+	v1, err := Add(v, "d:4", nil)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if v1.Epoch != 1 || len(v.Members) != 3 {
+		t.Errorf("Add must bump the epoch and leave the old view intact")
+	}
+	if _, err := Add(v1, "d:4", nil); err == nil {
+		t.Errorf("Add of an existing member should fail")
+	}
+
+	v2, err := Replace(v1, "b:2", "e:5", nil)
+	if err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+	if v2.Members[1].Labels["zone"] != "z2" {
+		t.Errorf("Replace must inherit the old node's labels, got %v", v2.Members[1].Labels)
+	}
+	if want := []string{"a:1", "e:5", "c:3", "d:4"}; !reflect.DeepEqual(Addrs(v2), want) {
+		t.Errorf("Replace: got %v, want %v", Addrs(v2), want)
+	}
+
+	v3, err := Remove(v2, "a:1")
+	if err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if v3.Epoch != 3 || Contains(v3, "a:1") || !Contains(v2, "a:1") {
+		t.Errorf("Remove: epoch=%d members=%v", v3.Epoch, Addrs(v3))
+	}
+	if Equal(v2, v3) || !Equal(v3, v3) {
+		t.Errorf("Equal misreports view identity")
+	}
+
+	v4, err := Drain(v3, "c:3")
+	if err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+	if !IsDraining(v4, "c:3") || IsDraining(v3, "c:3") {
+		t.Errorf("Drain must mark only the successor view")
+	}
+	if want := []string{"e:5", "d:4"}; !reflect.DeepEqual(Writable(v4), want) {
+		t.Errorf("Writable: got %v, want %v", Writable(v4), want)
+	}
+	if _, err := Drain(v4, "c:3"); err == nil {
+		t.Errorf("draining twice should fail")
+	}
+}
+
+func TestQuorumFor(t *testing.T) {
+	cases := []struct {
+		size, m, n int
+		want       Quorum
+	}{
+		{5, 3, 5, Quorum{F: 2, Echo: 5, Ready: 5}}, // classic 3‑of‑5
+		{6, 4, 6, Quorum{F: 2, Echo: 6, Ready: 5}},
+		{6, 3, 5, Quorum{F: 2, Echo: 5, Ready: 5}}, // extra node, same f
+		{4, 3, 5, Quorum{F: 1, Echo: 4, Ready: 3}}, // node removed
+		{2, 3, 5, Quorum{F: 0, Echo: 2, Ready: 1}}, // fragments stacked
+		{6, 7, 10, Quorum{F: 0, Echo: 6, Ready: 1}},
+	}
+	for _, c := range cases {
+		if got := QuorumFor(c.size, c.m, c.n); got != c.want {
+			t.Errorf("QuorumFor(%d,%d,%d) = %+v, want %+v", c.size, c.m, c.n, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// pkg/merkle/merkle.go
+// Package merkle builds Merkle trees over fragment blocks (RFC 6962 shape:
+// the left subtree of n leaves holds the largest power of two below n) and
+// checks inclusion proofs, so a block can be verified against a fragment's
+// root without the rest of the fragment.
+//
+// Leaves are the per-block SHA-256 sums from package blockhash; leaf and
+// interior nodes are domain-separated so neither can pose as the other.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+)
+
+func leafNode(sum []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write(sum)
+	return h.Sum(nil)
+}
+
+func interior(l, r []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(l)
+	h.Write(r)
+	return h.Sum(nil)
+}
+
+// split is the number of leaves in the left subtree of an n-leaf tree.
+func split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// Root returns the root of the tree over leaves; an empty fragment's root
+// is the hash of nothing.
+func Root(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		h := sha256.Sum256(nil)
+		return h[:]
+	case 1:
+		return leafNode(leaves[0])
+	}
+	k := split(len(leaves))
+	return interior(Root(leaves[:k]), Root(leaves[k:]))
+}
+
+// Proof returns the sibling hashes from leaf i up to the root.
+func Proof(leaves [][]byte, i int) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := split(len(leaves))
+	if i < k {
+		return append(Proof(leaves[:k], i), Root(leaves[k:]))
+	}
+	return append(Proof(leaves[k:], i-k), Root(leaves[:k]))
+}
+
+// Verify reports whether leaf is leaf i of an n-leaf tree with the given
+// root, using proof as returned by Proof.
+func Verify(root, leaf []byte, i, n int, proof [][]byte) bool {
+	if i < 0 || i >= n {
+		return false
+	}
+	node, rest, ok := climb(leafNode(leaf), i, n, proof)
+	return ok && len(rest) == 0 && bytes.Equal(node, root)
+}
+
+// climb folds proof into node for leaf i of an n-leaf subtree, consuming
+// siblings from the front (deepest first), and returns the subtree root.
+func climb(node []byte, i, n int, proof [][]byte) ([]byte, [][]byte, bool) {
+	if n == 1 {
+		return node, proof, true
+	}
+	k := split(n)
+	var ok bool
+	if i < k {
+		if node, proof, ok = climb(node, i, k, proof); !ok || len(proof) == 0 {
+			return nil, nil, false
+		}
+		return interior(node, proof[0]), proof[1:], true
+	}
+	if node, proof, ok = climb(node, i-k, n-k, proof); !ok || len(proof) == 0 {
+		return nil, nil, false
+	}
+	return interior(proof[0], node), proof[1:], true
+}
@@ -0,0 +1,49 @@
+// pkg/merkle/merkle_test.go
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/dattu/distributed_object_store/pkg/blockhash"
+)
+
+func leaves(n int) [][]byte {
+	out := make([][]byte, n)
+	for i := range out {
+		out[i] = blockhash.Sum([]byte(fmt.Sprintf("block %d", i)))
+	}
+	return out
+}
+
+func TestProofsVerify(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		ls := leaves(n)
+		root := Root(ls)
+		for i := 0; i < n; i++ {
+			p := Proof(ls, i)
+			if !Verify(root, ls[i], i, n, p) {
+				t.Fatalf("n=%d: proof for leaf %d rejected", n, i)
+			}
+			if Verify(root, ls[(i+1)%n], i, n, p) && n > 1 {
+				t.Fatalf("n=%d: wrong leaf accepted at %d", n, i)
+			}
+			if n > 1 && Verify(root, ls[i], (i+1)%n, n, p) {
+				t.Fatalf("n=%d: leaf %d accepted at the wrong index", n, i)
+			}
+		}
+	}
+}
+
+func TestRootCommitsToEveryLeaf(t *testing.T) {
+	ls := leaves(5)
+	root := Root(ls)
+	ls[3] = blockhash.Sum([]byte("tampered"))
+	if bytes.Equal(Root(ls), root) {
+		t.Fatalf("changing a leaf kept the root")
+	}
+	if !bytes.Equal(Root(leaves(1)), leafNode(leaves(1)[0])) {
+		t.Fatalf("single-leaf root is not the leaf node")
+	}
+}
@@ -0,0 +1,147 @@
+// pkg/placement/placement.go
+package placement
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/dattu/distributed_object_store/pkg/erasure"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+)
+
+// ErrUnsatisfiable is returned when the nodes cannot host n fragments such
+// that losing any single failure domain still leaves m of them readable.
+var ErrUnsatisfiable = errors.New("placement policy unsatisfiable")
+
+// Node is a placement target with its topology labels.
+type Node struct {
+	Addr   string
+	Labels map[string]string
+}
+
+// Policy describes how an object's fragments must be spread.
+type Policy struct {
+	Domain string // label key naming the failure domain, e.g. "zone"; "" = per node
+	Data   int    // m: fragments needed to decode
+	Total  int    // n: fragments written
+}
+
+// domainOf returns the failure domain of node; nodes without the label form
+// a domain of their own.
+func (p Policy) domainOf(node Node) string {
+	if d, ok := node.Labels[p.Domain]; ok && p.Domain != "" {
+		return d
+	}
+	return "node:" + node.Addr
+}
+
+// score is the rendezvous weight of node for one fragment of one object.
+func score(objectID string, idx int, addr string) uint64 {
+	h := sha256.Sum256([]byte(objectID + "\x00" + strconv.Itoa(idx) + "\x00" + addr))
+	return binary.BigEndian.Uint64(h[:8])
+}
+
+// Place maps each of the policy's n fragments of objectID to a node using
+// rendezvous hashing: fragment i goes to the highest‑scoring node for
+// (objectID, i) that still has room. A node takes a second fragment only when
+// there are fewer nodes than fragments, and no failure domain (nor node) ever
+// takes more than n‑m, so any one of them can fail without costing more than
+// the code tolerates. Adding or removing a node only moves the fragments
+// whose top choice changed.
+func Place(objectID string, nodes []Node, p Policy) ([]string, error) {
+	limit := p.Total - p.Data
+	if p.Data <= 0 || limit < 0 {
+		return nil, fmt.Errorf("invalid shard parameters: data=%d, total=%d", p.Data, p.Total)
+	}
+
+	domains := make(map[string]bool)
+	for _, node := range nodes {
+		domains[p.domainOf(node)] = true
+	}
+	if len(domains)*limit < p.Total {
+		return nil, fmt.Errorf("%w: %d nodes in %d %q domains hold at most %d of %d fragments at ≤%d per domain",
+			ErrUnsatisfiable, len(nodes), len(domains), p.Domain, len(domains)*limit, p.Total, limit)
+	}
+
+	perNode := make(map[string]int)
+	perDomain := make(map[string]int)
+	out := make([]string, p.Total)
+	ranked := make([]Node, len(nodes))
+	scores := make(map[string]uint64, len(nodes))
+	for i := range out {
+		copy(ranked, nodes)
+		for _, node := range nodes {
+			scores[node.Addr] = score(objectID, i, node.Addr)
+		}
+		sort.SliceStable(ranked, func(a, b int) bool {
+			return scores[ranked[a].Addr] > scores[ranked[b].Addr]
+		})
+		// prefer a node without a fragment yet; stack only when we must
+		for _, nodeCap := range []int{1, limit} {
+			for _, node := range ranked {
+				d := p.domainOf(node)
+				if perNode[node.Addr] < nodeCap && perDomain[d] < limit {
+					perNode[node.Addr]++
+					perDomain[d]++
+					out[i] = node.Addr
+					break
+				}
+			}
+			if out[i] != "" {
+				break
+			}
+		}
+		if out[i] == "" {
+			return nil, fmt.Errorf("%w: no room for fragment %d", ErrUnsatisfiable, i)
+		}
+	}
+	return out, nil
+}
+
+// ForView places objectID's m‑of‑n fragments on the writable members of v,
+// spreading across v's failure domain.
+func ForView(v *protocol.View, objectID string, m, n int) ([]string, error) {
+	var nodes []Node
+	for _, mem := range v.GetMembers() {
+		if mem.State == protocol.MemberState_ACTIVE {
+			nodes = append(nodes, Node{Addr: mem.Addr, Labels: mem.Labels})
+		}
+	}
+	return Place(objectID, nodes, Policy{Domain: v.GetFailureDomain(), Data: m, Total: n})
+}
+
+// ForCodec places an object encoded with c. The per‑domain cap is the code's
+// tolerance, which is n‑m for MDS codes and less for locally repairable ones.
+func ForCodec(v *protocol.View, objectID string, c erasure.Codec) ([]string, error) {
+	_, n := c.Shards()
+	return ForView(v, objectID, n-c.Tolerance(), n)
+}
+
+// Group returns the distinct nodes of an owner list, in fragment order: the
+// set of nodes that take part in the object's Echo/Ready rounds.
+func Group(owners []string) []string {
+	seen := make(map[string]bool, len(owners))
+	var out []string
+	for _, o := range owners {
+		if !seen[o] {
+			seen[o] = true
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// Indices returns the fragment indices that owners assigns to node.
+func Indices(owners []string, node string) []int {
+	var out []int
+	for i, o := range owners {
+		if o == node {
+			out = append(out, i)
+		}
+	}
+	return out
+}
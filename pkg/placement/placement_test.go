@@ -0,0 +1,110 @@
+// pkg/placement/placement_test.go
+package placement
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func cluster(size int) []Node {
+	nodes := make([]Node, size)
+	for i := range nodes {
+		nodes[i] = Node{Addr: fmt.Sprintf("node%02d:50051", i)}
+	}
+	return nodes
+}
+
+func TestPlacePerNode(t *testing.T) {
+	p := Policy{Data: 4, Total: 6}
+	owners, err := Place("obj", cluster(30), p)
+	if err != nil {
+		t.Fatalf("Place: %v", err)
+	}
+	if g := Group(owners); len(g) != p.Total {
+		t.Errorf("30 nodes, 4+2: want 6 distinct owners, got %v", owners)
+	}
+
+	// fewer nodes than fragments: stack, but never more than n-m per node
+	owners, err = Place("obj", cluster(3), Policy{Data: 3, Total: 5})
+	if err != nil {
+		t.Fatalf("Place: %v", err)
+	}
+	for _, addr := range Group(owners) {
+		if k := len(Indices(owners, addr)); k > 2 {
+			t.Errorf("%s holds %d fragments, want ≤2", addr, k)
+		}
+	}
+
+	if _, err := Place("obj", cluster(2), Policy{Data: 3, Total: 5}); !errors.Is(err, ErrUnsatisfiable) {
+		t.Errorf("2 nodes cannot lose one and keep 3 of 5: err=%v", err)
+	}
+}
+
+func TestPlaceSpreadsDomains(t *testing.T) {
+	zone := func(addr, z string) Node { return Node{Addr: addr, Labels: map[string]string{"zone": z}} }
+	nodes := []Node{
+		zone("a1", "a"), zone("a2", "a"), zone("a3", "a"), zone("a4", "a"),
+		zone("b1", "b"), zone("b2", "b"),
+		zone("c1", "c"),
+	}
+	p := Policy{Domain: "zone", Data: 4, Total: 6}
+	for _, id := range []string{"x", "y", "z", "build-1234"} {
+		owners, err := Place(id, nodes, p)
+		if err != nil {
+			t.Fatalf("Place(%s): %v", id, err)
+		}
+		perZone := make(map[string]int)
+		for _, addr := range owners {
+			perZone[addr[:1]]++
+		}
+		for z, cnt := range perZone {
+			if cnt > p.Total-p.Data {
+				t.Errorf("%s: zone %s holds %d fragments, losing it leaves fewer than %d", id, z, cnt, p.Data)
+			}
+		}
+	}
+
+	// two zones of capacity 2 cannot host 6 fragments
+	if _, err := Place("x", nodes[:6], p); !errors.Is(err, ErrUnsatisfiable) {
+		t.Errorf("want ErrUnsatisfiable, got %v", err)
+	}
+}
+
+func TestPlaceMovesLittle(t *testing.T) {
+	p := Policy{Data: 4, Total: 6}
+	nodes := cluster(30)
+	const objects = 500
+	added, removed := 0, 0
+	for i := 0; i < objects; i++ {
+		id := fmt.Sprintf("obj-%d", i)
+		before, _ := Place(id, nodes, p)
+
+		after, _ := Place(id, append(nodes, Node{Addr: "new:50051"}), p)
+		for j := range before {
+			if before[j] != after[j] {
+				added++
+			}
+		}
+
+		gone := nodes[i%len(nodes)].Addr
+		var rest []Node
+		for _, n := range nodes {
+			if n.Addr != gone {
+				rest = append(rest, n)
+			}
+		}
+		shrunk, _ := Place(id, rest, p)
+		for j := range before {
+			if before[j] != shrunk[j] {
+				removed++
+			}
+		}
+	}
+	// ideal movement is n/N fragments per object; allow 1.5x for the
+	// occasional knock-on move when a node frees up or fills
+	ideal := objects * p.Total / len(nodes)
+	if added > ideal*3/2 || removed > ideal*3/2 {
+		t.Errorf("moved %d (add) / %d (remove) fragments, ideal ≈ %d", added, removed, ideal)
+	}
+}
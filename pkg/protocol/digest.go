@@ -0,0 +1,118 @@
+// pkg/protocol/digest.go – canonical FPCC digest.
+// Echo and Ready carry this digest instead of the FPCC itself, and nodes
+// compare FPCCs by it, so it has to be a fixed encoding rather than proto
+// wire bytes (which do not promise a canonical form).
+
+package protocol
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"maps"
+	"slices"
+)
+
+// digestDomain prefixes the encoding so the digest cannot collide with a
+// hash of anything else the system computes.
+const digestDomain = "avid-fp/fpcc/v1"
+
+// Digest returns the SHA-256 of f's canonical encoding: every field, fixed
+// order, big-endian integers and length-prefixed byte strings. A missing
+// profile encodes like an all-zero one, as older objects have none; a
+// missing envelope, compression or manifest – or an unset transcoded flag,
+// precondition, metadata, tags, expiry, retention or creation time – adds
+// nothing, so objects written before those fields keep their digest.
+// Metadata and tags are encoded in key order.
+func (f *FPCC) Digest() []byte {
+	h := sha256.New()
+	var buf [8]byte
+	u64 := func(v uint64) {
+		binary.BigEndian.PutUint64(buf[:], v)
+		h.Write(buf[:])
+	}
+	blob := func(b []byte) {
+		u64(uint64(len(b)))
+		h.Write(b)
+	}
+
+	h.Write([]byte(digestDomain))
+	u64(f.GetSeed())
+	u64(f.GetSize())
+	u64(f.GetGeneration())
+	p := f.GetProfile()
+	u64(uint64(p.GetData()))
+	u64(uint64(p.GetTotal()))
+	u64(uint64(p.GetStripe()))
+	blob([]byte(p.GetCodec()))
+	u64(uint64(len(f.GetHashes())))
+	for i, hash := range f.GetHashes() {
+		blob(hash)
+		var fp uint64
+		if i < len(f.GetFps()) {
+			fp = f.GetFps()[i]
+		}
+		u64(fp)
+	}
+	u64(uint64(len(f.GetFps())))
+	u64(uint64(len(f.GetRoots())))
+	for _, root := range f.GetRoots() {
+		blob(root)
+	}
+	if e := f.GetEnvelope(); e != nil {
+		u64(9) // optional fields are tagged with their field number
+		blob([]byte(e.GetKeyId()))
+		blob(e.GetWrappedKey())
+		blob(e.GetSalt())
+		u64(uint64(e.GetIterations()))
+		u64(uint64(e.GetSegment()))
+		u64(e.GetSize())
+	}
+	if c := f.GetCompression(); c != nil {
+		u64(10)
+		blob([]byte(c.GetCodec()))
+		u64(c.GetSize())
+	}
+	if man := f.GetManifest(); man != nil {
+		u64(11)
+		u64(uint64(len(man.GetChunks())))
+		for _, c := range man.GetChunks() {
+			blob(c.GetHash())
+			u64(c.GetSize())
+		}
+	}
+	if f.GetTranscoded() {
+		u64(12)
+	}
+	if f.GetIfNoneMatch() {
+		u64(13)
+	}
+	if v := f.GetIfMatchVersion(); len(v) > 0 {
+		u64(14)
+		blob(v)
+	}
+	pairs := func(tag uint64, m map[string]string) {
+		if len(m) == 0 {
+			return
+		}
+		u64(tag)
+		u64(uint64(len(m)))
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			blob([]byte(k))
+			blob([]byte(m[k]))
+		}
+	}
+	pairs(15, f.GetMetadata())
+	pairs(16, f.GetTags())
+	if v := f.GetExpiresUnix(); v != 0 {
+		u64(17)
+		u64(uint64(v))
+	}
+	if f.GetRetain() {
+		u64(18)
+	}
+	if v := f.GetCreatedUnix(); v != 0 {
+		u64(19)
+		u64(uint64(v))
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,143 @@
+// pkg/protocol/digest_test.go
+package protocol
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func sampleFPCC() *FPCC {
+	return &FPCC{
+		Hashes:      [][]byte{{1, 2}, {3, 4}, {5, 6}},
+		Fps:         []uint64{7, 8, 9},
+		Seed:        42,
+		Profile:     &Profile{Data: 2, Total: 3, Codec: "rs", Stripe: 1 << 20},
+		Size:        1000,
+		Generation:  1,
+		Roots:       [][]byte{{10}, {11}, {12}},
+		Envelope:    &Envelope{KeyId: "k1", WrappedKey: []byte{14}, Segment: 64 << 10, Size: 900},
+		Compression: &Compression{Codec: "flate", Size: 5000},
+		Manifest:    &Manifest{Chunks: []*ChunkRef{{Hash: []byte{17}, Size: 300}, {Hash: []byte{18}, Size: 800}}},
+		Metadata:    map[string]string{"content-type": "text/plain", "owner": "ci"},
+		Tags:        map[string]string{"build": "42"},
+	}
+}
+
+func TestDigestCoversEveryField(t *testing.T) {
+	base := sampleFPCC().Digest()
+	if !bytes.Equal(base, proto.Clone(sampleFPCC()).(*FPCC).Digest()) {
+		t.Fatalf("digest is not deterministic")
+	}
+	for name, mutate := range map[string]func(*FPCC){
+		"hash":           func(f *FPCC) { f.Hashes[1][0] ^= 1 },
+		"fp":             func(f *FPCC) { f.Fps[2]++ },
+		"seed":           func(f *FPCC) { f.Seed++ },
+		"codec":          func(f *FPCC) { f.Profile.Codec = "lrc" },
+		"stripe":         func(f *FPCC) { f.Profile.Stripe = 0 },
+		"size":           func(f *FPCC) { f.Size++ },
+		"generation":     func(f *FPCC) { f.Generation = 0 },
+		"root":           func(f *FPCC) { f.Roots[0] = []byte{13} },
+		"no roots":       func(f *FPCC) { f.Roots = nil },
+		"key id":         func(f *FPCC) { f.Envelope.KeyId = "k2" },
+		"wrapped":        func(f *FPCC) { f.Envelope.WrappedKey = []byte{15} },
+		"salt":           func(f *FPCC) { f.Envelope.Salt = []byte{16} },
+		"plain size":     func(f *FPCC) { f.Envelope.Size++ },
+		"no envelope":    func(f *FPCC) { f.Envelope = nil },
+		"compression":    func(f *FPCC) { f.Compression.Codec = "zstd" },
+		"raw size":       func(f *FPCC) { f.Compression.Size++ },
+		"no compression": func(f *FPCC) { f.Compression = nil },
+		"chunk hash":     func(f *FPCC) { f.Manifest.Chunks[1].Hash = []byte{19} },
+		"chunk size":     func(f *FPCC) { f.Manifest.Chunks[0].Size++ },
+		"chunk order":    func(f *FPCC) { f.Manifest.Chunks[0], f.Manifest.Chunks[1] = f.Manifest.Chunks[1], f.Manifest.Chunks[0] },
+		"no manifest":    func(f *FPCC) { f.Manifest = nil },
+		"transcoded":     func(f *FPCC) { f.Transcoded = true },
+		"if none match":  func(f *FPCC) { f.IfNoneMatch = true },
+		"if match":       func(f *FPCC) { f.IfMatchVersion = []byte{1} },
+		"metadata":       func(f *FPCC) { f.Metadata["owner"] = "cd" },
+		"metadata key":   func(f *FPCC) { delete(f.Metadata, "owner"); f.Metadata["owners"] = "ci" },
+		"no metadata":    func(f *FPCC) { f.Metadata = nil },
+		"tag":            func(f *FPCC) { f.Tags["build"] = "43" },
+		"no tags":        func(f *FPCC) { f.Tags = nil },
+		"tag as meta":    func(f *FPCC) { f.Metadata["build"] = "42"; f.Tags = nil },
+		"meta boundary":  func(f *FPCC) { delete(f.Metadata, "owner"); f.Metadata["own"] = "erci" },
+		"expires":        func(f *FPCC) { f.ExpiresUnix = 1700000000 },
+		"retain":         func(f *FPCC) { f.Retain = true },
+		"created":        func(f *FPCC) { f.CreatedUnix = 1700000000 },
+		// moving a byte across a field boundary must change the encoding
+		"boundary": func(f *FPCC) { f.Hashes[0], f.Hashes[1] = []byte{1, 2, 3}, []byte{4} },
+	} {
+		f := sampleFPCC()
+		mutate(f)
+		if bytes.Equal(f.Digest(), base) {
+			t.Errorf("changing %s kept the digest", name)
+		}
+	}
+	if !bytes.Equal((&FPCC{Hashes: [][]byte{{1}}, Fps: []uint64{1}}).Digest(),
+		(&FPCC{Hashes: [][]byte{{1}}, Fps: []uint64{1}, Profile: &Profile{}}).Digest()) {
+		t.Errorf("nil and empty profile differ")
+	}
+}
+func TestCheckMetadata(t *testing.T) {
+	for _, c := range []struct {
+		meta, tags map[string]string
+		ok         bool
+	}{
+		{nil, nil, true},
+		{map[string]string{"content-type": "text/plain"}, map[string]string{"env": "prod"}, true},
+		{map[string]string{"empty": ""}, nil, true},
+		{map[string]string{"": "x"}, nil, false},
+		{nil, map[string]string{"a=b": "c"}, false},
+		{nil, map[string]string{"a,b": "c"}, false},
+		{map[string]string{"k": "line\nbreak"}, nil, false},
+		{map[string]string{"k": string(make([]byte, MaxMetadata))}, nil, false},
+	} {
+		if err := CheckMetadata(c.meta, c.tags); (err == nil) != c.ok {
+			t.Errorf("CheckMetadata(%v, %v) = %v, want ok=%v", c.meta, c.tags, err, c.ok)
+		}
+	}
+}
+
+func TestCheckID(t *testing.T) {
+	for id, ok := range map[string]bool{
+		"report.pdf":                 true,
+		"chunk:00ff":                 true,
+		"..hidden":                   true,
+		string(make([]byte, MaxID)):  false, // NULs
+		strings.Repeat("x", MaxID):   true,
+		strings.Repeat("x", MaxID+1): false,
+		"":                           false,
+		".":                          false,
+		"..":                         false,
+		"../x":                       false,
+		"a/b":                        false,
+		`a\b`:                        false,
+		"c#1":                        false,
+		"c|peer":                     false,
+		"c@digest":                   false,
+	} {
+		if err := CheckID(id); (err == nil) != ok {
+			t.Errorf("CheckID(%.20q) = %v, want ok=%v", id, err, ok)
+		}
+	}
+}
+
+func TestContentSize(t *testing.T) {
+	f := sampleFPCC()
+	for _, c := range []struct {
+		strip func(*FPCC)
+		want  uint64
+	}{
+		{func(*FPCC) {}, 1100},                       // chunks: 300 + 800
+		{func(f *FPCC) { f.Manifest = nil }, 5000},   // uncompressed
+		{func(f *FPCC) { f.Compression = nil }, 900}, // plaintext
+		{func(f *FPCC) { f.Envelope = nil }, 1000},   // as stored
+	} {
+		c.strip(f)
+		if got := f.ContentSize(); got != c.want {
+			t.Errorf("ContentSize = %d, want %d", got, c.want)
+		}
+	}
+}
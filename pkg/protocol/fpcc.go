@@ -0,0 +1,82 @@
+// pkg/protocol/fpcc.go – derived properties of an FPCC.
+
+package protocol
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ContentSize is the length of the object's content – what a reader gets
+// back once compression and encryption are undone and chunks reassembled –
+// as opposed to Size, the length of the bytes that were erasure coded.
+func (f *FPCC) ContentSize() uint64 {
+	if man := f.GetManifest(); man != nil {
+		var size uint64
+		for _, c := range man.GetChunks() {
+			size += c.GetSize()
+		}
+		return size
+	}
+	if c := f.GetCompression(); c != nil {
+		return c.GetSize()
+	}
+	if e := f.GetEnvelope(); e != nil {
+		return e.GetSize()
+	}
+	return f.GetSize()
+}
+
+// MaxMetadata caps the encoded size of an object's metadata and tags
+// together; they travel with the FPCC in every Disperse and to every node
+// that fetches it, so they stay small.
+const MaxMetadata = 8 << 10
+
+// CheckMetadata reports whether meta and tags are acceptable on an FPCC.
+// Keys must be non-empty and may not hold '=' or ',' – the separators the
+// CLI and the HTTP tag syntax use – and neither keys nor values may carry
+// control characters, since both end up in HTTP headers.
+func CheckMetadata(meta, tags map[string]string) error {
+	size := 0
+	for kind, m := range map[string]map[string]string{"metadata": meta, "tag": tags} {
+		for k, v := range m {
+			switch {
+			case k == "":
+				return fmt.Errorf("empty %s key", kind)
+			case strings.ContainsAny(k, "=,"):
+				return fmt.Errorf("%s key %q contains '=' or ','", kind, k)
+			case strings.ContainsFunc(k+v, unicode.IsControl):
+				return fmt.Errorf("%s %q contains a control character", kind, k)
+			}
+			size += len(k) + len(v)
+		}
+	}
+	if size > MaxMetadata {
+		return fmt.Errorf("metadata and tags are %d bytes, limit %d", size, MaxMetadata)
+	}
+	return nil
+}
+
+// MaxID bounds an object ID: it names a directory on every node.
+const MaxID = 255
+
+// CheckID reports whether id is safe as an object ID. Nodes keep an
+// object's fragments in a directory named after it, so an ID may be neither
+// "." nor "..", nor hold a path separator or NUL. Nor may it hold the
+// separators of their database keys – "#" before a generation, "|" before
+// a peer or manifest, "@" before a digest – or one object's keys would
+// also name another's.
+func CheckID(id string) error {
+	switch {
+	case id == "", id == ".", id == "..":
+		return fmt.Errorf("ID %q", id)
+	case len(id) > MaxID:
+		return fmt.Errorf("ID longer than %d bytes", MaxID)
+	case strings.ContainsAny(id, "/\\\x00"):
+		return fmt.Errorf("ID %q contains a path separator", id)
+	case strings.ContainsAny(id, "#|@"):
+		return fmt.Errorf("ID %q contains a key separator (#, | or @)", id)
+	}
+	return nil
+}
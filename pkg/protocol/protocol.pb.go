@@ -29,19 +29,150 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-// Fingerprinted cross‑checksum: per‑fragment hash, per‑fragment FP, plus the FP seed
-type FPCC struct {
+// Membership view: the epoch‑numbered set of nodes that make up the cluster
+type MemberState int32
+
+const (
+	MemberState_ACTIVE   MemberState = 0
+	MemberState_DRAINING MemberState = 1 // read‑only; its fragments are being re‑homed
+)
+
+// Enum value maps for MemberState.
+var (
+	MemberState_name = map[int32]string{
+		0: "ACTIVE",
+		1: "DRAINING",
+	}
+	MemberState_value = map[string]int32{
+		"ACTIVE":   0,
+		"DRAINING": 1,
+	}
+)
+
+func (x MemberState) Enum() *MemberState {
+	p := new(MemberState)
+	*p = x
+	return p
+}
+
+func (x MemberState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MemberState) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_protocol_protocol_proto_enumTypes[0].Descriptor()
+}
+
+func (MemberState) Type() protoreflect.EnumType {
+	return &file_pkg_protocol_protocol_proto_enumTypes[0]
+}
+
+func (x MemberState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MemberState.Descriptor instead.
+func (MemberState) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{0}
+}
+
+// Erasure profile an object was encoded with
+type Profile struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Hashes        [][]byte               `protobuf:"bytes,1,rep,name=hashes,proto3" json:"hashes,omitempty"`   // SHA‑256 hash of each fragment
-	Fps           []uint64               `protobuf:"varint,2,rep,packed,name=fps,proto3" json:"fps,omitempty"` // homomorphic fingerprint of each fragment
-	Seed          uint64                 `protobuf:"varint,3,opt,name=seed,proto3" json:"seed,omitempty"`      // secret evaluation point used for all fingerprints
+	Data          uint32                 `protobuf:"varint,1,opt,name=data,proto3" json:"data,omitempty"`     // m: fragments needed to decode
+	Total         uint32                 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`   // n: fragments written
+	Codec         string                 `protobuf:"bytes,3,opt,name=codec,proto3" json:"codec,omitempty"`    // erasure codec registry name; "" = Reed–Solomon
+	Stripe        uint32                 `protobuf:"varint,4,opt,name=stripe,proto3" json:"stripe,omitempty"` // bytes per fragment per stripe; 0 = encoded in one piece
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *Profile) Reset() {
+	*x = Profile{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Profile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Profile) ProtoMessage() {}
+
+func (x *Profile) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Profile.ProtoReflect.Descriptor instead.
+func (*Profile) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Profile) GetData() uint32 {
+	if x != nil {
+		return x.Data
+	}
+	return 0
+}
+
+func (x *Profile) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *Profile) GetCodec() string {
+	if x != nil {
+		return x.Codec
+	}
+	return ""
+}
+
+func (x *Profile) GetStripe() uint32 {
+	if x != nil {
+		return x.Stripe
+	}
+	return 0
+}
+
+// Fingerprinted cross‑checksum: per‑fragment hash, per‑fragment FP, plus the FP seed
+type FPCC struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Hashes         [][]byte               `protobuf:"bytes,1,rep,name=hashes,proto3" json:"hashes,omitempty"`                                                                                // SHA‑256 hash of each fragment
+	Fps            []uint64               `protobuf:"varint,2,rep,packed,name=fps,proto3" json:"fps,omitempty"`                                                                              // homomorphic fingerprint of each fragment
+	Seed           uint64                 `protobuf:"varint,3,opt,name=seed,proto3" json:"seed,omitempty"`                                                                                   // secret evaluation point used for all fingerprints
+	Profile        *Profile               `protobuf:"bytes,4,opt,name=profile,proto3" json:"profile,omitempty"`                                                                              // unset on objects written before profiles existed
+	Size           uint64                 `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`                                                                                   // original object length in bytes
+	Generation     uint64                 `protobuf:"varint,6,opt,name=generation,proto3" json:"generation,omitempty"`                                                                       // bumped by each new version or transcode of the object
+	Roots          [][]byte               `protobuf:"bytes,8,rep,name=roots,proto3" json:"roots,omitempty"`                                                                                  // Merkle root over each fragment's stripe‑sized blocks; empty on unstriped objects
+	Envelope       *Envelope              `protobuf:"bytes,9,opt,name=envelope,proto3" json:"envelope,omitempty"`                                                                            // set when the client encrypted the object; size and hashes are then of the ciphertext
+	Compression    *Compression           `protobuf:"bytes,10,opt,name=compression,proto3" json:"compression,omitempty"`                                                                     // set when the client compressed the object before encrypting and encoding it
+	Manifest       *Manifest              `protobuf:"bytes,11,opt,name=manifest,proto3" json:"manifest,omitempty"`                                                                           // set on a deduplicated object, whose own fragments are empty
+	Transcoded     bool                   `protobuf:"varint,12,opt,name=transcoded,proto3" json:"transcoded,omitempty"`                                                                      // this generation re‑encodes the previous one rather than being a new version
+	IfNoneMatch    bool                   `protobuf:"varint,13,opt,name=if_none_match,json=ifNoneMatch,proto3" json:"if_none_match,omitempty"`                                               // commit only if the object has no version: create, never replace
+	IfMatchVersion []byte                 `protobuf:"bytes,14,opt,name=if_match_version,json=ifMatchVersion,proto3" json:"if_match_version,omitempty"`                                       // commit only as the successor of the version with this FPCC digest
+	Metadata       map[string]string      `protobuf:"bytes,15,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // user metadata, e.g. content-type; see CheckMetadata
+	Tags           map[string]string      `protobuf:"bytes,16,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`         // user tags, which List can filter on
+	ExpiresUnix    int64                  `protobuf:"varint,17,opt,name=expires_unix,json=expiresUnix,proto3" json:"expires_unix,omitempty"`                                                 // when the object expires, instead of by object.ttl or a lifecycle rule; 0 = unset
+	Retain         bool                   `protobuf:"varint,18,opt,name=retain,proto3" json:"retain,omitempty"`                                                                              // never expires: exempt from object.ttl and lifecycle expiry
+	CreatedUnix    int64                  `protobuf:"varint,19,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`                                                 // when the client wrote this version; TTL and lifecycle age count from it. 0 on older objects
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
 func (x *FPCC) Reset() {
 	*x = FPCC{}
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[0]
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -53,7 +184,7 @@ func (x *FPCC) String() string {
 func (*FPCC) ProtoMessage() {}
 
 func (x *FPCC) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[0]
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -66,7 +197,7 @@ func (x *FPCC) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FPCC.ProtoReflect.Descriptor instead.
 func (*FPCC) Descriptor() ([]byte, []int) {
-	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{0}
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *FPCC) GetHashes() [][]byte {
@@ -90,31 +221,2915 @@ func (x *FPCC) GetSeed() uint64 {
 	return 0
 }
 
-type DisperseRequest struct {
+func (x *FPCC) GetProfile() *Profile {
+	if x != nil {
+		return x.Profile
+	}
+	return nil
+}
+
+func (x *FPCC) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FPCC) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *FPCC) GetRoots() [][]byte {
+	if x != nil {
+		return x.Roots
+	}
+	return nil
+}
+
+func (x *FPCC) GetEnvelope() *Envelope {
+	if x != nil {
+		return x.Envelope
+	}
+	return nil
+}
+
+func (x *FPCC) GetCompression() *Compression {
+	if x != nil {
+		return x.Compression
+	}
+	return nil
+}
+
+func (x *FPCC) GetManifest() *Manifest {
+	if x != nil {
+		return x.Manifest
+	}
+	return nil
+}
+
+func (x *FPCC) GetTranscoded() bool {
+	if x != nil {
+		return x.Transcoded
+	}
+	return false
+}
+
+func (x *FPCC) GetIfNoneMatch() bool {
+	if x != nil {
+		return x.IfNoneMatch
+	}
+	return false
+}
+
+func (x *FPCC) GetIfMatchVersion() []byte {
+	if x != nil {
+		return x.IfMatchVersion
+	}
+	return nil
+}
+
+func (x *FPCC) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *FPCC) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *FPCC) GetExpiresUnix() int64 {
+	if x != nil {
+		return x.ExpiresUnix
+	}
+	return 0
+}
+
+func (x *FPCC) GetRetain() bool {
+	if x != nil {
+		return x.Retain
+	}
+	return false
+}
+
+func (x *FPCC) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+// A deduplicated object: its content is these chunks in order, each stored
+// once as an object of its own (see pkg/chunker) and shared with every
+// other manifest that lists it.
+type Manifest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
-	FragmentIndex uint32                 `protobuf:"varint,2,opt,name=fragment_index,json=fragmentIndex,proto3" json:"fragment_index,omitempty"`
-	Fragment      []byte                 `protobuf:"bytes,3,opt,name=fragment,proto3" json:"fragment,omitempty"`
-	Fpcc          *FPCC                  `protobuf:"bytes,4,opt,name=fpcc,proto3" json:"fpcc,omitempty"`
+	Chunks        []*ChunkRef            `protobuf:"bytes,1,rep,name=chunks,proto3" json:"chunks,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DisperseRequest) Reset() {
-	*x = DisperseRequest{}
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[1]
+func (x *Manifest) Reset() {
+	*x = Manifest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Manifest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Manifest) ProtoMessage() {}
+
+func (x *Manifest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Manifest.ProtoReflect.Descriptor instead.
+func (*Manifest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Manifest) GetChunks() []*ChunkRef {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+type ChunkRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          []byte                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"` // SHA‑256 of the chunk, which names its object
+	Size          uint64                 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkRef) Reset() {
+	*x = ChunkRef{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkRef) ProtoMessage() {}
+
+func (x *ChunkRef) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkRef.ProtoReflect.Descriptor instead.
+func (*ChunkRef) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ChunkRef) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *ChunkRef) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// Client‑side compression of an object, undone on retrieve.
+type Compression struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Codec         string                 `protobuf:"bytes,1,opt,name=codec,proto3" json:"codec,omitempty"` // compression registry name, e.g. "flate"
+	Size          uint64                 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`  // uncompressed length
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Compression) Reset() {
+	*x = Compression{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Compression) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Compression) ProtoMessage() {}
+
+func (x *Compression) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Compression.ProtoReflect.Descriptor instead.
+func (*Compression) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Compression) GetCodec() string {
+	if x != nil {
+		return x.Codec
+	}
+	return ""
+}
+
+func (x *Compression) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// Client‑side encryption of an object: the data key that sealed it, wrapped
+// by a key‑encryption key the servers never see.
+type Envelope struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	KeyId         string                 `protobuf:"bytes,1,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`                // which KEK wrapped the data key
+	WrappedKey    []byte                 `protobuf:"bytes,2,opt,name=wrapped_key,json=wrappedKey,proto3" json:"wrapped_key,omitempty"` // nonce ‖ AES‑256‑GCM seal of the data key
+	Salt          []byte                 `protobuf:"bytes,3,opt,name=salt,proto3" json:"salt,omitempty"`                               // passphrase KEKs only: PBKDF2 salt
+	Iterations    uint32                 `protobuf:"varint,4,opt,name=iterations,proto3" json:"iterations,omitempty"`                  // passphrase KEKs only: PBKDF2 rounds
+	Segment       uint32                 `protobuf:"varint,5,opt,name=segment,proto3" json:"segment,omitempty"`                        // plaintext bytes per sealed segment
+	Size          uint64                 `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`                              // plaintext length
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Envelope) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *Envelope) GetWrappedKey() []byte {
+	if x != nil {
+		return x.WrappedKey
+	}
+	return nil
+}
+
+func (x *Envelope) GetSalt() []byte {
+	if x != nil {
+		return x.Salt
+	}
+	return nil
+}
+
+func (x *Envelope) GetIterations() uint32 {
+	if x != nil {
+		return x.Iterations
+	}
+	return 0
+}
+
+func (x *Envelope) GetSegment() uint32 {
+	if x != nil {
+		return x.Segment
+	}
+	return 0
+}
+
+func (x *Envelope) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// Inclusion proof of one fragment block under its FPCC Merkle root.
+type BlockProof struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Block         uint64                 `protobuf:"varint,1,opt,name=block,proto3" json:"block,omitempty"`
+	Path          [][]byte               `protobuf:"bytes,2,rep,name=path,proto3" json:"path,omitempty"` // sibling hashes, leaf level first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlockProof) Reset() {
+	*x = BlockProof{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockProof) ProtoMessage() {}
+
+func (x *BlockProof) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockProof.ProtoReflect.Descriptor instead.
+func (*BlockProof) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BlockProof) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *BlockProof) GetPath() [][]byte {
+	if x != nil {
+		return x.Path
+	}
+	return nil
+}
+
+type DisperseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	FragmentIndex uint32                 `protobuf:"varint,2,opt,name=fragment_index,json=fragmentIndex,proto3" json:"fragment_index,omitempty"`
+	Fragment      []byte                 `protobuf:"bytes,3,opt,name=fragment,proto3" json:"fragment,omitempty"`
+	Fpcc          *FPCC                  `protobuf:"bytes,4,opt,name=fpcc,proto3" json:"fpcc,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisperseRequest) Reset() {
+	*x = DisperseRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisperseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisperseRequest) ProtoMessage() {}
+
+func (x *DisperseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisperseRequest.ProtoReflect.Descriptor instead.
+func (*DisperseRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DisperseRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *DisperseRequest) GetFragmentIndex() uint32 {
+	if x != nil {
+		return x.FragmentIndex
+	}
+	return 0
+}
+
+func (x *DisperseRequest) GetFragment() []byte {
+	if x != nil {
+		return x.Fragment
+	}
+	return nil
+}
+
+func (x *DisperseRequest) GetFpcc() *FPCC {
+	if x != nil {
+		return x.Fpcc
+	}
+	return nil
+}
+
+type DisperseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisperseResponse) Reset() {
+	*x = DisperseResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisperseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisperseResponse) ProtoMessage() {}
+
+func (x *DisperseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisperseResponse.ProtoReflect.Descriptor instead.
+func (*DisperseResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DisperseResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *DisperseResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Echo and Ready name the FPCC by its canonical digest (FPCC.Digest); a
+// node that does not hold that FPCC fetches it from the sender with GetFPCC.
+type EchoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	Sender        string                 `protobuf:"bytes,3,opt,name=sender,proto3" json:"sender,omitempty"`          // cluster address of the echoing node
+	Digest        []byte                 `protobuf:"bytes,4,opt,name=digest,proto3" json:"digest,omitempty"`          // canonical digest of the FPCC being echoed
+	Generation    uint64                 `protobuf:"varint,5,opt,name=generation,proto3" json:"generation,omitempty"` // FPCC generation, to find the round locally
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EchoRequest) Reset() {
+	*x = EchoRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EchoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EchoRequest) ProtoMessage() {}
+
+func (x *EchoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EchoRequest.ProtoReflect.Descriptor instead.
+func (*EchoRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *EchoRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *EchoRequest) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+func (x *EchoRequest) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *EchoRequest) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+type EchoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EchoResponse) Reset() {
+	*x = EchoResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EchoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EchoResponse) ProtoMessage() {}
+
+func (x *EchoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EchoResponse.ProtoReflect.Descriptor instead.
+func (*EchoResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *EchoResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *EchoResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ReadyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	Sender        string                 `protobuf:"bytes,3,opt,name=sender,proto3" json:"sender,omitempty"` // cluster address of the node sending Ready
+	Digest        []byte                 `protobuf:"bytes,4,opt,name=digest,proto3" json:"digest,omitempty"`
+	Generation    uint64                 `protobuf:"varint,5,opt,name=generation,proto3" json:"generation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadyRequest) Reset() {
+	*x = ReadyRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadyRequest) ProtoMessage() {}
+
+func (x *ReadyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadyRequest.ProtoReflect.Descriptor instead.
+func (*ReadyRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ReadyRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *ReadyRequest) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+func (x *ReadyRequest) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *ReadyRequest) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+// Batched Echo / Ready: a node coalesces the messages bound for one peer
+// over a short window; results come back in request order.
+type EchoBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Echoes        []*EchoRequest         `protobuf:"bytes,1,rep,name=echoes,proto3" json:"echoes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EchoBatchRequest) Reset() {
+	*x = EchoBatchRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EchoBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EchoBatchRequest) ProtoMessage() {}
+
+func (x *EchoBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EchoBatchRequest.ProtoReflect.Descriptor instead.
+func (*EchoBatchRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *EchoBatchRequest) GetEchoes() []*EchoRequest {
+	if x != nil {
+		return x.Echoes
+	}
+	return nil
+}
+
+type EchoBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*EchoResponse        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EchoBatchResponse) Reset() {
+	*x = EchoBatchResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EchoBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EchoBatchResponse) ProtoMessage() {}
+
+func (x *EchoBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EchoBatchResponse.ProtoReflect.Descriptor instead.
+func (*EchoBatchResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *EchoBatchResponse) GetResults() []*EchoResponse {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ReadyBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Readies       []*ReadyRequest        `protobuf:"bytes,1,rep,name=readies,proto3" json:"readies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadyBatchRequest) Reset() {
+	*x = ReadyBatchRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadyBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadyBatchRequest) ProtoMessage() {}
+
+func (x *ReadyBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadyBatchRequest.ProtoReflect.Descriptor instead.
+func (*ReadyBatchRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ReadyBatchRequest) GetReadies() []*ReadyRequest {
+	if x != nil {
+		return x.Readies
+	}
+	return nil
+}
+
+type ReadyBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*ReadyResponse       `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadyBatchResponse) Reset() {
+	*x = ReadyBatchResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadyBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadyBatchResponse) ProtoMessage() {}
+
+func (x *ReadyBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadyBatchResponse.ProtoReflect.Descriptor instead.
+func (*ReadyBatchResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ReadyBatchResponse) GetResults() []*ReadyResponse {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type GetFPCCRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	Generation    uint64                 `protobuf:"varint,2,opt,name=generation,proto3" json:"generation,omitempty"`
+	Digest        []byte                 `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"` // the FPCC wanted; a node holding another one says so
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFPCCRequest) Reset() {
+	*x = GetFPCCRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFPCCRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFPCCRequest) ProtoMessage() {}
+
+func (x *GetFPCCRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFPCCRequest.ProtoReflect.Descriptor instead.
+func (*GetFPCCRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetFPCCRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *GetFPCCRequest) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *GetFPCCRequest) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+type GetFPCCResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Fpcc          *FPCC                  `protobuf:"bytes,3,opt,name=fpcc,proto3" json:"fpcc,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFPCCResponse) Reset() {
+	*x = GetFPCCResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFPCCResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFPCCResponse) ProtoMessage() {}
+
+func (x *GetFPCCResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFPCCResponse.ProtoReflect.Descriptor instead.
+func (*GetFPCCResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetFPCCResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *GetFPCCResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetFPCCResponse) GetFpcc() *FPCC {
+	if x != nil {
+		return x.Fpcc
+	}
+	return nil
+}
+
+type ReadyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadyResponse) Reset() {
+	*x = ReadyResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadyResponse) ProtoMessage() {}
+
+func (x *ReadyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadyResponse.ProtoReflect.Descriptor instead.
+func (*ReadyResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ReadyResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *ReadyResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type StatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	Version       []byte                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"` // FPCC digest of a version; empty = the current one
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatRequest) Reset() {
+	*x = StatRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatRequest) ProtoMessage() {}
+
+func (x *StatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatRequest.ProtoReflect.Descriptor instead.
+func (*StatRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *StatRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *StatRequest) GetVersion() []byte {
+	if x != nil {
+		return x.Version
+	}
+	return nil
+}
+
+type StatResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Fpcc          *FPCC                  `protobuf:"bytes,3,opt,name=fpcc,proto3" json:"fpcc,omitempty"`
+	CreatedUnix   int64                  `protobuf:"varint,4,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"` // when this node first saw the object
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatResponse) Reset() {
+	*x = StatResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatResponse) ProtoMessage() {}
+
+func (x *StatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatResponse.ProtoReflect.Descriptor instead.
+func (*StatResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *StatResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *StatResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *StatResponse) GetFpcc() *FPCC {
+	if x != nil {
+		return x.Fpcc
+	}
+	return nil
+}
+
+func (x *StatResponse) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+// Delete drops the node's copy of an object: fragments, FPCC and round
+// state. Clients send it to every member.
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeleteRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+type DeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Found         bool                   `protobuf:"varint,3,opt,name=found,proto3" json:"found,omitempty"` // the node knew the object
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteResponse) Reset() {
+	*x = DeleteResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResponse) ProtoMessage() {}
+
+func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DeleteResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *DeleteResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DeleteResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+// List pages through the committed objects a node knows, in ID order.
+// Each node only knows its placement groups', so clients merge the pages
+// of every member.
+type ListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	StartAfter    string                 `protobuf:"bytes,2,opt,name=start_after,json=startAfter,proto3" json:"start_after,omitempty"`
+	Limit         uint32                 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                                                                        // 0 = the node's default
+	Tags          map[string]string      `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // only objects carrying every one of these tags
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *ListRequest) GetStartAfter() string {
+	if x != nil {
+		return x.StartAfter
+	}
+	return ""
+}
+
+func (x *ListRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListRequest) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Objects       []*ObjectEntry         `protobuf:"bytes,3,rep,name=objects,proto3" json:"objects,omitempty"`
+	Truncated     bool                   `protobuf:"varint,4,opt,name=truncated,proto3" json:"truncated,omitempty"` // more objects follow the last one
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *ListResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ListResponse) GetObjects() []*ObjectEntry {
+	if x != nil {
+		return x.Objects
+	}
+	return nil
+}
+
+func (x *ListResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type ObjectEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	Size          uint64                 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"` // content length, see FPCC.ContentSize
+	Generation    uint64                 `protobuf:"varint,3,opt,name=generation,proto3" json:"generation,omitempty"`
+	CreatedUnix   int64                  `protobuf:"varint,4,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+	Digest        []byte                 `protobuf:"bytes,5,opt,name=digest,proto3" json:"digest,omitempty"` // FPCC.Digest() of the committed FPCC
+	Metadata      map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Tags          map[string]string      `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ObjectEntry) Reset() {
+	*x = ObjectEntry{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ObjectEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObjectEntry) ProtoMessage() {}
+
+func (x *ObjectEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObjectEntry.ProtoReflect.Descriptor instead.
+func (*ObjectEntry) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ObjectEntry) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *ObjectEntry) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ObjectEntry) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *ObjectEntry) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+func (x *ObjectEntry) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *ObjectEntry) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *ObjectEntry) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+// Versions lists what a node keeps of an object: its current version and
+// the noncurrent ones retention has not dropped yet, newest first.
+type VersionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VersionsRequest) Reset() {
+	*x = VersionsRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VersionsRequest) ProtoMessage() {}
+
+func (x *VersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VersionsRequest.ProtoReflect.Descriptor instead.
+func (*VersionsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *VersionsRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+type VersionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Versions      []*VersionEntry        `protobuf:"bytes,3,rep,name=versions,proto3" json:"versions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VersionsResponse) Reset() {
+	*x = VersionsResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VersionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VersionsResponse) ProtoMessage() {}
+
+func (x *VersionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VersionsResponse.ProtoReflect.Descriptor instead.
+func (*VersionsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *VersionsResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *VersionsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *VersionsResponse) GetVersions() []*VersionEntry {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type VersionEntry struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Fpcc           *FPCC                  `protobuf:"bytes,1,opt,name=fpcc,proto3" json:"fpcc,omitempty"` // its digest is the version ID, its generation the number
+	CreatedUnix    int64                  `protobuf:"varint,2,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+	SupersededUnix int64                  `protobuf:"varint,3,opt,name=superseded_unix,json=supersededUnix,proto3" json:"superseded_unix,omitempty"` // 0 for the current version
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *VersionEntry) Reset() {
+	*x = VersionEntry{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VersionEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VersionEntry) ProtoMessage() {}
+
+func (x *VersionEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VersionEntry.ProtoReflect.Descriptor instead.
+func (*VersionEntry) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *VersionEntry) GetFpcc() *FPCC {
+	if x != nil {
+		return x.Fpcc
+	}
+	return nil
+}
+
+func (x *VersionEntry) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+func (x *VersionEntry) GetSupersededUnix() int64 {
+	if x != nil {
+		return x.SupersededUnix
+	}
+	return 0
+}
+
+// Re‑homing of an already committed fragment from one node to another
+// (drain / repair); the receiver checks it against the FPCC before storing.
+type HandoffRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	FragmentIndex uint32                 `protobuf:"varint,2,opt,name=fragment_index,json=fragmentIndex,proto3" json:"fragment_index,omitempty"`
+	Fragment      []byte                 `protobuf:"bytes,3,opt,name=fragment,proto3" json:"fragment,omitempty"`
+	Fpcc          *FPCC                  `protobuf:"bytes,4,opt,name=fpcc,proto3" json:"fpcc,omitempty"`
+	Sender        string                 `protobuf:"bytes,5,opt,name=sender,proto3" json:"sender,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandoffRequest) Reset() {
+	*x = HandoffRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandoffRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandoffRequest) ProtoMessage() {}
+
+func (x *HandoffRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandoffRequest.ProtoReflect.Descriptor instead.
+func (*HandoffRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *HandoffRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *HandoffRequest) GetFragmentIndex() uint32 {
+	if x != nil {
+		return x.FragmentIndex
+	}
+	return 0
+}
+
+func (x *HandoffRequest) GetFragment() []byte {
+	if x != nil {
+		return x.Fragment
+	}
+	return nil
+}
+
+func (x *HandoffRequest) GetFpcc() *FPCC {
+	if x != nil {
+		return x.Fpcc
+	}
+	return nil
+}
+
+func (x *HandoffRequest) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+type HandoffResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandoffResponse) Reset() {
+	*x = HandoffResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandoffResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandoffResponse) ProtoMessage() {}
+
+func (x *HandoffResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandoffResponse.ProtoReflect.Descriptor instead.
+func (*HandoffResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *HandoffResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *HandoffResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type LocateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	Generation    uint64                 `protobuf:"varint,2,opt,name=generation,proto3" json:"generation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LocateRequest) Reset() {
+	*x = LocateRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LocateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocateRequest) ProtoMessage() {}
+
+func (x *LocateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocateRequest.ProtoReflect.Descriptor instead.
+func (*LocateRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *LocateRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *LocateRequest) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+type LocateResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Ok              bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error           string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	FragmentIndices []uint32               `protobuf:"varint,3,rep,packed,name=fragment_indices,json=fragmentIndices,proto3" json:"fragment_indices,omitempty"` // fragments held by the responder
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *LocateResponse) Reset() {
+	*x = LocateResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LocateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocateResponse) ProtoMessage() {}
+
+func (x *LocateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocateResponse.ProtoReflect.Descriptor instead.
+func (*LocateResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *LocateResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *LocateResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *LocateResponse) GetFragmentIndices() []uint32 {
+	if x != nil {
+		return x.FragmentIndices
+	}
+	return nil
+}
+
+type RetrieveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	FragmentIndex uint32                 `protobuf:"varint,2,opt,name=fragment_index,json=fragmentIndex,proto3" json:"fragment_index,omitempty"`
+	Generation    uint64                 `protobuf:"varint,3,opt,name=generation,proto3" json:"generation,omitempty"` // FPCC generation the reader is decoding
+	Offset        uint64                 `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`         // first fragment byte to return
+	Length        uint64                 `protobuf:"varint,5,opt,name=length,proto3" json:"length,omitempty"`         // bytes to return from offset; 0 = to the end
+	Proofs        bool                   `protobuf:"varint,6,opt,name=proofs,proto3" json:"proofs,omitempty"`         // include inclusion proofs for the blocks returned
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetrieveRequest) Reset() {
+	*x = RetrieveRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetrieveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetrieveRequest) ProtoMessage() {}
+
+func (x *RetrieveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetrieveRequest.ProtoReflect.Descriptor instead.
+func (*RetrieveRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *RetrieveRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *RetrieveRequest) GetFragmentIndex() uint32 {
+	if x != nil {
+		return x.FragmentIndex
+	}
+	return 0
+}
+
+func (x *RetrieveRequest) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *RetrieveRequest) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *RetrieveRequest) GetLength() uint64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *RetrieveRequest) GetProofs() bool {
+	if x != nil {
+		return x.Proofs
+	}
+	return false
+}
+
+type RetrieveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Fragment      []byte                 `protobuf:"bytes,3,opt,name=fragment,proto3" json:"fragment,omitempty"`
+	FragmentIndex uint32                 `protobuf:"varint,4,opt,name=fragment_index,json=fragmentIndex,proto3" json:"fragment_index,omitempty"`
+	Fpcc          *FPCC                  `protobuf:"bytes,5,opt,name=fpcc,proto3" json:"fpcc,omitempty"`
+	Proofs        []*BlockProof          `protobuf:"bytes,6,rep,name=proofs,proto3" json:"proofs,omitempty"` // when requested: every block the range overlaps
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetrieveResponse) Reset() {
+	*x = RetrieveResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetrieveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetrieveResponse) ProtoMessage() {}
+
+func (x *RetrieveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetrieveResponse.ProtoReflect.Descriptor instead.
+func (*RetrieveResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *RetrieveResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *RetrieveResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *RetrieveResponse) GetFragment() []byte {
+	if x != nil {
+		return x.Fragment
+	}
+	return nil
+}
+
+func (x *RetrieveResponse) GetFragmentIndex() uint32 {
+	if x != nil {
+		return x.FragmentIndex
+	}
+	return 0
+}
+
+func (x *RetrieveResponse) GetFpcc() *FPCC {
+	if x != nil {
+		return x.Fpcc
+	}
+	return nil
+}
+
+func (x *RetrieveResponse) GetProofs() []*BlockProof {
+	if x != nil {
+		return x.Proofs
+	}
+	return nil
+}
+
+// HasChunks asks which chunks, by hash, the node knows to be committed and
+// safe to reference for a while yet.
+type HasChunksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hashes        [][]byte               `protobuf:"bytes,1,rep,name=hashes,proto3" json:"hashes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HasChunksRequest) Reset() {
+	*x = HasChunksRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HasChunksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HasChunksRequest) ProtoMessage() {}
+
+func (x *HasChunksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HasChunksRequest.ProtoReflect.Descriptor instead.
+func (*HasChunksRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *HasChunksRequest) GetHashes() [][]byte {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+type HasChunksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Held          []bool                 `protobuf:"varint,3,rep,packed,name=held,proto3" json:"held,omitempty"` // one per requested hash
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HasChunksResponse) Reset() {
+	*x = HasChunksResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HasChunksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HasChunksResponse) ProtoMessage() {}
+
+func (x *HasChunksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HasChunksResponse.ProtoReflect.Descriptor instead.
+func (*HasChunksResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *HasChunksResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *HasChunksResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *HasChunksResponse) GetHeld() []bool {
+	if x != nil {
+		return x.Held
+	}
+	return nil
+}
+
+// Streaming Disperse: the first chunk names the fragment and carries the
+// FPCC, later ones only data. Lets fragments exceed the gRPC message limit.
+type DisperseChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	FragmentIndex uint32                 `protobuf:"varint,2,opt,name=fragment_index,json=fragmentIndex,proto3" json:"fragment_index,omitempty"`
+	Fpcc          *FPCC                  `protobuf:"bytes,3,opt,name=fpcc,proto3" json:"fpcc,omitempty"`
+	Data          []byte                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisperseChunk) Reset() {
+	*x = DisperseChunk{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisperseChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisperseChunk) ProtoMessage() {}
+
+func (x *DisperseChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisperseChunk.ProtoReflect.Descriptor instead.
+func (*DisperseChunk) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *DisperseChunk) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *DisperseChunk) GetFragmentIndex() uint32 {
+	if x != nil {
+		return x.FragmentIndex
+	}
+	return 0
+}
+
+func (x *DisperseChunk) GetFpcc() *FPCC {
+	if x != nil {
+		return x.Fpcc
+	}
+	return nil
+}
+
+func (x *DisperseChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Streaming Retrieve: the first chunk carries ok/error, the FPCC and the
+// whole fragment's length, later ones only data (of the requested range).
+type RetrieveChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Fpcc          *FPCC                  `protobuf:"bytes,3,opt,name=fpcc,proto3" json:"fpcc,omitempty"`
+	Length        uint64                 `protobuf:"varint,4,opt,name=length,proto3" json:"length,omitempty"`
+	Data          []byte                 `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	Proofs        []*BlockProof          `protobuf:"bytes,6,rep,name=proofs,proto3" json:"proofs,omitempty"` // first chunk only, as in RetrieveResponse
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetrieveChunk) Reset() {
+	*x = RetrieveChunk{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetrieveChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetrieveChunk) ProtoMessage() {}
+
+func (x *RetrieveChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetrieveChunk.ProtoReflect.Descriptor instead.
+func (*RetrieveChunk) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *RetrieveChunk) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *RetrieveChunk) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *RetrieveChunk) GetFpcc() *FPCC {
+	if x != nil {
+		return x.Fpcc
+	}
+	return nil
+}
+
+func (x *RetrieveChunk) GetLength() uint64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *RetrieveChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *RetrieveChunk) GetProofs() []*BlockProof {
+	if x != nil {
+		return x.Proofs
+	}
+	return nil
+}
+
+// Coordinator RPCs: a thin client streams a whole object to any node,
+// which erasure codes, fingerprints and disperses it to the owners, and
+// reads it back through a node, which collects and verifies m fragments.
+// They fail with gRPC status codes: NotFound, AlreadyExists,
+// InvalidArgument, Unavailable, DataLoss.
+type PutObjectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"` // first message only
+	Options       *PutOptions            `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`                   // first message only
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutObjectRequest) Reset() {
+	*x = PutObjectRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutObjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutObjectRequest) ProtoMessage() {}
+
+func (x *PutObjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutObjectRequest.ProtoReflect.Descriptor instead.
+func (*PutObjectRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *PutObjectRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *PutObjectRequest) GetOptions() *PutOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *PutObjectRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Unset options take the coordinator's config.
+type PutOptions struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Codec          string                 `protobuf:"bytes,1,opt,name=codec,proto3" json:"codec,omitempty"`
+	Data           uint32                 `protobuf:"varint,2,opt,name=data,proto3" json:"data,omitempty"`
+	Total          uint32                 `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	Compression    *string                `protobuf:"bytes,4,opt,name=compression,proto3,oneof" json:"compression,omitempty"` // "" = off
+	Dedup          *bool                  `protobuf:"varint,5,opt,name=dedup,proto3,oneof" json:"dedup,omitempty"`
+	Encrypt        *bool                  `protobuf:"varint,6,opt,name=encrypt,proto3,oneof" json:"encrypt,omitempty"`                                // under the coordinator's keys
+	IfNoneMatch    bool                   `protobuf:"varint,7,opt,name=if_none_match,json=ifNoneMatch,proto3" json:"if_none_match,omitempty"`         // fail with FailedPrecondition if the object exists
+	IfMatchVersion string                 `protobuf:"bytes,8,opt,name=if_match_version,json=ifMatchVersion,proto3" json:"if_match_version,omitempty"` // fail with FailedPrecondition unless this version ID is current
+	Metadata       map[string]string      `protobuf:"bytes,9,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Tags           map[string]string      `protobuf:"bytes,10,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ExpiresUnix    int64                  `protobuf:"varint,11,opt,name=expires_unix,json=expiresUnix,proto3" json:"expires_unix,omitempty"` // see FPCC.expires_unix
+	Retain         bool                   `protobuf:"varint,12,opt,name=retain,proto3" json:"retain,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PutOptions) Reset() {
+	*x = PutOptions{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutOptions) ProtoMessage() {}
+
+func (x *PutOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutOptions.ProtoReflect.Descriptor instead.
+func (*PutOptions) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *PutOptions) GetCodec() string {
+	if x != nil {
+		return x.Codec
+	}
+	return ""
+}
+
+func (x *PutOptions) GetData() uint32 {
+	if x != nil {
+		return x.Data
+	}
+	return 0
+}
+
+func (x *PutOptions) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *PutOptions) GetCompression() string {
+	if x != nil && x.Compression != nil {
+		return *x.Compression
+	}
+	return ""
+}
+
+func (x *PutOptions) GetDedup() bool {
+	if x != nil && x.Dedup != nil {
+		return *x.Dedup
+	}
+	return false
+}
+
+func (x *PutOptions) GetEncrypt() bool {
+	if x != nil && x.Encrypt != nil {
+		return *x.Encrypt
+	}
+	return false
+}
+
+func (x *PutOptions) GetIfNoneMatch() bool {
+	if x != nil {
+		return x.IfNoneMatch
+	}
+	return false
+}
+
+func (x *PutOptions) GetIfMatchVersion() string {
+	if x != nil {
+		return x.IfMatchVersion
+	}
+	return ""
+}
+
+func (x *PutOptions) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *PutOptions) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *PutOptions) GetExpiresUnix() int64 {
+	if x != nil {
+		return x.ExpiresUnix
+	}
+	return 0
+}
+
+func (x *PutOptions) GetRetain() bool {
+	if x != nil {
+		return x.Retain
+	}
+	return false
+}
+
+type PutObjectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fpcc          *FPCC                  `protobuf:"bytes,1,opt,name=fpcc,proto3" json:"fpcc,omitempty"`
+	CreatedUnix   int64                  `protobuf:"varint,2,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutObjectResponse) Reset() {
+	*x = PutObjectResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutObjectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutObjectResponse) ProtoMessage() {}
+
+func (x *PutObjectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutObjectResponse.ProtoReflect.Descriptor instead.
+func (*PutObjectResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *PutObjectResponse) GetFpcc() *FPCC {
+	if x != nil {
+		return x.Fpcc
+	}
+	return nil
+}
+
+func (x *PutObjectResponse) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+type GetObjectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	Offset        uint64                 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Length        uint64                 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`  // 0 = to the end
+	Version       string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"` // hex FPCC digest; "" = the current version
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetObjectRequest) Reset() {
+	*x = GetObjectRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetObjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetObjectRequest) ProtoMessage() {}
+
+func (x *GetObjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetObjectRequest.ProtoReflect.Descriptor instead.
+func (*GetObjectRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *GetObjectRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *GetObjectRequest) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *GetObjectRequest) GetLength() uint64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *GetObjectRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+// The first chunk carries the FPCC, later ones only verified data.
+type GetObjectChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fpcc          *FPCC                  `protobuf:"bytes,1,opt,name=fpcc,proto3" json:"fpcc,omitempty"`
+	CreatedUnix   int64                  `protobuf:"varint,2,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetObjectChunk) Reset() {
+	*x = GetObjectChunk{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetObjectChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetObjectChunk) ProtoMessage() {}
+
+func (x *GetObjectChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetObjectChunk.ProtoReflect.Descriptor instead.
+func (*GetObjectChunk) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *GetObjectChunk) GetFpcc() *FPCC {
+	if x != nil {
+		return x.Fpcc
+	}
+	return nil
+}
+
+func (x *GetObjectChunk) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+func (x *GetObjectChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type Member struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"` // host:port of the node's gRPC endpoint
+	State         MemberState            `protobuf:"varint,2,opt,name=state,proto3,enum=protocol.MemberState" json:"state,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // topology, e.g. zone / rack / host
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Member) Reset() {
+	*x = Member{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Member) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Member) ProtoMessage() {}
+
+func (x *Member) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Member.ProtoReflect.Descriptor instead.
+func (*Member) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *Member) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *Member) GetState() MemberState {
+	if x != nil {
+		return x.State
+	}
+	return MemberState_ACTIVE
+}
+
+func (x *Member) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type View struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Epoch         uint64                 `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"` // bumped by every committed membership change
+	Members       []*Member              `protobuf:"bytes,2,rep,name=members,proto3" json:"members,omitempty"`
+	FailureDomain string                 `protobuf:"bytes,3,opt,name=failure_domain,json=failureDomain,proto3" json:"failure_domain,omitempty"` // label key placement spreads fragments across
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *View) Reset() {
+	*x = View{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *View) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*View) ProtoMessage() {}
+
+func (x *View) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use View.ProtoReflect.Descriptor instead.
+func (*View) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *View) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *View) GetMembers() []*Member {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+func (x *View) GetFailureDomain() string {
+	if x != nil {
+		return x.FailureDomain
+	}
+	return ""
+}
+
+type GetViewRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetViewRequest) Reset() {
+	*x = GetViewRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetViewRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetViewRequest) ProtoMessage() {}
+
+func (x *GetViewRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetViewRequest.ProtoReflect.Descriptor instead.
+func (*GetViewRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{46}
+}
+
+type AddNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddNodeRequest) Reset() {
+	*x = AddNodeRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DisperseRequest) String() string {
+func (x *AddNodeRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DisperseRequest) ProtoMessage() {}
+func (*AddNodeRequest) ProtoMessage() {}
 
-func (x *DisperseRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[1]
+func (x *AddNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -125,62 +3140,93 @@ func (x *DisperseRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DisperseRequest.ProtoReflect.Descriptor instead.
-func (*DisperseRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use AddNodeRequest.ProtoReflect.Descriptor instead.
+func (*AddNodeRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{47}
 }
 
-func (x *DisperseRequest) GetObjectId() string {
+func (x *AddNodeRequest) GetAddr() string {
 	if x != nil {
-		return x.ObjectId
+		return x.Addr
 	}
 	return ""
 }
 
-func (x *DisperseRequest) GetFragmentIndex() uint32 {
+func (x *AddNodeRequest) GetLabels() map[string]string {
 	if x != nil {
-		return x.FragmentIndex
+		return x.Labels
 	}
-	return 0
+	return nil
 }
 
-func (x *DisperseRequest) GetFragment() []byte {
+type RemoveNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveNodeRequest) Reset() {
+	*x = RemoveNodeRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveNodeRequest) ProtoMessage() {}
+
+func (x *RemoveNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[48]
 	if x != nil {
-		return x.Fragment
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *DisperseRequest) GetFpcc() *FPCC {
+// Deprecated: Use RemoveNodeRequest.ProtoReflect.Descriptor instead.
+func (*RemoveNodeRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *RemoveNodeRequest) GetAddr() string {
 	if x != nil {
-		return x.Fpcc
+		return x.Addr
 	}
-	return nil
+	return ""
 }
 
-type DisperseResponse struct {
+type ReplaceNodeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	OldAddr       string                 `protobuf:"bytes,1,opt,name=old_addr,json=oldAddr,proto3" json:"old_addr,omitempty"`
+	NewAddr       string                 `protobuf:"bytes,2,opt,name=new_addr,json=newAddr,proto3" json:"new_addr,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // defaults to the old node's labels
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DisperseResponse) Reset() {
-	*x = DisperseResponse{}
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[2]
+func (x *ReplaceNodeRequest) Reset() {
+	*x = ReplaceNodeRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DisperseResponse) String() string {
+func (x *ReplaceNodeRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DisperseResponse) ProtoMessage() {}
+func (*ReplaceNodeRequest) ProtoMessage() {}
 
-func (x *DisperseResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[2]
+func (x *ReplaceNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -191,48 +3237,54 @@ func (x *DisperseResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DisperseResponse.ProtoReflect.Descriptor instead.
-func (*DisperseResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use ReplaceNodeRequest.ProtoReflect.Descriptor instead.
+func (*ReplaceNodeRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{49}
 }
 
-func (x *DisperseResponse) GetOk() bool {
+func (x *ReplaceNodeRequest) GetOldAddr() string {
 	if x != nil {
-		return x.Ok
+		return x.OldAddr
 	}
-	return false
+	return ""
 }
 
-func (x *DisperseResponse) GetError() string {
+func (x *ReplaceNodeRequest) GetNewAddr() string {
 	if x != nil {
-		return x.Error
+		return x.NewAddr
 	}
 	return ""
 }
 
-type EchoRequest struct {
+func (x *ReplaceNodeRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type DrainRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
-	Fpcc          *FPCC                  `protobuf:"bytes,2,opt,name=fpcc,proto3" json:"fpcc,omitempty"`
+	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *EchoRequest) Reset() {
-	*x = EchoRequest{}
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[3]
+func (x *DrainRequest) Reset() {
+	*x = DrainRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *EchoRequest) String() string {
+func (x *DrainRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EchoRequest) ProtoMessage() {}
+func (*DrainRequest) ProtoMessage() {}
 
-func (x *EchoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[3]
+func (x *DrainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -243,48 +3295,45 @@ func (x *EchoRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EchoRequest.ProtoReflect.Descriptor instead.
-func (*EchoRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use DrainRequest.ProtoReflect.Descriptor instead.
+func (*DrainRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{50}
 }
 
-func (x *EchoRequest) GetObjectId() string {
+func (x *DrainRequest) GetAddr() string {
 	if x != nil {
-		return x.ObjectId
+		return x.Addr
 	}
 	return ""
 }
 
-func (x *EchoRequest) GetFpcc() *FPCC {
-	if x != nil {
-		return x.Fpcc
-	}
-	return nil
-}
-
-type EchoResponse struct {
+type DrainStatusResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
 	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Draining      bool                   `protobuf:"varint,3,opt,name=draining,proto3" json:"draining,omitempty"`
+	Objects       uint32                 `protobuf:"varint,4,opt,name=objects,proto3" json:"objects,omitempty"` // objects with a fragment on the draining node
+	Rehomed       uint32                 `protobuf:"varint,5,opt,name=rehomed,proto3" json:"rehomed,omitempty"` // objects back at full n‑fragment redundancy elsewhere
+	SafeToRemove  bool                   `protobuf:"varint,6,opt,name=safe_to_remove,json=safeToRemove,proto3" json:"safe_to_remove,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *EchoResponse) Reset() {
-	*x = EchoResponse{}
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[4]
+func (x *DrainStatusResponse) Reset() {
+	*x = DrainStatusResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *EchoResponse) String() string {
+func (x *DrainStatusResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EchoResponse) ProtoMessage() {}
+func (*DrainStatusResponse) ProtoMessage() {}
 
-func (x *EchoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[4]
+func (x *DrainStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -295,48 +3344,77 @@ func (x *EchoResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EchoResponse.ProtoReflect.Descriptor instead.
-func (*EchoResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use DrainStatusResponse.ProtoReflect.Descriptor instead.
+func (*DrainStatusResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{51}
 }
 
-func (x *EchoResponse) GetOk() bool {
+func (x *DrainStatusResponse) GetOk() bool {
 	if x != nil {
 		return x.Ok
 	}
 	return false
 }
 
-func (x *EchoResponse) GetError() string {
+func (x *DrainStatusResponse) GetError() string {
 	if x != nil {
 		return x.Error
 	}
 	return ""
 }
 
-type ReadyRequest struct {
+func (x *DrainStatusResponse) GetDraining() bool {
+	if x != nil {
+		return x.Draining
+	}
+	return false
+}
+
+func (x *DrainStatusResponse) GetObjects() uint32 {
+	if x != nil {
+		return x.Objects
+	}
+	return 0
+}
+
+func (x *DrainStatusResponse) GetRehomed() uint32 {
+	if x != nil {
+		return x.Rehomed
+	}
+	return 0
+}
+
+func (x *DrainStatusResponse) GetSafeToRemove() bool {
+	if x != nil {
+		return x.SafeToRemove
+	}
+	return false
+}
+
+type MembershipResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
-	Fpcc          *FPCC                  `protobuf:"bytes,2,opt,name=fpcc,proto3" json:"fpcc,omitempty"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	View          *View                  `protobuf:"bytes,3,opt,name=view,proto3" json:"view,omitempty"` // view in force after the call
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ReadyRequest) Reset() {
-	*x = ReadyRequest{}
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[5]
+func (x *MembershipResponse) Reset() {
+	*x = MembershipResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ReadyRequest) String() string {
+func (x *MembershipResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReadyRequest) ProtoMessage() {}
+func (*MembershipResponse) ProtoMessage() {}
 
-func (x *ReadyRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[5]
+func (x *MembershipResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -347,48 +3425,57 @@ func (x *ReadyRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReadyRequest.ProtoReflect.Descriptor instead.
-func (*ReadyRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use MembershipResponse.ProtoReflect.Descriptor instead.
+func (*MembershipResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{52}
 }
 
-func (x *ReadyRequest) GetObjectId() string {
+func (x *MembershipResponse) GetOk() bool {
 	if x != nil {
-		return x.ObjectId
+		return x.Ok
+	}
+	return false
+}
+
+func (x *MembershipResponse) GetError() string {
+	if x != nil {
+		return x.Error
 	}
 	return ""
 }
 
-func (x *ReadyRequest) GetFpcc() *FPCC {
+func (x *MembershipResponse) GetView() *View {
 	if x != nil {
-		return x.Fpcc
+		return x.View
 	}
 	return nil
 }
 
-type ReadyResponse struct {
+// Two‑phase view change: a majority of the current view must accept the
+// proposal before the proposer commits it everywhere.
+type ProposeViewRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	View          *View                  `protobuf:"bytes,1,opt,name=view,proto3" json:"view,omitempty"`
+	BaseEpoch     uint64                 `protobuf:"varint,2,opt,name=base_epoch,json=baseEpoch,proto3" json:"base_epoch,omitempty"` // epoch the proposal was derived from
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ReadyResponse) Reset() {
-	*x = ReadyResponse{}
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[6]
+func (x *ProposeViewRequest) Reset() {
+	*x = ProposeViewRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ReadyResponse) String() string {
+func (x *ProposeViewRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReadyResponse) ProtoMessage() {}
+func (*ProposeViewRequest) ProtoMessage() {}
 
-func (x *ReadyResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[6]
+func (x *ProposeViewRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -399,48 +3486,47 @@ func (x *ReadyResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReadyResponse.ProtoReflect.Descriptor instead.
-func (*ReadyResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use ProposeViewRequest.ProtoReflect.Descriptor instead.
+func (*ProposeViewRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{53}
 }
 
-func (x *ReadyResponse) GetOk() bool {
+func (x *ProposeViewRequest) GetView() *View {
 	if x != nil {
-		return x.Ok
+		return x.View
 	}
-	return false
+	return nil
 }
 
-func (x *ReadyResponse) GetError() string {
+func (x *ProposeViewRequest) GetBaseEpoch() uint64 {
 	if x != nil {
-		return x.Error
+		return x.BaseEpoch
 	}
-	return ""
+	return 0
 }
 
-type RetrieveRequest struct {
+type CommitViewRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ObjectId      string                 `protobuf:"bytes,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
-	FragmentIndex uint32                 `protobuf:"varint,2,opt,name=fragment_index,json=fragmentIndex,proto3" json:"fragment_index,omitempty"`
+	View          *View                  `protobuf:"bytes,1,opt,name=view,proto3" json:"view,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RetrieveRequest) Reset() {
-	*x = RetrieveRequest{}
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[7]
+func (x *CommitViewRequest) Reset() {
+	*x = CommitViewRequest{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RetrieveRequest) String() string {
+func (x *CommitViewRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RetrieveRequest) ProtoMessage() {}
+func (*CommitViewRequest) ProtoMessage() {}
 
-func (x *RetrieveRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[7]
+func (x *CommitViewRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -451,51 +3537,42 @@ func (x *RetrieveRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RetrieveRequest.ProtoReflect.Descriptor instead.
-func (*RetrieveRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{7}
-}
-
-func (x *RetrieveRequest) GetObjectId() string {
-	if x != nil {
-		return x.ObjectId
-	}
-	return ""
+// Deprecated: Use CommitViewRequest.ProtoReflect.Descriptor instead.
+func (*CommitViewRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{54}
 }
 
-func (x *RetrieveRequest) GetFragmentIndex() uint32 {
+func (x *CommitViewRequest) GetView() *View {
 	if x != nil {
-		return x.FragmentIndex
+		return x.View
 	}
-	return 0
+	return nil
 }
 
-type RetrieveResponse struct {
+type ViewResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
 	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
-	Fragment      []byte                 `protobuf:"bytes,3,opt,name=fragment,proto3" json:"fragment,omitempty"`
-	FragmentIndex uint32                 `protobuf:"varint,4,opt,name=fragment_index,json=fragmentIndex,proto3" json:"fragment_index,omitempty"`
-	Fpcc          *FPCC                  `protobuf:"bytes,5,opt,name=fpcc,proto3" json:"fpcc,omitempty"`
+	View          *View                  `protobuf:"bytes,3,opt,name=view,proto3" json:"view,omitempty"` // responder's committed view
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RetrieveResponse) Reset() {
-	*x = RetrieveResponse{}
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[8]
+func (x *ViewResponse) Reset() {
+	*x = ViewResponse{}
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RetrieveResponse) String() string {
+func (x *ViewResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RetrieveResponse) ProtoMessage() {}
+func (*ViewResponse) ProtoMessage() {}
 
-func (x *RetrieveResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protocol_protocol_proto_msgTypes[8]
+func (x *ViewResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_protocol_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -506,42 +3583,28 @@ func (x *RetrieveResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RetrieveResponse.ProtoReflect.Descriptor instead.
-func (*RetrieveResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use ViewResponse.ProtoReflect.Descriptor instead.
+func (*ViewResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_protocol_proto_rawDescGZIP(), []int{55}
 }
 
-func (x *RetrieveResponse) GetOk() bool {
+func (x *ViewResponse) GetOk() bool {
 	if x != nil {
 		return x.Ok
 	}
 	return false
 }
 
-func (x *RetrieveResponse) GetError() string {
+func (x *ViewResponse) GetError() string {
 	if x != nil {
 		return x.Error
 	}
 	return ""
 }
 
-func (x *RetrieveResponse) GetFragment() []byte {
-	if x != nil {
-		return x.Fragment
-	}
-	return nil
-}
-
-func (x *RetrieveResponse) GetFragmentIndex() uint32 {
-	if x != nil {
-		return x.FragmentIndex
-	}
-	return 0
-}
-
-func (x *RetrieveResponse) GetFpcc() *FPCC {
+func (x *ViewResponse) GetView() *View {
 	if x != nil {
-		return x.Fpcc
+		return x.View
 	}
 	return nil
 }
@@ -550,11 +3613,64 @@ var File_pkg_protocol_protocol_proto protoreflect.FileDescriptor
 
 const file_pkg_protocol_protocol_proto_rawDesc = "" +
 	"\n" +
-	"\x1bpkg/protocol/protocol.proto\x12\bprotocol\"D\n" +
+	"\x1bpkg/protocol/protocol.proto\x12\bprotocol\"a\n" +
+	"\aProfile\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\rR\x04data\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\rR\x05total\x12\x14\n" +
+	"\x05codec\x18\x03 \x01(\tR\x05codec\x12\x16\n" +
+	"\x06stripe\x18\x04 \x01(\rR\x06stripe\"\x84\x06\n" +
 	"\x04FPCC\x12\x16\n" +
 	"\x06hashes\x18\x01 \x03(\fR\x06hashes\x12\x10\n" +
 	"\x03fps\x18\x02 \x03(\x04R\x03fps\x12\x12\n" +
-	"\x04seed\x18\x03 \x01(\x04R\x04seed\"\x95\x01\n" +
+	"\x04seed\x18\x03 \x01(\x04R\x04seed\x12+\n" +
+	"\aprofile\x18\x04 \x01(\v2\x11.protocol.ProfileR\aprofile\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x04R\x04size\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x06 \x01(\x04R\n" +
+	"generation\x12\x14\n" +
+	"\x05roots\x18\b \x03(\fR\x05roots\x12.\n" +
+	"\benvelope\x18\t \x01(\v2\x12.protocol.EnvelopeR\benvelope\x127\n" +
+	"\vcompression\x18\n" +
+	" \x01(\v2\x15.protocol.CompressionR\vcompression\x12.\n" +
+	"\bmanifest\x18\v \x01(\v2\x12.protocol.ManifestR\bmanifest\x12\x1e\n" +
+	"\n" +
+	"transcoded\x18\f \x01(\bR\n" +
+	"transcoded\x12\"\n" +
+	"\rif_none_match\x18\r \x01(\bR\vifNoneMatch\x12(\n" +
+	"\x10if_match_version\x18\x0e \x01(\fR\x0eifMatchVersion\x128\n" +
+	"\bmetadata\x18\x0f \x03(\v2\x1c.protocol.FPCC.MetadataEntryR\bmetadata\x12,\n" +
+	"\x04tags\x18\x10 \x03(\v2\x18.protocol.FPCC.TagsEntryR\x04tags\x12!\n" +
+	"\fexpires_unix\x18\x11 \x01(\x03R\vexpiresUnix\x12\x16\n" +
+	"\x06retain\x18\x12 \x01(\bR\x06retain\x12!\n" +
+	"\fcreated_unix\x18\x13 \x01(\x03R\vcreatedUnix\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01J\x04\b\a\x10\b\"6\n" +
+	"\bManifest\x12*\n" +
+	"\x06chunks\x18\x01 \x03(\v2\x12.protocol.ChunkRefR\x06chunks\"2\n" +
+	"\bChunkRef\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\fR\x04hash\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x04R\x04size\"7\n" +
+	"\vCompression\x12\x14\n" +
+	"\x05codec\x18\x01 \x01(\tR\x05codec\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x04R\x04size\"\xa4\x01\n" +
+	"\bEnvelope\x12\x15\n" +
+	"\x06key_id\x18\x01 \x01(\tR\x05keyId\x12\x1f\n" +
+	"\vwrapped_key\x18\x02 \x01(\fR\n" +
+	"wrappedKey\x12\x12\n" +
+	"\x04salt\x18\x03 \x01(\fR\x04salt\x12\x1e\n" +
+	"\n" +
+	"iterations\x18\x04 \x01(\rR\n" +
+	"iterations\x12\x18\n" +
+	"\asegment\x18\x05 \x01(\rR\asegment\x12\x12\n" +
+	"\x04size\x18\x06 \x01(\x04R\x04size\"6\n" +
+	"\n" +
+	"BlockProof\x12\x14\n" +
+	"\x05block\x18\x01 \x01(\x04R\x05block\x12\x12\n" +
+	"\x04path\x18\x02 \x03(\fR\x04path\"\x95\x01\n" +
 	"\x0fDisperseRequest\x12\x1b\n" +
 	"\tobject_id\x18\x01 \x01(\tR\bobjectId\x12%\n" +
 	"\x0efragment_index\x18\x02 \x01(\rR\rfragmentIndex\x12\x1a\n" +
@@ -562,33 +3678,278 @@ const file_pkg_protocol_protocol_proto_rawDesc = "" +
 	"\x04fpcc\x18\x04 \x01(\v2\x0e.protocol.FPCCR\x04fpcc\"8\n" +
 	"\x10DisperseResponse\x12\x0e\n" +
 	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"N\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x80\x01\n" +
 	"\vEchoRequest\x12\x1b\n" +
-	"\tobject_id\x18\x01 \x01(\tR\bobjectId\x12\"\n" +
-	"\x04fpcc\x18\x02 \x01(\v2\x0e.protocol.FPCCR\x04fpcc\"4\n" +
+	"\tobject_id\x18\x01 \x01(\tR\bobjectId\x12\x16\n" +
+	"\x06sender\x18\x03 \x01(\tR\x06sender\x12\x16\n" +
+	"\x06digest\x18\x04 \x01(\fR\x06digest\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x05 \x01(\x04R\n" +
+	"generationJ\x04\b\x02\x10\x03\"4\n" +
 	"\fEchoResponse\x12\x0e\n" +
 	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"O\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x81\x01\n" +
 	"\fReadyRequest\x12\x1b\n" +
-	"\tobject_id\x18\x01 \x01(\tR\bobjectId\x12\"\n" +
-	"\x04fpcc\x18\x02 \x01(\v2\x0e.protocol.FPCCR\x04fpcc\"5\n" +
+	"\tobject_id\x18\x01 \x01(\tR\bobjectId\x12\x16\n" +
+	"\x06sender\x18\x03 \x01(\tR\x06sender\x12\x16\n" +
+	"\x06digest\x18\x04 \x01(\fR\x06digest\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x05 \x01(\x04R\n" +
+	"generationJ\x04\b\x02\x10\x03\"A\n" +
+	"\x10EchoBatchRequest\x12-\n" +
+	"\x06echoes\x18\x01 \x03(\v2\x15.protocol.EchoRequestR\x06echoes\"E\n" +
+	"\x11EchoBatchResponse\x120\n" +
+	"\aresults\x18\x01 \x03(\v2\x16.protocol.EchoResponseR\aresults\"E\n" +
+	"\x11ReadyBatchRequest\x120\n" +
+	"\areadies\x18\x01 \x03(\v2\x16.protocol.ReadyRequestR\areadies\"G\n" +
+	"\x12ReadyBatchResponse\x121\n" +
+	"\aresults\x18\x01 \x03(\v2\x17.protocol.ReadyResponseR\aresults\"e\n" +
+	"\x0eGetFPCCRequest\x12\x1b\n" +
+	"\tobject_id\x18\x01 \x01(\tR\bobjectId\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x02 \x01(\x04R\n" +
+	"generation\x12\x16\n" +
+	"\x06digest\x18\x03 \x01(\fR\x06digest\"[\n" +
+	"\x0fGetFPCCResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\"\n" +
+	"\x04fpcc\x18\x03 \x01(\v2\x0e.protocol.FPCCR\x04fpcc\"5\n" +
 	"\rReadyResponse\x12\x0e\n" +
 	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"U\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"D\n" +
+	"\vStatRequest\x12\x1b\n" +
+	"\tobject_id\x18\x01 \x01(\tR\bobjectId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\fR\aversion\"{\n" +
+	"\fStatResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\"\n" +
+	"\x04fpcc\x18\x03 \x01(\v2\x0e.protocol.FPCCR\x04fpcc\x12!\n" +
+	"\fcreated_unix\x18\x04 \x01(\x03R\vcreatedUnix\",\n" +
+	"\rDeleteRequest\x12\x1b\n" +
+	"\tobject_id\x18\x01 \x01(\tR\bobjectId\"L\n" +
+	"\x0eDeleteResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x14\n" +
+	"\x05found\x18\x03 \x01(\bR\x05found\"\xca\x01\n" +
+	"\vListRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x1f\n" +
+	"\vstart_after\x18\x02 \x01(\tR\n" +
+	"startAfter\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\rR\x05limit\x123\n" +
+	"\x04tags\x18\x04 \x03(\v2\x1f.protocol.ListRequest.TagsEntryR\x04tags\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x83\x01\n" +
+	"\fListResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12/\n" +
+	"\aobjects\x18\x03 \x03(\v2\x15.protocol.ObjectEntryR\aobjects\x12\x1c\n" +
+	"\ttruncated\x18\x04 \x01(\bR\ttruncated\"\x85\x03\n" +
+	"\vObjectEntry\x12\x1b\n" +
+	"\tobject_id\x18\x01 \x01(\tR\bobjectId\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x04R\x04size\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x03 \x01(\x04R\n" +
+	"generation\x12!\n" +
+	"\fcreated_unix\x18\x04 \x01(\x03R\vcreatedUnix\x12\x16\n" +
+	"\x06digest\x18\x05 \x01(\fR\x06digest\x12?\n" +
+	"\bmetadata\x18\x06 \x03(\v2#.protocol.ObjectEntry.MetadataEntryR\bmetadata\x123\n" +
+	"\x04tags\x18\a \x03(\v2\x1f.protocol.ObjectEntry.TagsEntryR\x04tags\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\".\n" +
+	"\x0fVersionsRequest\x12\x1b\n" +
+	"\tobject_id\x18\x01 \x01(\tR\bobjectId\"l\n" +
+	"\x10VersionsResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x122\n" +
+	"\bversions\x18\x03 \x03(\v2\x16.protocol.VersionEntryR\bversions\"~\n" +
+	"\fVersionEntry\x12\"\n" +
+	"\x04fpcc\x18\x01 \x01(\v2\x0e.protocol.FPCCR\x04fpcc\x12!\n" +
+	"\fcreated_unix\x18\x02 \x01(\x03R\vcreatedUnix\x12'\n" +
+	"\x0fsuperseded_unix\x18\x03 \x01(\x03R\x0esupersededUnix\"\xac\x01\n" +
+	"\x0eHandoffRequest\x12\x1b\n" +
+	"\tobject_id\x18\x01 \x01(\tR\bobjectId\x12%\n" +
+	"\x0efragment_index\x18\x02 \x01(\rR\rfragmentIndex\x12\x1a\n" +
+	"\bfragment\x18\x03 \x01(\fR\bfragment\x12\"\n" +
+	"\x04fpcc\x18\x04 \x01(\v2\x0e.protocol.FPCCR\x04fpcc\x12\x16\n" +
+	"\x06sender\x18\x05 \x01(\tR\x06sender\"7\n" +
+	"\x0fHandoffResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"L\n" +
+	"\rLocateRequest\x12\x1b\n" +
+	"\tobject_id\x18\x01 \x01(\tR\bobjectId\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x02 \x01(\x04R\n" +
+	"generation\"a\n" +
+	"\x0eLocateResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12)\n" +
+	"\x10fragment_indices\x18\x03 \x03(\rR\x0ffragmentIndices\"\xbd\x01\n" +
 	"\x0fRetrieveRequest\x12\x1b\n" +
 	"\tobject_id\x18\x01 \x01(\tR\bobjectId\x12%\n" +
-	"\x0efragment_index\x18\x02 \x01(\rR\rfragmentIndex\"\x9f\x01\n" +
+	"\x0efragment_index\x18\x02 \x01(\rR\rfragmentIndex\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x03 \x01(\x04R\n" +
+	"generation\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x04R\x06offset\x12\x16\n" +
+	"\x06length\x18\x05 \x01(\x04R\x06length\x12\x16\n" +
+	"\x06proofs\x18\x06 \x01(\bR\x06proofs\"\xcd\x01\n" +
 	"\x10RetrieveResponse\x12\x0e\n" +
 	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1a\n" +
 	"\bfragment\x18\x03 \x01(\fR\bfragment\x12%\n" +
 	"\x0efragment_index\x18\x04 \x01(\rR\rfragmentIndex\x12\"\n" +
-	"\x04fpcc\x18\x05 \x01(\v2\x0e.protocol.FPCCR\x04fpcc2\x82\x02\n" +
+	"\x04fpcc\x18\x05 \x01(\v2\x0e.protocol.FPCCR\x04fpcc\x12,\n" +
+	"\x06proofs\x18\x06 \x03(\v2\x14.protocol.BlockProofR\x06proofs\"*\n" +
+	"\x10HasChunksRequest\x12\x16\n" +
+	"\x06hashes\x18\x01 \x03(\fR\x06hashes\"M\n" +
+	"\x11HasChunksResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
+	"\x04held\x18\x03 \x03(\bR\x04held\"\x8b\x01\n" +
+	"\rDisperseChunk\x12\x1b\n" +
+	"\tobject_id\x18\x01 \x01(\tR\bobjectId\x12%\n" +
+	"\x0efragment_index\x18\x02 \x01(\rR\rfragmentIndex\x12\"\n" +
+	"\x04fpcc\x18\x03 \x01(\v2\x0e.protocol.FPCCR\x04fpcc\x12\x12\n" +
+	"\x04data\x18\x04 \x01(\fR\x04data\"\xb3\x01\n" +
+	"\rRetrieveChunk\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\"\n" +
+	"\x04fpcc\x18\x03 \x01(\v2\x0e.protocol.FPCCR\x04fpcc\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x04R\x06length\x12\x12\n" +
+	"\x04data\x18\x05 \x01(\fR\x04data\x12,\n" +
+	"\x06proofs\x18\x06 \x03(\v2\x14.protocol.BlockProofR\x06proofs\"s\n" +
+	"\x10PutObjectRequest\x12\x1b\n" +
+	"\tobject_id\x18\x01 \x01(\tR\bobjectId\x12.\n" +
+	"\aoptions\x18\x02 \x01(\v2\x14.protocol.PutOptionsR\aoptions\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\"\xc6\x04\n" +
+	"\n" +
+	"PutOptions\x12\x14\n" +
+	"\x05codec\x18\x01 \x01(\tR\x05codec\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\rR\x04data\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\rR\x05total\x12%\n" +
+	"\vcompression\x18\x04 \x01(\tH\x00R\vcompression\x88\x01\x01\x12\x19\n" +
+	"\x05dedup\x18\x05 \x01(\bH\x01R\x05dedup\x88\x01\x01\x12\x1d\n" +
+	"\aencrypt\x18\x06 \x01(\bH\x02R\aencrypt\x88\x01\x01\x12\"\n" +
+	"\rif_none_match\x18\a \x01(\bR\vifNoneMatch\x12(\n" +
+	"\x10if_match_version\x18\b \x01(\tR\x0eifMatchVersion\x12>\n" +
+	"\bmetadata\x18\t \x03(\v2\".protocol.PutOptions.MetadataEntryR\bmetadata\x122\n" +
+	"\x04tags\x18\n" +
+	" \x03(\v2\x1e.protocol.PutOptions.TagsEntryR\x04tags\x12!\n" +
+	"\fexpires_unix\x18\v \x01(\x03R\vexpiresUnix\x12\x16\n" +
+	"\x06retain\x18\f \x01(\bR\x06retain\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x0e\n" +
+	"\f_compressionB\b\n" +
+	"\x06_dedupB\n" +
+	"\n" +
+	"\b_encrypt\"Z\n" +
+	"\x11PutObjectResponse\x12\"\n" +
+	"\x04fpcc\x18\x01 \x01(\v2\x0e.protocol.FPCCR\x04fpcc\x12!\n" +
+	"\fcreated_unix\x18\x02 \x01(\x03R\vcreatedUnix\"y\n" +
+	"\x10GetObjectRequest\x12\x1b\n" +
+	"\tobject_id\x18\x01 \x01(\tR\bobjectId\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x04R\x06offset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x04R\x06length\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\"k\n" +
+	"\x0eGetObjectChunk\x12\"\n" +
+	"\x04fpcc\x18\x01 \x01(\v2\x0e.protocol.FPCCR\x04fpcc\x12!\n" +
+	"\fcreated_unix\x18\x02 \x01(\x03R\vcreatedUnix\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\"\xba\x01\n" +
+	"\x06Member\x12\x12\n" +
+	"\x04addr\x18\x01 \x01(\tR\x04addr\x12+\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x15.protocol.MemberStateR\x05state\x124\n" +
+	"\x06labels\x18\x03 \x03(\v2\x1c.protocol.Member.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"o\n" +
+	"\x04View\x12\x14\n" +
+	"\x05epoch\x18\x01 \x01(\x04R\x05epoch\x12*\n" +
+	"\amembers\x18\x02 \x03(\v2\x10.protocol.MemberR\amembers\x12%\n" +
+	"\x0efailure_domain\x18\x03 \x01(\tR\rfailureDomain\"\x10\n" +
+	"\x0eGetViewRequest\"\x9d\x01\n" +
+	"\x0eAddNodeRequest\x12\x12\n" +
+	"\x04addr\x18\x01 \x01(\tR\x04addr\x12<\n" +
+	"\x06labels\x18\x02 \x03(\v2$.protocol.AddNodeRequest.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"'\n" +
+	"\x11RemoveNodeRequest\x12\x12\n" +
+	"\x04addr\x18\x01 \x01(\tR\x04addr\"\xc7\x01\n" +
+	"\x12ReplaceNodeRequest\x12\x19\n" +
+	"\bold_addr\x18\x01 \x01(\tR\aoldAddr\x12\x19\n" +
+	"\bnew_addr\x18\x02 \x01(\tR\anewAddr\x12@\n" +
+	"\x06labels\x18\x03 \x03(\v2(.protocol.ReplaceNodeRequest.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\"\n" +
+	"\fDrainRequest\x12\x12\n" +
+	"\x04addr\x18\x01 \x01(\tR\x04addr\"\xb1\x01\n" +
+	"\x13DrainStatusResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1a\n" +
+	"\bdraining\x18\x03 \x01(\bR\bdraining\x12\x18\n" +
+	"\aobjects\x18\x04 \x01(\rR\aobjects\x12\x18\n" +
+	"\arehomed\x18\x05 \x01(\rR\arehomed\x12$\n" +
+	"\x0esafe_to_remove\x18\x06 \x01(\bR\fsafeToRemove\"^\n" +
+	"\x12MembershipResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\"\n" +
+	"\x04view\x18\x03 \x01(\v2\x0e.protocol.ViewR\x04view\"W\n" +
+	"\x12ProposeViewRequest\x12\"\n" +
+	"\x04view\x18\x01 \x01(\v2\x0e.protocol.ViewR\x04view\x12\x1d\n" +
+	"\n" +
+	"base_epoch\x18\x02 \x01(\x04R\tbaseEpoch\"7\n" +
+	"\x11CommitViewRequest\x12\"\n" +
+	"\x04view\x18\x01 \x01(\v2\x0e.protocol.ViewR\x04view\"X\n" +
+	"\fViewResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\"\n" +
+	"\x04view\x18\x03 \x01(\v2\x0e.protocol.ViewR\x04view*'\n" +
+	"\vMemberState\x12\n" +
+	"\n" +
+	"\x06ACTIVE\x10\x00\x12\f\n" +
+	"\bDRAINING\x10\x012\xa0\t\n" +
 	"\tDispersal\x12A\n" +
 	"\bDisperse\x12\x19.protocol.DisperseRequest\x1a\x1a.protocol.DisperseResponse\x125\n" +
 	"\x04Echo\x12\x15.protocol.EchoRequest\x1a\x16.protocol.EchoResponse\x128\n" +
 	"\x05Ready\x12\x16.protocol.ReadyRequest\x1a\x17.protocol.ReadyResponse\x12A\n" +
-	"\bRetrieve\x12\x19.protocol.RetrieveRequest\x1a\x1a.protocol.RetrieveResponseBAZ?github.com/dattu/distributed_object_store/pkg/protocol;protocolb\x06proto3"
+	"\bRetrieve\x12\x19.protocol.RetrieveRequest\x1a\x1a.protocol.RetrieveResponse\x12>\n" +
+	"\aHandoff\x12\x18.protocol.HandoffRequest\x1a\x19.protocol.HandoffResponse\x12;\n" +
+	"\x06Locate\x12\x17.protocol.LocateRequest\x1a\x18.protocol.LocateResponse\x125\n" +
+	"\x04Stat\x12\x15.protocol.StatRequest\x1a\x16.protocol.StatResponse\x12>\n" +
+	"\aGetFPCC\x12\x18.protocol.GetFPCCRequest\x1a\x19.protocol.GetFPCCResponse\x12D\n" +
+	"\tEchoBatch\x12\x1a.protocol.EchoBatchRequest\x1a\x1b.protocol.EchoBatchResponse\x12G\n" +
+	"\n" +
+	"ReadyBatch\x12\x1b.protocol.ReadyBatchRequest\x1a\x1c.protocol.ReadyBatchResponse\x12G\n" +
+	"\x0eDisperseStream\x12\x17.protocol.DisperseChunk\x1a\x1a.protocol.DisperseResponse(\x01\x12F\n" +
+	"\x0eRetrieveStream\x12\x19.protocol.RetrieveRequest\x1a\x17.protocol.RetrieveChunk0\x01\x12D\n" +
+	"\tHasChunks\x12\x1a.protocol.HasChunksRequest\x1a\x1b.protocol.HasChunksResponse\x12;\n" +
+	"\x06Delete\x12\x17.protocol.DeleteRequest\x1a\x18.protocol.DeleteResponse\x125\n" +
+	"\x04List\x12\x15.protocol.ListRequest\x1a\x16.protocol.ListResponse\x12A\n" +
+	"\bVersions\x12\x19.protocol.VersionsRequest\x1a\x1a.protocol.VersionsResponse\x12F\n" +
+	"\tPutObject\x12\x1a.protocol.PutObjectRequest\x1a\x1b.protocol.PutObjectResponse(\x01\x12C\n" +
+	"\tGetObject\x12\x1a.protocol.GetObjectRequest\x1a\x18.protocol.GetObjectChunk0\x012\xa5\x04\n" +
+	"\n" +
+	"Membership\x123\n" +
+	"\aGetView\x12\x18.protocol.GetViewRequest\x1a\x0e.protocol.View\x12A\n" +
+	"\aAddNode\x12\x18.protocol.AddNodeRequest\x1a\x1c.protocol.MembershipResponse\x12G\n" +
+	"\n" +
+	"RemoveNode\x12\x1b.protocol.RemoveNodeRequest\x1a\x1c.protocol.MembershipResponse\x12I\n" +
+	"\vReplaceNode\x12\x1c.protocol.ReplaceNodeRequest\x1a\x1c.protocol.MembershipResponse\x12=\n" +
+	"\x05Drain\x12\x16.protocol.DrainRequest\x1a\x1c.protocol.MembershipResponse\x12D\n" +
+	"\vDrainStatus\x12\x16.protocol.DrainRequest\x1a\x1d.protocol.DrainStatusResponse\x12C\n" +
+	"\vProposeView\x12\x1c.protocol.ProposeViewRequest\x1a\x16.protocol.ViewResponse\x12A\n" +
+	"\n" +
+	"CommitView\x12\x1b.protocol.CommitViewRequest\x1a\x16.protocol.ViewResponseBAZ?github.com/dattu/distributed_object_store/pkg/protocol;protocolb\x06proto3"
 
 var (
 	file_pkg_protocol_protocol_proto_rawDescOnce sync.Once
@@ -602,36 +3963,175 @@ func file_pkg_protocol_protocol_proto_rawDescGZIP() []byte {
 	return file_pkg_protocol_protocol_proto_rawDescData
 }
 
-var file_pkg_protocol_protocol_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_pkg_protocol_protocol_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_pkg_protocol_protocol_proto_msgTypes = make([]protoimpl.MessageInfo, 66)
 var file_pkg_protocol_protocol_proto_goTypes = []any{
-	(*FPCC)(nil),             // 0: protocol.FPCC
-	(*DisperseRequest)(nil),  // 1: protocol.DisperseRequest
-	(*DisperseResponse)(nil), // 2: protocol.DisperseResponse
-	(*EchoRequest)(nil),      // 3: protocol.EchoRequest
-	(*EchoResponse)(nil),     // 4: protocol.EchoResponse
-	(*ReadyRequest)(nil),     // 5: protocol.ReadyRequest
-	(*ReadyResponse)(nil),    // 6: protocol.ReadyResponse
-	(*RetrieveRequest)(nil),  // 7: protocol.RetrieveRequest
-	(*RetrieveResponse)(nil), // 8: protocol.RetrieveResponse
+	(MemberState)(0),            // 0: protocol.MemberState
+	(*Profile)(nil),             // 1: protocol.Profile
+	(*FPCC)(nil),                // 2: protocol.FPCC
+	(*Manifest)(nil),            // 3: protocol.Manifest
+	(*ChunkRef)(nil),            // 4: protocol.ChunkRef
+	(*Compression)(nil),         // 5: protocol.Compression
+	(*Envelope)(nil),            // 6: protocol.Envelope
+	(*BlockProof)(nil),          // 7: protocol.BlockProof
+	(*DisperseRequest)(nil),     // 8: protocol.DisperseRequest
+	(*DisperseResponse)(nil),    // 9: protocol.DisperseResponse
+	(*EchoRequest)(nil),         // 10: protocol.EchoRequest
+	(*EchoResponse)(nil),        // 11: protocol.EchoResponse
+	(*ReadyRequest)(nil),        // 12: protocol.ReadyRequest
+	(*EchoBatchRequest)(nil),    // 13: protocol.EchoBatchRequest
+	(*EchoBatchResponse)(nil),   // 14: protocol.EchoBatchResponse
+	(*ReadyBatchRequest)(nil),   // 15: protocol.ReadyBatchRequest
+	(*ReadyBatchResponse)(nil),  // 16: protocol.ReadyBatchResponse
+	(*GetFPCCRequest)(nil),      // 17: protocol.GetFPCCRequest
+	(*GetFPCCResponse)(nil),     // 18: protocol.GetFPCCResponse
+	(*ReadyResponse)(nil),       // 19: protocol.ReadyResponse
+	(*StatRequest)(nil),         // 20: protocol.StatRequest
+	(*StatResponse)(nil),        // 21: protocol.StatResponse
+	(*DeleteRequest)(nil),       // 22: protocol.DeleteRequest
+	(*DeleteResponse)(nil),      // 23: protocol.DeleteResponse
+	(*ListRequest)(nil),         // 24: protocol.ListRequest
+	(*ListResponse)(nil),        // 25: protocol.ListResponse
+	(*ObjectEntry)(nil),         // 26: protocol.ObjectEntry
+	(*VersionsRequest)(nil),     // 27: protocol.VersionsRequest
+	(*VersionsResponse)(nil),    // 28: protocol.VersionsResponse
+	(*VersionEntry)(nil),        // 29: protocol.VersionEntry
+	(*HandoffRequest)(nil),      // 30: protocol.HandoffRequest
+	(*HandoffResponse)(nil),     // 31: protocol.HandoffResponse
+	(*LocateRequest)(nil),       // 32: protocol.LocateRequest
+	(*LocateResponse)(nil),      // 33: protocol.LocateResponse
+	(*RetrieveRequest)(nil),     // 34: protocol.RetrieveRequest
+	(*RetrieveResponse)(nil),    // 35: protocol.RetrieveResponse
+	(*HasChunksRequest)(nil),    // 36: protocol.HasChunksRequest
+	(*HasChunksResponse)(nil),   // 37: protocol.HasChunksResponse
+	(*DisperseChunk)(nil),       // 38: protocol.DisperseChunk
+	(*RetrieveChunk)(nil),       // 39: protocol.RetrieveChunk
+	(*PutObjectRequest)(nil),    // 40: protocol.PutObjectRequest
+	(*PutOptions)(nil),          // 41: protocol.PutOptions
+	(*PutObjectResponse)(nil),   // 42: protocol.PutObjectResponse
+	(*GetObjectRequest)(nil),    // 43: protocol.GetObjectRequest
+	(*GetObjectChunk)(nil),      // 44: protocol.GetObjectChunk
+	(*Member)(nil),              // 45: protocol.Member
+	(*View)(nil),                // 46: protocol.View
+	(*GetViewRequest)(nil),      // 47: protocol.GetViewRequest
+	(*AddNodeRequest)(nil),      // 48: protocol.AddNodeRequest
+	(*RemoveNodeRequest)(nil),   // 49: protocol.RemoveNodeRequest
+	(*ReplaceNodeRequest)(nil),  // 50: protocol.ReplaceNodeRequest
+	(*DrainRequest)(nil),        // 51: protocol.DrainRequest
+	(*DrainStatusResponse)(nil), // 52: protocol.DrainStatusResponse
+	(*MembershipResponse)(nil),  // 53: protocol.MembershipResponse
+	(*ProposeViewRequest)(nil),  // 54: protocol.ProposeViewRequest
+	(*CommitViewRequest)(nil),   // 55: protocol.CommitViewRequest
+	(*ViewResponse)(nil),        // 56: protocol.ViewResponse
+	nil,                         // 57: protocol.FPCC.MetadataEntry
+	nil,                         // 58: protocol.FPCC.TagsEntry
+	nil,                         // 59: protocol.ListRequest.TagsEntry
+	nil,                         // 60: protocol.ObjectEntry.MetadataEntry
+	nil,                         // 61: protocol.ObjectEntry.TagsEntry
+	nil,                         // 62: protocol.PutOptions.MetadataEntry
+	nil,                         // 63: protocol.PutOptions.TagsEntry
+	nil,                         // 64: protocol.Member.LabelsEntry
+	nil,                         // 65: protocol.AddNodeRequest.LabelsEntry
+	nil,                         // 66: protocol.ReplaceNodeRequest.LabelsEntry
 }
 var file_pkg_protocol_protocol_proto_depIdxs = []int32{
-	0, // 0: protocol.DisperseRequest.fpcc:type_name -> protocol.FPCC
-	0, // 1: protocol.EchoRequest.fpcc:type_name -> protocol.FPCC
-	0, // 2: protocol.ReadyRequest.fpcc:type_name -> protocol.FPCC
-	0, // 3: protocol.RetrieveResponse.fpcc:type_name -> protocol.FPCC
-	1, // 4: protocol.Dispersal.Disperse:input_type -> protocol.DisperseRequest
-	3, // 5: protocol.Dispersal.Echo:input_type -> protocol.EchoRequest
-	5, // 6: protocol.Dispersal.Ready:input_type -> protocol.ReadyRequest
-	7, // 7: protocol.Dispersal.Retrieve:input_type -> protocol.RetrieveRequest
-	2, // 8: protocol.Dispersal.Disperse:output_type -> protocol.DisperseResponse
-	4, // 9: protocol.Dispersal.Echo:output_type -> protocol.EchoResponse
-	6, // 10: protocol.Dispersal.Ready:output_type -> protocol.ReadyResponse
-	8, // 11: protocol.Dispersal.Retrieve:output_type -> protocol.RetrieveResponse
-	8, // [8:12] is the sub-list for method output_type
-	4, // [4:8] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	1,  // 0: protocol.FPCC.profile:type_name -> protocol.Profile
+	6,  // 1: protocol.FPCC.envelope:type_name -> protocol.Envelope
+	5,  // 2: protocol.FPCC.compression:type_name -> protocol.Compression
+	3,  // 3: protocol.FPCC.manifest:type_name -> protocol.Manifest
+	57, // 4: protocol.FPCC.metadata:type_name -> protocol.FPCC.MetadataEntry
+	58, // 5: protocol.FPCC.tags:type_name -> protocol.FPCC.TagsEntry
+	4,  // 6: protocol.Manifest.chunks:type_name -> protocol.ChunkRef
+	2,  // 7: protocol.DisperseRequest.fpcc:type_name -> protocol.FPCC
+	10, // 8: protocol.EchoBatchRequest.echoes:type_name -> protocol.EchoRequest
+	11, // 9: protocol.EchoBatchResponse.results:type_name -> protocol.EchoResponse
+	12, // 10: protocol.ReadyBatchRequest.readies:type_name -> protocol.ReadyRequest
+	19, // 11: protocol.ReadyBatchResponse.results:type_name -> protocol.ReadyResponse
+	2,  // 12: protocol.GetFPCCResponse.fpcc:type_name -> protocol.FPCC
+	2,  // 13: protocol.StatResponse.fpcc:type_name -> protocol.FPCC
+	59, // 14: protocol.ListRequest.tags:type_name -> protocol.ListRequest.TagsEntry
+	26, // 15: protocol.ListResponse.objects:type_name -> protocol.ObjectEntry
+	60, // 16: protocol.ObjectEntry.metadata:type_name -> protocol.ObjectEntry.MetadataEntry
+	61, // 17: protocol.ObjectEntry.tags:type_name -> protocol.ObjectEntry.TagsEntry
+	29, // 18: protocol.VersionsResponse.versions:type_name -> protocol.VersionEntry
+	2,  // 19: protocol.VersionEntry.fpcc:type_name -> protocol.FPCC
+	2,  // 20: protocol.HandoffRequest.fpcc:type_name -> protocol.FPCC
+	2,  // 21: protocol.RetrieveResponse.fpcc:type_name -> protocol.FPCC
+	7,  // 22: protocol.RetrieveResponse.proofs:type_name -> protocol.BlockProof
+	2,  // 23: protocol.DisperseChunk.fpcc:type_name -> protocol.FPCC
+	2,  // 24: protocol.RetrieveChunk.fpcc:type_name -> protocol.FPCC
+	7,  // 25: protocol.RetrieveChunk.proofs:type_name -> protocol.BlockProof
+	41, // 26: protocol.PutObjectRequest.options:type_name -> protocol.PutOptions
+	62, // 27: protocol.PutOptions.metadata:type_name -> protocol.PutOptions.MetadataEntry
+	63, // 28: protocol.PutOptions.tags:type_name -> protocol.PutOptions.TagsEntry
+	2,  // 29: protocol.PutObjectResponse.fpcc:type_name -> protocol.FPCC
+	2,  // 30: protocol.GetObjectChunk.fpcc:type_name -> protocol.FPCC
+	0,  // 31: protocol.Member.state:type_name -> protocol.MemberState
+	64, // 32: protocol.Member.labels:type_name -> protocol.Member.LabelsEntry
+	45, // 33: protocol.View.members:type_name -> protocol.Member
+	65, // 34: protocol.AddNodeRequest.labels:type_name -> protocol.AddNodeRequest.LabelsEntry
+	66, // 35: protocol.ReplaceNodeRequest.labels:type_name -> protocol.ReplaceNodeRequest.LabelsEntry
+	46, // 36: protocol.MembershipResponse.view:type_name -> protocol.View
+	46, // 37: protocol.ProposeViewRequest.view:type_name -> protocol.View
+	46, // 38: protocol.CommitViewRequest.view:type_name -> protocol.View
+	46, // 39: protocol.ViewResponse.view:type_name -> protocol.View
+	8,  // 40: protocol.Dispersal.Disperse:input_type -> protocol.DisperseRequest
+	10, // 41: protocol.Dispersal.Echo:input_type -> protocol.EchoRequest
+	12, // 42: protocol.Dispersal.Ready:input_type -> protocol.ReadyRequest
+	34, // 43: protocol.Dispersal.Retrieve:input_type -> protocol.RetrieveRequest
+	30, // 44: protocol.Dispersal.Handoff:input_type -> protocol.HandoffRequest
+	32, // 45: protocol.Dispersal.Locate:input_type -> protocol.LocateRequest
+	20, // 46: protocol.Dispersal.Stat:input_type -> protocol.StatRequest
+	17, // 47: protocol.Dispersal.GetFPCC:input_type -> protocol.GetFPCCRequest
+	13, // 48: protocol.Dispersal.EchoBatch:input_type -> protocol.EchoBatchRequest
+	15, // 49: protocol.Dispersal.ReadyBatch:input_type -> protocol.ReadyBatchRequest
+	38, // 50: protocol.Dispersal.DisperseStream:input_type -> protocol.DisperseChunk
+	34, // 51: protocol.Dispersal.RetrieveStream:input_type -> protocol.RetrieveRequest
+	36, // 52: protocol.Dispersal.HasChunks:input_type -> protocol.HasChunksRequest
+	22, // 53: protocol.Dispersal.Delete:input_type -> protocol.DeleteRequest
+	24, // 54: protocol.Dispersal.List:input_type -> protocol.ListRequest
+	27, // 55: protocol.Dispersal.Versions:input_type -> protocol.VersionsRequest
+	40, // 56: protocol.Dispersal.PutObject:input_type -> protocol.PutObjectRequest
+	43, // 57: protocol.Dispersal.GetObject:input_type -> protocol.GetObjectRequest
+	47, // 58: protocol.Membership.GetView:input_type -> protocol.GetViewRequest
+	48, // 59: protocol.Membership.AddNode:input_type -> protocol.AddNodeRequest
+	49, // 60: protocol.Membership.RemoveNode:input_type -> protocol.RemoveNodeRequest
+	50, // 61: protocol.Membership.ReplaceNode:input_type -> protocol.ReplaceNodeRequest
+	51, // 62: protocol.Membership.Drain:input_type -> protocol.DrainRequest
+	51, // 63: protocol.Membership.DrainStatus:input_type -> protocol.DrainRequest
+	54, // 64: protocol.Membership.ProposeView:input_type -> protocol.ProposeViewRequest
+	55, // 65: protocol.Membership.CommitView:input_type -> protocol.CommitViewRequest
+	9,  // 66: protocol.Dispersal.Disperse:output_type -> protocol.DisperseResponse
+	11, // 67: protocol.Dispersal.Echo:output_type -> protocol.EchoResponse
+	19, // 68: protocol.Dispersal.Ready:output_type -> protocol.ReadyResponse
+	35, // 69: protocol.Dispersal.Retrieve:output_type -> protocol.RetrieveResponse
+	31, // 70: protocol.Dispersal.Handoff:output_type -> protocol.HandoffResponse
+	33, // 71: protocol.Dispersal.Locate:output_type -> protocol.LocateResponse
+	21, // 72: protocol.Dispersal.Stat:output_type -> protocol.StatResponse
+	18, // 73: protocol.Dispersal.GetFPCC:output_type -> protocol.GetFPCCResponse
+	14, // 74: protocol.Dispersal.EchoBatch:output_type -> protocol.EchoBatchResponse
+	16, // 75: protocol.Dispersal.ReadyBatch:output_type -> protocol.ReadyBatchResponse
+	9,  // 76: protocol.Dispersal.DisperseStream:output_type -> protocol.DisperseResponse
+	39, // 77: protocol.Dispersal.RetrieveStream:output_type -> protocol.RetrieveChunk
+	37, // 78: protocol.Dispersal.HasChunks:output_type -> protocol.HasChunksResponse
+	23, // 79: protocol.Dispersal.Delete:output_type -> protocol.DeleteResponse
+	25, // 80: protocol.Dispersal.List:output_type -> protocol.ListResponse
+	28, // 81: protocol.Dispersal.Versions:output_type -> protocol.VersionsResponse
+	42, // 82: protocol.Dispersal.PutObject:output_type -> protocol.PutObjectResponse
+	44, // 83: protocol.Dispersal.GetObject:output_type -> protocol.GetObjectChunk
+	46, // 84: protocol.Membership.GetView:output_type -> protocol.View
+	53, // 85: protocol.Membership.AddNode:output_type -> protocol.MembershipResponse
+	53, // 86: protocol.Membership.RemoveNode:output_type -> protocol.MembershipResponse
+	53, // 87: protocol.Membership.ReplaceNode:output_type -> protocol.MembershipResponse
+	53, // 88: protocol.Membership.Drain:output_type -> protocol.MembershipResponse
+	52, // 89: protocol.Membership.DrainStatus:output_type -> protocol.DrainStatusResponse
+	56, // 90: protocol.Membership.ProposeView:output_type -> protocol.ViewResponse
+	56, // 91: protocol.Membership.CommitView:output_type -> protocol.ViewResponse
+	66, // [66:92] is the sub-list for method output_type
+	40, // [40:66] is the sub-list for method input_type
+	40, // [40:40] is the sub-list for extension type_name
+	40, // [40:40] is the sub-list for extension extendee
+	0,  // [0:40] is the sub-list for field type_name
 }
 
 func init() { file_pkg_protocol_protocol_proto_init() }
@@ -639,18 +4139,20 @@ func file_pkg_protocol_protocol_proto_init() {
 	if File_pkg_protocol_protocol_proto != nil {
 		return
 	}
+	file_pkg_protocol_protocol_proto_msgTypes[40].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_protocol_protocol_proto_rawDesc), len(file_pkg_protocol_protocol_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   9,
+			NumEnums:      1,
+			NumMessages:   66,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_pkg_protocol_protocol_proto_goTypes,
 		DependencyIndexes: file_pkg_protocol_protocol_proto_depIdxs,
+		EnumInfos:         file_pkg_protocol_protocol_proto_enumTypes,
 		MessageInfos:      file_pkg_protocol_protocol_proto_msgTypes,
 	}.Build()
 	File_pkg_protocol_protocol_proto = out.File
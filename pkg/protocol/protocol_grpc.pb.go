@@ -27,10 +27,24 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Dispersal_Disperse_FullMethodName = "/protocol.Dispersal/Disperse"
-	Dispersal_Echo_FullMethodName     = "/protocol.Dispersal/Echo"
-	Dispersal_Ready_FullMethodName    = "/protocol.Dispersal/Ready"
-	Dispersal_Retrieve_FullMethodName = "/protocol.Dispersal/Retrieve"
+	Dispersal_Disperse_FullMethodName       = "/protocol.Dispersal/Disperse"
+	Dispersal_Echo_FullMethodName           = "/protocol.Dispersal/Echo"
+	Dispersal_Ready_FullMethodName          = "/protocol.Dispersal/Ready"
+	Dispersal_Retrieve_FullMethodName       = "/protocol.Dispersal/Retrieve"
+	Dispersal_Handoff_FullMethodName        = "/protocol.Dispersal/Handoff"
+	Dispersal_Locate_FullMethodName         = "/protocol.Dispersal/Locate"
+	Dispersal_Stat_FullMethodName           = "/protocol.Dispersal/Stat"
+	Dispersal_GetFPCC_FullMethodName        = "/protocol.Dispersal/GetFPCC"
+	Dispersal_EchoBatch_FullMethodName      = "/protocol.Dispersal/EchoBatch"
+	Dispersal_ReadyBatch_FullMethodName     = "/protocol.Dispersal/ReadyBatch"
+	Dispersal_DisperseStream_FullMethodName = "/protocol.Dispersal/DisperseStream"
+	Dispersal_RetrieveStream_FullMethodName = "/protocol.Dispersal/RetrieveStream"
+	Dispersal_HasChunks_FullMethodName      = "/protocol.Dispersal/HasChunks"
+	Dispersal_Delete_FullMethodName         = "/protocol.Dispersal/Delete"
+	Dispersal_List_FullMethodName           = "/protocol.Dispersal/List"
+	Dispersal_Versions_FullMethodName       = "/protocol.Dispersal/Versions"
+	Dispersal_PutObject_FullMethodName      = "/protocol.Dispersal/PutObject"
+	Dispersal_GetObject_FullMethodName      = "/protocol.Dispersal/GetObject"
 )
 
 // DispersalClient is the client API for Dispersal service.
@@ -41,6 +55,20 @@ type DispersalClient interface {
 	Echo(ctx context.Context, in *EchoRequest, opts ...grpc.CallOption) (*EchoResponse, error)
 	Ready(ctx context.Context, in *ReadyRequest, opts ...grpc.CallOption) (*ReadyResponse, error)
 	Retrieve(ctx context.Context, in *RetrieveRequest, opts ...grpc.CallOption) (*RetrieveResponse, error)
+	Handoff(ctx context.Context, in *HandoffRequest, opts ...grpc.CallOption) (*HandoffResponse, error)
+	Locate(ctx context.Context, in *LocateRequest, opts ...grpc.CallOption) (*LocateResponse, error)
+	Stat(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*StatResponse, error)
+	GetFPCC(ctx context.Context, in *GetFPCCRequest, opts ...grpc.CallOption) (*GetFPCCResponse, error)
+	EchoBatch(ctx context.Context, in *EchoBatchRequest, opts ...grpc.CallOption) (*EchoBatchResponse, error)
+	ReadyBatch(ctx context.Context, in *ReadyBatchRequest, opts ...grpc.CallOption) (*ReadyBatchResponse, error)
+	DisperseStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[DisperseChunk, DisperseResponse], error)
+	RetrieveStream(ctx context.Context, in *RetrieveRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RetrieveChunk], error)
+	HasChunks(ctx context.Context, in *HasChunksRequest, opts ...grpc.CallOption) (*HasChunksResponse, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	Versions(ctx context.Context, in *VersionsRequest, opts ...grpc.CallOption) (*VersionsResponse, error)
+	PutObject(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PutObjectRequest, PutObjectResponse], error)
+	GetObject(ctx context.Context, in *GetObjectRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GetObjectChunk], error)
 }
 
 type dispersalClient struct {
@@ -91,6 +119,170 @@ func (c *dispersalClient) Retrieve(ctx context.Context, in *RetrieveRequest, opt
 	return out, nil
 }
 
+func (c *dispersalClient) Handoff(ctx context.Context, in *HandoffRequest, opts ...grpc.CallOption) (*HandoffResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HandoffResponse)
+	err := c.cc.Invoke(ctx, Dispersal_Handoff_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dispersalClient) Locate(ctx context.Context, in *LocateRequest, opts ...grpc.CallOption) (*LocateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LocateResponse)
+	err := c.cc.Invoke(ctx, Dispersal_Locate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dispersalClient) Stat(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*StatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatResponse)
+	err := c.cc.Invoke(ctx, Dispersal_Stat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dispersalClient) GetFPCC(ctx context.Context, in *GetFPCCRequest, opts ...grpc.CallOption) (*GetFPCCResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFPCCResponse)
+	err := c.cc.Invoke(ctx, Dispersal_GetFPCC_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dispersalClient) EchoBatch(ctx context.Context, in *EchoBatchRequest, opts ...grpc.CallOption) (*EchoBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EchoBatchResponse)
+	err := c.cc.Invoke(ctx, Dispersal_EchoBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dispersalClient) ReadyBatch(ctx context.Context, in *ReadyBatchRequest, opts ...grpc.CallOption) (*ReadyBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadyBatchResponse)
+	err := c.cc.Invoke(ctx, Dispersal_ReadyBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dispersalClient) DisperseStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[DisperseChunk, DisperseResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Dispersal_ServiceDesc.Streams[0], Dispersal_DisperseStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DisperseChunk, DisperseResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Dispersal_DisperseStreamClient = grpc.ClientStreamingClient[DisperseChunk, DisperseResponse]
+
+func (c *dispersalClient) RetrieveStream(ctx context.Context, in *RetrieveRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RetrieveChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Dispersal_ServiceDesc.Streams[1], Dispersal_RetrieveStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RetrieveRequest, RetrieveChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Dispersal_RetrieveStreamClient = grpc.ServerStreamingClient[RetrieveChunk]
+
+func (c *dispersalClient) HasChunks(ctx context.Context, in *HasChunksRequest, opts ...grpc.CallOption) (*HasChunksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HasChunksResponse)
+	err := c.cc.Invoke(ctx, Dispersal_HasChunks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dispersalClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, Dispersal_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dispersalClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, Dispersal_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dispersalClient) Versions(ctx context.Context, in *VersionsRequest, opts ...grpc.CallOption) (*VersionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VersionsResponse)
+	err := c.cc.Invoke(ctx, Dispersal_Versions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dispersalClient) PutObject(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PutObjectRequest, PutObjectResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Dispersal_ServiceDesc.Streams[2], Dispersal_PutObject_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PutObjectRequest, PutObjectResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Dispersal_PutObjectClient = grpc.ClientStreamingClient[PutObjectRequest, PutObjectResponse]
+
+func (c *dispersalClient) GetObject(ctx context.Context, in *GetObjectRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GetObjectChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Dispersal_ServiceDesc.Streams[3], Dispersal_GetObject_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetObjectRequest, GetObjectChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Dispersal_GetObjectClient = grpc.ServerStreamingClient[GetObjectChunk]
+
 // DispersalServer is the server API for Dispersal service.
 // All implementations must embed UnimplementedDispersalServer
 // for forward compatibility.
@@ -99,6 +291,20 @@ type DispersalServer interface {
 	Echo(context.Context, *EchoRequest) (*EchoResponse, error)
 	Ready(context.Context, *ReadyRequest) (*ReadyResponse, error)
 	Retrieve(context.Context, *RetrieveRequest) (*RetrieveResponse, error)
+	Handoff(context.Context, *HandoffRequest) (*HandoffResponse, error)
+	Locate(context.Context, *LocateRequest) (*LocateResponse, error)
+	Stat(context.Context, *StatRequest) (*StatResponse, error)
+	GetFPCC(context.Context, *GetFPCCRequest) (*GetFPCCResponse, error)
+	EchoBatch(context.Context, *EchoBatchRequest) (*EchoBatchResponse, error)
+	ReadyBatch(context.Context, *ReadyBatchRequest) (*ReadyBatchResponse, error)
+	DisperseStream(grpc.ClientStreamingServer[DisperseChunk, DisperseResponse]) error
+	RetrieveStream(*RetrieveRequest, grpc.ServerStreamingServer[RetrieveChunk]) error
+	HasChunks(context.Context, *HasChunksRequest) (*HasChunksResponse, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	Versions(context.Context, *VersionsRequest) (*VersionsResponse, error)
+	PutObject(grpc.ClientStreamingServer[PutObjectRequest, PutObjectResponse]) error
+	GetObject(*GetObjectRequest, grpc.ServerStreamingServer[GetObjectChunk]) error
 	mustEmbedUnimplementedDispersalServer()
 }
 
@@ -121,6 +327,48 @@ func (UnimplementedDispersalServer) Ready(context.Context, *ReadyRequest) (*Read
 func (UnimplementedDispersalServer) Retrieve(context.Context, *RetrieveRequest) (*RetrieveResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Retrieve not implemented")
 }
+func (UnimplementedDispersalServer) Handoff(context.Context, *HandoffRequest) (*HandoffResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Handoff not implemented")
+}
+func (UnimplementedDispersalServer) Locate(context.Context, *LocateRequest) (*LocateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Locate not implemented")
+}
+func (UnimplementedDispersalServer) Stat(context.Context, *StatRequest) (*StatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stat not implemented")
+}
+func (UnimplementedDispersalServer) GetFPCC(context.Context, *GetFPCCRequest) (*GetFPCCResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFPCC not implemented")
+}
+func (UnimplementedDispersalServer) EchoBatch(context.Context, *EchoBatchRequest) (*EchoBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EchoBatch not implemented")
+}
+func (UnimplementedDispersalServer) ReadyBatch(context.Context, *ReadyBatchRequest) (*ReadyBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadyBatch not implemented")
+}
+func (UnimplementedDispersalServer) DisperseStream(grpc.ClientStreamingServer[DisperseChunk, DisperseResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method DisperseStream not implemented")
+}
+func (UnimplementedDispersalServer) RetrieveStream(*RetrieveRequest, grpc.ServerStreamingServer[RetrieveChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method RetrieveStream not implemented")
+}
+func (UnimplementedDispersalServer) HasChunks(context.Context, *HasChunksRequest) (*HasChunksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HasChunks not implemented")
+}
+func (UnimplementedDispersalServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedDispersalServer) List(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedDispersalServer) Versions(context.Context, *VersionsRequest) (*VersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Versions not implemented")
+}
+func (UnimplementedDispersalServer) PutObject(grpc.ClientStreamingServer[PutObjectRequest, PutObjectResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method PutObject not implemented")
+}
+func (UnimplementedDispersalServer) GetObject(*GetObjectRequest, grpc.ServerStreamingServer[GetObjectChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method GetObject not implemented")
+}
 func (UnimplementedDispersalServer) mustEmbedUnimplementedDispersalServer() {}
 func (UnimplementedDispersalServer) testEmbeddedByValue()                   {}
 
@@ -214,28 +462,673 @@ func _Dispersal_Retrieve_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
-// Dispersal_ServiceDesc is the grpc.ServiceDesc for Dispersal service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var Dispersal_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "protocol.Dispersal",
-	HandlerType: (*DispersalServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Disperse",
-			Handler:    _Dispersal_Disperse_Handler,
-		},
-		{
-			MethodName: "Echo",
-			Handler:    _Dispersal_Echo_Handler,
-		},
-		{
-			MethodName: "Ready",
-			Handler:    _Dispersal_Ready_Handler,
-		},
-		{
-			MethodName: "Retrieve",
-			Handler:    _Dispersal_Retrieve_Handler,
+func _Dispersal_Handoff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandoffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DispersalServer).Handoff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dispersal_Handoff_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DispersalServer).Handoff(ctx, req.(*HandoffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dispersal_Locate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LocateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DispersalServer).Locate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dispersal_Locate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DispersalServer).Locate(ctx, req.(*LocateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dispersal_Stat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DispersalServer).Stat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dispersal_Stat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DispersalServer).Stat(ctx, req.(*StatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dispersal_GetFPCC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFPCCRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DispersalServer).GetFPCC(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dispersal_GetFPCC_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DispersalServer).GetFPCC(ctx, req.(*GetFPCCRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dispersal_EchoBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EchoBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DispersalServer).EchoBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dispersal_EchoBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DispersalServer).EchoBatch(ctx, req.(*EchoBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dispersal_ReadyBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadyBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DispersalServer).ReadyBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dispersal_ReadyBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DispersalServer).ReadyBatch(ctx, req.(*ReadyBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dispersal_DisperseStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DispersalServer).DisperseStream(&grpc.GenericServerStream[DisperseChunk, DisperseResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Dispersal_DisperseStreamServer = grpc.ClientStreamingServer[DisperseChunk, DisperseResponse]
+
+func _Dispersal_RetrieveStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RetrieveRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DispersalServer).RetrieveStream(m, &grpc.GenericServerStream[RetrieveRequest, RetrieveChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Dispersal_RetrieveStreamServer = grpc.ServerStreamingServer[RetrieveChunk]
+
+func _Dispersal_HasChunks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HasChunksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DispersalServer).HasChunks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dispersal_HasChunks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DispersalServer).HasChunks(ctx, req.(*HasChunksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dispersal_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DispersalServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dispersal_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DispersalServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dispersal_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DispersalServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dispersal_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DispersalServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dispersal_Versions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DispersalServer).Versions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dispersal_Versions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DispersalServer).Versions(ctx, req.(*VersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dispersal_PutObject_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DispersalServer).PutObject(&grpc.GenericServerStream[PutObjectRequest, PutObjectResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Dispersal_PutObjectServer = grpc.ClientStreamingServer[PutObjectRequest, PutObjectResponse]
+
+func _Dispersal_GetObject_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetObjectRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DispersalServer).GetObject(m, &grpc.GenericServerStream[GetObjectRequest, GetObjectChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Dispersal_GetObjectServer = grpc.ServerStreamingServer[GetObjectChunk]
+
+// Dispersal_ServiceDesc is the grpc.ServiceDesc for Dispersal service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Dispersal_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "protocol.Dispersal",
+	HandlerType: (*DispersalServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Disperse",
+			Handler:    _Dispersal_Disperse_Handler,
+		},
+		{
+			MethodName: "Echo",
+			Handler:    _Dispersal_Echo_Handler,
+		},
+		{
+			MethodName: "Ready",
+			Handler:    _Dispersal_Ready_Handler,
+		},
+		{
+			MethodName: "Retrieve",
+			Handler:    _Dispersal_Retrieve_Handler,
+		},
+		{
+			MethodName: "Handoff",
+			Handler:    _Dispersal_Handoff_Handler,
+		},
+		{
+			MethodName: "Locate",
+			Handler:    _Dispersal_Locate_Handler,
+		},
+		{
+			MethodName: "Stat",
+			Handler:    _Dispersal_Stat_Handler,
+		},
+		{
+			MethodName: "GetFPCC",
+			Handler:    _Dispersal_GetFPCC_Handler,
+		},
+		{
+			MethodName: "EchoBatch",
+			Handler:    _Dispersal_EchoBatch_Handler,
+		},
+		{
+			MethodName: "ReadyBatch",
+			Handler:    _Dispersal_ReadyBatch_Handler,
+		},
+		{
+			MethodName: "HasChunks",
+			Handler:    _Dispersal_HasChunks_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Dispersal_Delete_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _Dispersal_List_Handler,
+		},
+		{
+			MethodName: "Versions",
+			Handler:    _Dispersal_Versions_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DisperseStream",
+			Handler:       _Dispersal_DisperseStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "RetrieveStream",
+			Handler:       _Dispersal_RetrieveStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "PutObject",
+			Handler:       _Dispersal_PutObject_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "GetObject",
+			Handler:       _Dispersal_GetObject_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/protocol/protocol.proto",
+}
+
+const (
+	Membership_GetView_FullMethodName     = "/protocol.Membership/GetView"
+	Membership_AddNode_FullMethodName     = "/protocol.Membership/AddNode"
+	Membership_RemoveNode_FullMethodName  = "/protocol.Membership/RemoveNode"
+	Membership_ReplaceNode_FullMethodName = "/protocol.Membership/ReplaceNode"
+	Membership_Drain_FullMethodName       = "/protocol.Membership/Drain"
+	Membership_DrainStatus_FullMethodName = "/protocol.Membership/DrainStatus"
+	Membership_ProposeView_FullMethodName = "/protocol.Membership/ProposeView"
+	Membership_CommitView_FullMethodName  = "/protocol.Membership/CommitView"
+)
+
+// MembershipClient is the client API for Membership service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MembershipClient interface {
+	GetView(ctx context.Context, in *GetViewRequest, opts ...grpc.CallOption) (*View, error)
+	AddNode(ctx context.Context, in *AddNodeRequest, opts ...grpc.CallOption) (*MembershipResponse, error)
+	RemoveNode(ctx context.Context, in *RemoveNodeRequest, opts ...grpc.CallOption) (*MembershipResponse, error)
+	ReplaceNode(ctx context.Context, in *ReplaceNodeRequest, opts ...grpc.CallOption) (*MembershipResponse, error)
+	Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*MembershipResponse, error)
+	DrainStatus(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainStatusResponse, error)
+	ProposeView(ctx context.Context, in *ProposeViewRequest, opts ...grpc.CallOption) (*ViewResponse, error)
+	CommitView(ctx context.Context, in *CommitViewRequest, opts ...grpc.CallOption) (*ViewResponse, error)
+}
+
+type membershipClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMembershipClient(cc grpc.ClientConnInterface) MembershipClient {
+	return &membershipClient{cc}
+}
+
+func (c *membershipClient) GetView(ctx context.Context, in *GetViewRequest, opts ...grpc.CallOption) (*View, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(View)
+	err := c.cc.Invoke(ctx, Membership_GetView_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *membershipClient) AddNode(ctx context.Context, in *AddNodeRequest, opts ...grpc.CallOption) (*MembershipResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MembershipResponse)
+	err := c.cc.Invoke(ctx, Membership_AddNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *membershipClient) RemoveNode(ctx context.Context, in *RemoveNodeRequest, opts ...grpc.CallOption) (*MembershipResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MembershipResponse)
+	err := c.cc.Invoke(ctx, Membership_RemoveNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *membershipClient) ReplaceNode(ctx context.Context, in *ReplaceNodeRequest, opts ...grpc.CallOption) (*MembershipResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MembershipResponse)
+	err := c.cc.Invoke(ctx, Membership_ReplaceNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *membershipClient) Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*MembershipResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MembershipResponse)
+	err := c.cc.Invoke(ctx, Membership_Drain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *membershipClient) DrainStatus(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DrainStatusResponse)
+	err := c.cc.Invoke(ctx, Membership_DrainStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *membershipClient) ProposeView(ctx context.Context, in *ProposeViewRequest, opts ...grpc.CallOption) (*ViewResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ViewResponse)
+	err := c.cc.Invoke(ctx, Membership_ProposeView_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *membershipClient) CommitView(ctx context.Context, in *CommitViewRequest, opts ...grpc.CallOption) (*ViewResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ViewResponse)
+	err := c.cc.Invoke(ctx, Membership_CommitView_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MembershipServer is the server API for Membership service.
+// All implementations must embed UnimplementedMembershipServer
+// for forward compatibility.
+type MembershipServer interface {
+	GetView(context.Context, *GetViewRequest) (*View, error)
+	AddNode(context.Context, *AddNodeRequest) (*MembershipResponse, error)
+	RemoveNode(context.Context, *RemoveNodeRequest) (*MembershipResponse, error)
+	ReplaceNode(context.Context, *ReplaceNodeRequest) (*MembershipResponse, error)
+	Drain(context.Context, *DrainRequest) (*MembershipResponse, error)
+	DrainStatus(context.Context, *DrainRequest) (*DrainStatusResponse, error)
+	ProposeView(context.Context, *ProposeViewRequest) (*ViewResponse, error)
+	CommitView(context.Context, *CommitViewRequest) (*ViewResponse, error)
+	mustEmbedUnimplementedMembershipServer()
+}
+
+// UnimplementedMembershipServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMembershipServer struct{}
+
+func (UnimplementedMembershipServer) GetView(context.Context, *GetViewRequest) (*View, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetView not implemented")
+}
+func (UnimplementedMembershipServer) AddNode(context.Context, *AddNodeRequest) (*MembershipResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddNode not implemented")
+}
+func (UnimplementedMembershipServer) RemoveNode(context.Context, *RemoveNodeRequest) (*MembershipResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveNode not implemented")
+}
+func (UnimplementedMembershipServer) ReplaceNode(context.Context, *ReplaceNodeRequest) (*MembershipResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplaceNode not implemented")
+}
+func (UnimplementedMembershipServer) Drain(context.Context, *DrainRequest) (*MembershipResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Drain not implemented")
+}
+func (UnimplementedMembershipServer) DrainStatus(context.Context, *DrainRequest) (*DrainStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DrainStatus not implemented")
+}
+func (UnimplementedMembershipServer) ProposeView(context.Context, *ProposeViewRequest) (*ViewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProposeView not implemented")
+}
+func (UnimplementedMembershipServer) CommitView(context.Context, *CommitViewRequest) (*ViewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommitView not implemented")
+}
+func (UnimplementedMembershipServer) mustEmbedUnimplementedMembershipServer() {}
+func (UnimplementedMembershipServer) testEmbeddedByValue()                    {}
+
+// UnsafeMembershipServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MembershipServer will
+// result in compilation errors.
+type UnsafeMembershipServer interface {
+	mustEmbedUnimplementedMembershipServer()
+}
+
+func RegisterMembershipServer(s grpc.ServiceRegistrar, srv MembershipServer) {
+	// If the following call pancis, it indicates UnimplementedMembershipServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Membership_ServiceDesc, srv)
+}
+
+func _Membership_GetView_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetViewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MembershipServer).GetView(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Membership_GetView_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MembershipServer).GetView(ctx, req.(*GetViewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Membership_AddNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MembershipServer).AddNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Membership_AddNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MembershipServer).AddNode(ctx, req.(*AddNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Membership_RemoveNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MembershipServer).RemoveNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Membership_RemoveNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MembershipServer).RemoveNode(ctx, req.(*RemoveNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Membership_ReplaceNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplaceNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MembershipServer).ReplaceNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Membership_ReplaceNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MembershipServer).ReplaceNode(ctx, req.(*ReplaceNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Membership_Drain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MembershipServer).Drain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Membership_Drain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MembershipServer).Drain(ctx, req.(*DrainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Membership_DrainStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MembershipServer).DrainStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Membership_DrainStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MembershipServer).DrainStatus(ctx, req.(*DrainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Membership_ProposeView_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProposeViewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MembershipServer).ProposeView(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Membership_ProposeView_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MembershipServer).ProposeView(ctx, req.(*ProposeViewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Membership_CommitView_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitViewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MembershipServer).CommitView(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Membership_CommitView_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MembershipServer).CommitView(ctx, req.(*CommitViewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Membership_ServiceDesc is the grpc.ServiceDesc for Membership service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Membership_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "protocol.Membership",
+	HandlerType: (*MembershipServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetView",
+			Handler:    _Membership_GetView_Handler,
+		},
+		{
+			MethodName: "AddNode",
+			Handler:    _Membership_AddNode_Handler,
+		},
+		{
+			MethodName: "RemoveNode",
+			Handler:    _Membership_RemoveNode_Handler,
+		},
+		{
+			MethodName: "ReplaceNode",
+			Handler:    _Membership_ReplaceNode_Handler,
+		},
+		{
+			MethodName: "Drain",
+			Handler:    _Membership_Drain_Handler,
+		},
+		{
+			MethodName: "DrainStatus",
+			Handler:    _Membership_DrainStatus_Handler,
+		},
+		{
+			MethodName: "ProposeView",
+			Handler:    _Membership_ProposeView_Handler,
+		},
+		{
+			MethodName: "CommitView",
+			Handler:    _Membership_CommitView_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
@@ -0,0 +1,220 @@
+// pkg/s3/list.go – ListBuckets, ListObjectsV2 and the original
+// ListObjects.
+
+package s3
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/client"
+)
+
+type listAllMyBucketsResult struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListAllMyBucketsResult"`
+	Owner   owner    `xml:"Owner"`
+	Buckets []bucket `xml:"Buckets>Bucket"`
+}
+
+type owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+type bucket struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+func (g *Gateway) listBuckets(w http.ResponseWriter, r *http.Request) {
+	res := listAllMyBucketsResult{Owner: owner{ID: "avid-fp", DisplayName: "avid-fp"}}
+	for _, b := range g.buckets {
+		res.Buckets = append(res.Buckets, bucket{Name: b, CreationDate: g.started.UTC().Format(time.RFC3339)})
+	}
+	writeXML(w, http.StatusOK, res)
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name       `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	Delimiter             string         `xml:"Delimiter,omitempty"`
+	Marker                *string        `xml:"Marker"`
+	NextMarker            string         `xml:"NextMarker,omitempty"`
+	StartAfter            string         `xml:"StartAfter,omitempty"`
+	ContinuationToken     string         `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	KeyCount              *int           `xml:"KeyCount"`
+	MaxKeys               int            `xml:"MaxKeys"`
+	EncodingType          string         `xml:"EncodingType,omitempty"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	Contents              []object       `xml:"Contents"`
+	CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+}
+
+type object struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+// listObjects serves both versions of ListObjects: V2 pages with an
+// opaque continuation token, V1 with the last key as marker.
+func (g *Gateway) listObjects(w http.ResponseWriter, r *http.Request, bucket string) {
+	q := r.URL.Query()
+	v2 := q.Get("list-type") == "2"
+	prefix, delim := q.Get("prefix"), q.Get("delimiter")
+	max := 1000
+	if s := q.Get("max-keys"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			g.fail(w, r, &apiError{http.StatusBadRequest, "InvalidArgument", "max-keys must be a non-negative integer."})
+			return
+		}
+		max = min(n, 1000)
+	}
+
+	// after is where the listing resumes, in the hex form of keys
+	var after string
+	switch {
+	case v2 && q.Has("continuation-token"):
+		var ok bool
+		if after, ok = decodeToken(q.Get("continuation-token")); !ok {
+			g.fail(w, r, &apiError{http.StatusBadRequest, "InvalidArgument", "The continuation token provided is incorrect."})
+			return
+		}
+	case v2:
+		after = hex.EncodeToString([]byte(q.Get("start-after")))
+	default:
+		m := q.Get("marker")
+		after = hex.EncodeToString([]byte(m))
+		if delim != "" && strings.HasPrefix(m, prefix) && strings.HasSuffix(m, delim) {
+			after += "g" // a NextMarker that is a common prefix
+		}
+	}
+
+	l, err := g.list(r.Context(), bucket, prefix, delim, after, max)
+	if err != nil {
+		g.fail(w, r, err)
+		return
+	}
+
+	enc := func(s string) string { return s }
+	res := listBucketResult{Name: bucket, MaxKeys: max, IsTruncated: l.truncated}
+	if q.Get("encoding-type") == "url" {
+		enc = url.QueryEscape
+		res.EncodingType = "url"
+	}
+	res.Prefix, res.Delimiter = enc(prefix), enc(delim)
+	for _, o := range l.objects {
+		res.Contents = append(res.Contents, object{
+			Key:          enc(o.key),
+			LastModified: o.info.Created.UTC().Format("2006-01-02T15:04:05.000Z"),
+			ETag:         etag(o.info),
+			Size:         o.info.Size,
+			StorageClass: "STANDARD",
+		})
+	}
+	for _, p := range l.prefixes {
+		res.CommonPrefixes = append(res.CommonPrefixes, commonPrefix{Prefix: enc(p)})
+	}
+	if v2 {
+		n := len(res.Contents) + len(res.CommonPrefixes)
+		res.KeyCount = &n
+		res.StartAfter = enc(q.Get("start-after"))
+		res.ContinuationToken = q.Get("continuation-token")
+		if l.truncated {
+			res.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(l.next))
+		}
+	} else {
+		marker := enc(q.Get("marker"))
+		res.Marker = &marker
+		if l.truncated {
+			res.NextMarker = enc(l.last)
+		}
+	}
+	writeXML(w, http.StatusOK, res)
+}
+
+type listing struct {
+	objects   []listed
+	prefixes  []string
+	truncated bool
+	next      string // where the next page resumes, in hex form
+	last      string // the last key or common prefix returned
+}
+
+type listed struct {
+	key  string
+	info *client.ObjectInfo
+}
+
+// list collects up to max keys and common prefixes of bucket under prefix
+// that sort after the hex-form key after. A common prefix is returned
+// once and the listing resumes past all the keys it holds: "g" sorts
+// after every hex digit.
+func (g *Gateway) list(ctx context.Context, bucket, prefix, delim, after string, max int) (*listing, error) {
+	base := idPrefix(bucket)
+	l := &listing{}
+	for max > 0 {
+		opts := client.ListOptions{Prefix: base + hex.EncodeToString([]byte(prefix))}
+		if after != "" {
+			opts.StartAfter = base + after
+		}
+		page, next, err := g.store.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		skipped := false
+		for _, info := range page {
+			key, ok := keyOf(bucket, info.ID)
+			if !ok {
+				continue
+			}
+			if len(l.objects)+len(l.prefixes) == max {
+				l.truncated = true
+				return l, nil
+			}
+			if i := strings.Index(key[len(prefix):], delim); delim != "" && i >= 0 {
+				cp := key[:len(prefix)+i+len(delim)]
+				l.prefixes = append(l.prefixes, cp)
+				after = hex.EncodeToString([]byte(cp)) + "g"
+				l.next, l.last = after, cp
+				skipped = true
+				break
+			}
+			l.objects = append(l.objects, listed{key, info})
+			after = hex.EncodeToString([]byte(key))
+			l.next, l.last = after, key
+		}
+		if !skipped && next == "" {
+			break
+		}
+	}
+	return l, nil
+}
+
+// decodeToken checks a continuation token: a hex-form key, or a common
+// prefix followed by "g".
+func decodeToken(token string) (string, bool) {
+	b, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return "", false
+	}
+	h := strings.TrimSuffix(string(b), "g")
+	_, err = hex.DecodeString(h)
+	return string(b), err == nil
+}
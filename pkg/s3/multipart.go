@@ -0,0 +1,354 @@
+// pkg/s3/multipart.go – multipart uploads. The gateway keeps no state of
+// its own: an upload is a marker object naming its bucket and key, and
+// carrying the metadata and tags of the object to be, and each part an
+// object of its own until CompleteMultipartUpload concatenates them into
+// the final object and deletes them.
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/client"
+	"github.com/dattu/distributed_object_store/pkg/sigv4"
+)
+
+const maxParts = 10000
+
+// uploadID is the ID of the marker object of upload u; its parts follow
+// it, after a colon.
+func uploadID(u string) string {
+	return "s3mp:" + u
+}
+
+func partID(u string, n int) string {
+	return fmt.Sprintf("%s:%05d", uploadID(u), n)
+}
+
+// checkUpload makes sure upload u exists and belongs to bucket/key, and
+// returns its marker.
+func (g *Gateway) checkUpload(ctx context.Context, u, bucket, key string) (*client.ObjectInfo, error) {
+	if b, err := hex.DecodeString(u); err != nil || len(b) != 16 {
+		return nil, errNoSuchUpload
+	}
+	var owner bytes.Buffer
+	marker, err := g.store.Get(ctx, uploadID(u), &owner)
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			return nil, errNoSuchUpload
+		}
+		return nil, err
+	}
+	if owner.String() != bucket+"\n"+key {
+		return nil, errNoSuchUpload
+	}
+	return marker, nil
+}
+
+// parts lists the parts uploaded so far, by part number.
+func (g *Gateway) parts(ctx context.Context, u string) (map[int]*client.ObjectInfo, error) {
+	prefix := uploadID(u) + ":"
+	parts := make(map[int]*client.ObjectInfo)
+	opts := client.ListOptions{Prefix: prefix}
+	for {
+		page, next, err := g.store.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range page {
+			if n, err := strconv.Atoi(strings.TrimPrefix(info.ID, prefix)); err == nil {
+				parts[n] = info
+			}
+		}
+		if next == "" {
+			return parts, nil
+		}
+		opts.StartAfter = next
+	}
+}
+
+type initiateResult struct {
+	XMLName  xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ InitiateMultipartUploadResult"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+func (g *Gateway) createUpload(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	b := make([]byte, 16)
+	rand.Read(b)
+	u := hex.EncodeToString(b)
+	meta, tags, err := metadata(r.Header)
+	if err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	opts := g.store.PutOptions()
+	opts.Metadata, opts.Tags = meta, tags
+	if _, err := g.store.Put(r.Context(), uploadID(u), strings.NewReader(bucket+"\n"+key), &opts); err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	writeXML(w, http.StatusOK, initiateResult{Bucket: bucket, Key: key, UploadID: u})
+}
+
+func (g *Gateway) uploadPart(w http.ResponseWriter, r *http.Request, sig *sigv4.Request, bucket, key string) {
+	q := r.URL.Query()
+	n, err := strconv.Atoi(q.Get("partNumber"))
+	if err != nil || n < 1 || n > maxParts {
+		g.fail(w, r, &apiError{http.StatusBadRequest, "InvalidArgument", "Part number must be an integer between 1 and 10000, inclusive."})
+		return
+	}
+	u := q.Get("uploadId")
+	if _, err := g.checkUpload(r.Context(), u, bucket, key); err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	info, err := g.put(r, sig, partID(u, n), nil)
+	if err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", etag(info))
+	w.WriteHeader(http.StatusOK)
+}
+
+type completeRequest struct {
+	Parts []struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	} `xml:"Part"`
+}
+
+type completeResult struct {
+	XMLName  xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CompleteMultipartUploadResult"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+// completeUpload concatenates the listed parts into the object. That
+// takes as long as reading and writing the whole object, so, as S3 does,
+// it answers 200 at once and keeps the connection alive with whitespace
+// until the result – or an error document – follows.
+func (g *Gateway) completeUpload(w http.ResponseWriter, r *http.Request, sig *sigv4.Request, bucket, key string) {
+	ctx := r.Context()
+	u := r.URL.Query().Get("uploadId")
+	var req completeRequest
+	if err := readXML(r, sig, &req); err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	if len(req.Parts) == 0 || len(req.Parts) > maxParts {
+		g.fail(w, r, errMalformedXML)
+		return
+	}
+	marker, err := g.checkUpload(ctx, u, bucket, key)
+	if err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	opts, err := g.putOptions(r, marker.Metadata, marker.Tags)
+	if err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	parts, err := g.parts(ctx, u)
+	if err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	for i, p := range req.Parts {
+		if i > 0 && p.PartNumber <= req.Parts[i-1].PartNumber {
+			g.fail(w, r, errPartOrder)
+			return
+		}
+		info, ok := parts[p.PartNumber]
+		if !ok || strings.Trim(p.ETag, `"`) != strings.Trim(etag(info), `"`) {
+			g.fail(w, r, errInvalidPart)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, xml.Header)
+	stop := keepAlive(w, 10*time.Second)
+	info, err := g.concat(ctx, u, req, bucket, key, opts)
+	stop()
+	if err != nil {
+		e := toAPI(err)
+		g.logf("S3 complete %s/%s: %v", bucket, key, err)
+		xml.NewEncoder(w).Encode(errorBody{apiError: e, Resource: r.URL.Path, RequestID: w.Header().Get("x-amz-request-id")})
+		return
+	}
+	xml.NewEncoder(w).Encode(completeResult{Location: r.URL.Path, Bucket: bucket, Key: key, ETag: etag(info)})
+}
+
+// concat writes the parts of upload u into the object, with opts if set,
+// and drops the upload.
+func (g *Gateway) concat(ctx context.Context, u string, req completeRequest, bucket, key string, opts *client.PutOptions) (*client.ObjectInfo, error) {
+	f, err := os.CreateTemp("", "s3mp-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	for _, p := range req.Parts {
+		if _, err := g.store.Get(ctx, partID(u, p.PartNumber), f); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	info, err := g.store.Put(ctx, objectID(bucket, key), f, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.dropUpload(ctx, u); err != nil {
+		g.logf("S3 upload %s: dropping parts: %v", u, err)
+	}
+	return info, nil
+}
+
+// dropUpload deletes the parts of upload u, then its marker. Parts left
+// behind by a failure are deleted by the next abort.
+func (g *Gateway) dropUpload(ctx context.Context, u string) error {
+	parts, err := g.parts(ctx, u)
+	if err != nil {
+		return err
+	}
+	for _, info := range parts {
+		if err := g.store.Delete(ctx, info.ID); err != nil && !errors.Is(err, client.ErrNotFound) {
+			return err
+		}
+	}
+	if err := g.store.Delete(ctx, uploadID(u)); err != nil && !errors.Is(err, client.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// keepAlive writes a space to w every interval until stopped.
+func keepAlive(w http.ResponseWriter, interval time.Duration) (stop func()) {
+	done, exited := make(chan struct{}), make(chan struct{})
+	rc := http.NewResponseController(w)
+	go func() {
+		defer close(exited)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				io.WriteString(w, " ")
+				rc.Flush()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+func (g *Gateway) abortUpload(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	u := r.URL.Query().Get("uploadId")
+	if _, err := g.checkUpload(r.Context(), u, bucket, key); err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	if err := g.dropUpload(r.Context(), u); err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type listPartsResult struct {
+	XMLName              xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListPartsResult"`
+	Bucket               string   `xml:"Bucket"`
+	Key                  string   `xml:"Key"`
+	UploadID             string   `xml:"UploadId"`
+	PartNumberMarker     int      `xml:"PartNumberMarker"`
+	NextPartNumberMarker int      `xml:"NextPartNumberMarker"`
+	MaxParts             int      `xml:"MaxParts"`
+	IsTruncated          bool     `xml:"IsTruncated"`
+	StorageClass         string   `xml:"StorageClass"`
+	Parts                []part   `xml:"Part"`
+}
+
+type part struct {
+	PartNumber   int    `xml:"PartNumber"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+}
+
+func (g *Gateway) listParts(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	q := r.URL.Query()
+	u := q.Get("uploadId")
+	res := listPartsResult{Bucket: bucket, Key: key, UploadID: u, MaxParts: 1000, StorageClass: "STANDARD"}
+	if s := q.Get("max-parts"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			g.fail(w, r, errInvalidArg)
+			return
+		}
+		res.MaxParts = min(n, 1000)
+	}
+	if s := q.Get("part-number-marker"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			g.fail(w, r, errInvalidArg)
+			return
+		}
+		res.PartNumberMarker = n
+	}
+	if _, err := g.checkUpload(r.Context(), u, bucket, key); err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	parts, err := g.parts(r.Context(), u)
+	if err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	numbers := slices.Sorted(func(yield func(int) bool) {
+		for n := range parts {
+			if n > res.PartNumberMarker && !yield(n) {
+				return
+			}
+		}
+	})
+	if len(numbers) > res.MaxParts {
+		numbers, res.IsTruncated = numbers[:res.MaxParts], true
+	}
+	for _, n := range numbers {
+		info := parts[n]
+		res.Parts = append(res.Parts, part{
+			PartNumber:   n,
+			LastModified: info.Created.UTC().Format("2006-01-02T15:04:05.000Z"),
+			ETag:         etag(info),
+			Size:         info.Size,
+		})
+		res.NextPartNumberMarker = n
+	}
+	writeXML(w, http.StatusOK, res)
+}
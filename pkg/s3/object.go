@@ -0,0 +1,422 @@
+// pkg/s3/object.go – PutObject, GetObject, HeadObject, DeleteObject and
+// DeleteObjects.
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dattu/distributed_object_store/pkg/client"
+	"github.com/dattu/distributed_object_store/pkg/sigv4"
+)
+
+// etag is the entity tag of an object: its FPCC digest. It changes
+// whenever the content does, but it is not the content's MD5 – the gateway
+// never sees a multipart object in one piece, and the servers only know
+// fragments – and its 64 digits keep clients from taking it for one.
+func etag(info *client.ObjectInfo) string {
+	return `"` + hex.EncodeToString(info.Digest) + `"`
+}
+
+func (g *Gateway) putObject(w http.ResponseWriter, r *http.Request, sig *sigv4.Request, bucket, key string) {
+	meta, tags, err := metadata(r.Header)
+	if err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	opts, err := g.putOptions(r, meta, tags)
+	if err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	info, err := g.put(r, sig, objectID(bucket, key), opts)
+	if err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", etag(info))
+	w.WriteHeader(http.StatusOK)
+}
+
+// put stores the body of r as object id, with opts if set. Writing an
+// existing ID adds a version, which S3 clients see as the object being
+// overwritten.
+func (g *Gateway) put(r *http.Request, sig *sigv4.Request, id string, opts *client.PutOptions) (*client.ObjectInfo, error) {
+	f, err := spool(r, sig)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	return g.store.Put(r.Context(), id, f, opts)
+}
+
+// putOptions returns the options of a write storing meta and tags under
+// the conditions of r – If-None-Match: * to create, If-Match with the ETag
+// of the version to replace – or nil for a plain unconditional one. The
+// ETag is the version ID.
+func (g *Gateway) putOptions(r *http.Request, meta, tags map[string]string) (*client.PutOptions, error) {
+	none, match := r.Header.Get("If-None-Match"), r.Header.Get("If-Match")
+	if none == "" && match == "" && meta == nil && tags == nil {
+		return nil, nil
+	}
+	if none != "" && none != "*" {
+		return nil, errNotImplemented
+	}
+	opts := g.store.PutOptions()
+	opts.IfNoneMatch = none == "*"
+	opts.IfMatchVersion = strings.Trim(match, `"`)
+	opts.Metadata, opts.Tags = meta, tags
+	return &opts, nil
+}
+
+// metaPrefix starts the headers carrying user metadata.
+const metaPrefix = "X-Amz-Meta-"
+
+// metadata returns the metadata a PutObject or CreateMultipartUpload
+// sends – x-amz-meta-* headers under their lower-case suffix, and the
+// headers GetObject returns as stored (Content-Type and the others of
+// responseHeaders) under their lower-case names – and its x-amz-tagging.
+func metadata(h http.Header) (meta, tags map[string]string, err error) {
+	meta = make(map[string]string)
+	for k, vs := range h {
+		if name, ok := strings.CutPrefix(k, metaPrefix); ok && name != "" {
+			meta[strings.ToLower(name)] = strings.Join(vs, ",")
+		}
+	}
+	for _, k := range responseHeaders {
+		if v := h.Get(k); v != "" {
+			meta[strings.ToLower(k)] = v
+		}
+	}
+	if len(meta) == 0 {
+		meta = nil
+	}
+	if v := h.Get("X-Amz-Tagging"); v != "" {
+		q, err := url.ParseQuery(v)
+		if err != nil {
+			return nil, nil, errInvalidTag
+		}
+		tags = make(map[string]string, len(q))
+		for k, kv := range q {
+			tags[k] = kv[len(kv)-1]
+		}
+	}
+	return meta, tags, nil
+}
+
+// setMetadata returns the metadata of info in the headers of a GetObject.
+func setMetadata(h http.Header, info *client.ObjectInfo) {
+	h.Set("Content-Type", "application/octet-stream")
+	for k, v := range info.Metadata {
+		if header, ok := responseHeaders["response-"+k]; ok {
+			h.Set(header, v)
+		} else {
+			h.Set(metaPrefix+k, v)
+		}
+	}
+	if len(info.Tags) > 0 {
+		h.Set("X-Amz-Tagging-Count", strconv.Itoa(len(info.Tags)))
+	}
+}
+
+// spool copies the body of r to a temporary file, checking it against its
+// signature, Content-MD5 and declared length on the way: nothing reaches
+// the store that was not sent as signed.
+func spool(r *http.Request, sig *sigv4.Request) (*os.File, error) {
+	body, err := sig.Body(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	var want []byte
+	if h := r.Header.Get("Content-MD5"); h != "" {
+		if want, err = base64.StdEncoding.DecodeString(h); err != nil || len(want) != md5.Size {
+			return nil, errInvalidDigest
+		}
+	}
+	size := r.ContentLength
+	if sig.Streaming() {
+		size = -1
+		if h := r.Header.Get("X-Amz-Decoded-Content-Length"); h != "" {
+			if size, err = strconv.ParseInt(h, 10, 64); err != nil {
+				return nil, errInvalidArg
+			}
+		}
+	}
+
+	f, err := os.CreateTemp("", "s3-*")
+	if err != nil {
+		return nil, err
+	}
+	h := md5.New()
+	n, err := io.Copy(f, io.TeeReader(body, h))
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		err = errIncomplete
+	case err == nil && size >= 0 && n != size:
+		err = errIncomplete
+	case err == nil && want != nil && !bytes.Equal(h.Sum(nil), want):
+		err = errBadDigest
+	case err == nil:
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
+
+// the query parameters of a GET that override response headers
+var responseHeaders = map[string]string{
+	"response-content-type":        "Content-Type",
+	"response-content-language":    "Content-Language",
+	"response-expires":             "Expires",
+	"response-cache-control":       "Cache-Control",
+	"response-content-disposition": "Content-Disposition",
+	"response-content-encoding":    "Content-Encoding",
+}
+
+// getObject serves GetObject and HeadObject.
+func (g *Gateway) getObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	ctx := r.Context()
+	id := objectID(bucket, key)
+	info, err := g.store.Stat(ctx, id)
+	if err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	tag := etag(info)
+	h := w.Header()
+	h.Set("ETag", tag)
+	h.Set("Accept-Ranges", "bytes")
+	if !info.Created.IsZero() {
+		h.Set("Last-Modified", info.Created.UTC().Format(http.TimeFormat))
+	}
+	switch m := r.Header.Get("If-Match"); {
+	case m != "" && m != "*" && !matchETag(m, tag):
+		g.fail(w, r, errPrecondition)
+		return
+	case matchETag(r.Header.Get("If-None-Match"), tag):
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	setMetadata(h, info)
+	for param, header := range responseHeaders {
+		if v := r.URL.Query().Get(param); v != "" {
+			h.Set(header, v)
+		}
+	}
+
+	status, off, length := http.StatusOK, int64(0), info.Size
+	if spec := r.Header.Get("Range"); spec != "" {
+		o, l, ok, err := parseRange(spec, info.Size)
+		if err != nil {
+			h.Set("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+			g.fail(w, r, err)
+			return
+		}
+		if ok {
+			status, off, length = http.StatusPartialContent, o, l
+			h.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", off, off+length-1, info.Size))
+		}
+	}
+	h.Set("Content-Length", strconv.FormatInt(length, 10))
+	if r.Method == http.MethodHead || length == 0 {
+		w.WriteHeader(status)
+		return
+	}
+
+	lw := &lazyWriter{w: w, status: status}
+	if status == http.StatusPartialContent {
+		_, err = g.store.GetRange(ctx, id, lw, off, length)
+	} else {
+		_, err = g.store.Get(ctx, id, lw)
+	}
+	switch {
+	case err == nil && !lw.wrote:
+		w.WriteHeader(status)
+	case err != nil && !lw.wrote:
+		for k := range h {
+			if strings.HasPrefix(k, metaPrefix) || slices.Contains([]string{"Content-Length", "Content-Range", "ETag", "Last-Modified", "Accept-Ranges", "X-Amz-Tagging-Count"}, k) {
+				h.Del(k)
+			}
+		}
+		for _, k := range responseHeaders {
+			h.Del(k)
+		}
+		g.fail(w, r, err)
+	case err != nil:
+		// the status line is out: all we can do is cut the response short
+		if !errors.Is(err, context.Canceled) {
+			g.logf("S3 GET %s: %v", r.URL.Path, err)
+		}
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// lazyWriter sends the status line with the first byte of the body, so
+// that a read failing before then can still answer with an error.
+type lazyWriter struct {
+	w      http.ResponseWriter
+	status int
+	wrote  bool
+}
+
+func (l *lazyWriter) Write(p []byte) (int, error) {
+	if !l.wrote {
+		l.wrote = true
+		l.w.WriteHeader(l.status)
+	}
+	return l.w.Write(p)
+}
+
+func matchETag(header, tag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == "*" || t == tag || `"`+t+`"` == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRange reads a single-range Range header against an object of size
+// bytes. A header it cannot parse is ignored, as HTTP says (ok false); a
+// range outside the object is errRange.
+func parseRange(spec string, size int64) (off, length int64, ok bool, err error) {
+	spec, found := strings.CutPrefix(spec, "bytes=")
+	first, last, dash := strings.Cut(spec, "-")
+	if !found || !dash || strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+	if first == "" { // suffix: the last n bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, false, nil
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, false, errRange
+		}
+		n = min(n, size)
+		return size - n, n, true, nil
+	}
+	off, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || off < 0 {
+		return 0, 0, false, nil
+	}
+	end := size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < off {
+			return 0, 0, false, nil
+		}
+		end = min(end, size-1)
+	}
+	if off >= size {
+		return 0, 0, false, errRange
+	}
+	return off, end - off + 1, true, nil
+}
+
+// deleteObject succeeds whether or not the key exists, as in S3.
+func (g *Gateway) deleteObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	if err := g.store.Delete(r.Context(), objectID(bucket, key)); err != nil && !errors.Is(err, client.ErrNotFound) {
+		g.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type deleteRequest struct {
+	Quiet   bool `xml:"Quiet"`
+	Objects []struct {
+		Key string `xml:"Key"`
+	} `xml:"Object"`
+}
+
+type deleteResult struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ DeleteResult"`
+	Deleted []deletedKey
+	Errors  []deleteError `xml:"Error"`
+}
+
+type deletedKey struct {
+	XMLName xml.Name `xml:"Deleted"`
+	Key     string   `xml:"Key"`
+}
+
+type deleteError struct {
+	Key     string `xml:"Key"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// maxXML bounds the request documents the gateway parses.
+const maxXML = 4 << 20
+
+// readXML decodes the signed body of r into v.
+func readXML(r *http.Request, sig *sigv4.Request, v any) error {
+	body, err := sig.Body(io.LimitReader(r.Body, maxXML))
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if h := r.Header.Get("Content-MD5"); h != "" {
+		sum := md5.Sum(data)
+		if h != base64.StdEncoding.EncodeToString(sum[:]) {
+			return errBadDigest
+		}
+	}
+	if xml.Unmarshal(data, v) != nil {
+		return errMalformedXML
+	}
+	return nil
+}
+
+// deleteObjects serves DeleteObjects, the batch delete of `aws s3 rm
+// --recursive`.
+func (g *Gateway) deleteObjects(w http.ResponseWriter, r *http.Request, sig *sigv4.Request, bucket string) {
+	var req deleteRequest
+	if err := readXML(r, sig, &req); err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	if len(req.Objects) == 0 || len(req.Objects) > 1000 {
+		g.fail(w, r, errMalformedXML)
+		return
+	}
+	var res deleteResult
+	for _, o := range req.Objects {
+		var err error = errKeyTooLong
+		if id := objectID(bucket, o.Key); len(id) <= maxID {
+			if err = g.store.Delete(r.Context(), id); errors.Is(err, client.ErrNotFound) {
+				err = nil
+			}
+		}
+		if err != nil {
+			e := toAPI(err)
+			res.Errors = append(res.Errors, deleteError{Key: o.Key, Code: e.Code, Message: e.Message})
+		} else if !req.Quiet {
+			res.Deleted = append(res.Deleted, deletedKey{Key: o.Key})
+		}
+	}
+	writeXML(w, http.StatusOK, res)
+}
@@ -0,0 +1,326 @@
+// pkg/s3/s3.go
+// Package s3 serves the core of the S3 REST API on top of the object
+// store: PutObject, GetObject (with Range), HeadObject, DeleteObject,
+// DeleteObjects, ListObjectsV2 and multipart uploads, every request
+// authenticated with SigV4. Buckets are fixed by configuration; a key maps to the object ID
+// "s3:<bucket>:<hex key>", which keeps IDs flat (no '/' or ".." reaching
+// the servers' data directories) and sorts like the keys themselves.
+package s3
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/client"
+	"github.com/dattu/distributed_object_store/pkg/sigv4"
+)
+
+// Store is the part of *client.Client the gateway uses.
+type Store interface {
+	PutOptions() client.PutOptions
+	Put(ctx context.Context, id string, r io.Reader, opts *client.PutOptions) (*client.ObjectInfo, error)
+	Get(ctx context.Context, id string, w io.Writer) (*client.ObjectInfo, error)
+	GetRange(ctx context.Context, id string, w io.Writer, off, length int64) (*client.ObjectInfo, error)
+	Stat(ctx context.Context, id string) (*client.ObjectInfo, error)
+	List(ctx context.Context, opts client.ListOptions) ([]*client.ObjectInfo, string, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// Config configures a Gateway.
+type Config struct {
+	Buckets  []string        // the buckets served; there is no CreateBucket
+	Region   string          // reported by GetBucketLocation; default us-east-1
+	Domain   string          // if set, <bucket>.<Domain> hosts are virtual-hosted-style
+	Verifier *sigv4.Verifier // required: every request is signed
+
+	Logf func(format string, args ...any)
+}
+
+// Gateway is an http.Handler speaking S3.
+type Gateway struct {
+	store   Store
+	cfg     Config
+	buckets []string // sorted
+	started time.Time
+}
+
+// maxID is the longest object ID the servers accept: the ID is a
+// directory name in their data directories.
+const maxID = 255
+
+var bucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// New returns a gateway serving cfg.Buckets out of store.
+func New(store Store, cfg Config) (*Gateway, error) {
+	if cfg.Verifier == nil {
+		return nil, errors.New("s3: no credentials to verify requests with")
+	}
+	if len(cfg.Buckets) == 0 {
+		return nil, errors.New("s3: no buckets configured")
+	}
+	for _, b := range cfg.Buckets {
+		if !bucketName.MatchString(b) || strings.Contains(b, "..") {
+			return nil, fmt.Errorf("s3: invalid bucket name %q", b)
+		}
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	buckets := slices.Clone(cfg.Buckets)
+	slices.Sort(buckets)
+	return &Gateway{store: store, cfg: cfg, buckets: slices.Compact(buckets), started: time.Now()}, nil
+}
+
+func (g *Gateway) logf(format string, args ...any) {
+	if g.cfg.Logf != nil {
+		g.cfg.Logf(format, args...)
+	}
+}
+
+// objectID maps a key of bucket to its object ID.
+func objectID(bucket, key string) string {
+	return idPrefix(bucket) + hex.EncodeToString([]byte(key))
+}
+
+func idPrefix(bucket string) string {
+	return "s3:" + bucket + ":"
+}
+
+// keyOf inverts objectID.
+func keyOf(bucket, id string) (string, bool) {
+	h, ok := strings.CutPrefix(id, idPrefix(bucket))
+	if !ok {
+		return "", false
+	}
+	key, err := hex.DecodeString(h)
+	return string(key), err == nil
+}
+
+// ServeHTTP authenticates r and dispatches it on bucket, key and
+// subresource.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reqID := make([]byte, 8)
+	rand.Read(reqID)
+	w.Header().Set("x-amz-request-id", strings.ToUpper(hex.EncodeToString(reqID)))
+	w.Header().Set("Server", "avid-fp-s3")
+
+	sig, err := g.cfg.Verifier.Verify(r)
+	if err != nil {
+		g.fail(w, r, err)
+		return
+	}
+	bucket, key := g.route(r)
+	q := r.URL.Query()
+	switch {
+	case bucket == "":
+		if r.Method != http.MethodGet {
+			g.fail(w, r, errMethod)
+			return
+		}
+		g.listBuckets(w, r)
+		return
+	case !slices.Contains(g.buckets, bucket):
+		g.fail(w, r, errNoSuchBucket)
+		return
+	}
+
+	if key == "" {
+		switch {
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodGet && q.Has("location"):
+			loc := locationConstraint{Region: g.cfg.Region}
+			if loc.Region == "us-east-1" {
+				loc.Region = "" // S3's way of saying us-east-1
+			}
+			writeXML(w, http.StatusOK, loc)
+		case r.Method == http.MethodPost && q.Has("delete"):
+			g.deleteObjects(w, r, sig, bucket)
+		case r.Method == http.MethodGet && (q.Has("list-type") || onlyListParams(q)):
+			g.listObjects(w, r, bucket)
+		case r.Method == http.MethodGet:
+			g.fail(w, r, errNotImplemented)
+		default:
+			g.fail(w, r, errMethod)
+		}
+		return
+	}
+	if len(objectID(bucket, key)) > maxID {
+		g.fail(w, r, errKeyTooLong)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		g.createUpload(w, r, bucket, key)
+	case r.Method == http.MethodPut && q.Has("uploadId") && q.Has("partNumber"):
+		g.uploadPart(w, r, sig, bucket, key)
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		g.completeUpload(w, r, sig, bucket, key)
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		g.abortUpload(w, r, bucket, key)
+	case r.Method == http.MethodGet && q.Has("uploadId"):
+		g.listParts(w, r, bucket, key)
+	case hasSubresource(q):
+		g.fail(w, r, errNotImplemented)
+	case r.Method == http.MethodPut:
+		if r.Header.Get("X-Amz-Copy-Source") != "" {
+			g.fail(w, r, errNotImplemented)
+			return
+		}
+		g.putObject(w, r, sig, bucket, key)
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		g.getObject(w, r, bucket, key)
+	case r.Method == http.MethodDelete:
+		g.deleteObject(w, r, bucket, key)
+	default:
+		g.fail(w, r, errMethod)
+	}
+}
+
+// route splits r into bucket and key, virtual-hosted-style when its host
+// is a subdomain of Config.Domain and path-style otherwise.
+func (g *Gateway) route(r *http.Request) (bucket, key string) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if d := g.cfg.Domain; d != "" {
+		if b, ok := strings.CutSuffix(host, "."+d); ok {
+			return b, path
+		}
+	}
+	bucket, key, _ = strings.Cut(path, "/")
+	return bucket, key
+}
+
+// the listing parameters; anything else on a bucket GET is a subresource
+// we do not serve
+func onlyListParams(q map[string][]string) bool {
+	for k := range q {
+		switch {
+		case k == "prefix", k == "delimiter", k == "marker", k == "max-keys", k == "encoding-type":
+		case strings.HasPrefix(k, "X-Amz-"):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// hasSubresource reports whether an object request names one (?acl,
+// ?tagging, …); signature query parameters do not count.
+func hasSubresource(q map[string][]string) bool {
+	for k := range q {
+		if !strings.HasPrefix(k, "X-Amz-") && k != "x-id" && !strings.HasPrefix(k, "response-") {
+			return true
+		}
+	}
+	return false
+}
+
+// apiError is an S3 error response.
+type apiError struct {
+	Status  int    `xml:"-"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (e *apiError) Error() string { return e.Code + ": " + e.Message }
+
+var (
+	errNoSuchBucket   = &apiError{http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist."}
+	errNoSuchKey      = &apiError{http.StatusNotFound, "NoSuchKey", "The specified key does not exist."}
+	errNoSuchUpload   = &apiError{http.StatusNotFound, "NoSuchUpload", "The specified multipart upload does not exist."}
+	errKeyTooLong     = &apiError{http.StatusBadRequest, "KeyTooLongError", "Your key is too long."}
+	errBadDigest      = &apiError{http.StatusBadRequest, "BadDigest", "The Content-MD5 you specified did not match what was received."}
+	errInvalidDigest  = &apiError{http.StatusBadRequest, "InvalidDigest", "The Content-MD5 you specified is not valid."}
+	errIncomplete     = &apiError{http.StatusBadRequest, "IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header."}
+	errInvalidArg     = &apiError{http.StatusBadRequest, "InvalidArgument", "Invalid argument."}
+	errInvalidTag     = &apiError{http.StatusBadRequest, "InvalidTag", "The x-amz-tagging header is not a valid URL query."}
+	errInvalidPart    = &apiError{http.StatusBadRequest, "InvalidPart", "One or more of the specified parts could not be found or did not match its ETag."}
+	errPartOrder      = &apiError{http.StatusBadRequest, "InvalidPartOrder", "The list of parts was not in ascending order."}
+	errMalformedXML   = &apiError{http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema."}
+	errRange          = &apiError{http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable."}
+	errMethod         = &apiError{http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource."}
+	errNotImplemented = &apiError{http.StatusNotImplemented, "NotImplemented", "A header or query you provided implies functionality that is not implemented."}
+	errPrecondition   = &apiError{http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold."}
+)
+
+// toAPI maps an error of the store or of sigv4 onto an S3 error.
+func toAPI(err error) *apiError {
+	var e *apiError
+	switch {
+	case errors.As(err, &e):
+		return e
+	case errors.Is(err, sigv4.ErrMissing):
+		return &apiError{http.StatusForbidden, "AccessDenied", "Access Denied."}
+	case errors.Is(err, sigv4.ErrAccessKey):
+		return &apiError{http.StatusForbidden, "InvalidAccessKeyId", "The AWS access key ID you provided does not exist in our records."}
+	case errors.Is(err, sigv4.ErrSignature):
+		return &apiError{http.StatusForbidden, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided."}
+	case errors.Is(err, sigv4.ErrExpired):
+		return &apiError{http.StatusForbidden, "RequestTimeTooSkewed", "The difference between the request time and the server's time is too large, or the request has expired."}
+	case errors.Is(err, sigv4.ErrMalformed):
+		return &apiError{http.StatusBadRequest, "AuthorizationHeaderMalformed", err.Error()}
+	case errors.Is(err, sigv4.ErrPayload):
+		return &apiError{http.StatusBadRequest, "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed."}
+	case errors.Is(err, client.ErrNotFound):
+		return errNoSuchKey
+	case errors.Is(err, client.ErrPrecondition):
+		return errPrecondition
+	case errors.Is(err, client.ErrInvalid):
+		return &apiError{http.StatusBadRequest, "InvalidRequest", err.Error()}
+	case errors.Is(err, client.ErrUnavailable):
+		return &apiError{http.StatusServiceUnavailable, "ServiceUnavailable", "Not enough storage servers are reachable."}
+	case errors.Is(err, context.Canceled):
+		return &apiError{http.StatusBadRequest, "RequestTimeout", "The request was canceled."}
+	default:
+		return &apiError{http.StatusInternalServerError, "InternalError", "We encountered an internal error. Please try again."}
+	}
+}
+
+type errorBody struct {
+	XMLName xml.Name `xml:"Error"`
+	*apiError
+	Resource  string `xml:"Resource"`
+	RequestID string `xml:"RequestId"`
+}
+
+// fail answers r with err as an S3 error document; HEAD gets the status
+// alone.
+func (g *Gateway) fail(w http.ResponseWriter, r *http.Request, err error) {
+	e := toAPI(err)
+	if e.Status >= 500 {
+		g.logf("S3 %s %s: %v", r.Method, r.URL.Path, err)
+	}
+	if r.Method == http.MethodHead {
+		w.WriteHeader(e.Status)
+		return
+	}
+	writeXML(w, e.Status, errorBody{apiError: e, Resource: r.URL.Path, RequestID: w.Header().Get("x-amz-request-id")})
+}
+
+func writeXML(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(v)
+}
+
+type locationConstraint struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ LocationConstraint"`
+	Region  string   `xml:",chardata"`
+}
@@ -0,0 +1,582 @@
+// pkg/s3/s3_test.go
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dattu/distributed_object_store/pkg/client"
+	"github.com/dattu/distributed_object_store/pkg/protocol"
+	"github.com/dattu/distributed_object_store/pkg/sigv4"
+)
+
+// memStore keeps objects in memory; a Put replaces the object, as a new
+// version does on the cluster.
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	infos   map[string]*client.ObjectInfo
+}
+
+func newMemStore() *memStore {
+	return &memStore{objects: make(map[string][]byte), infos: make(map[string]*client.ObjectInfo)}
+}
+
+func (s *memStore) PutOptions() client.PutOptions { return client.PutOptions{} }
+
+func (s *memStore) Put(ctx context.Context, id string, r io.Reader, opts *client.PutOptions) (*client.ObjectInfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cur := s.infos[id]; opts != nil && (opts.IfNoneMatch && cur != nil ||
+		opts.IfMatchVersion != "" && (cur == nil || cur.VersionID() != opts.IfMatchVersion)) {
+		return nil, &client.Error{Op: "put", ID: id, Err: client.ErrPrecondition}
+	}
+	sum := sha256.Sum256(data)
+	fpcc := &protocol.FPCC{Seed: rand.Uint64(), Size: uint64(len(data)), Hashes: [][]byte{sum[:]}}
+	info := &client.ObjectInfo{ID: id, Size: int64(len(data)), Created: time.Now(), Digest: fpcc.Digest(), FPCC: fpcc}
+	if opts != nil {
+		info.Metadata, info.Tags = opts.Metadata, opts.Tags
+	}
+	s.objects[id], s.infos[id] = data, info
+	return info, nil
+}
+
+func (s *memStore) Get(ctx context.Context, id string, w io.Writer) (*client.ObjectInfo, error) {
+	return s.GetRange(ctx, id, w, 0, 0)
+}
+
+func (s *memStore) GetRange(ctx context.Context, id string, w io.Writer, off, length int64) (*client.ObjectInfo, error) {
+	s.mu.Lock()
+	data, ok := s.objects[id]
+	info := s.infos[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, &client.Error{Op: "get", ID: id, Err: client.ErrNotFound}
+	}
+	if length == 0 {
+		length = int64(len(data)) - off
+	}
+	_, err := w.Write(data[off : off+length])
+	return info, err
+}
+
+func (s *memStore) Stat(ctx context.Context, id string) (*client.ObjectInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info, ok := s.infos[id]
+	if !ok {
+		return nil, &client.Error{Op: "stat", ID: id, Err: client.ErrNotFound}
+	}
+	return info, nil
+}
+
+// List pages by 3 so that the gateway has to follow pages. Like the
+// cluster's, its entries carry no FPCC.
+func (s *memStore) List(ctx context.Context, opts client.ListOptions) ([]*client.ObjectInfo, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	for id := range s.objects {
+		if strings.HasPrefix(id, opts.Prefix) && id > opts.StartAfter {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	var next string
+	if len(ids) > 3 {
+		ids, next = ids[:3], ids[2]
+	}
+	var out []*client.ObjectInfo
+	for _, id := range ids {
+		info := *s.infos[id]
+		info.FPCC = nil
+		out = append(out, &info)
+	}
+	return out, next, nil
+}
+
+func (s *memStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.objects[id]; !ok {
+		return &client.Error{Op: "delete", ID: id, Err: client.ErrNotFound}
+	}
+	delete(s.objects, id)
+	delete(s.infos, id)
+	return nil
+}
+
+func (s *memStore) ids() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Sorted(func(yield func(string) bool) {
+		for id := range s.objects {
+			if !yield(id) {
+				return
+			}
+		}
+	})
+}
+
+const (
+	testKey    = "AKIDTEST"
+	testSecret = "secret/for+tests"
+)
+
+type gateway struct {
+	t     *testing.T
+	store *memStore
+	srv   *httptest.Server
+}
+
+func newGateway(t *testing.T) *gateway {
+	t.Helper()
+	store := newMemStore()
+	g, err := New(store, Config{
+		Buckets:  []string{"photos", "logs"},
+		Verifier: &sigv4.Verifier{Creds: sigv4.Credentials{testKey: testSecret}, Region: "us-east-1"},
+		Logf:     t.Logf,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(g)
+	t.Cleanup(srv.Close)
+	return &gateway{t, store, srv}
+}
+
+// sign signs r with the test credentials over a hashed body.
+func sign(r *http.Request, body []byte, secret string) {
+	now := time.Now().UTC()
+	day := now.Format("20060102")
+	sum := sha256.Sum256(body)
+	payload := hex.EncodeToString(sum[:])
+	r.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	r.Header.Set("X-Amz-Content-Sha256", payload)
+	headers := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	canonical := sigv4.CanonicalRequest(r, headers, payload, nil)
+	scope := day + "/us-east-1/s3/aws4_request"
+	c := sha256.Sum256([]byte(canonical))
+	sts := "AWS4-HMAC-SHA256\n" + now.Format("20060102T150405Z") + "\n" + scope + "\n" + hex.EncodeToString(c[:])
+	mac := hmacSum(sigv4.SigningKey(secret, day, "us-east-1", "s3"), sts)
+	r.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+testKey+"/"+scope+
+		",SignedHeaders="+strings.Join(headers, ";")+",Signature="+mac)
+}
+
+func hmacSum(key []byte, data string) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type resp struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+func (g *gateway) do(method, path string, body []byte, header ...string) resp {
+	g.t.Helper()
+	r, err := http.NewRequest(method, g.srv.URL+path, bytes.NewReader(body))
+	if err != nil {
+		g.t.Fatal(err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	sign(r, body, testSecret)
+	res, err := http.DefaultClient.Do(r)
+	if err != nil {
+		g.t.Fatal(err)
+	}
+	defer res.Body.Close()
+	data, _ := io.ReadAll(res.Body)
+	return resp{res.StatusCode, res.Header, data}
+}
+
+func code(body []byte) string {
+	var e struct{ Code string }
+	xml.Unmarshal(body, &e)
+	return e.Code
+}
+
+func TestObjects(t *testing.T) {
+	g := newGateway(t)
+	data := []byte("0123456789abcdefghij")
+
+	if r := g.do("PUT", "/photos/2024/a b+c.txt", data); r.status != 200 || r.header.Get("ETag") == "" {
+		t.Fatalf("put: %d %s", r.status, r.body)
+	}
+	r := g.do("GET", "/photos/2024/a%20b+c.txt", nil)
+	if r.status != 200 || !bytes.Equal(r.body, data) {
+		t.Fatalf("get: %d %q", r.status, r.body)
+	}
+	tag := r.header.Get("ETag")
+	if h := g.do("HEAD", "/photos/2024/a%20b+c.txt", nil); h.status != 200 || h.header.Get("Content-Length") != "20" || h.header.Get("ETag") != tag {
+		t.Errorf("head: %d %v", h.status, h.header)
+	}
+	if r := g.do("GET", "/photos/2024/a%20b+c.txt", nil, "If-None-Match", tag); r.status != http.StatusNotModified {
+		t.Errorf("If-None-Match: %d", r.status)
+	}
+
+	for spec, want := range map[string]string{
+		"bytes=2-5": "2345", "bytes=15-": "fghij", "bytes=-3": "hij", "bytes=18-99": "ij",
+	} {
+		r := g.do("GET", "/photos/2024/a%20b+c.txt", nil, "Range", spec)
+		if r.status != http.StatusPartialContent || string(r.body) != want {
+			t.Errorf("%s: %d %q", spec, r.status, r.body)
+		}
+	}
+	if r := g.do("GET", "/photos/2024/a%20b+c.txt", nil, "Range", "bytes=20-"); r.status != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("range past the end: %d", r.status)
+	}
+	if r := g.do("GET", "/photos/2024/a%20b+c.txt", nil, "Range", "bytes=5-2"); r.status != 200 {
+		t.Errorf("unparseable range not ignored: %d", r.status)
+	}
+
+	// keys with '/' and ".." stay flat IDs
+	g.do("PUT", "/photos/../../etc", data)
+	for _, id := range g.store.ids() {
+		if strings.ContainsAny(id, "/.") {
+			t.Errorf("object ID %q", id)
+		}
+	}
+
+	// S3 overwrites; a bad payload leaves the old object alone
+	if r := g.do("PUT", "/photos/2024/a%20b+c.txt", []byte("v2")); r.status != 200 || r.header.Get("ETag") == tag {
+		t.Fatalf("overwrite: %d %v", r.status, r.header)
+	}
+	sum := md5.Sum([]byte("other"))
+	if r := g.do("PUT", "/photos/2024/a%20b+c.txt", []byte("v3"), "Content-MD5", base64.StdEncoding.EncodeToString(sum[:])); code(r.body) != "BadDigest" {
+		t.Errorf("Content-MD5 mismatch: %d %s", r.status, r.body)
+	}
+	if r := g.do("GET", "/photos/2024/a%20b+c.txt", nil); string(r.body) != "v2" {
+		t.Errorf("after rejected put: %q", r.body)
+	}
+
+	// conditional writes
+	if r := g.do("PUT", "/photos/2024/a%20b+c.txt", []byte("v3"), "If-None-Match", "*"); code(r.body) != "PreconditionFailed" {
+		t.Errorf("If-None-Match over an object: %d %s", r.status, r.body)
+	}
+	if r := g.do("PUT", "/photos/2024/a%20b+c.txt", []byte("v3"), "If-Match", tag); r.status != http.StatusPreconditionFailed {
+		t.Errorf("If-Match of an old ETag: %d %s", r.status, r.body)
+	}
+	v2 := g.do("HEAD", "/photos/2024/a%20b+c.txt", nil).header.Get("ETag")
+	if r := g.do("PUT", "/photos/2024/a%20b+c.txt", []byte("v3"), "If-Match", v2); r.status != 200 {
+		t.Errorf("If-Match of the current ETag: %d %s", r.status, r.body)
+	}
+	if r := g.do("PUT", "/photos/new", []byte("x"), "If-None-Match", "*"); r.status != 200 {
+		t.Errorf("If-None-Match of a new key: %d %s", r.status, r.body)
+	}
+	if r := g.do("PUT", "/photos/new", []byte("x"), "If-None-Match", v2); code(r.body) != "NotImplemented" {
+		t.Errorf("If-None-Match with an ETag: %d %s", r.status, r.body)
+	}
+
+	// metadata and tags
+	if r := g.do("PUT", "/photos/notes", []byte("hi"), "Content-Type", "text/plain", "Cache-Control", "no-cache",
+		"X-Amz-Meta-Owner", "ci", "X-Amz-Tagging", "env=prod&team=a"); r.status != 200 {
+		t.Fatalf("put with metadata: %d %s", r.status, r.body)
+	}
+	h := g.do("HEAD", "/photos/notes", nil).header
+	if h.Get("Content-Type") != "text/plain" || h.Get("Cache-Control") != "no-cache" || h.Get("X-Amz-Meta-Owner") != "ci" || h.Get("X-Amz-Tagging-Count") != "2" {
+		t.Errorf("head of an object with metadata: %v", h)
+	}
+	if h := g.do("GET", "/photos/notes?response-content-type=text/html", nil).header; h.Get("Content-Type") != "text/html" {
+		t.Errorf("response-content-type: %v", h)
+	}
+	if r := g.do("PUT", "/photos/notes", []byte("hi"), "X-Amz-Tagging", "a=%zz"); code(r.body) != "InvalidTag" {
+		t.Errorf("malformed tagging: %d %s", r.status, r.body)
+	}
+
+	if r := g.do("PUT", "/photos/empty", nil); r.status != 200 {
+		t.Errorf("empty put: %d", r.status)
+	}
+	if r := g.do("GET", "/photos/empty", nil); r.status != 200 || len(r.body) != 0 {
+		t.Errorf("empty get: %d %q", r.status, r.body)
+	}
+
+	if r := g.do("DELETE", "/photos/2024/a%20b+c.txt", nil); r.status != http.StatusNoContent {
+		t.Errorf("delete: %d", r.status)
+	}
+	if r := g.do("DELETE", "/photos/2024/a%20b+c.txt", nil); r.status != http.StatusNoContent {
+		t.Errorf("second delete: %d", r.status)
+	}
+	if r := g.do("GET", "/photos/2024/a%20b+c.txt", nil); code(r.body) != "NoSuchKey" {
+		t.Errorf("get after delete: %d %s", r.status, r.body)
+	}
+	if r := g.do("HEAD", "/photos/2024/a%20b+c.txt", nil); r.status != 404 {
+		t.Errorf("head after delete: %d", r.status)
+	}
+	if r := g.do("GET", "/nope/x", nil); code(r.body) != "NoSuchBucket" {
+		t.Errorf("unknown bucket: %d %s", r.status, r.body)
+	}
+	if r := g.do("PUT", "/photos/"+strings.Repeat("k", 200), data); code(r.body) != "KeyTooLongError" {
+		t.Errorf("long key: %d %s", r.status, r.body)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	g := newGateway(t)
+	get := func(mod func(*http.Request)) string {
+		r, _ := http.NewRequest("PUT", g.srv.URL+"/photos/k", strings.NewReader("data"))
+		sign(r, []byte("data"), testSecret)
+		mod(r)
+		res, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return code(body)
+	}
+	cases := map[string]func(*http.Request){
+		"AccessDenied":              func(r *http.Request) { r.Header.Del("Authorization") },
+		"SignatureDoesNotMatch":     func(r *http.Request) { sign(r, []byte("data"), "wrong") },
+		"XAmzContentSHA256Mismatch": func(r *http.Request) { r.Body = io.NopCloser(strings.NewReader("dat4")) },
+		"RequestTimeTooSkewed": func(r *http.Request) {
+			r.Header.Set("X-Amz-Date", time.Now().Add(-time.Hour).UTC().Format("20060102T150405Z"))
+		},
+	}
+	for want, mod := range cases {
+		if got := get(mod); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+	if ids := g.store.ids(); len(ids) != 0 {
+		t.Errorf("rejected requests stored %v", ids)
+	}
+}
+
+type listResult struct {
+	Keys                  []string `xml:"Contents>Key"`
+	ETags                 []string `xml:"Contents>ETag"`
+	Prefixes              []string `xml:"CommonPrefixes>Prefix"`
+	IsTruncated           bool
+	KeyCount              int
+	NextContinuationToken string
+	NextMarker            string
+}
+
+func TestList(t *testing.T) {
+	g := newGateway(t)
+	keys := []string{"a", "b/1", "b/2", "b/3", "c/x/1", "c/y", "d e", "e"}
+	for _, k := range keys {
+		g.do("PUT", "/photos/"+url.PathEscape(k), []byte(k))
+	}
+	g.do("PUT", "/logs/other", nil)
+
+	list := func(query string) listResult {
+		t.Helper()
+		r := g.do("GET", "/photos?"+query, nil)
+		if r.status != 200 {
+			t.Fatalf("%s: %d %s", query, r.status, r.body)
+		}
+		var l listResult
+		if err := xml.Unmarshal(r.body, &l); err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+	l := list("list-type=2")
+	if !slices.Equal(l.Keys, keys) || l.IsTruncated || l.KeyCount != len(keys) {
+		t.Errorf("all: %+v", l)
+	}
+	for i, k := range l.Keys {
+		if tag := g.do("HEAD", "/photos/"+url.PathEscape(k), nil).header.Get("ETag"); tag != l.ETags[i] {
+			t.Errorf("%s: listed ETag %s, HEAD says %s", k, l.ETags[i], tag)
+		}
+	}
+	if l := list("list-type=2&prefix=b%2F"); !slices.Equal(l.Keys, keys[1:4]) {
+		t.Errorf("prefix: %+v", l)
+	}
+	if l := list("list-type=2&start-after=c"); !slices.Equal(l.Keys, keys[4:]) {
+		t.Errorf("start-after: %+v", l)
+	}
+	if l := list("list-type=2&delimiter=%2F"); !slices.Equal(l.Keys, []string{"a", "d e", "e"}) || !slices.Equal(l.Prefixes, []string{"b/", "c/"}) {
+		t.Errorf("delimiter: %+v", l)
+	}
+	if l := list("list-type=2&delimiter=%2F&prefix=c%2F"); !slices.Equal(l.Keys, []string{"c/y"}) || !slices.Equal(l.Prefixes, []string{"c/x/"}) {
+		t.Errorf("delimiter under a prefix: %+v", l)
+	}
+	if l := list("list-type=2&encoding-type=url&prefix=d"); !slices.Equal(l.Keys, []string{"d+e"}) {
+		t.Errorf("url encoding: %+v", l)
+	}
+
+	// page through with every page size, continuation tokens and markers
+	for _, delim := range []string{"", "%2F"} {
+		want := list("list-type=2&delimiter=" + delim)
+		all := append(want.Keys, want.Prefixes...)
+		for size := 1; size <= len(all); size++ {
+			var got []string
+			query := fmt.Sprintf("list-type=2&delimiter=%s&max-keys=%d", delim, size)
+			for token := ""; ; {
+				l := list(query + token)
+				got = append(append(got, l.Keys...), l.Prefixes...)
+				if !l.IsTruncated {
+					break
+				}
+				token = "&continuation-token=" + url.QueryEscape(l.NextContinuationToken)
+			}
+			var v1 []string
+			for marker := ""; ; {
+				l := list(fmt.Sprintf("delimiter=%s&max-keys=%d&marker=%s", delim, size, marker))
+				v1 = append(append(v1, l.Keys...), l.Prefixes...)
+				if !l.IsTruncated {
+					break
+				}
+				marker = url.QueryEscape(l.NextMarker)
+			}
+			slices.Sort(got)
+			slices.Sort(v1)
+			slices.Sort(all)
+			if !slices.Equal(got, all) || !slices.Equal(v1, all) {
+				t.Errorf("delimiter %q, pages of %d: V2 %q, V1 %q, want %q", delim, size, got, v1, all)
+			}
+		}
+	}
+	if r := g.do("GET", "/photos?list-type=2&continuation-token=bm9wZQ==", nil); code(r.body) != "InvalidArgument" {
+		t.Errorf("bad token: %s", r.body)
+	}
+
+	r := g.do("POST", "/photos?delete", []byte(`<Delete><Object><Key>a</Key></Object><Object><Key>b/1</Key></Object><Object><Key>zz</Key></Object></Delete>`))
+	var res struct {
+		Deleted []string `xml:"Deleted>Key"`
+	}
+	if xml.Unmarshal(r.body, &res); r.status != 200 || len(res.Deleted) != 3 {
+		t.Errorf("DeleteObjects: %d %s", r.status, r.body)
+	}
+	if l := list("list-type=2&max-keys=2"); !slices.Equal(l.Keys, []string{"b/2", "b/3"}) {
+		t.Errorf("after DeleteObjects: %+v", l)
+	}
+
+	r = g.do("GET", "/", nil)
+	if r.status != 200 || !bytes.Contains(r.body, []byte("<Name>logs</Name>")) || !bytes.Contains(r.body, []byte("<Name>photos</Name>")) {
+		t.Errorf("ListBuckets: %d %s", r.status, r.body)
+	}
+}
+
+func TestMultipart(t *testing.T) {
+	g := newGateway(t)
+	r := g.do("POST", "/photos/big?uploads", nil, "Content-Type", "image/png", "X-Amz-Meta-Camera", "x100")
+	var init struct{ UploadId string }
+	if xml.Unmarshal(r.body, &init); r.status != 200 || init.UploadId == "" {
+		t.Fatalf("create: %d %s", r.status, r.body)
+	}
+	u := init.UploadId
+	parts := [][]byte{bytes.Repeat([]byte("1"), 1000), bytes.Repeat([]byte("2"), 700), []byte("3")}
+	var tags []string
+	for i, p := range parts {
+		r := g.do("PUT", fmt.Sprintf("/photos/big?partNumber=%d&uploadId=%s", i+1, u), p)
+		if r.status != 200 {
+			t.Fatalf("part %d: %d %s", i+1, r.status, r.body)
+		}
+		tags = append(tags, r.header.Get("ETag"))
+	}
+	// a part uploaded again replaces the first attempt
+	parts[1] = bytes.Repeat([]byte("x"), 700)
+	tags[1] = g.do("PUT", "/photos/big?partNumber=2&uploadId="+u, parts[1]).header.Get("ETag")
+
+	if r := g.do("PUT", "/photos/other?partNumber=1&uploadId="+u, parts[0]); code(r.body) != "NoSuchUpload" {
+		t.Errorf("part for another key: %d %s", r.status, r.body)
+	}
+	r = g.do("GET", "/photos/big?uploadId="+u, nil)
+	var lp struct {
+		Parts []struct{ PartNumber, Size int } `xml:"Part"`
+	}
+	if xml.Unmarshal(r.body, &lp); len(lp.Parts) != 3 || lp.Parts[1].Size != 700 {
+		t.Errorf("ListParts: %s", r.body)
+	}
+
+	complete := func(nums ...int) resp {
+		var b strings.Builder
+		b.WriteString("<CompleteMultipartUpload>")
+		for _, n := range nums {
+			fmt.Fprintf(&b, "<Part><PartNumber>%d</PartNumber><ETag>%s</ETag></Part>", n, tags[n-1])
+		}
+		b.WriteString("</CompleteMultipartUpload>")
+		return g.do("POST", "/photos/big?uploadId="+u, []byte(b.String()))
+	}
+	if r := complete(2, 1); code(r.body) != "InvalidPartOrder" {
+		t.Errorf("unordered parts: %s", r.body)
+	}
+	tags[0], tags[2] = tags[2], tags[0]
+	if r := complete(1, 3); code(r.body) != "InvalidPart" {
+		t.Errorf("wrong ETag: %s", r.body)
+	}
+	tags[0], tags[2] = tags[2], tags[0]
+
+	r = complete(1, 2, 3)
+	var done struct{ ETag string }
+	if xml.Unmarshal(bytes.TrimSpace(r.body[len(xml.Header):]), &done); r.status != 200 || done.ETag == "" {
+		t.Fatalf("complete: %d %s", r.status, r.body)
+	}
+	r = g.do("GET", "/photos/big", nil)
+	if !bytes.Equal(r.body, bytes.Join(parts, nil)) || r.header.Get("ETag") != done.ETag {
+		t.Errorf("assembled object: %d bytes, ETag %s vs %s", len(r.body), r.header.Get("ETag"), done.ETag)
+	}
+	if r.header.Get("Content-Type") != "image/png" || r.header.Get("X-Amz-Meta-Camera") != "x100" {
+		t.Errorf("assembled object lost the upload's metadata: %v", r.header)
+	}
+	if ids := g.store.ids(); len(ids) != 1 {
+		t.Errorf("upload left %v", ids)
+	}
+	if r := complete(1, 2, 3); code(r.body) != "NoSuchUpload" {
+		t.Errorf("completed twice: %s", r.body)
+	}
+
+	r = g.do("POST", "/photos/big?uploads", nil)
+	xml.Unmarshal(r.body, &init)
+	g.do("PUT", "/photos/big?partNumber=1&uploadId="+init.UploadId, parts[0])
+	if r := g.do("DELETE", "/photos/big?uploadId="+init.UploadId, nil); r.status != http.StatusNoContent {
+		t.Errorf("abort: %d %s", r.status, r.body)
+	}
+	if ids := g.store.ids(); len(ids) != 1 {
+		t.Errorf("abort left %v", ids)
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	for _, c := range []struct {
+		spec        string
+		size        int64
+		off, length int64
+		ok, err     bool
+	}{
+		{"bytes=0-0", 10, 0, 1, true, false},
+		{"bytes=0-", 10, 0, 10, true, false},
+		{"bytes=9-20", 10, 9, 1, true, false},
+		{"bytes=-20", 10, 0, 10, true, false},
+		{"bytes=-0", 10, 0, 0, false, true},
+		{"bytes=10-", 10, 0, 0, false, true},
+		{"bytes=0-0", 0, 0, 0, false, true},
+		{"bytes=0-1,4-5", 10, 0, 0, false, false},
+		{"items=0-1", 10, 0, 0, false, false},
+		{"bytes=x-1", 10, 0, 0, false, false},
+	} {
+		off, length, ok, err := parseRange(c.spec, c.size)
+		if off != c.off || length != c.length || ok != c.ok || (err != nil) != c.err {
+			t.Errorf("%s of %d: %d+%d %v %v", c.spec, c.size, off, length, ok, err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// pkg/sigv4/body.go – checking the payload of a verified request.
+// A body is either hashed as a whole (its SHA-256 is signed in the
+// headers), unsigned, or sent aws-chunked: each chunk carries a signature
+// chained from the request's, and the trailer variants end with checksum
+// headers after the last chunk.
+
+package sigv4
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrPayload means the body does not match the hash or chunk signatures
+// it was signed with.
+var ErrPayload = errors.New("payload does not match its signature")
+
+// maxChunk bounds the chunk a streaming upload may send; a chunk is held
+// in memory until its signature checks.
+const maxChunk = 16 << 20
+
+// Body returns the content of a request body signed as req says: read to
+// EOF, it fails with ErrPayload instead of returning bytes that were not
+// signed.
+func (req *Request) Body(body io.Reader) (io.Reader, error) {
+	switch p := req.PayloadHash; p {
+	case UnsignedPayload:
+		return body, nil
+	case StreamingPayload, StreamingPayloadTrailer:
+		return &chunkedReader{br: bufio.NewReader(body), req: req, prev: req.signature}, nil
+	case StreamingUnsignedTrailer:
+		return &chunkedReader{br: bufio.NewReader(body)}, nil
+	default:
+		want, err := hex.DecodeString(p)
+		if err != nil || len(want) != sha256.Size {
+			return nil, fmt.Errorf("%w: x-amz-content-sha256 %q", ErrMalformed, p)
+		}
+		return &hashedReader{r: body, h: sha256.New(), want: want}, nil
+	}
+}
+
+// Streaming reports whether the body is aws-chunked.
+func (req *Request) Streaming() bool {
+	return strings.HasPrefix(req.PayloadHash, "STREAMING-")
+}
+
+type hashedReader struct {
+	r    io.Reader
+	h    hash.Hash
+	want []byte
+}
+
+func (r *hashedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.h.Write(p[:n])
+	if err == io.EOF && !bytes.Equal(r.h.Sum(nil), r.want) {
+		return n, ErrPayload
+	}
+	return n, err
+}
+
+// chunkedReader decodes an aws-chunked body, checking each chunk's
+// signature when req is set.
+type chunkedReader struct {
+	br   *bufio.Reader
+	req  *Request // nil: unsigned chunks
+	prev string   // signature of the previous chunk
+	cur  []byte
+	err  error
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	for len(r.cur) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.cur, r.err = r.next()
+	}
+	n := copy(p, r.cur)
+	r.cur = r.cur[n:]
+	return n, nil
+}
+
+// next reads one chunk; io.EOF after the last one and its trailers.
+func (r *chunkedReader) next() ([]byte, error) {
+	line, err := r.line()
+	if err != nil {
+		return nil, err
+	}
+	sizeHex, ext, _ := strings.Cut(line, ";")
+	size, err := strconv.ParseInt(strings.TrimSpace(sizeHex), 16, 64)
+	if err != nil || size < 0 || size > maxChunk {
+		return nil, fmt.Errorf("%w: chunk header %q", ErrPayload, line)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.br, data); err != nil {
+		return nil, fmt.Errorf("%w: short chunk", ErrPayload)
+	}
+	if r.req != nil {
+		sig, ok := strings.CutPrefix(ext, "chunk-signature=")
+		if !ok || !hmac.Equal([]byte(sig), []byte(r.chunkSignature(data))) {
+			return nil, ErrPayload
+		}
+		r.prev = sig
+	}
+	if size > 0 {
+		if end, err := r.line(); err != nil || end != "" {
+			return nil, fmt.Errorf("%w: chunk not terminated", ErrPayload)
+		}
+		return data, nil
+	}
+	// trailing headers, e.g. x-amz-checksum-crc32, up to an empty line
+	for {
+		l, err := r.line()
+		if err != nil {
+			return nil, fmt.Errorf("%w: trailer: %v", ErrPayload, err)
+		}
+		if l == "" {
+			return nil, io.EOF
+		}
+	}
+}
+
+func (r *chunkedReader) chunkSignature(data []byte) string {
+	sum := sha256.Sum256(data)
+	sts := "AWS4-HMAC-SHA256-PAYLOAD\n" + r.req.Date.Format(timeFmt) + "\n" + r.req.scope + "\n" +
+		r.prev + "\n" + emptySHA256 + "\n" + hex.EncodeToString(sum[:])
+	return hex.EncodeToString(hmacSHA256(r.req.key, sts))
+}
+
+// line reads up to CRLF.
+func (r *chunkedReader) line() (string, error) {
+	l, err := r.br.ReadSlice('\n')
+	switch err {
+	case nil:
+	case bufio.ErrBufferFull:
+		return "", fmt.Errorf("%w: line too long", ErrPayload)
+	case io.EOF:
+		return "", io.ErrUnexpectedEOF
+	default:
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(l), "\n"), "\r"), nil
+}
@@ -0,0 +1,316 @@
+// pkg/sigv4/sigv4.go
+// Package sigv4 verifies AWS Signature Version 4 on incoming S3 requests:
+// the Authorization header, presigned URLs and the per-chunk signatures
+// of streaming uploads. Secrets come from a local credentials file in the
+// format of ~/.aws/credentials.
+package sigv4
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	algorithm = "AWS4-HMAC-SHA256"
+	timeFmt   = "20060102T150405Z"
+	// MaxSkew is how far a request's date may be from the gateway's clock.
+	MaxSkew = 15 * time.Minute
+
+	// x-amz-content-sha256 values that are not a hash of the body
+	UnsignedPayload          = "UNSIGNED-PAYLOAD"
+	StreamingPayload         = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	StreamingPayloadTrailer  = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER"
+	StreamingUnsignedTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+
+	emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+var (
+	// ErrMissing means the request carries no signature at all.
+	ErrMissing = errors.New("request is not signed")
+	// ErrAccessKey means the access key is not in the credentials file.
+	ErrAccessKey = errors.New("unknown access key")
+	// ErrSignature means the signature does not match the request.
+	ErrSignature = errors.New("signature does not match")
+	// ErrMalformed means the signature fields cannot be parsed.
+	ErrMalformed = errors.New("malformed signature")
+	// ErrExpired means the request is outside MaxSkew or its presigned
+	// lifetime.
+	ErrExpired = errors.New("request has expired")
+)
+
+// Credentials maps access key IDs to secret keys.
+type Credentials map[string]string
+
+// LoadCredentials reads an AWS-style credentials file: INI sections each
+// holding aws_access_key_id and aws_secret_access_key.
+func LoadCredentials(path string) (Credentials, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseCredentials(f)
+}
+
+// ParseCredentials reads the credentials file format from r.
+func ParseCredentials(r io.Reader) (Credentials, error) {
+	creds := make(Credentials)
+	var id, secret string
+	flush := func() error {
+		switch {
+		case id == "" && secret == "":
+		case id == "" || secret == "":
+			return fmt.Errorf("section with only one of aws_access_key_id and aws_secret_access_key")
+		default:
+			creds[id] = secret
+		}
+		id, secret = "", ""
+		return nil
+	}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[':
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		default:
+			k, v, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("bad line %q", line)
+			}
+			switch strings.TrimSpace(k) {
+			case "aws_access_key_id":
+				id = strings.TrimSpace(v)
+			case "aws_secret_access_key":
+				secret = strings.TrimSpace(v)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if len(creds) == 0 {
+		return nil, errors.New("no credentials")
+	}
+	return creds, nil
+}
+
+// Request is what a verified request was signed with.
+type Request struct {
+	AccessKey   string
+	Region      string
+	Date        time.Time
+	PayloadHash string // x-amz-content-sha256, or UnsignedPayload
+
+	scope     string
+	key       []byte // signing key
+	signature string
+}
+
+// Verifier checks requests against a set of credentials.
+type Verifier struct {
+	Creds  Credentials
+	Region string // if set, the only region accepted in a scope
+	Now    func() time.Time
+}
+
+func (v *Verifier) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
+
+// Verify checks the signature of r, from its Authorization header or its
+// presigned query. It does not read the body: a hashed payload is checked
+// by Body as it is read.
+func (v *Verifier) Verify(r *http.Request) (*Request, error) {
+	if r.URL.Query().Get("X-Amz-Algorithm") != "" {
+		return v.verifyPresigned(r)
+	}
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return nil, ErrMissing
+	}
+	rest, ok := strings.CutPrefix(auth, algorithm+" ")
+	if !ok {
+		return nil, fmt.Errorf("%w: algorithm", ErrMalformed)
+	}
+	fields := make(map[string]string)
+	for _, kv := range strings.Split(rest, ",") {
+		k, val, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrMalformed, kv)
+		}
+		fields[k] = val
+	}
+	date := r.Header.Get("X-Amz-Date")
+	if date == "" {
+		date = r.Header.Get("Date")
+	}
+	t, err := time.Parse(timeFmt, date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date %q", ErrMalformed, date)
+	}
+	if d := v.now().Sub(t); d > MaxSkew || d < -MaxSkew {
+		return nil, ErrExpired
+	}
+	payload := r.Header.Get("X-Amz-Content-Sha256")
+	if payload == "" {
+		return nil, fmt.Errorf("%w: no x-amz-content-sha256", ErrMalformed)
+	}
+	req, err := v.check(r, fields["Credential"], fields["SignedHeaders"], fields["Signature"], t, payload, nil)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (v *Verifier) verifyPresigned(r *http.Request) (*Request, error) {
+	q := r.URL.Query()
+	if q.Get("X-Amz-Algorithm") != algorithm {
+		return nil, fmt.Errorf("%w: algorithm", ErrMalformed)
+	}
+	t, err := time.Parse(timeFmt, q.Get("X-Amz-Date"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: date", ErrMalformed)
+	}
+	expires, err := strconv.Atoi(q.Get("X-Amz-Expires"))
+	if err != nil || expires < 0 || expires > 7*24*3600 {
+		return nil, fmt.Errorf("%w: expires", ErrMalformed)
+	}
+	now := v.now()
+	if now.Before(t.Add(-MaxSkew)) || now.After(t.Add(time.Duration(expires)*time.Second)) {
+		return nil, ErrExpired
+	}
+	return v.check(r, q.Get("X-Amz-Credential"), q.Get("X-Amz-SignedHeaders"), q.Get("X-Amz-Signature"), t, UnsignedPayload, []string{"X-Amz-Signature"})
+}
+
+// check recomputes the signature of r from its parts.
+func (v *Verifier) check(r *http.Request, credential, signed, signature string, t time.Time, payload string, skipQuery []string) (*Request, error) {
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[4] != "aws4_request" || parts[3] != "s3" || signed == "" || signature == "" {
+		return nil, fmt.Errorf("%w: credential %q", ErrMalformed, credential)
+	}
+	if parts[1] != t.Format("20060102") {
+		return nil, fmt.Errorf("%w: scope date", ErrMalformed)
+	}
+	if v.Region != "" && parts[2] != v.Region {
+		return nil, fmt.Errorf("%w: region %q, want %q", ErrMalformed, parts[2], v.Region)
+	}
+	secret, ok := v.Creds[parts[0]]
+	if !ok {
+		return nil, ErrAccessKey
+	}
+	req := &Request{
+		AccessKey:   parts[0],
+		Region:      parts[2],
+		Date:        t,
+		PayloadHash: payload,
+		scope:       strings.Join(parts[1:], "/"),
+		key:         SigningKey(secret, parts[1], parts[2], parts[3]),
+	}
+	headers := strings.Split(signed, ";")
+	if !slices.Contains(headers, "host") {
+		return nil, fmt.Errorf("%w: host is not signed", ErrMalformed)
+	}
+	canonical := CanonicalRequest(r, headers, payload, skipQuery)
+	sts := algorithm + "\n" + t.Format(timeFmt) + "\n" + req.scope + "\n" + hexSHA256([]byte(canonical))
+	req.signature = hex.EncodeToString(hmacSHA256(req.key, sts))
+	if !hmac.Equal([]byte(req.signature), []byte(strings.ToLower(signature))) {
+		return nil, ErrSignature
+	}
+	return req, nil
+}
+
+// SigningKey derives the key that signs requests of one day, region and
+// service.
+func SigningKey(secret, day, region, service string) []byte {
+	k := hmacSHA256([]byte("AWS4"+secret), day)
+	k = hmacSHA256(k, region)
+	k = hmacSHA256(k, service)
+	return hmacSHA256(k, "aws4_request")
+}
+
+// CanonicalRequest builds the canonical form of r that SigV4 signs, over
+// the headers named and without the query parameters in skip.
+func CanonicalRequest(r *http.Request, headers []string, payload string, skip []string) string {
+	var b strings.Builder
+	b.WriteString(r.Method + "\n")
+	b.WriteString(uriEncode(r.URL.Path, false) + "\n")
+
+	var query []string
+	for k, vals := range r.URL.Query() {
+		if slices.Contains(skip, k) {
+			continue
+		}
+		for _, val := range vals {
+			query = append(query, uriEncode(k, true)+"="+uriEncode(val, true))
+		}
+	}
+	slices.Sort(query)
+	b.WriteString(strings.Join(query, "&") + "\n")
+
+	for _, h := range headers {
+		var val string
+		switch {
+		case h == "host":
+			val = r.Host
+		case h == "content-length" && r.Header.Get(h) == "":
+			val = strconv.FormatInt(r.ContentLength, 10)
+		default:
+			val = strings.Join(r.Header.Values(h), ",")
+		}
+		b.WriteString(h + ":" + strings.Join(strings.Fields(val), " ") + "\n")
+	}
+	b.WriteString("\n" + strings.Join(headers, ";") + "\n")
+	b.WriteString(payload)
+	return b.String()
+}
+
+// uriEncode percent-encodes every byte but the unreserved ones – and '/'
+// in a path.
+func uriEncode(s string, slash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !slash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	s := sha256.Sum256(data)
+	return hex.EncodeToString(s[:])
+}
@@ -2,14 +2,40 @@
 package storage
 
 import (
+	"bytes"
+	"io"
 	"os"
+	"path/filepath"
 )
 
-// AtomicWrite writes data to tmpPath + ".tmp" then renames, guaranteeing
-// that either the file is fully written or not present at all.
+// AtomicWrite writes data to a temporary file next to path then renames,
+// guaranteeing that either the file is fully written or not present at all.
 func AtomicWrite(path string, data []byte, perm os.FileMode) error {
-	tmp := path + ".tmp"
-	if err := os.WriteFile(tmp, data, perm); err != nil {
+	return AtomicWriteFrom(path, bytes.NewReader(data), perm, nil)
+}
+
+// AtomicWriteFrom streams r into a temporary file next to path and renames
+// it into place only if check, called once the last byte is on disk,
+// returns nil. Every call gets a temporary file of its own, so concurrent
+// writers of one path never clobber each other's; the last rename wins.
+func AtomicWriteFrom(path string, r io.Reader, perm os.FileMode, check func(n int64) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	n, err := io.Copy(f, r)
+	if err == nil {
+		err = f.Chmod(perm)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && check != nil {
+		err = check(n)
+	}
+	if err != nil {
+		os.Remove(tmp)
 		return err
 	}
 	return os.Rename(tmp, path)
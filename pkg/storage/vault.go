@@ -0,0 +1,450 @@
+// pkg/storage/vault.go
+// Vault encrypts what a node keeps at rest – fragment files and sensitive
+// bolt values – under a node key read from a local key file. Every file gets
+// its own random file key, stored in a header wrapped by the node key, and
+// its body is sealed with AES-256-GCM in fixed segments so range reads can
+// open just the segments they touch. Rotating the node key therefore only
+// rewrites headers. Callers always see plaintext: hashes and fingerprints
+// are computed exactly as without a vault.
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	vaultMagic   = "AVIDENC1"
+	keyIDSize    = 8
+	nonceSize    = 12
+	tagSize      = 16
+	fileKeySize  = 32
+	headerSize   = len(vaultMagic) + keyIDSize + nonceSize + fileKeySize + tagSize
+	vaultSegment = 64 << 10 // plaintext per sealed segment
+	sealedTag    = 0x01     // first byte of a sealed bolt value; JSON starts with '{'
+)
+
+// ErrUnknownKey means data was sealed under a node key the vault does not hold.
+var ErrUnknownKey = errors.New("sealed under an unknown node key")
+
+// File is an open fragment, decrypted on the fly when it is sealed.
+type File interface {
+	io.ReadSeekCloser
+	io.ReaderAt
+	Size() int64 // plaintext length
+}
+
+// Vault seals files and values under a node key. A nil *Vault stores
+// plaintext, and every method falls back to the plain behaviour.
+type Vault struct {
+	current []byte                 // ID of the key new data is sealed under
+	keys    map[string]cipher.AEAD // by key ID
+}
+
+// LoadKey reads a 32-byte key stored raw or as 64 hex digits.
+func LoadKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == fileKeySize {
+		return data, nil
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != fileKeySize {
+		return nil, fmt.Errorf("%s: want %d raw bytes or %d hex digits", path, fileKeySize, 2*fileKeySize)
+	}
+	return key, nil
+}
+
+// OpenVault seals new data under the key in keyFile and can still open
+// data sealed under any of oldKeyFiles. An empty keyFile means no vault.
+func OpenVault(keyFile string, oldKeyFiles []string) (*Vault, error) {
+	if keyFile == "" {
+		if len(oldKeyFiles) > 0 {
+			return nil, errors.New("old key files given without a current key file")
+		}
+		return nil, nil
+	}
+	v := &Vault{keys: make(map[string]cipher.AEAD)}
+	for i, path := range append([]string{keyFile}, oldKeyFiles...) {
+		key, err := LoadKey(path)
+		if err != nil {
+			return nil, err
+		}
+		id := keyID(key)
+		if i == 0 {
+			v.current = id
+		}
+		v.keys[string(id)] = newGCM(key)
+	}
+	return v, nil
+}
+
+// KeyID returns the hex ID of the current node key, "" without a vault.
+func (v *Vault) KeyID() string {
+	if v == nil {
+		return ""
+	}
+	return hex.EncodeToString(v.current)
+}
+
+func keyID(key []byte) []byte {
+	h := sha256.Sum256(append([]byte("avid-fp/node-key/v1"), key...))
+	return h[:keyIDSize]
+}
+
+/* ---------------- files ---------------- */
+
+// AtomicWrite is AtomicWrite with the file sealed.
+func (v *Vault) AtomicWrite(path string, data []byte, perm os.FileMode) error {
+	if v == nil {
+		return AtomicWrite(path, data, perm)
+	}
+	return v.AtomicWriteFrom(path, bytes.NewReader(data), perm, nil)
+}
+
+// AtomicWriteFrom is AtomicWriteFrom with the file sealed; check still
+// receives the plaintext length.
+func (v *Vault) AtomicWriteFrom(path string, r io.Reader, perm os.FileMode, check func(n int64) error) error {
+	if v == nil {
+		return AtomicWriteFrom(path, r, perm, check)
+	}
+	sr := v.sealReader(r)
+	return AtomicWriteFrom(path, sr, perm, func(int64) error {
+		if check == nil {
+			return nil
+		}
+		return check(sr.plain)
+	})
+}
+
+// ReadFile returns the plaintext of the file at path.
+func (v *Vault) ReadFile(path string) ([]byte, error) {
+	f, err := v.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf := make([]byte, f.Size())
+	if _, err := io.ReadFull(f, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Open opens the file at path for reading. Files written before the vault
+// was configured have no header and are read as they are.
+func (v *Vault) Open(path string) (File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if v == nil {
+		return &plainFile{File: f, size: info.Size()}, nil
+	}
+	var hdr [headerSize]byte
+	if _, err := f.ReadAt(hdr[:], 0); err != nil || string(hdr[:len(vaultMagic)]) != vaultMagic {
+		f.Seek(0, io.SeekStart)
+		return &plainFile{File: f, size: info.Size()}, nil
+	}
+	fileKey, err := v.unwrap(hdr[:])
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	sf := &sealedFile{f: f, aead: newGCM(fileKey), cached: -1}
+	if err := sf.layout(info.Size() - int64(headerSize)); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return sf, nil
+}
+
+// Rotate rewraps the file key of the file at path under the current node
+// key, sealing the file first if it is still plaintext. It reports whether
+// the file changed. Only the header is rewritten, in place.
+func (v *Vault) Rotate(path string) (bool, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	var hdr [headerSize]byte
+	if _, err := f.ReadAt(hdr[:], 0); err != nil || string(hdr[:len(vaultMagic)]) != vaultMagic {
+		info, err := f.Stat()
+		if err != nil {
+			return false, err
+		}
+		return true, v.AtomicWriteFrom(path, io.NewSectionReader(f, 0, info.Size()), info.Mode().Perm(), nil)
+	}
+	if bytes.Equal(hdr[len(vaultMagic):len(vaultMagic)+keyIDSize], v.current) {
+		return false, nil
+	}
+	fileKey, err := v.unwrap(hdr[:])
+	if err != nil {
+		return false, err
+	}
+	if _, err := f.WriteAt(v.wrap(fileKey), 0); err != nil {
+		return false, err
+	}
+	return true, f.Sync()
+}
+
+// wrap builds a file header carrying fileKey sealed under the current key.
+func (v *Vault) wrap(fileKey []byte) []byte {
+	aad := append([]byte(vaultMagic), v.current...)
+	nonce := random(nonceSize)
+	hdr := append(append([]byte(nil), aad...), nonce...)
+	return v.keys[string(v.current)].Seal(hdr, nonce, fileKey, aad)
+}
+
+func (v *Vault) unwrap(hdr []byte) ([]byte, error) {
+	id := hdr[len(vaultMagic) : len(vaultMagic)+keyIDSize]
+	aead := v.keys[string(id)]
+	if aead == nil {
+		return nil, fmt.Errorf("%w %x", ErrUnknownKey, id)
+	}
+	nonce := hdr[len(vaultMagic)+keyIDSize : len(vaultMagic)+keyIDSize+nonceSize]
+	fileKey, err := aead.Open(nil, nonce, hdr[len(vaultMagic)+keyIDSize+nonceSize:], hdr[:len(vaultMagic)+keyIDSize])
+	if err != nil {
+		return nil, errors.New("file key does not authenticate")
+	}
+	return fileKey, nil
+}
+
+// segmentNonce is the segment index plus a flag on the last segment, so
+// segments cannot be reordered or the file cut short unnoticed.
+func segmentNonce(i int64, final bool) []byte {
+	n := make([]byte, nonceSize)
+	binary.BigEndian.PutUint64(n, uint64(i))
+	if final {
+		n[8] = 1
+	}
+	return n
+}
+
+// sealReader yields the header and then the sealed segments of r.
+type sealReader struct {
+	r     io.Reader
+	aead  cipher.AEAD
+	idx   int64
+	buf   []byte // next segment plus one byte of lookahead
+	fill  int
+	out   []byte
+	plain int64
+	done  bool
+}
+
+func (v *Vault) sealReader(r io.Reader) *sealReader {
+	fileKey := random(fileKeySize)
+	return &sealReader{r: r, aead: newGCM(fileKey), buf: make([]byte, vaultSegment+1), out: v.wrap(fileKey)}
+}
+
+func (s *sealReader) Read(p []byte) (int, error) {
+	for len(s.out) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		k, err := io.ReadFull(s.r, s.buf[s.fill:])
+		s.fill += k
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, err
+		}
+		body := s.buf[:min(s.fill, vaultSegment)]
+		s.done = s.fill <= vaultSegment
+		s.out = s.aead.Seal(nil, segmentNonce(s.idx, s.done), body, nil)
+		s.plain += int64(len(body))
+		s.fill = copy(s.buf, s.buf[len(body):s.fill])
+		s.idx++
+	}
+	k := copy(p, s.out)
+	s.out = s.out[k:]
+	return k, nil
+}
+
+type plainFile struct {
+	*os.File
+	size int64
+}
+
+func (f *plainFile) Size() int64 { return f.size }
+
+// sealedFile decrypts a sealed file one segment at a time, keeping the last
+// segment it opened for sequential reads.
+type sealedFile struct {
+	f      *os.File
+	aead   cipher.AEAD
+	size   int64 // plaintext
+	last   int64 // index of the final segment
+	pos    int64
+	cached int64
+	plain  []byte
+	buf    []byte
+}
+
+// layout derives the plaintext size from the sealed body length.
+func (f *sealedFile) layout(body int64) error {
+	full, rem := body/(vaultSegment+tagSize), body%(vaultSegment+tagSize)
+	segs := full
+	if rem > 0 {
+		if rem < tagSize {
+			return errors.New("sealed file is truncated")
+		}
+		segs++
+	}
+	if segs == 0 {
+		return errors.New("sealed file has no segments")
+	}
+	f.size, f.last = body-segs*tagSize, segs-1
+	return nil
+}
+
+func (f *sealedFile) Size() int64  { return f.size }
+func (f *sealedFile) Close() error { return f.f.Close() }
+
+func (f *sealedFile) segment(i int64) ([]byte, error) {
+	if i == f.cached {
+		return f.plain, nil
+	}
+	off := int64(headerSize) + i*(vaultSegment+tagSize)
+	n := int64(vaultSegment + tagSize)
+	if i == f.last {
+		n = f.size - i*vaultSegment + tagSize
+	}
+	if cap(f.buf) < int(n) {
+		f.buf = make([]byte, vaultSegment+tagSize)
+	}
+	buf := f.buf[:n]
+	if _, err := f.f.ReadAt(buf, off); err != nil {
+		return nil, err
+	}
+	plain, err := f.aead.Open(f.plain[:0], segmentNonce(i, i == f.last), buf, nil)
+	if err != nil {
+		f.cached = -1
+		return nil, fmt.Errorf("segment %d does not authenticate", i)
+	}
+	f.plain, f.cached = plain, i
+	return plain, nil
+}
+
+func (f *sealedFile) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	n := 0
+	for n < len(p) {
+		if off >= f.size {
+			return n, io.EOF
+		}
+		seg, err := f.segment(off / vaultSegment)
+		if err != nil {
+			return n, err
+		}
+		k := copy(p[n:], seg[off%vaultSegment:])
+		n += k
+		off += int64(k)
+	}
+	return n, nil
+}
+
+func (f *sealedFile) Read(p []byte) (int, error) {
+	if len(p) > 0 && f.pos >= f.size {
+		return 0, io.EOF
+	}
+	n, err := f.ReadAt(p[:min(int64(len(p)), f.size-f.pos)], f.pos)
+	f.pos += int64(n)
+	return n, err
+}
+
+func (f *sealedFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.pos
+	case io.SeekEnd:
+		offset += f.size
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	f.pos = offset
+	return offset, nil
+}
+
+/* ---------------- bolt values ---------------- */
+
+// Seal encrypts value for storage under name, e.g. bucket + "/" + key;
+// opening it under any other name fails.
+func (v *Vault) Seal(name, value []byte) []byte {
+	if v == nil {
+		return value
+	}
+	out := append([]byte{sealedTag}, v.current...)
+	nonce := random(nonceSize)
+	out = append(out, nonce...)
+	return v.keys[string(v.current)].Seal(out, nonce, value, name)
+}
+
+// Unseal returns the plaintext of a value stored under name. Values written
+// before the vault was configured are returned as they are.
+func (v *Vault) Unseal(name, value []byte) ([]byte, error) {
+	if len(value) == 0 || value[0] != sealedTag {
+		return value, nil
+	}
+	if v == nil {
+		return nil, errors.New("value is sealed but no node key is configured")
+	}
+	if len(value) < 1+keyIDSize+nonceSize {
+		return nil, errors.New("sealed value is truncated")
+	}
+	id := value[1 : 1+keyIDSize]
+	aead := v.keys[string(id)]
+	if aead == nil {
+		return nil, fmt.Errorf("%w %x", ErrUnknownKey, id)
+	}
+	plain, err := aead.Open(nil, value[1+keyIDSize:1+keyIDSize+nonceSize], value[1+keyIDSize+nonceSize:], name)
+	if err != nil {
+		return nil, errors.New("sealed value does not authenticate")
+	}
+	return plain, nil
+}
+
+// Current reports whether value is sealed under the current node key.
+func (v *Vault) Current(value []byte) bool {
+	return len(value) > keyIDSize && value[0] == sealedTag && bytes.Equal(value[1:1+keyIDSize], v.current)
+}
+
+func newGCM(key []byte) cipher.AEAD {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err) // keys are always 32 bytes
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return aead
+}
+
+func random(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,191 @@
+// pkg/storage/vault_test.go
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func writeKey(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(random(fileKeySize))+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVaultFilesRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	v, err := OpenVault(writeKey(t, dir, "k1"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range []int{0, 1, vaultSegment - 1, vaultSegment, vaultSegment + 1, 3*vaultSegment + 17} {
+		data := make([]byte, size)
+		rand.Read(data)
+		path := filepath.Join(dir, "frag")
+		var got int64
+		if err := v.AtomicWriteFrom(path, bytes.NewReader(data), 0o600, func(n int64) error { got = n; return nil }); err != nil || got != int64(size) {
+			t.Fatalf("size %d: write %v, check saw %d bytes", size, err, got)
+		}
+		raw, _ := os.ReadFile(path)
+		if size > 16 && bytes.Contains(raw, data[:16]) {
+			t.Fatalf("size %d: plaintext on disk", size)
+		}
+		back, err := v.ReadFile(path)
+		if err != nil || !bytes.Equal(back, data) {
+			t.Fatalf("size %d: read back %v", size, err)
+		}
+
+		f, err := v.Open(path)
+		if err != nil || f.Size() != int64(size) {
+			t.Fatalf("size %d: Open %v, Size %d", size, err, f.Size())
+		}
+		if size > 10 {
+			off := int64(size / 2)
+			buf := make([]byte, size-int(off))
+			if _, err := f.ReadAt(buf, off); err != nil || !bytes.Equal(buf, data[off:]) {
+				t.Errorf("size %d: ReadAt(%d): %v", size, off, err)
+			}
+			f.Seek(off, io.SeekStart)
+			if rest, err := io.ReadAll(f); err != nil || !bytes.Equal(rest, data[off:]) {
+				t.Errorf("size %d: Seek+Read: %v", size, err)
+			}
+		}
+		f.Close()
+	}
+}
+
+func TestVaultDetectsTampering(t *testing.T) {
+	dir := t.TempDir()
+	v, _ := OpenVault(writeKey(t, dir, "k1"), nil)
+	path := filepath.Join(dir, "frag")
+	data := make([]byte, 2*vaultSegment+5)
+	v.AtomicWrite(path, data, 0o600)
+	raw, _ := os.ReadFile(path)
+
+	flipped := bytes.Clone(raw)
+	flipped[headerSize+vaultSegment+100] ^= 1
+	os.WriteFile(path, flipped, 0o600)
+	if _, err := v.ReadFile(path); err == nil {
+		t.Errorf("flipped bit went unnoticed")
+	}
+	os.WriteFile(path, raw[:headerSize+2*(vaultSegment+tagSize)], 0o600) // drop the last segment
+	if _, err := v.ReadFile(path); err == nil {
+		t.Errorf("truncation went unnoticed")
+	}
+
+	other, _ := OpenVault(writeKey(t, dir, "k2"), nil)
+	os.WriteFile(path, raw, 0o600)
+	if _, err := other.ReadFile(path); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("foreign key: %v", err)
+	}
+}
+
+func TestVaultRotation(t *testing.T) {
+	dir := t.TempDir()
+	k1, k2 := writeKey(t, dir, "k1"), writeKey(t, dir, "k2")
+	old, _ := OpenVault(k1, nil)
+	sealed, plain := filepath.Join(dir, "sealed"), filepath.Join(dir, "plain")
+	data := []byte("fragment bytes, long enough to be worth reading back")
+	old.AtomicWrite(sealed, data, 0o600)
+	os.WriteFile(plain, data, 0o600) // written before the vault existed
+	value := old.Seal([]byte("fpccs/obj"), []byte(`{"seed":1}`))
+
+	v, err := OpenVault(k2, []string{k1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back, err := v.ReadFile(plain); err != nil || !bytes.Equal(back, data) {
+		t.Fatalf("legacy plaintext: %v", err)
+	}
+	for _, path := range []string{sealed, plain} {
+		if changed, err := v.Rotate(path); err != nil || !changed {
+			t.Fatalf("Rotate(%s) = %v, %v", filepath.Base(path), changed, err)
+		}
+		if changed, _ := v.Rotate(path); changed {
+			t.Errorf("second Rotate(%s) changed the file", filepath.Base(path))
+		}
+	}
+
+	// with k1 gone, everything must open under k2 alone
+	fresh, _ := OpenVault(k2, nil)
+	for _, path := range []string{sealed, plain} {
+		if back, err := fresh.ReadFile(path); err != nil || !bytes.Equal(back, data) {
+			t.Errorf("%s after rotation: %v", filepath.Base(path), err)
+		}
+	}
+	if _, err := fresh.Unseal([]byte("fpccs/obj"), value); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("value under retired key: %v", err)
+	}
+	if v.Current(value) {
+		t.Errorf("value under k1 reported current")
+	}
+	if got, err := v.Unseal([]byte("fpccs/obj"), v.Seal([]byte("fpccs/obj"), []byte("x"))); err != nil || string(got) != "x" {
+		t.Errorf("value round trip: %q %v", got, err)
+	}
+	if _, err := v.Unseal([]byte("fpccs/other"), value); err == nil {
+		t.Errorf("value opened under another name")
+	}
+	if got, _ := v.Unseal([]byte("fpccs/obj"), []byte(`{"seed":1}`)); string(got) != `{"seed":1}` {
+		t.Errorf("legacy plaintext value changed: %q", got)
+	}
+}
+
+func TestConcurrentAtomicWrites(t *testing.T) {
+	dir := t.TempDir()
+	v, err := OpenVault(writeKey(t, dir, "k1"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "frag")
+	bodies := make([][]byte, 8)
+	for i := range bodies {
+		bodies[i] = bytes.Repeat([]byte{byte(i)}, 3*vaultSegment)
+	}
+
+	// every writer is mid‑write before any renames, so with a shared
+	// temporary file they would truncate and rename each other's
+	var wg, started sync.WaitGroup
+	release := make(chan struct{})
+	errs := make([]error, len(bodies))
+	for i, body := range bodies {
+		wg.Add(1)
+		started.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = v.AtomicWriteFrom(path, bytes.NewReader(body), 0o600, func(int64) error {
+				started.Done()
+				<-release
+				return nil
+			})
+		}()
+	}
+	started.Wait()
+	close(release)
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("writer %d: %v", i, err)
+		}
+	}
+
+	got, err := v.ReadFile(path)
+	if err != nil || !bytes.Equal(got, bytes.Repeat(got[:1], len(got))) || len(got) != 3*vaultSegment {
+		t.Fatalf("file mixes writers or is cut short: %v", err)
+	}
+	if left, _ := filepath.Glob(path + ".*.tmp"); len(left) > 0 {
+		t.Fatalf("temporary files left behind: %v", left)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Fatalf("mode %v, want 0600", info.Mode().Perm())
+	}
+}